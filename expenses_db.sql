@@ -21,6 +21,7 @@ CREATE TABLE public."user"
     profile_pic character varying,
     birthday    date,
     created_at  timestamp with time zone,
+    is_guest    boolean               NOT NULL DEFAULT false,
     CONSTRAINT user_pk PRIMARY KEY (id),
     CONSTRAINT username_un UNIQUE (username),
     CONSTRAINT email_un UNIQUE (email)
@@ -31,12 +32,17 @@ CREATE TABLE public."user"
 DROP TABLE IF EXISTS public.trip CASCADE;
 CREATE TABLE public.trip
 (
-    id          uuid NOT NULL DEFAULT uuid_generate_v4(),
-    name        character varying,
-    description character varying,
-    location    character varying,
-    start_date  date NOT NULL,
-    end_date    date NOT NULL,
+    id                 uuid NOT NULL DEFAULT uuid_generate_v4(),
+    name               character varying,
+    description        character varying,
+    location           character varying,
+    start_date         date NOT NULL,
+    end_date           date NOT NULL,
+    budget             numeric,
+    approval_threshold numeric,
+    status             character varying NOT NULL DEFAULT 'active',
+    closed_at          timestamp with time zone,
+    deleted_at         timestamp with time zone,
     CONSTRAINT travel_pk PRIMARY KEY (id)
 );
 -- ddl-end --
@@ -61,11 +67,16 @@ CREATE TABLE public.token
 DROP TABLE IF EXISTS public.user_trip_association CASCADE;
 CREATE TABLE public.user_trip_association
 (
-    presence_start_date date    NOT NULL,
-    presence_end_date   date    NOT NULL,
-    is_accepted         boolean NOT NULL,
-    id_user             uuid    NOT NULL,
-    id_trip             uuid    NOT NULL,
+    presence_start_date date                     NOT NULL,
+    presence_end_date   date                     NOT NULL,
+    is_accepted         boolean                  NOT NULL,
+    role                character varying        NOT NULL DEFAULT 'member',
+    invited_by          uuid,
+    invited_at          timestamp with time zone,
+    invite_expires_at   timestamp with time zone,
+    invite_reminded_at  timestamp with time zone,
+    id_user             uuid                     NOT NULL,
+    id_trip             uuid                     NOT NULL,
     CONSTRAINT many_user_has_many_travel_pk PRIMARY KEY (id_user, id_trip)
 );
 -- ddl-end --
@@ -80,11 +91,20 @@ CREATE TABLE public.cost_category
     icon        character varying,
     color       character varying,
     id_trip     uuid NOT NULL,
-    CONSTRAINT cost_category_pk PRIMARY KEY (id),
-    CONSTRAINT cost_category_un UNIQUE (name, id_trip)
+    id_parent   uuid,
+    budget      numeric,
+    alert_threshold_percent integer,
+    deleted_at  timestamp with time zone,
+    CONSTRAINT cost_category_pk PRIMARY KEY (id)
 );
 -- ddl-end --
 
+-- object: cost_category_un | type: INDEX --
+-- Names only have to be unique among the cost categories of a trip that are not in the trash
+DROP INDEX IF EXISTS public.cost_category_un CASCADE;
+CREATE UNIQUE INDEX cost_category_un ON public.cost_category (name, id_trip) WHERE deleted_at IS NULL;
+-- ddl-end --
+
 -- object: public.cost | type: TABLE --
 DROP TABLE IF EXISTS public.cost CASCADE;
 CREATE TABLE public.cost
@@ -95,7 +115,14 @@ CREATE TABLE public.cost
     created_at       timestamp with time zone,
     deducted_at      date,
     end_date         date,
+    is_private       boolean NOT NULL DEFAULT false,
+    paid_from_kitty  boolean NOT NULL DEFAULT false,
+    split_by_presence boolean NOT NULL DEFAULT false,
     id_cost_category uuid NOT NULL,
+    created_by       uuid,
+    updated_by       uuid,
+    status           character varying NOT NULL DEFAULT 'approved',
+    deleted_at       timestamp with time zone,
     CONSTRAINT cost_pk PRIMARY KEY (id)
 );
 -- ddl-end --
@@ -124,6 +151,8 @@ CREATE TABLE public.transaction
     created_at    timestamp with time zone,
     currency_code character varying,
     is_confirmed  boolean NOT NULL,
+    type          character varying NOT NULL DEFAULT 'payment',
+    note          character varying,
     CONSTRAINT transaction_pk PRIMARY KEY (id)
 );
 -- ddl-end --
@@ -145,6 +174,256 @@ CREATE TABLE public.debt
 );
 -- ddl-end --
 
+-- object: public.planned_cost | type: TABLE --
+DROP TABLE IF EXISTS public.planned_cost CASCADE;
+CREATE TABLE public.planned_cost
+(
+    id               uuid    NOT NULL DEFAULT uuid_generate_v4(),
+    amount           numeric NOT NULL,
+    description      character varying,
+    created_at       timestamp with time zone,
+    id_cost_category uuid    NOT NULL,
+    CONSTRAINT planned_cost_pk PRIMARY KEY (id)
+);
+-- ddl-end --
+
+-- object: public.user_planned_cost_association | type: TABLE --
+DROP TABLE IF EXISTS public.user_planned_cost_association CASCADE;
+CREATE TABLE public.user_planned_cost_association
+(
+    id_user         uuid    NOT NULL,
+    id_planned_cost uuid    NOT NULL,
+    amount          numeric NOT NULL,
+    CONSTRAINT user_planned_cost_association_pk PRIMARY KEY (id_user, id_planned_cost)
+);
+-- ddl-end --
+
+-- object: public.planned_cost_link | type: TABLE --
+DROP TABLE IF EXISTS public.planned_cost_link CASCADE;
+CREATE TABLE public.planned_cost_link
+(
+    id_planned_cost uuid NOT NULL,
+    id_cost         uuid NOT NULL,
+    CONSTRAINT planned_cost_link_pk PRIMARY KEY (id_planned_cost, id_cost),
+    CONSTRAINT planned_cost_link_cost_un UNIQUE (id_cost)
+);
+-- ddl-end --
+
+-- object: public.kitty | type: TABLE --
+DROP TABLE IF EXISTS public.kitty CASCADE;
+CREATE TABLE public.kitty
+(
+    id         uuid NOT NULL DEFAULT uuid_generate_v4(),
+    id_trip    uuid NOT NULL,
+    id_user    uuid NOT NULL,
+    created_at timestamp with time zone,
+    settled_at timestamp with time zone,
+    CONSTRAINT kitty_pk PRIMARY KEY (id),
+    CONSTRAINT kitty_un UNIQUE (id_trip),
+    CONSTRAINT kitty_user_un UNIQUE (id_user)
+);
+-- ddl-end --
+
+-- object: public.kitty_entry | type: TABLE --
+DROP TABLE IF EXISTS public.kitty_entry CASCADE;
+CREATE TABLE public.kitty_entry
+(
+    id          uuid    NOT NULL DEFAULT uuid_generate_v4(),
+    id_kitty    uuid    NOT NULL,
+    id_user     uuid    NOT NULL,
+    amount      numeric NOT NULL,
+    description character varying,
+    created_at  timestamp with time zone,
+    CONSTRAINT kitty_entry_pk PRIMARY KEY (id)
+);
+-- ddl-end --
+
+-- object: public.cost_suggestion | type: TABLE --
+DROP TABLE IF EXISTS public.cost_suggestion CASCADE;
+CREATE TABLE public.cost_suggestion
+(
+    id         uuid                     NOT NULL DEFAULT uuid_generate_v4(),
+    id_cost    uuid                     NOT NULL,
+    id_user    uuid                     NOT NULL,
+    changes    jsonb                    NOT NULL,
+    comment    character varying,
+    status     character varying        NOT NULL DEFAULT 'pending',
+    created_at timestamp with time zone NOT NULL,
+    decided_at timestamp with time zone,
+    decided_by uuid,
+    CONSTRAINT cost_suggestion_pk PRIMARY KEY (id)
+);
+-- ddl-end --
+
+-- object: public.cost_approval | type: TABLE --
+DROP TABLE IF EXISTS public.cost_approval CASCADE;
+CREATE TABLE public.cost_approval
+(
+    id_cost    uuid                     NOT NULL,
+    id_user    uuid                     NOT NULL,
+    status     character varying        NOT NULL DEFAULT 'pending',
+    decided_at timestamp with time zone,
+    CONSTRAINT cost_approval_pk PRIMARY KEY (id_cost, id_user)
+);
+-- ddl-end --
+
+-- object: public.trip_invite | type: TABLE --
+DROP TABLE IF EXISTS public.trip_invite CASCADE;
+CREATE TABLE public.trip_invite
+(
+    id          uuid                     NOT NULL DEFAULT uuid_generate_v4(),
+    id_trip     uuid                     NOT NULL,
+    id_inviter  uuid                     NOT NULL,
+    id_guest    uuid,
+    email       character varying,
+    token       character varying        NOT NULL,
+    role        character varying        NOT NULL DEFAULT 'member',
+    created_at  timestamp with time zone NOT NULL,
+    expires_at  timestamp with time zone NOT NULL,
+    accepted_at timestamp with time zone,
+    revoked_at  timestamp with time zone,
+    CONSTRAINT trip_invite_pk PRIMARY KEY (id),
+    CONSTRAINT trip_invite_token_uq UNIQUE (token)
+);
+-- ddl-end --
+
+-- object: public.trip_template | type: TABLE --
+DROP TABLE IF EXISTS public.trip_template CASCADE;
+CREATE TABLE public.trip_template
+(
+    id                 uuid                     NOT NULL DEFAULT uuid_generate_v4(),
+    id_user            uuid                     NOT NULL,
+    name               character varying        NOT NULL,
+    description        character varying,
+    location           character varying,
+    budget             numeric,
+    approval_threshold numeric,
+    created_at         timestamp with time zone NOT NULL,
+    CONSTRAINT trip_template_pk PRIMARY KEY (id)
+);
+-- ddl-end --
+
+-- object: public.trip_template_cost_category | type: TABLE --
+DROP TABLE IF EXISTS public.trip_template_cost_category CASCADE;
+CREATE TABLE public.trip_template_cost_category
+(
+    id                      uuid              NOT NULL DEFAULT uuid_generate_v4(),
+    id_trip_template        uuid              NOT NULL,
+    name                    character varying NOT NULL,
+    description             character varying,
+    icon                    character varying,
+    color                   character varying,
+    budget                  numeric,
+    alert_threshold_percent integer,
+    parent_name             character varying,
+    CONSTRAINT trip_template_cost_category_pk PRIMARY KEY (id)
+);
+-- ddl-end --
+
+-- object: public.trip_template_participant | type: TABLE --
+DROP TABLE IF EXISTS public.trip_template_participant CASCADE;
+CREATE TABLE public.trip_template_participant
+(
+    id_trip_template uuid              NOT NULL,
+    id_user          uuid              NOT NULL,
+    role             character varying NOT NULL DEFAULT 'member',
+    CONSTRAINT trip_template_participant_pk PRIMARY KEY (id_trip_template, id_user)
+);
+-- ddl-end --
+
+-- object: public.cost_tag | type: TABLE --
+DROP TABLE IF EXISTS public.cost_tag CASCADE;
+CREATE TABLE public.cost_tag
+(
+    id_cost uuid              NOT NULL,
+    tag     character varying NOT NULL,
+    CONSTRAINT cost_tag_pk PRIMARY KEY (id_cost, tag)
+);
+-- ddl-end --
+
+-- object: public.cost_category_rule | type: TABLE --
+DROP TABLE IF EXISTS public.cost_category_rule CASCADE;
+CREATE TABLE public.cost_category_rule
+(
+    id               uuid    NOT NULL DEFAULT uuid_generate_v4(),
+    id_trip          uuid    NOT NULL,
+    id_cost_category uuid    NOT NULL,
+    priority         integer NOT NULL DEFAULT 0,
+    min_amount       numeric,
+    max_amount       numeric,
+    created_at       timestamp with time zone,
+    CONSTRAINT cost_category_rule_pk PRIMARY KEY (id)
+);
+-- ddl-end --
+
+-- object: public.cost_category_rule_keyword | type: TABLE --
+DROP TABLE IF EXISTS public.cost_category_rule_keyword CASCADE;
+CREATE TABLE public.cost_category_rule_keyword
+(
+    id_cost_category_rule uuid              NOT NULL,
+    keyword               character varying NOT NULL,
+    CONSTRAINT cost_category_rule_keyword_pk PRIMARY KEY (id_cost_category_rule, keyword)
+);
+-- ddl-end --
+
+-- object: public.cost_category_rule_debtor | type: TABLE --
+DROP TABLE IF EXISTS public.cost_category_rule_debtor CASCADE;
+CREATE TABLE public.cost_category_rule_debtor
+(
+    id_cost_category_rule uuid NOT NULL,
+    id_user               uuid NOT NULL,
+    CONSTRAINT cost_category_rule_debtor_pk PRIMARY KEY (id_cost_category_rule, id_user)
+);
+-- ddl-end --
+
+-- object: public.cost_comment | type: TABLE --
+DROP TABLE IF EXISTS public.cost_comment CASCADE;
+CREATE TABLE public.cost_comment
+(
+    id         uuid                     NOT NULL DEFAULT uuid_generate_v4(),
+    id_cost    uuid                     NOT NULL,
+    id_user    uuid                     NOT NULL,
+    content    character varying        NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    CONSTRAINT cost_comment_pk PRIMARY KEY (id)
+);
+-- ddl-end --
+
+-- object: public.cost_version | type: TABLE --
+DROP TABLE IF EXISTS public.cost_version CASCADE;
+CREATE TABLE public.cost_version
+(
+    id         uuid                     NOT NULL DEFAULT uuid_generate_v4(),
+    id_cost    uuid                     NOT NULL,
+    version    integer                  NOT NULL,
+    snapshot   jsonb                    NOT NULL,
+    created_by uuid,
+    created_at timestamp with time zone NOT NULL,
+    CONSTRAINT cost_version_pk PRIMARY KEY (id),
+    CONSTRAINT cost_version_uq UNIQUE (id_cost, version)
+);
+-- ddl-end --
+
+-- object: public.trip_activity | type: TABLE --
+DROP TABLE IF EXISTS public.trip_activity CASCADE;
+CREATE TABLE public.trip_activity
+(
+    id         uuid                     NOT NULL DEFAULT uuid_generate_v4(),
+    id_trip    uuid                     NOT NULL,
+    id_user    uuid,
+    type       varchar(32)              NOT NULL,
+    id_subject uuid,
+    details    jsonb,
+    created_at timestamp with time zone NOT NULL,
+    CONSTRAINT trip_activity_pk PRIMARY KEY (id)
+);
+-- ddl-end --
+
+-- object: trip_activity_trip_idx | type: INDEX --
+DROP INDEX IF EXISTS public.trip_activity_trip_idx CASCADE;
+CREATE INDEX trip_activity_trip_idx ON public.trip_activity (id_trip, created_at DESC);
+-- ddl-end --
+
 
 -- object: user_fk | type: CONSTRAINT --
 -- ALTER TABLE public.token DROP CONSTRAINT IF EXISTS user_fk CASCADE;
@@ -247,6 +526,304 @@ ALTER TABLE public.debt
     ADD CONSTRAINT debitor_fk FOREIGN KEY (id_debtor)
         REFERENCES public."user" (id) MATCH FULL
         ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: cost_category_fk | type: CONSTRAINT --
+-- ALTER TABLE public.planned_cost DROP CONSTRAINT IF EXISTS cost_category_fk CASCADE;
+ALTER TABLE public.planned_cost
+    ADD CONSTRAINT cost_category_fk FOREIGN KEY (id_cost_category)
+        REFERENCES public.cost_category (id) MATCH FULL
+        ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: user_fk | type: CONSTRAINT --
+-- ALTER TABLE public.user_planned_cost_association DROP CONSTRAINT IF EXISTS user_fk CASCADE;
+ALTER TABLE public.user_planned_cost_association
+    ADD CONSTRAINT user_fk FOREIGN KEY (id_user)
+        REFERENCES public."user" (id) MATCH FULL
+        ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: planned_cost_fk | type: CONSTRAINT --
+-- ALTER TABLE public.user_planned_cost_association DROP CONSTRAINT IF EXISTS planned_cost_fk CASCADE;
+ALTER TABLE public.user_planned_cost_association
+    ADD CONSTRAINT planned_cost_fk FOREIGN KEY (id_planned_cost)
+        REFERENCES public.planned_cost (id) MATCH FULL
+        ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: planned_cost_fk | type: CONSTRAINT --
+-- ALTER TABLE public.planned_cost_link DROP CONSTRAINT IF EXISTS planned_cost_fk CASCADE;
+ALTER TABLE public.planned_cost_link
+    ADD CONSTRAINT planned_cost_fk FOREIGN KEY (id_planned_cost)
+        REFERENCES public.planned_cost (id) MATCH FULL
+        ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: cost_fk | type: CONSTRAINT --
+-- ALTER TABLE public.planned_cost_link DROP CONSTRAINT IF EXISTS cost_fk CASCADE;
+ALTER TABLE public.planned_cost_link
+    ADD CONSTRAINT cost_fk FOREIGN KEY (id_cost)
+        REFERENCES public.cost (id) MATCH FULL
+        ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: trip_fk | type: CONSTRAINT --
+-- ALTER TABLE public.kitty DROP CONSTRAINT IF EXISTS trip_fk CASCADE;
+ALTER TABLE public.kitty
+    ADD CONSTRAINT trip_fk FOREIGN KEY (id_trip)
+        REFERENCES public.trip (id) MATCH FULL
+        ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: user_fk | type: CONSTRAINT --
+-- ALTER TABLE public.kitty DROP CONSTRAINT IF EXISTS user_fk CASCADE;
+ALTER TABLE public.kitty
+    ADD CONSTRAINT user_fk FOREIGN KEY (id_user)
+        REFERENCES public."user" (id) MATCH FULL
+        ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: kitty_fk | type: CONSTRAINT --
+-- ALTER TABLE public.kitty_entry DROP CONSTRAINT IF EXISTS kitty_fk CASCADE;
+ALTER TABLE public.kitty_entry
+    ADD CONSTRAINT kitty_fk FOREIGN KEY (id_kitty)
+        REFERENCES public.kitty (id) MATCH FULL
+        ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: user_fk | type: CONSTRAINT --
+-- ALTER TABLE public.kitty_entry DROP CONSTRAINT IF EXISTS user_fk CASCADE;
+ALTER TABLE public.kitty_entry
+    ADD CONSTRAINT user_fk FOREIGN KEY (id_user)
+        REFERENCES public."user" (id) MATCH FULL
+        ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+
+-- object: cost_created_by_fk | type: CONSTRAINT --
+-- ALTER TABLE public.cost DROP CONSTRAINT IF EXISTS cost_created_by_fk CASCADE;
+ALTER TABLE public.cost
+    ADD CONSTRAINT cost_created_by_fk FOREIGN KEY (created_by)
+        REFERENCES public."user" (id) MATCH FULL
+        ON DELETE SET NULL ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: cost_updated_by_fk | type: CONSTRAINT --
+-- ALTER TABLE public.cost DROP CONSTRAINT IF EXISTS cost_updated_by_fk CASCADE;
+ALTER TABLE public.cost
+    ADD CONSTRAINT cost_updated_by_fk FOREIGN KEY (updated_by)
+        REFERENCES public."user" (id) MATCH FULL
+        ON DELETE SET NULL ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: cost_suggestion_cost_fk | type: CONSTRAINT --
+-- ALTER TABLE public.cost_suggestion DROP CONSTRAINT IF EXISTS cost_suggestion_cost_fk CASCADE;
+ALTER TABLE public.cost_suggestion
+    ADD CONSTRAINT cost_suggestion_cost_fk FOREIGN KEY (id_cost)
+        REFERENCES public.cost (id) MATCH FULL
+        ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: cost_suggestion_user_fk | type: CONSTRAINT --
+-- ALTER TABLE public.cost_suggestion DROP CONSTRAINT IF EXISTS cost_suggestion_user_fk CASCADE;
+ALTER TABLE public.cost_suggestion
+    ADD CONSTRAINT cost_suggestion_user_fk FOREIGN KEY (id_user)
+        REFERENCES public."user" (id) MATCH FULL
+        ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: cost_suggestion_decided_by_fk | type: CONSTRAINT --
+-- ALTER TABLE public.cost_suggestion DROP CONSTRAINT IF EXISTS cost_suggestion_decided_by_fk CASCADE;
+ALTER TABLE public.cost_suggestion
+    ADD CONSTRAINT cost_suggestion_decided_by_fk FOREIGN KEY (decided_by)
+        REFERENCES public."user" (id) MATCH FULL
+        ON DELETE SET NULL ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: cost_approval_cost_fk | type: CONSTRAINT --
+-- ALTER TABLE public.cost_approval DROP CONSTRAINT IF EXISTS cost_approval_cost_fk CASCADE;
+ALTER TABLE public.cost_approval
+    ADD CONSTRAINT cost_approval_cost_fk FOREIGN KEY (id_cost)
+        REFERENCES public.cost (id) MATCH FULL
+        ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: cost_approval_user_fk | type: CONSTRAINT --
+-- ALTER TABLE public.cost_approval DROP CONSTRAINT IF EXISTS cost_approval_user_fk CASCADE;
+ALTER TABLE public.cost_approval
+    ADD CONSTRAINT cost_approval_user_fk FOREIGN KEY (id_user)
+        REFERENCES public."user" (id) MATCH FULL
+        ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: trip_invite_trip_fk | type: CONSTRAINT --
+-- ALTER TABLE public.trip_invite DROP CONSTRAINT IF EXISTS trip_invite_trip_fk CASCADE;
+ALTER TABLE public.trip_invite
+    ADD CONSTRAINT trip_invite_trip_fk FOREIGN KEY (id_trip)
+        REFERENCES public.trip (id) MATCH FULL
+        ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: trip_invite_user_fk | type: CONSTRAINT --
+-- ALTER TABLE public.trip_invite DROP CONSTRAINT IF EXISTS trip_invite_user_fk CASCADE;
+ALTER TABLE public.trip_invite
+    ADD CONSTRAINT trip_invite_user_fk FOREIGN KEY (id_inviter)
+        REFERENCES public."user" (id) MATCH FULL
+        ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: user_trip_association_inviter_fk | type: CONSTRAINT --
+-- ALTER TABLE public.user_trip_association DROP CONSTRAINT IF EXISTS user_trip_association_inviter_fk CASCADE;
+ALTER TABLE public.user_trip_association
+    ADD CONSTRAINT user_trip_association_inviter_fk FOREIGN KEY (invited_by)
+        REFERENCES public."user" (id) MATCH FULL
+        ON DELETE SET NULL ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: trip_invite_guest_fk | type: CONSTRAINT --
+-- ALTER TABLE public.trip_invite DROP CONSTRAINT IF EXISTS trip_invite_guest_fk CASCADE;
+ALTER TABLE public.trip_invite
+    ADD CONSTRAINT trip_invite_guest_fk FOREIGN KEY (id_guest)
+        REFERENCES public."user" (id) MATCH FULL
+        ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: trip_template_user_fk | type: CONSTRAINT --
+-- ALTER TABLE public.trip_template DROP CONSTRAINT IF EXISTS trip_template_user_fk CASCADE;
+ALTER TABLE public.trip_template
+    ADD CONSTRAINT trip_template_user_fk FOREIGN KEY (id_user)
+        REFERENCES public."user" (id) MATCH FULL
+        ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: trip_template_cost_category_template_fk | type: CONSTRAINT --
+-- ALTER TABLE public.trip_template_cost_category DROP CONSTRAINT IF EXISTS trip_template_cost_category_template_fk CASCADE;
+ALTER TABLE public.trip_template_cost_category
+    ADD CONSTRAINT trip_template_cost_category_template_fk FOREIGN KEY (id_trip_template)
+        REFERENCES public.trip_template (id) MATCH FULL
+        ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: trip_template_participant_template_fk | type: CONSTRAINT --
+-- ALTER TABLE public.trip_template_participant DROP CONSTRAINT IF EXISTS trip_template_participant_template_fk CASCADE;
+ALTER TABLE public.trip_template_participant
+    ADD CONSTRAINT trip_template_participant_template_fk FOREIGN KEY (id_trip_template)
+        REFERENCES public.trip_template (id) MATCH FULL
+        ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: trip_template_participant_user_fk | type: CONSTRAINT --
+-- ALTER TABLE public.trip_template_participant DROP CONSTRAINT IF EXISTS trip_template_participant_user_fk CASCADE;
+ALTER TABLE public.trip_template_participant
+    ADD CONSTRAINT trip_template_participant_user_fk FOREIGN KEY (id_user)
+        REFERENCES public."user" (id) MATCH FULL
+        ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: cost_category_parent_fk | type: CONSTRAINT --
+-- ALTER TABLE public.cost_category DROP CONSTRAINT IF EXISTS cost_category_parent_fk CASCADE;
+ALTER TABLE public.cost_category
+    ADD CONSTRAINT cost_category_parent_fk FOREIGN KEY (id_parent)
+        REFERENCES public.cost_category (id) MATCH FULL
+        ON DELETE SET NULL ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: cost_tag_cost_fk | type: CONSTRAINT --
+-- ALTER TABLE public.cost_tag DROP CONSTRAINT IF EXISTS cost_tag_cost_fk CASCADE;
+ALTER TABLE public.cost_tag
+    ADD CONSTRAINT cost_tag_cost_fk FOREIGN KEY (id_cost)
+        REFERENCES public.cost (id) MATCH FULL
+        ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: cost_category_rule_trip_fk | type: CONSTRAINT --
+-- ALTER TABLE public.cost_category_rule DROP CONSTRAINT IF EXISTS cost_category_rule_trip_fk CASCADE;
+ALTER TABLE public.cost_category_rule
+    ADD CONSTRAINT cost_category_rule_trip_fk FOREIGN KEY (id_trip)
+        REFERENCES public.trip (id) MATCH FULL
+        ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: cost_category_rule_cost_category_fk | type: CONSTRAINT --
+-- ALTER TABLE public.cost_category_rule DROP CONSTRAINT IF EXISTS cost_category_rule_cost_category_fk CASCADE;
+ALTER TABLE public.cost_category_rule
+    ADD CONSTRAINT cost_category_rule_cost_category_fk FOREIGN KEY (id_cost_category)
+        REFERENCES public.cost_category (id) MATCH FULL
+        ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: cost_category_rule_keyword_rule_fk | type: CONSTRAINT --
+-- ALTER TABLE public.cost_category_rule_keyword DROP CONSTRAINT IF EXISTS cost_category_rule_keyword_rule_fk CASCADE;
+ALTER TABLE public.cost_category_rule_keyword
+    ADD CONSTRAINT cost_category_rule_keyword_rule_fk FOREIGN KEY (id_cost_category_rule)
+        REFERENCES public.cost_category_rule (id) MATCH FULL
+        ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: cost_category_rule_debtor_rule_fk | type: CONSTRAINT --
+-- ALTER TABLE public.cost_category_rule_debtor DROP CONSTRAINT IF EXISTS cost_category_rule_debtor_rule_fk CASCADE;
+ALTER TABLE public.cost_category_rule_debtor
+    ADD CONSTRAINT cost_category_rule_debtor_rule_fk FOREIGN KEY (id_cost_category_rule)
+        REFERENCES public.cost_category_rule (id) MATCH FULL
+        ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: cost_category_rule_debtor_user_fk | type: CONSTRAINT --
+-- ALTER TABLE public.cost_category_rule_debtor DROP CONSTRAINT IF EXISTS cost_category_rule_debtor_user_fk CASCADE;
+ALTER TABLE public.cost_category_rule_debtor
+    ADD CONSTRAINT cost_category_rule_debtor_user_fk FOREIGN KEY (id_user)
+        REFERENCES public."user" (id) MATCH FULL
+        ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: cost_comment_cost_fk | type: CONSTRAINT --
+-- ALTER TABLE public.cost_comment DROP CONSTRAINT IF EXISTS cost_comment_cost_fk CASCADE;
+ALTER TABLE public.cost_comment
+    ADD CONSTRAINT cost_comment_cost_fk FOREIGN KEY (id_cost)
+        REFERENCES public.cost (id) MATCH FULL
+        ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: cost_comment_user_fk | type: CONSTRAINT --
+-- ALTER TABLE public.cost_comment DROP CONSTRAINT IF EXISTS cost_comment_user_fk CASCADE;
+ALTER TABLE public.cost_comment
+    ADD CONSTRAINT cost_comment_user_fk FOREIGN KEY (id_user)
+        REFERENCES public."user" (id) MATCH FULL
+        ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: cost_version_cost_fk | type: CONSTRAINT --
+-- ALTER TABLE public.cost_version DROP CONSTRAINT IF EXISTS cost_version_cost_fk CASCADE;
+ALTER TABLE public.cost_version
+    ADD CONSTRAINT cost_version_cost_fk FOREIGN KEY (id_cost)
+        REFERENCES public.cost (id) MATCH FULL
+        ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: cost_version_user_fk | type: CONSTRAINT --
+-- ALTER TABLE public.cost_version DROP CONSTRAINT IF EXISTS cost_version_user_fk CASCADE;
+ALTER TABLE public.cost_version
+    ADD CONSTRAINT cost_version_user_fk FOREIGN KEY (created_by)
+        REFERENCES public."user" (id) MATCH FULL
+        ON DELETE SET NULL ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: trip_activity_trip_fk | type: CONSTRAINT --
+-- ALTER TABLE public.trip_activity DROP CONSTRAINT IF EXISTS trip_activity_trip_fk CASCADE;
+ALTER TABLE public.trip_activity
+    ADD CONSTRAINT trip_activity_trip_fk FOREIGN KEY (id_trip)
+        REFERENCES public.trip (id) MATCH FULL
+        ON DELETE CASCADE ON UPDATE CASCADE;
+-- ddl-end --
+
+-- object: trip_activity_user_fk | type: CONSTRAINT --
+-- ALTER TABLE public.trip_activity DROP CONSTRAINT IF EXISTS trip_activity_user_fk CASCADE;
+ALTER TABLE public.trip_activity
+    ADD CONSTRAINT trip_activity_user_fk FOREIGN KEY (id_user)
+        REFERENCES public."user" (id) MATCH FULL
+        ON DELETE SET NULL ON UPDATE CASCADE;
+-- ddl-end --
 
 -- object: "grant_CU_26541e8cda" | type: PERMISSION --
 GRANT CREATE, USAGE
@@ -6,11 +6,13 @@ import (
 	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/managers"
 	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
 	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/repositories"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/utils"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/shopspring/decimal"
 	"log"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -21,27 +23,64 @@ type CostCtl interface {
 	GetCostEntries(ctx context.Context, params *models.CostQueryParams) ([]*models.CostDTO, *models.ExpenseServiceError)
 	PatchCostEntry(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID, request models.CostDTO) (*models.CostDTO, *models.ExpenseServiceError)
 	DeleteCostEntry(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID) *models.ExpenseServiceError
-	GetCostOverview(ctx context.Context) (*models.CostOverviewDTO, *models.ExpenseServiceError)
+	GetCostOverview(ctx context.Context, groupBy string) (*models.CostOverviewDTO, *models.ExpenseServiceError)
+	CreateCostSuggestion(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID, request models.CostSuggestionRequest) (*models.CostSuggestionDTO, *models.ExpenseServiceError)
+	GetCostSuggestions(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID) ([]*models.CostSuggestionDTO, *models.ExpenseServiceError)
+	ApproveCostSuggestion(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID, costSuggestionId *uuid.UUID) (*models.CostDTO, *models.ExpenseServiceError)
+	RejectCostSuggestion(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID, costSuggestionId *uuid.UUID) (*models.CostSuggestionDTO, *models.ExpenseServiceError)
+	ApproveCost(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID) (*models.CostDTO, *models.ExpenseServiceError)
+	RejectCost(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID) (*models.CostDTO, *models.ExpenseServiceError)
+	GetCostsAwaitingApproval(ctx context.Context, tripId *uuid.UUID) ([]*models.CostDTO, *models.ExpenseServiceError)
+	CreateCostComment(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID, request models.CostCommentRequest) (*models.CostCommentDTO, *models.ExpenseServiceError)
+	GetCostComments(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID) ([]*models.CostCommentDTO, *models.ExpenseServiceError)
+	DeleteCostComment(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID, costCommentId *uuid.UUID) *models.ExpenseServiceError
+	GetCostVersions(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID) ([]*models.CostVersionDTO, *models.ExpenseServiceError)
+	RevertCostVersion(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID, costVersionId *uuid.UUID) (*models.CostDTO, *models.ExpenseServiceError)
+	RestoreCost(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID) (*models.CostDTO, *models.ExpenseServiceError)
 }
 
 // CostController Cost Controller structure
 type CostController struct {
-	DatabaseMgr      managers.DatabaseMgr
-	CostRepo         repositories.CostRepo
-	UserRepo         repositories.UserRepo
-	TripRepo         repositories.TripRepo
-	CostCategoryRepo repositories.CostCategoryRepo
-	DebtRepo         repositories.DebtRepo
+	MailMgr            managers.MailMgr
+	DatabaseMgr        managers.DatabaseMgr
+	EventMgr           managers.EventMgr
+	JobMgr             managers.JobMgr
+	CostRepo           repositories.CostRepo
+	UserRepo           repositories.UserRepo
+	TripRepo           repositories.TripRepo
+	CostCategoryRepo   repositories.CostCategoryRepo
+	DebtRepo           repositories.DebtRepo
+	KittyRepo          repositories.KittyRepo
+	CostSuggestionRepo repositories.CostSuggestionRepo
+	CostApprovalRepo   repositories.CostApprovalRepo
+
+	CostCategoryRuleRepo repositories.CostCategoryRuleRepo
+	CostCommentRepo      repositories.CostCommentRepo
+	CostVersionRepo      repositories.CostVersionRepo
 }
 
-func (cc *CostController) GetCostOverview(ctx context.Context) (*models.CostOverviewDTO, *models.ExpenseServiceError) {
+// maxCostTagLength is the maximum length of a single cost tag
+const maxCostTagLength = 30
+
+// maxCostCommentLength is the maximum length of a comment on a cost
+const maxCostCommentLength = 1000
+
+func (cc *CostController) GetCostOverview(ctx context.Context, groupBy string) (*models.CostOverviewDTO, *models.ExpenseServiceError) {
 	// Get user id from context
 	userId, ok := ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID)
 	if !ok {
 		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
 	}
 
-	response, err := cc.CostRepo.GetCostOverview(ctx, userId)
+	switch groupBy {
+	case "":
+		groupBy = models.CostOverviewGroupByCategory
+	case models.CostOverviewGroupByCategory, models.CostOverviewGroupByTopLevelCategory, models.CostOverviewGroupByTag:
+	default:
+		return nil, expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	response, err := cc.CostRepo.GetCostOverview(ctx, userId, groupBy)
 	if err != nil {
 		return nil, err
 	}
@@ -51,6 +90,11 @@ func (cc *CostController) GetCostOverview(ctx context.Context) (*models.CostOver
 
 // CreateCostEntry Creates a cost entry and inserts it into the database
 func (cc *CostController) CreateCostEntry(ctx context.Context, tripId *uuid.UUID, createCostRequest models.CostDTO) (*models.CostDTO, *models.ExpenseServiceError) {
+	// Check if user is allowed to add costs
+	if _, serviceErr := validateTripPermission(ctx, cc.TripRepo, tripId, models.TripPermissionWriteCosts); serviceErr != nil {
+		return nil, serviceErr
+	}
+
 	// Begin transaction
 	tx, err := cc.DatabaseMgr.BeginTx(ctx)
 	if err != nil {
@@ -69,24 +113,53 @@ func (cc *CostController) CreateCostEntry(ctx context.Context, tripId *uuid.UUID
 	costId := uuid.New()
 	now := time.Now()
 	deductionDate := now
+	userId := ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID)
 
 	if createCostRequest.DeductionDate != "" {
 		deductionDate, _ = time.Parse(time.RFC3339, createCostRequest.DeductionDate)
 	}
 
-	// Distribute cost among contributors
-	if serviceErr := DistributeCosts(&createCostRequest); serviceErr != nil {
+	// A private cost cannot be paid from the shared kitty
+	if createCostRequest.IsPrivate && createCostRequest.PaidFromKitty {
+		return nil, expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	// Private costs are paid and carried by the creator alone
+	if createCostRequest.IsPrivate {
+		user, repoErr := cc.UserRepo.GetUserById(ctx, userId)
+		if repoErr != nil {
+			return nil, repoErr
+		}
+
+		createCostRequest.Creditor = user.Username
+		createCostRequest.Debtors = []*models.Contributor{{Username: user.Username}}
+	}
+
+	// Costs without a cost category are assigned by the rules of the trip
+	if createCostRequest.CostCategoryID == nil {
+		if serviceErr := cc.applyCostCategoryRule(ctx, tripId, &createCostRequest); serviceErr != nil {
+			return nil, serviceErr
+		}
+	}
+
+	// The cost category has to belong to the trip and must not be in the trash
+	if _, serviceErr := getCostCategoryOfTripTx(ctx, tx, cc.CostCategoryRepo, tripId, createCostRequest.CostCategoryID); serviceErr != nil {
 		return nil, serviceErr
 	}
 
 	// Create cost entry
 	costEntry := &models.CostSchema{
-		CostID:         &costId,
-		Amount:         decimal.RequireFromString(createCostRequest.Amount),
-		Description:    createCostRequest.Description,
-		CreationDate:   &now,
-		DeductionDate:  &deductionDate,
-		CostCategoryID: createCostRequest.CostCategoryID,
+		CostID:          &costId,
+		Description:     createCostRequest.Description,
+		CreationDate:    &now,
+		DeductionDate:   &deductionDate,
+		IsPrivate:       createCostRequest.IsPrivate,
+		PaidFromKitty:   createCostRequest.PaidFromKitty,
+		SplitByPresence: createCostRequest.SplitByPresence,
+		CostCategoryID:  createCostRequest.CostCategoryID,
+		CreatedBy:       userId,
+		UpdatedBy:       userId,
+		Status:          models.CostStatusApproved,
 	}
 
 	if createCostRequest.EndDate != "" {
@@ -94,33 +167,68 @@ func (cc *CostController) CreateCostEntry(ctx context.Context, tripId *uuid.UUID
 		costEntry.EndDate = &endDate
 	}
 
+	// Costs split by presence are shared by the participants present on the dates of the cost
+	if createCostRequest.SplitByPresence {
+		if createCostRequest.IsPrivate || len(createCostRequest.Debtors) > 0 {
+			return nil, expense_errors.EXPENSE_BAD_REQUEST
+		}
+
+		debtors, serviceErr := cc.getPresenceDebtorsTx(ctx, tx, tripId, costEntry)
+		if serviceErr != nil {
+			return nil, serviceErr
+		}
+		createCostRequest.Debtors = debtors
+	}
+
+	// Distribute cost among contributors
+	if serviceErr := DistributeCosts(&createCostRequest); serviceErr != nil {
+		return nil, serviceErr
+	}
+	costEntry.Amount = decimal.RequireFromString(createCostRequest.Amount)
+
 	// Insert cost entry into database
 	if repoErr := cc.CostRepo.AddTx(ctx, tx, costEntry); repoErr != nil {
 		return nil, repoErr
 	}
 
-	// Get creditor user from database
-	creditorUser, repoErr := cc.UserRepo.GetUserBySchema(ctx, &models.UserSchema{Username: createCostRequest.Creditor})
-	if repoErr != nil {
-		return nil, repoErr
-	}
+	if len(createCostRequest.Tags) > 0 {
+		tags, serviceErr := normalizeCostTags(createCostRequest.Tags)
+		if serviceErr != nil {
+			return nil, serviceErr
+		}
 
-	// Add creditor with zero amount to contributors if not already present
-	var creditorFound bool
-	for _, contributor := range createCostRequest.Debtors {
-		if contributor.Username == creditorUser.Username {
-			creditorFound = true
-			break
+		if repoErr := cc.CostRepo.ReplaceCostTagsTx(ctx, tx, costEntry.CostID, tags); repoErr != nil {
+			return nil, repoErr
 		}
 	}
-	if !creditorFound {
-		createCostRequest.Debtors = append(createCostRequest.Debtors, &models.Contributor{
-			Username: creditorUser.Username,
-			Amount:   "0.0",
-		})
+
+	// Costs paid from the kitty have no creditor
+	var creditorUser *models.UserSchema
+	if !createCostRequest.PaidFromKitty {
+		// Get creditor user from database
+		var repoErr *models.ExpenseServiceError
+		creditorUser, repoErr = cc.UserRepo.GetUserBySchema(ctx, &models.UserSchema{Username: createCostRequest.Creditor})
+		if repoErr != nil {
+			return nil, repoErr
+		}
+
+		// Add creditor with zero amount to contributors if not already present
+		var creditorFound bool
+		for _, contributor := range createCostRequest.Debtors {
+			if contributor.Username == creditorUser.Username {
+				creditorFound = true
+				break
+			}
+		}
+		if !creditorFound {
+			createCostRequest.Debtors = append(createCostRequest.Debtors, &models.Contributor{
+				Username: creditorUser.Username,
+				Amount:   "0.0",
+			})
+		}
 	}
 
-	contributors := make([]*models.Contributor, len(createCostRequest.Debtors))
+	contributions := make([]*models.CostContributionSchema, len(createCostRequest.Debtors))
 	for i, contributor := range createCostRequest.Debtors {
 		contributorUser, repoErr := cc.UserRepo.GetUserBySchema(ctx, &models.UserSchema{Username: contributor.Username})
 		if repoErr != nil {
@@ -135,7 +243,7 @@ func (cc *CostController) CreateCostEntry(ctx context.Context, tripId *uuid.UUID
 		contribution := &models.CostContributionSchema{
 			CostID:     &costId,
 			UserID:     contributorUser.UserID,
-			IsCreditor: contributor.Username == creditorUser.Username,
+			IsCreditor: creditorUser != nil && contributor.Username == creditorUser.Username,
 			Amount:     decimal.RequireFromString(contributor.Amount),
 		}
 
@@ -144,17 +252,23 @@ func (cc *CostController) CreateCostEntry(ctx context.Context, tripId *uuid.UUID
 			return nil, repoErr
 		}
 
-		// Calculate debt
-		if contributorUser.Username != creditorUser.Username {
-			if serviceErr := cc.DebtRepo.CalculateDebt(ctx, tx, creditorUser.UserID, contributorUser.UserID, tripId, contribution.Amount); serviceErr != nil {
-				return nil, serviceErr
-			}
-		}
+		contributions[i] = contribution
+	}
 
-		contributors[i] = &models.Contributor{
-			Username: contributor.Username,
-			Amount:   contributor.Amount,
-		}
+	// Store the initial version of the cost
+	if serviceErr := cc.addCostVersionTx(ctx, tx, costEntry, contributions, userId, &now); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	// Calculate debts, costs above the approval threshold of the trip wait for their debtors instead
+	if serviceErr := cc.bookCostTx(ctx, tx, tripId, costEntry, creditorUser, contributions); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	// Get total cost of cost category before the new cost is committed
+	previousCategoryTotal, repoErr := cc.CostRepo.GetTotalCostByCostCategoryID(ctx, costEntry.CostCategoryID)
+	if repoErr != nil {
+		return nil, repoErr
 	}
 
 	// Commit transaction
@@ -163,6 +277,13 @@ func (cc *CostController) CreateCostEntry(ctx context.Context, tripId *uuid.UUID
 		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
 	}
 
+	// Private costs do not count towards the budget of the cost category
+	if !costEntry.IsPrivate {
+		cc.notifyIfBudgetThresholdCrossed(ctx, tripId, costEntry.CostCategoryID, *previousCategoryTotal)
+	}
+
+	cc.publishCostEvent(ctx, models.TripEventCostAdded, tripId, costEntry, userId, nil)
+
 	return cc.mapCostToResponse(ctx, costEntry), nil
 }
 
@@ -172,6 +293,11 @@ func (cc *CostController) GetCostDetails(ctx context.Context, costId *uuid.UUID)
 	if repoErr != nil {
 		return nil, repoErr
 	}
+
+	if serviceErr := cc.validateCostVisibility(ctx, cost); serviceErr != nil {
+		return nil, serviceErr
+	}
+
 	return cc.mapCostToResponse(ctx, cost), nil
 }
 
@@ -180,13 +306,20 @@ func (cc *CostController) GetCostEntries(ctx context.Context, params *models.Cos
 	// Optional parameters: costCategoryId, username
 	var costs []*models.CostSchema
 
+	userId, ok := ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID)
+	if !ok {
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// Private costs are only visible to their creator, who is the only contributor of a private cost
 	var args []interface{}
-	query := `SELECT DISTINCT c.* FROM cost c INNER JOIN cost_category cc on c.id_cost_category = cc.id INNER JOIN user_cost_association uca on c.id = uca.id_cost WHERE id_trip = $1`
-	args = append(args, params.TripId)
+	query := `SELECT DISTINCT c.id, c.amount, c.description, c.created_at, c.deducted_at, c.end_date, c.is_private, c.paid_from_kitty, c.split_by_presence, c.id_cost_category, c.created_by, c.updated_by, c.status FROM cost c INNER JOIN cost_category cc on c.id_cost_category = cc.id INNER JOIN user_cost_association uca on c.id = uca.id_cost WHERE id_trip = $1 AND c.deleted_at IS NULL AND (c.is_private = false OR uca.id_user = $2)`
+	args = append(args, params.TripId, userId)
 
+	// Filtering by a top-level category includes the costs of its subcategories
 	if params.CostCategoryId != nil {
-		query += ` AND c.id_cost_category = $` + strconv.Itoa(len(args)+1) // returns ' AND id_cost_category = $2'
-		args = append(args, params.CostCategoryId)                         // returns ' AND id_cost_category = $2'
+		query += ` AND (c.id_cost_category = $` + strconv.Itoa(len(args)+1) + ` OR cc.id_parent = $` + strconv.Itoa(len(args)+1) + `)`
+		args = append(args, params.CostCategoryId)
 	}
 
 	if params.CostCategoryName != nil {
@@ -199,10 +332,16 @@ func (cc *CostController) GetCostEntries(ctx context.Context, params *models.Cos
 			return nil, expense_errors.EXPENSE_BAD_REQUEST
 		}
 
-		query += ` AND c.id_cost_category = $` + strconv.Itoa(len(args)+1)
+		query += ` AND (c.id_cost_category = $` + strconv.Itoa(len(args)+1) + ` OR cc.id_parent = $` + strconv.Itoa(len(args)+1) + `)`
 		args = append(args, costCategory.CostCategoryID)
 	}
 
+	// Costs have to carry every requested tag
+	for _, tag := range params.Tags {
+		query += ` AND EXISTS (SELECT 1 FROM cost_tag ct WHERE ct.id_cost = c.id AND ct.tag = $` + strconv.Itoa(len(args)+1) + `)`
+		args = append(args, tag)
+	}
+
 	if params.UserId != nil {
 		query += ` AND uca.id_user = $` + strconv.Itoa(len(args)+1)
 		args = append(args, params.UserId)
@@ -288,7 +427,7 @@ func (cc *CostController) GetCostEntries(ctx context.Context, params *models.Cos
 
 	for rows.Next() {
 		var cost models.CostSchema
-		err := rows.Scan(&cost.CostID, &cost.Amount, &cost.Description, &cost.CreationDate, &cost.DeductionDate, &cost.EndDate, &cost.CostCategoryID)
+		err := rows.Scan(&cost.CostID, &cost.Amount, &cost.Description, &cost.CreationDate, &cost.DeductionDate, &cost.EndDate, &cost.IsPrivate, &cost.PaidFromKitty, &cost.SplitByPresence, &cost.CostCategoryID, &cost.CreatedBy, &cost.UpdatedBy, &cost.Status)
 		if err != nil {
 			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
 		}
@@ -305,6 +444,16 @@ func (cc *CostController) GetCostEntries(ctx context.Context, params *models.Cos
 }
 
 func (cc *CostController) PatchCostEntry(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID, request models.CostDTO) (*models.CostDTO, *models.ExpenseServiceError) {
+	// Get cost entry from database, it has to belong to the trip
+	cost, serviceErr := cc.getVisibleCostOfTrip(ctx, tripId, costId)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	if serviceErr := cc.validateCostWritePermission(ctx, tripId, cost); serviceErr != nil {
+		return nil, serviceErr
+	}
+
 	// Begin transaction
 	tx, err := cc.DatabaseMgr.BeginTx(ctx)
 	if err != nil {
@@ -320,172 +469,165 @@ func (cc *CostController) PatchCostEntry(ctx context.Context, tripId *uuid.UUID,
 		}
 	}(tx)
 
-	// Get cost entry from database
-	cost, repoErr := cc.CostRepo.GetCostByID(ctx, costId)
-	if repoErr != nil {
-		return nil, repoErr
+	previousAmount := cost.Amount
+	cost.UpdatedBy = ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID)
+	if serviceErr := cc.updateCostTx(ctx, tx, tripId, cost, request); serviceErr != nil {
+		return nil, serviceErr
 	}
 
-	oldCreditorUser, repoErr := cc.CostRepo.GetCostCreditor(ctx, costId)
+	// Get total cost of cost category before the changes are committed
+	previousCategoryTotal, repoErr := cc.CostRepo.GetTotalCostByCostCategoryID(ctx, cost.CostCategoryID)
 	if repoErr != nil {
 		return nil, repoErr
 	}
 
-	amountChanged := request.Amount != "" && request.Amount != cost.Amount.String()
-	creditorChanged := request.Creditor != "" && request.Creditor != oldCreditorUser.Username
-	debtorsChanged := request.Debtors != nil && len(request.Debtors) > 0
-	contributionsChanged := creditorChanged || debtorsChanged
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		log.Printf("Error while committing transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
 
-	// Update cost entry if request contains new values
-	if amountChanged {
-		amount, err := ValidateAmount(request.Amount)
-		if err != nil {
-			return nil, err
-		}
-		cost.Amount = amount
+	if !cost.IsPrivate {
+		cc.notifyIfBudgetThresholdCrossed(ctx, tripId, cost.CostCategoryID, *previousCategoryTotal)
 	}
 
-	if request.Description != "" {
-		cost.Description = request.Description
+	cc.publishCostEvent(ctx, models.TripEventCostEdited, tripId, cost, cost.UpdatedBy, &previousAmount)
+
+	return cc.mapCostToResponse(ctx, cost), nil
+}
+
+func (cc *CostController) DeleteCostEntry(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID) *models.ExpenseServiceError {
+	// Begin transaction
+	tx, err := cc.DatabaseMgr.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Error while beginning transaction: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
 	}
 
-	if request.DeductionDate != "" {
-		deductionDate, _ := time.Parse(time.RFC3339, request.DeductionDate)
-		cost.DeductionDate = &deductionDate
+	// Make sure to rollback the transaction if it fails
+	defer func(tx pgx.Tx) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			log.Printf("Error while rolling back transaction: %v", err)
+		}
+	}(tx)
+
+	cost, serviceErr := cc.getVisibleCostOfTrip(ctx, tripId, costId)
+	if serviceErr != nil {
+		return serviceErr
 	}
 
-	if request.EndDate != "" {
-		endDate, _ := time.Parse(time.RFC3339, request.EndDate)
-		cost.EndDate = &endDate
+	if serviceErr := cc.validateCostWritePermission(ctx, tripId, cost); serviceErr != nil {
+		return serviceErr
 	}
 
-	if request.CostCategoryID != nil {
-		cost.CostCategoryID = request.CostCategoryID
+	// Get all cost contributions
+	contributions, repoErr := cc.CostRepo.GetCostContributors(ctx, costId)
+	if repoErr != nil {
+		return repoErr
 	}
 
+	// Get creditor, costs paid from the kitty have none
 	var creditor *models.UserSchema
-	if creditorChanged {
-		creditor, repoErr = cc.UserRepo.GetUserBySchema(ctx, &models.UserSchema{Username: request.Creditor})
+	if !cost.PaidFromKitty {
+		creditor, repoErr = cc.CostRepo.GetCostCreditor(ctx, costId)
 		if repoErr != nil {
-			return nil, repoErr
+			return repoErr
 		}
-		if repoErr = cc.TripRepo.ValidateIfUserHasAccepted(ctx, tripId, creditor.UserID); repoErr != nil {
-			return nil, repoErr
+	}
+
+	// Remove debt from all contributors, costs that were never approved did not create any
+	if cost.Status == models.CostStatusApproved {
+		if serviceErr := cc.applyCostDebtsTx(ctx, tx, tripId, cost, creditor, contributions, true); serviceErr != nil {
+			return serviceErr
 		}
-	} else {
-		creditor = oldCreditorUser
 	}
-	request.Creditor = creditor.Username // Only a check, not needed for response
 
-	// If only amount has changed, not the contributions, then get the contributions from the database
-	if amountChanged && !contributionsChanged {
-		contributions, repoErr := cc.CostRepo.GetCostContributors(ctx, costId)
+	repoErr = cc.CostRepo.DeleteTx(ctx, tx, costId)
+	if repoErr != nil {
+		return repoErr
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		log.Printf("Error while committing transaction: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	cc.publishCostEvent(ctx, models.TripEventCostDeleted, tripId, cost, ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID), nil)
+
+	return nil
+}
+
+// RestoreCost moves a deleted cost out of the trash and books its debts again. A cost category in the trash has to be
+// restored first, and all contributors still have to be part of the trip
+func (cc *CostController) RestoreCost(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID) (*models.CostDTO, *models.ExpenseServiceError) {
+	cost, repoErr := cc.CostRepo.GetDeletedCostByID(ctx, costId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	// Cost has to belong to the trip
+	costCategory, repoErr := cc.CostCategoryRepo.GetCostCategoryByID(ctx, cost.CostCategoryID)
+	if repoErr == expense_errors.EXPENSE_NOT_FOUND {
+		deletedCostCategory, repoErr := cc.CostCategoryRepo.GetDeletedCostCategoryByID(ctx, cost.CostCategoryID)
 		if repoErr != nil {
 			return nil, repoErr
 		}
 
-		request.Debtors = make([]*models.Contributor, len(contributions))
-		for i, contribution := range contributions {
-			user, err := cc.UserRepo.GetUserById(ctx, contribution.UserID)
-			if err != nil {
-				return nil, err
-			}
-
-			request.Debtors[i] = &models.Contributor{
-				Username: user.Username,
-				Amount:   "", // Algorithm will calculate new amount
-			}
-
-			if contribution.Amount.IsZero() {
-				request.Debtors[i].Amount = "0" // Stay zero if it was zero before
-			}
+		if deletedCostCategory.TripID.String() != tripId.String() {
+			return nil, expense_errors.EXPENSE_NOT_FOUND
 		}
+		return nil, expense_errors.EXPENSE_CONFLICT
 	}
 
-	// Add creditor with zero amount to contributors if not already present
-	var creditorFound bool
-	for _, contributor := range request.Debtors {
-		if contributor.Username == request.Creditor {
-			creditorFound = true
-			break
-		}
+	if repoErr != nil {
+		return nil, repoErr
 	}
-	if !creditorFound {
-		request.Debtors = append(request.Debtors, &models.Contributor{
-			Username: request.Creditor,
-			Amount:   "0.0",
-		})
+
+	if costCategory.TripID.String() != tripId.String() {
+		return nil, expense_errors.EXPENSE_NOT_FOUND
 	}
 
-	// Distribute cost among contributors
-	if serviceErr := DistributeCosts(&request); serviceErr != nil {
+	if serviceErr := cc.validateCostVisibility(ctx, cost); serviceErr != nil {
 		return nil, serviceErr
 	}
 
-	// Delete old cost contributions and subtract debt from users
-	contributors, repoErr := cc.CostRepo.GetCostContributors(ctx, costId)
+	if serviceErr := cc.validateCostWritePermission(ctx, tripId, cost); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	contributions, repoErr := cc.CostRepo.GetCostContributors(ctx, costId)
 	if repoErr != nil {
 		return nil, repoErr
 	}
 
-	for _, contributor := range contributors {
-		// Delete cost contribution from database
-		if repoErr := cc.CostRepo.DeleteCostContributionTx(ctx, tx, contributor.UserID, costId); repoErr != nil {
-			return nil, repoErr
+	// Debts can only be booked again between participants that are still part of the trip
+	for _, contribution := range contributions {
+		participant, repoErr := cc.TripRepo.GetTripParticipant(ctx, tripId, contribution.UserID)
+		if repoErr == expense_errors.EXPENSE_NOT_FOUND || (repoErr == nil && !participant.HasAccepted) {
+			return nil, expense_errors.EXPENSE_CONFLICT
 		}
 
-		// Subtract debt from user
-		if repoErr := cc.DebtRepo.CalculateDebt(ctx, tx, creditor.UserID, contributor.UserID, tripId, contributor.Amount.Neg()); repoErr != nil {
+		if repoErr != nil {
 			return nil, repoErr
 		}
 	}
 
-	// Create cost contributions and add debt to users
-	for _, contributor := range request.Debtors {
-		// Get user from database
-		user, repoErr := cc.UserRepo.GetUserBySchema(ctx, &models.UserSchema{Username: contributor.Username})
+	// Get creditor, costs paid from the kitty have none
+	var creditor *models.UserSchema
+	if !cost.PaidFromKitty {
+		creditor, repoErr = cc.CostRepo.GetCostCreditor(ctx, costId)
 		if repoErr != nil {
 			return nil, repoErr
 		}
-
-		contribution := &models.CostContributionSchema{
-			CostID:     costId,
-			UserID:     user.UserID,
-			IsCreditor: contributor.Username == request.Creditor,
-			Amount:     decimal.RequireFromString(contributor.Amount),
-		}
-
-		// Insert cost contribution into database
-		if repoErr := cc.CostRepo.AddCostContributorTx(ctx, tx, contribution); repoErr != nil {
-			return nil, repoErr
-		}
-
-		// Add debt to user
-		if repoErr := cc.DebtRepo.CalculateDebt(ctx, tx, creditor.UserID, user.UserID, tripId, contribution.Amount); repoErr != nil {
-			return nil, repoErr
-		}
-	}
-
-	// Update cost entry in database
-	if repoErr := cc.CostRepo.UpdateTx(ctx, tx, cost); repoErr != nil {
-		return nil, repoErr
-	}
-
-	// Commit transaction
-	if err = tx.Commit(ctx); err != nil {
-		log.Printf("Error while committing transaction: %v", err)
-		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
 	}
 
-	return cc.mapCostToResponse(ctx, cost), nil
-}
-
-func (cc *CostController) DeleteCostEntry(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID) *models.ExpenseServiceError {
 	// Begin transaction
 	tx, err := cc.DatabaseMgr.BeginTx(ctx)
 	if err != nil {
 		log.Printf("Error while beginning transaction: %v", err)
-		return expense_errors.EXPENSE_INTERNAL_ERROR
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
 	}
 
 	// Make sure to rollback the transaction if it fails
@@ -496,56 +638,1539 @@ func (cc *CostController) DeleteCostEntry(ctx context.Context, tripId *uuid.UUID
 		}
 	}(tx)
 
-	// Get all cost contributions
-	contributions, repoErr := cc.CostRepo.GetCostContributors(ctx, costId)
-	if repoErr != nil {
-		return repoErr
-	}
-
-	// Get creditor
-	creditor, repoErr := cc.CostRepo.GetCostCreditor(ctx, costId)
-	if repoErr != nil {
-		return repoErr
+	if repoErr := cc.CostRepo.RestoreTx(ctx, tx, costId); repoErr != nil {
+		return nil, repoErr
 	}
 
-	// Remove debt from all contributors
-	for _, contribution := range contributions {
-		if contribution.IsCreditor {
-			continue
+	// Only approved costs had their debts taken back when they were deleted, pending costs still wait for their approvals
+	if cost.Status == models.CostStatusApproved {
+		if serviceErr := cc.applyCostDebtsTx(ctx, tx, tripId, cost, creditor, contributions, false); serviceErr != nil {
+			return nil, serviceErr
 		}
-		// Subtract debt from user
-		cc.DebtRepo.CalculateDebt(ctx, tx, creditor.UserID, contribution.UserID, tripId, contribution.Amount.Neg())
 	}
 
-	repoErr = cc.CostRepo.DeleteTx(ctx, tx, costId)
-	if repoErr != nil {
-		return repoErr
+	// If everything went well, commit the transaction
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error while committing transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
 	}
 
-	// Commit transaction
-	if err = tx.Commit(ctx); err != nil {
+	cost.DeletedAt = nil
+	cc.publishCostEvent(ctx, models.TripEventCostRestored, tripId, cost, ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID), nil)
+
+	return cc.mapCostToResponse(ctx, cost), nil
+}
+
+// updateCostTx applies the changes of the request to the cost and rebooks the contributions and debts
+func (cc *CostController) updateCostTx(ctx context.Context, tx pgx.Tx, tripId *uuid.UUID, cost *models.CostSchema, request models.CostDTO) *models.ExpenseServiceError {
+	// Costs paid from the kitty have no creditor
+	var oldCreditorUser *models.UserSchema
+	var repoErr *models.ExpenseServiceError
+	if !cost.PaidFromKitty {
+		oldCreditorUser, repoErr = cc.CostRepo.GetCostCreditor(ctx, cost.CostID)
+		if repoErr != nil {
+			return repoErr
+		}
+	}
+
+	// Costs created before versioning was introduced get their current state as first version, attributed to their creator
+	versionCount, repoErr := cc.CostVersionRepo.CountCostVersionsTx(ctx, tx, cost.CostID)
+	if repoErr != nil {
+		return repoErr
+	}
+
+	if versionCount == 0 {
+		currentContributions, repoErr := cc.CostRepo.GetCostContributors(ctx, cost.CostID)
+		if repoErr != nil {
+			return repoErr
+		}
+
+		if serviceErr := cc.addCostVersionTx(ctx, tx, cost, currentContributions, cost.CreatedBy, cost.CreationDate); serviceErr != nil {
+			return serviceErr
+		}
+	}
+
+	amountChanged := request.Amount != "" && request.Amount != cost.Amount.String()
+	creditorChanged := request.Creditor != "" && (oldCreditorUser == nil || request.Creditor != oldCreditorUser.Username)
+	debtorsChanged := request.Debtors != nil && len(request.Debtors) > 0
+	datesChanged := request.DeductionDate != "" || request.EndDate != ""
+	contributionsChanged := creditorChanged || debtorsChanged
+
+	// The visibility of a cost cannot be changed and private costs always belong to their creator alone
+	if cost.IsPrivate && (contributionsChanged || request.SplitByPresence) {
+		return expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	// Explicit contributors replace the split by presence, both at once are contradictory
+	if request.SplitByPresence && debtorsChanged {
+		return expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	// A cost paid from the kitty cannot be assigned to a creditor
+	if cost.PaidFromKitty && creditorChanged {
+		return expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	// Update cost entry if request contains new values
+	if amountChanged {
+		amount, err := ValidateAmount(request.Amount)
+		if err != nil {
+			return err
+		}
+		cost.Amount = amount
+	}
+
+	if request.Description != "" {
+		cost.Description = request.Description
+	}
+
+	if request.DeductionDate != "" {
+		deductionDate, _ := time.Parse(time.RFC3339, request.DeductionDate)
+		cost.DeductionDate = &deductionDate
+	}
+
+	if request.EndDate != "" {
+		endDate, _ := time.Parse(time.RFC3339, request.EndDate)
+		cost.EndDate = &endDate
+	}
+
+	if request.CostCategoryID != nil {
+		if _, serviceErr := getCostCategoryOfTripTx(ctx, tx, cc.CostCategoryRepo, tripId, request.CostCategoryID); serviceErr != nil {
+			return serviceErr
+		}
+		cost.CostCategoryID = request.CostCategoryID
+	}
+
+	if request.Tags != nil {
+		tags, serviceErr := normalizeCostTags(request.Tags)
+		if serviceErr != nil {
+			return serviceErr
+		}
+
+		if repoErr := cc.CostRepo.ReplaceCostTagsTx(ctx, tx, cost.CostID, tags); repoErr != nil {
+			return repoErr
+		}
+	}
+
+	creditor := oldCreditorUser
+	if creditorChanged {
+		creditor, repoErr = cc.UserRepo.GetUserBySchema(ctx, &models.UserSchema{Username: request.Creditor})
+		if repoErr != nil {
+			return repoErr
+		}
+		if repoErr = cc.TripRepo.ValidateIfUserHasAccepted(ctx, tripId, creditor.UserID); repoErr != nil {
+			return repoErr
+		}
+	}
+
+	if creditor != nil {
+		request.Creditor = creditor.Username // Only a check, not needed for response
+	}
+
+	if request.SplitByPresence {
+		cost.SplitByPresence = true
+	} else if debtorsChanged {
+		cost.SplitByPresence = false
+	}
+
+	// The debtors of a cost split by presence follow its dates and the presence of the participants
+	if cost.SplitByPresence && (request.SplitByPresence || amountChanged || datesChanged) {
+		debtors, serviceErr := cc.getPresenceDebtorsTx(ctx, tx, tripId, cost)
+		if serviceErr != nil {
+			return serviceErr
+		}
+		request.Debtors = debtors
+		contributionsChanged = true
+
+		if request.Amount == "" {
+			request.Amount = cost.Amount.String()
+		}
+	}
+
+	// If only amount has changed, not the contributions, then get the contributions from the database
+	if amountChanged && !contributionsChanged {
+		contributions, repoErr := cc.CostRepo.GetCostContributors(ctx, cost.CostID)
+		if repoErr != nil {
+			return repoErr
+		}
+
+		request.Debtors = make([]*models.Contributor, len(contributions))
+		for i, contribution := range contributions {
+			user, err := cc.UserRepo.GetUserById(ctx, contribution.UserID)
+			if err != nil {
+				return err
+			}
+
+			request.Debtors[i] = &models.Contributor{
+				Username: user.Username,
+				Amount:   "", // Algorithm will calculate new amount
+			}
+
+			if contribution.Amount.IsZero() {
+				request.Debtors[i].Amount = "0" // Stay zero if it was zero before
+			}
+		}
+	}
+
+	// Add creditor with zero amount to contributors if not already present
+	var creditorFound bool
+	for _, contributor := range request.Debtors {
+		if contributor.Username == request.Creditor {
+			creditorFound = true
+			break
+		}
+	}
+	if !creditorFound && creditor != nil {
+		request.Debtors = append(request.Debtors, &models.Contributor{
+			Username: request.Creditor,
+			Amount:   "0.0",
+		})
+	}
+
+	// Distribute cost among contributors
+	if serviceErr := DistributeCosts(&request); serviceErr != nil {
+		return serviceErr
+	}
+
+	// Delete old cost contributions and subtract debt from users
+	oldContributions, repoErr := cc.CostRepo.GetCostContributors(ctx, cost.CostID)
+	if repoErr != nil {
+		return repoErr
+	}
+
+	for _, contribution := range oldContributions {
+		// Delete cost contribution from database
+		if repoErr := cc.CostRepo.DeleteCostContributionTx(ctx, tx, contribution.UserID, cost.CostID); repoErr != nil {
+			return repoErr
+		}
+	}
+
+	// Only approved costs created debts that have to be taken back
+	wasBooked := cost.Status == models.CostStatusApproved
+	if wasBooked {
+		if serviceErr := cc.applyCostDebtsTx(ctx, tx, tripId, cost, oldCreditorUser, oldContributions, true); serviceErr != nil {
+			return serviceErr
+		}
+	}
+
+	// Create cost contributions and add debt to users
+	contributions := make([]*models.CostContributionSchema, len(request.Debtors))
+	for i, contributor := range request.Debtors {
+		// Get user from database
+		user, repoErr := cc.UserRepo.GetUserBySchema(ctx, &models.UserSchema{Username: contributor.Username})
+		if repoErr != nil {
+			return repoErr
+		}
+
+		contribution := &models.CostContributionSchema{
+			CostID:     cost.CostID,
+			UserID:     user.UserID,
+			IsCreditor: creditor != nil && contributor.Username == request.Creditor,
+			Amount:     decimal.RequireFromString(contributor.Amount),
+		}
+
+		// Insert cost contribution into database
+		if repoErr := cc.CostRepo.AddCostContributorTx(ctx, tx, contribution); repoErr != nil {
+			return repoErr
+		}
+
+		contributions[i] = contribution
+	}
+
+	switch {
+	case amountChanged || contributionsChanged:
+		// Changed amounts and contributions have to be approved again if the cost is above the approval threshold
+		if serviceErr := cc.bookCostTx(ctx, tx, tripId, cost, creditor, contributions); serviceErr != nil {
+			return serviceErr
+		}
+	case wasBooked:
+		if serviceErr := cc.applyCostDebtsTx(ctx, tx, tripId, cost, creditor, contributions, false); serviceErr != nil {
+			return serviceErr
+		}
+	}
+
+	// Every change is stored as a new version
+	now := time.Now()
+	if serviceErr := cc.addCostVersionTx(ctx, tx, cost, contributions, cost.UpdatedBy, &now); serviceErr != nil {
+		return serviceErr
+	}
+
+	// Update cost entry in database
+	return cc.CostRepo.UpdateTx(ctx, tx, cost)
+}
+
+// ApproveCost stores the approval of the current user. Once all debtors approved, the debts of the cost are booked
+func (cc *CostController) ApproveCost(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID) (*models.CostDTO, *models.ExpenseServiceError) {
+	cost, approval, serviceErr := cc.getPendingCostApproval(ctx, tripId, costId)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	// Begin transaction
+	tx, err := cc.DatabaseMgr.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Error while beginning transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// Make sure to rollback the transaction if it fails
+	defer func(tx pgx.Tx) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			log.Printf("Error while rolling back transaction: %v", err)
+		}
+	}(tx)
+
+	// Decisions of other debtors wait for this one, so the last approval always sees all others
+	if serviceErr := cc.lockPendingCostTx(ctx, tx, costId); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	now := time.Now()
+	approval.Status = models.CostStatusApproved
+	approval.DecisionDate = &now
+	if repoErr := cc.CostApprovalRepo.UpdateCostApprovalTx(ctx, tx, approval); repoErr != nil {
+		return nil, repoErr
+	}
+
+	pendingApprovals, repoErr := cc.CostApprovalRepo.CountPendingCostApprovalsTx(ctx, tx, costId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	// Book the debts with the last approval
+	if pendingApprovals == 0 {
+		contributions, repoErr := cc.CostRepo.GetCostContributors(ctx, costId)
+		if repoErr != nil {
+			return nil, repoErr
+		}
+
+		// Costs paid from the kitty have no creditor
+		var creditor *models.UserSchema
+		if !cost.PaidFromKitty {
+			creditor, repoErr = cc.CostRepo.GetCostCreditor(ctx, costId)
+			if repoErr != nil {
+				return nil, repoErr
+			}
+		}
+
+		cost.Status = models.CostStatusApproved
+		if repoErr := cc.CostRepo.UpdateCostStatusTx(ctx, tx, costId, cost.Status); repoErr != nil {
+			return nil, repoErr
+		}
+
+		if serviceErr := cc.applyCostDebtsTx(ctx, tx, tripId, cost, creditor, contributions, false); serviceErr != nil {
+			return nil, serviceErr
+		}
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		log.Printf("Error while committing transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return cc.mapCostToResponse(ctx, cost), nil
+}
+
+// RejectCost rejects a pending cost on behalf of the current user. A single rejection is enough, the cost then needs
+// to be edited to be approved again
+func (cc *CostController) RejectCost(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID) (*models.CostDTO, *models.ExpenseServiceError) {
+	cost, approval, serviceErr := cc.getPendingCostApproval(ctx, tripId, costId)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	// Begin transaction
+	tx, err := cc.DatabaseMgr.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Error while beginning transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// Make sure to rollback the transaction if it fails
+	defer func(tx pgx.Tx) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			log.Printf("Error while rolling back transaction: %v", err)
+		}
+	}(tx)
+
+	// Decisions of other debtors wait for this one, so the last approval always sees all others
+	if serviceErr := cc.lockPendingCostTx(ctx, tx, costId); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	now := time.Now()
+	approval.Status = models.CostStatusRejected
+	approval.DecisionDate = &now
+	if repoErr := cc.CostApprovalRepo.UpdateCostApprovalTx(ctx, tx, approval); repoErr != nil {
+		return nil, repoErr
+	}
+
+	cost.Status = models.CostStatusRejected
+	if repoErr := cc.CostRepo.UpdateCostStatusTx(ctx, tx, costId, cost.Status); repoErr != nil {
+		return nil, repoErr
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		log.Printf("Error while committing transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return cc.mapCostToResponse(ctx, cost), nil
+}
+
+// GetCostsAwaitingApproval returns all pending costs of the trip the current user still has to decide on
+func (cc *CostController) GetCostsAwaitingApproval(ctx context.Context, tripId *uuid.UUID) ([]*models.CostDTO, *models.ExpenseServiceError) {
+	costs, repoErr := cc.CostRepo.GetCostsAwaitingApproval(ctx, tripId, ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID))
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	response := make([]*models.CostDTO, len(costs))
+	for i, cost := range costs {
+		response[i] = cc.mapCostToResponse(ctx, cost)
+	}
+
+	return response, nil
+}
+
+// lockPendingCostTx locks the cost and checks that no other decision has completed it in the meantime
+func (cc *CostController) lockPendingCostTx(ctx context.Context, tx pgx.Tx, costId *uuid.UUID) *models.ExpenseServiceError {
+	status, repoErr := cc.CostRepo.GetCostStatusForUpdateTx(ctx, tx, costId)
+	if repoErr != nil {
+		return repoErr
+	}
+
+	if status != models.CostStatusPending {
+		return expense_errors.EXPENSE_CONFLICT
+	}
+
+	return nil
+}
+
+// getPendingCostApproval returns a pending cost of the trip and the open approval of the current user for it
+func (cc *CostController) getPendingCostApproval(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID) (*models.CostSchema, *models.CostApprovalSchema, *models.ExpenseServiceError) {
+	cost, serviceErr := cc.getVisibleCostOfTrip(ctx, tripId, costId)
+	if serviceErr != nil {
+		return nil, nil, serviceErr
+	}
+
+	if cost.Status != models.CostStatusPending {
+		return nil, nil, expense_errors.EXPENSE_CONFLICT
+	}
+
+	// Only debtors of the cost can decide on it
+	approval, repoErr := cc.CostApprovalRepo.GetCostApproval(ctx, costId, ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID))
+	if repoErr != nil {
+		if repoErr == expense_errors.EXPENSE_NOT_FOUND {
+			return nil, nil, expense_errors.EXPENSE_FORBIDDEN
+		}
+		return nil, nil, repoErr
+	}
+
+	if approval.Status != models.CostStatusPending {
+		return nil, nil, expense_errors.EXPENSE_CONFLICT
+	}
+
+	return cost, approval, nil
+}
+
+// CreateCostSuggestion stores a change to a cost that has to be approved by someone allowed to edit the cost
+func (cc *CostController) CreateCostSuggestion(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID, request models.CostSuggestionRequest) (*models.CostSuggestionDTO, *models.ExpenseServiceError) {
+	cost, serviceErr := cc.getVisibleCostOfTrip(ctx, tripId, costId)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	// Viewers cannot suggest changes
+	participant, serviceErr := validateTripPermission(ctx, cc.TripRepo, tripId, models.TripPermissionWriteCosts)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	// Suggestions are checked like a cost entry, so they can be applied once they are approved
+	if serviceErr := cc.validateCostSuggestionChanges(ctx, tripId, cost, request.Changes); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	suggestionId := uuid.New()
+	now := time.Now()
+	suggestion := &models.CostSuggestionSchema{
+		CostSuggestionID: &suggestionId,
+		CostID:           cost.CostID,
+		UserID:           participant.UserID,
+		Changes:          request.Changes,
+		Comment:          request.Comment,
+		Status:           models.CostSuggestionStatusPending,
+		CreationDate:     &now,
+	}
+
+	if repoErr := cc.CostSuggestionRepo.AddCostSuggestion(ctx, suggestion); repoErr != nil {
+		return nil, repoErr
+	}
+
+	return cc.mapCostSuggestionToResponse(ctx, suggestion), nil
+}
+
+func (cc *CostController) GetCostSuggestions(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID) ([]*models.CostSuggestionDTO, *models.ExpenseServiceError) {
+	cost, serviceErr := cc.getVisibleCostOfTrip(ctx, tripId, costId)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	suggestions, repoErr := cc.CostSuggestionRepo.GetCostSuggestionsByCostID(ctx, cost.CostID)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	response := make([]*models.CostSuggestionDTO, len(suggestions))
+	for i, suggestion := range suggestions {
+		response[i] = cc.mapCostSuggestionToResponse(ctx, suggestion)
+	}
+
+	return response, nil
+}
+
+// ApproveCostSuggestion applies a pending suggestion to the cost. The author of the suggestion is recorded as the last editor
+func (cc *CostController) ApproveCostSuggestion(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID, costSuggestionId *uuid.UUID) (*models.CostDTO, *models.ExpenseServiceError) {
+	cost, suggestion, serviceErr := cc.getPendingCostSuggestion(ctx, tripId, costId, costSuggestionId)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	// Begin transaction
+	tx, err := cc.DatabaseMgr.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Error while beginning transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// Make sure to rollback the transaction if it fails
+	defer func(tx pgx.Tx) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			log.Printf("Error while rolling back transaction: %v", err)
+		}
+	}(tx)
+
+	// Decide on the suggestion first, only one of concurrent decisions finds it still pending and gets to apply it
+	now := time.Now()
+	suggestion.Status = models.CostSuggestionStatusApproved
+	suggestion.DecisionDate = &now
+	suggestion.DecidedBy = ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID)
+	if repoErr := cc.CostSuggestionRepo.UpdateCostSuggestionStatusTx(ctx, tx, suggestion); repoErr != nil {
+		return nil, repoErr
+	}
+
+	previousAmount := cost.Amount
+	cost.UpdatedBy = suggestion.UserID
+	if serviceErr := cc.updateCostTx(ctx, tx, tripId, cost, *suggestion.Changes); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	// Get total cost of cost category before the changes are committed
+	previousCategoryTotal, repoErr := cc.CostRepo.GetTotalCostByCostCategoryID(ctx, cost.CostCategoryID)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		log.Printf("Error while committing transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	if !cost.IsPrivate {
+		cc.notifyIfBudgetThresholdCrossed(ctx, tripId, cost.CostCategoryID, *previousCategoryTotal)
+	}
+
+	cc.publishCostEvent(ctx, models.TripEventCostEdited, tripId, cost, cost.UpdatedBy, &previousAmount)
+
+	return cc.mapCostToResponse(ctx, cost), nil
+}
+
+func (cc *CostController) RejectCostSuggestion(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID, costSuggestionId *uuid.UUID) (*models.CostSuggestionDTO, *models.ExpenseServiceError) {
+	_, suggestion, serviceErr := cc.getPendingCostSuggestion(ctx, tripId, costId, costSuggestionId)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	// Begin transaction
+	tx, err := cc.DatabaseMgr.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Error while beginning transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// Make sure to rollback the transaction if it fails
+	defer func(tx pgx.Tx) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			log.Printf("Error while rolling back transaction: %v", err)
+		}
+	}(tx)
+
+	now := time.Now()
+	suggestion.Status = models.CostSuggestionStatusRejected
+	suggestion.DecisionDate = &now
+	suggestion.DecidedBy = ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID)
+	if repoErr := cc.CostSuggestionRepo.UpdateCostSuggestionStatusTx(ctx, tx, suggestion); repoErr != nil {
+		return nil, repoErr
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
 		log.Printf("Error while committing transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return cc.mapCostSuggestionToResponse(ctx, suggestion), nil
+}
+
+// getPendingCostSuggestion returns the cost and one of its pending suggestions if the user is allowed to decide on it
+func (cc *CostController) getPendingCostSuggestion(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID, costSuggestionId *uuid.UUID) (*models.CostSchema, *models.CostSuggestionSchema, *models.ExpenseServiceError) {
+	cost, serviceErr := cc.getVisibleCostOfTrip(ctx, tripId, costId)
+	if serviceErr != nil {
+		return nil, nil, serviceErr
+	}
+
+	// Only users allowed to edit the cost can decide on suggestions
+	if serviceErr := cc.validateCostWritePermission(ctx, tripId, cost); serviceErr != nil {
+		return nil, nil, serviceErr
+	}
+
+	suggestion, repoErr := cc.CostSuggestionRepo.GetCostSuggestionByID(ctx, costSuggestionId)
+	if repoErr != nil {
+		return nil, nil, repoErr
+	}
+
+	if suggestion.CostID.String() != cost.CostID.String() {
+		return nil, nil, expense_errors.EXPENSE_NOT_FOUND
+	}
+
+	if suggestion.Status != models.CostSuggestionStatusPending {
+		return nil, nil, expense_errors.EXPENSE_CONFLICT
+	}
+
+	return cost, suggestion, nil
+}
+
+// validateCostSuggestionChanges checks the suggested changes against the same rules as a new or edited cost
+func (cc *CostController) validateCostSuggestionChanges(ctx context.Context, tripId *uuid.UUID, cost *models.CostSchema, changes *models.CostDTO) *models.ExpenseServiceError {
+	amount := cost.Amount.String()
+	if changes.Amount != "" {
+		if _, serviceErr := ValidateAmount(changes.Amount); serviceErr != nil {
+			return serviceErr
+		}
+		amount = changes.Amount
+	}
+
+	creditorChanged := changes.Creditor != ""
+	debtorsChanged := len(changes.Debtors) > 0
+
+	// Private costs always belong to their creator alone, costs paid from the kitty have no creditor
+	if cost.IsPrivate && (creditorChanged || debtorsChanged || changes.SplitByPresence) {
+		return expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	if (changes.SplitByPresence && debtorsChanged) || (cost.PaidFromKitty && creditorChanged) {
+		return expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	// The cost category has to belong to the trip and must not be in the trash
+	if changes.CostCategoryID != nil {
+		costCategory, repoErr := cc.CostCategoryRepo.GetCostCategoryByID(ctx, changes.CostCategoryID)
+		if repoErr != nil {
+			return repoErr
+		}
+
+		if costCategory.TripID.String() != tripId.String() {
+			return expense_errors.EXPENSE_NOT_FOUND
+		}
+	}
+
+	// The cost cannot end before it is deducted
+	deductionDate, endDate := cost.DeductionDate, cost.EndDate
+	if changes.DeductionDate != "" {
+		date, _ := time.Parse(time.RFC3339, changes.DeductionDate)
+		deductionDate = &date
+	}
+
+	if changes.EndDate != "" {
+		date, _ := time.Parse(time.RFC3339, changes.EndDate)
+		endDate = &date
+	}
+
+	if deductionDate != nil && endDate != nil && deductionDate.After(*endDate) {
+		return expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	// Creditor and debtors have to be part of the trip
+	usernames := make([]string, 0, len(changes.Debtors)+1)
+	if creditorChanged {
+		usernames = append(usernames, changes.Creditor)
+	}
+
+	debtors := make([]*models.Contributor, len(changes.Debtors))
+	for i, debtor := range changes.Debtors {
+		usernames = append(usernames, debtor.Username)
+		debtors[i] = &models.Contributor{Username: debtor.Username, Amount: debtor.Amount}
+	}
+
+	for _, username := range usernames {
+		user, repoErr := cc.UserRepo.GetUserBySchema(ctx, &models.UserSchema{Username: username})
+		if repoErr != nil {
+			return repoErr
+		}
+
+		if repoErr = cc.TripRepo.ValidateIfUserHasAccepted(ctx, tripId, user.UserID); repoErr != nil {
+			return repoErr
+		}
+	}
+
+	// The contributions have to add up to the amount of the cost, the suggestion itself keeps the amounts as given
+	if debtorsChanged {
+		if serviceErr := DistributeCosts(&models.CostDTO{Amount: amount, Debtors: debtors}); serviceErr != nil {
+			return serviceErr
+		}
+	}
+
+	return nil
+}
+
+func (cc *CostController) mapCostSuggestionToResponse(ctx context.Context, suggestion *models.CostSuggestionSchema) *models.CostSuggestionDTO {
+	response := &models.CostSuggestionDTO{
+		CostSuggestionID: suggestion.CostSuggestionID,
+		CostID:           suggestion.CostID,
+		Changes:          suggestion.Changes,
+		Comment:          suggestion.Comment,
+		Status:           suggestion.Status,
+		CreationDate:     suggestion.CreationDate.String(),
+	}
+
+	if author, repoErr := cc.UserRepo.GetUserById(ctx, suggestion.UserID); repoErr == nil {
+		response.SuggestedBy = author.Username
+	}
+
+	if suggestion.DecisionDate != nil {
+		response.DecisionDate = suggestion.DecisionDate.String()
+	}
+
+	if suggestion.DecidedBy != nil {
+		if decider, repoErr := cc.UserRepo.GetUserById(ctx, suggestion.DecidedBy); repoErr == nil {
+			response.DecidedBy = decider.Username
+		}
+	}
+
+	return response
+}
+
+// CreateCostComment Adds a comment to a cost. Mentioned participants are notified by mail
+func (cc *CostController) CreateCostComment(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID, request models.CostCommentRequest) (*models.CostCommentDTO, *models.ExpenseServiceError) {
+	cost, serviceErr := cc.getVisibleCostOfTrip(ctx, tripId, costId)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	userId := ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID)
+
+	// Pending invitees cannot join the discussion yet
+	if repoErr := cc.TripRepo.ValidateIfUserHasAccepted(ctx, tripId, userId); repoErr != nil {
+		return nil, repoErr
+	}
+
+	content := strings.TrimSpace(request.Content)
+	if content == "" || len(content) > maxCostCommentLength {
+		return nil, expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	commentId := uuid.New()
+	now := time.Now()
+	comment := &models.CostCommentSchema{
+		CostCommentID: &commentId,
+		CostID:        cost.CostID,
+		UserID:        userId,
+		Content:       content,
+		CreationDate:  &now,
+	}
+
+	if repoErr := cc.CostCommentRepo.AddCostComment(ctx, comment); repoErr != nil {
+		return nil, repoErr
+	}
+
+	// Private costs are only visible to their creator, so nobody else can be mentioned
+	if !cost.IsPrivate {
+		cc.notifyCostCommentMentions(ctx, tripId, cost, comment)
+	}
+
+	return cc.mapCostCommentToResponse(ctx, comment), nil
+}
+
+// GetCostComments Returns the comments of a cost, oldest first
+func (cc *CostController) GetCostComments(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID) ([]*models.CostCommentDTO, *models.ExpenseServiceError) {
+	cost, serviceErr := cc.getVisibleCostOfTrip(ctx, tripId, costId)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	// Pending invitees cannot read the discussion yet
+	if repoErr := cc.TripRepo.ValidateIfUserHasAccepted(ctx, tripId, ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID)); repoErr != nil {
+		return nil, repoErr
+	}
+
+	comments, repoErr := cc.CostCommentRepo.GetCostCommentsByCostID(ctx, cost.CostID)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	response := make([]*models.CostCommentDTO, len(comments))
+	for i, comment := range comments {
+		response[i] = cc.mapCostCommentToResponse(ctx, comment)
+	}
+
+	return response, nil
+}
+
+// DeleteCostComment Deletes a comment. Authors can delete their own comments, users allowed to manage costs all comments
+func (cc *CostController) DeleteCostComment(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID, costCommentId *uuid.UUID) *models.ExpenseServiceError {
+	cost, serviceErr := cc.getVisibleCostOfTrip(ctx, tripId, costId)
+	if serviceErr != nil {
+		return serviceErr
+	}
+
+	userId := ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID)
+
+	// Pending invitees cannot take part in the discussion yet
+	if repoErr := cc.TripRepo.ValidateIfUserHasAccepted(ctx, tripId, userId); repoErr != nil {
+		return repoErr
+	}
+
+	comment, repoErr := cc.CostCommentRepo.GetCostCommentByID(ctx, costCommentId)
+	if repoErr != nil {
+		return repoErr
+	}
+
+	if comment.CostID.String() != cost.CostID.String() {
+		return expense_errors.EXPENSE_NOT_FOUND
+	}
+
+	if comment.UserID.String() != userId.String() {
+		if _, serviceErr := validateTripPermission(ctx, cc.TripRepo, tripId, models.TripPermissionManageCosts); serviceErr != nil {
+			return serviceErr
+		}
+	}
+
+	return cc.CostCommentRepo.DeleteCostComment(ctx, costCommentId)
+}
+
+// GetCostVersions Returns the versions of a cost, oldest first, each with the changes compared to the previous version
+func (cc *CostController) GetCostVersions(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID) ([]*models.CostVersionDTO, *models.ExpenseServiceError) {
+	cost, serviceErr := cc.getVisibleCostOfTrip(ctx, tripId, costId)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	costVersions, repoErr := cc.CostVersionRepo.GetCostVersionsByCostID(ctx, cost.CostID)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	// Usernames are looked up once per user, deleted users stay empty
+	usernames := make(map[uuid.UUID]string)
+	getUsername := func(userId *uuid.UUID) string {
+		if userId == nil {
+			return ""
+		}
+
+		if username, ok := usernames[*userId]; ok {
+			return username
+		}
+
+		if user, repoErr := cc.UserRepo.GetUserById(ctx, userId); repoErr == nil {
+			usernames[*userId] = user.Username
+		}
+		return usernames[*userId]
+	}
+
+	response := make([]*models.CostVersionDTO, len(costVersions))
+	var previous *models.CostVersionSnapshot
+	for i, costVersion := range costVersions {
+		response[i] = &models.CostVersionDTO{
+			CostVersionID: costVersion.CostVersionID,
+			Version:       costVersion.Version,
+			ChangedBy:     getUsername(costVersion.CreatedBy),
+			CreationDate:  costVersion.CreationDate.String(),
+			Changes:       diffCostVersions(previous, costVersion.Snapshot, getUsername),
+		}
+		previous = costVersion.Snapshot
+	}
+
+	return response, nil
+}
+
+// RevertCostVersion Restores the amount, details and shares of an earlier version. The revert is applied like any
+// other change, so the debts are calculated again and a new version is stored
+func (cc *CostController) RevertCostVersion(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID, costVersionId *uuid.UUID) (*models.CostDTO, *models.ExpenseServiceError) {
+	cost, serviceErr := cc.getVisibleCostOfTrip(ctx, tripId, costId)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	costVersion, repoErr := cc.CostVersionRepo.GetCostVersionByID(ctx, costVersionId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	if costVersion.CostID.String() != cost.CostID.String() {
+		return nil, expense_errors.EXPENSE_NOT_FOUND
+	}
+
+	snapshot := costVersion.Snapshot
+	request := models.CostDTO{
+		Amount:      snapshot.Amount.String(),
+		Description: snapshot.Description,
+	}
+
+	if snapshot.DeductionDate != nil {
+		request.DeductionDate = snapshot.DeductionDate.Format(time.RFC3339)
+	}
+
+	if snapshot.EndDate != nil {
+		request.EndDate = snapshot.EndDate.Format(time.RFC3339)
+	}
+
+	// The cost stays in its current category if the category of the version has been deleted in the meantime
+	if costCategory, repoErr := cc.CostCategoryRepo.GetCostCategoryByID(ctx, snapshot.CostCategoryID); repoErr == nil && costCategory.TripID.String() == tripId.String() {
+		request.CostCategoryID = snapshot.CostCategoryID
+	}
+
+	// Private costs always belong to their creator alone, so only their details are reverted.
+	// Costs split by presence are split again based on the current presence of the participants
+	if !cost.IsPrivate {
+		request.SplitByPresence = snapshot.SplitByPresence
+		for _, contribution := range snapshot.Contributions {
+			// Claimed guests are replaced in the snapshots, so a missing user has deleted their account
+			// and the shares of the version cannot be restored
+			user, repoErr := cc.UserRepo.GetUserById(ctx, contribution.UserID)
+			if repoErr == expense_errors.EXPENSE_USER_NOT_FOUND {
+				return nil, expense_errors.EXPENSE_CONFLICT
+			} else if repoErr != nil {
+				return nil, repoErr
+			}
+
+			if contribution.IsCreditor {
+				request.Creditor = user.Username
+			}
+
+			if !snapshot.SplitByPresence {
+				request.Debtors = append(request.Debtors, &models.Contributor{
+					Username: user.Username,
+					Amount:   contribution.Amount.String(),
+				})
+			}
+		}
+	}
+
+	return cc.PatchCostEntry(ctx, tripId, costId, request)
+}
+
+// addCostVersionTx stores the state of the cost and its contributions as a new version
+func (cc *CostController) addCostVersionTx(ctx context.Context, tx pgx.Tx, cost *models.CostSchema, contributions []*models.CostContributionSchema, createdBy *uuid.UUID, creationDate *time.Time) *models.ExpenseServiceError {
+	costVersionId := uuid.New()
+	costVersion := &models.CostVersionSchema{
+		CostVersionID: &costVersionId,
+		CostID:        cost.CostID,
+		Snapshot: &models.CostVersionSnapshot{
+			Amount:          cost.Amount,
+			Description:     cost.Description,
+			DeductionDate:   cost.DeductionDate,
+			EndDate:         cost.EndDate,
+			CostCategoryID:  cost.CostCategoryID,
+			PaidFromKitty:   cost.PaidFromKitty,
+			SplitByPresence: cost.SplitByPresence,
+			Contributions:   make([]*models.CostVersionContribution, len(contributions)),
+		},
+		CreatedBy:    createdBy,
+		CreationDate: creationDate,
+	}
+
+	for i, contribution := range contributions {
+		costVersion.Snapshot.Contributions[i] = &models.CostVersionContribution{
+			UserID:     contribution.UserID,
+			Amount:     contribution.Amount,
+			IsCreditor: contribution.IsCreditor,
+		}
+	}
+
+	return cc.CostVersionRepo.AddCostVersionTx(ctx, tx, costVersion)
+}
+
+// diffCostVersions returns the fields that changed between two versions. Without a previous version all fields count as new
+func diffCostVersions(previous *models.CostVersionSnapshot, current *models.CostVersionSnapshot, getUsername func(userId *uuid.UUID) string) []*models.CostFieldChangeDTO {
+	changes := make([]*models.CostFieldChangeDTO, 0)
+	addChange := func(field string, username string, oldValue string, newValue string) {
+		if oldValue != newValue {
+			changes = append(changes, &models.CostFieldChangeDTO{
+				Field:    field,
+				Username: username,
+				OldValue: oldValue,
+				NewValue: newValue,
+			})
+		}
+	}
+
+	formatDate := func(date *time.Time) string {
+		if date == nil {
+			return ""
+		}
+		return date.String()
+	}
+
+	formatId := func(id *uuid.UUID) string {
+		if id == nil {
+			return ""
+		}
+		return id.String()
+	}
+
+	getCreditor := func(snapshot *models.CostVersionSnapshot) string {
+		for _, contribution := range snapshot.Contributions {
+			if contribution.IsCreditor {
+				return getUsername(contribution.UserID)
+			}
+		}
+		return ""
+	}
+
+	// Shares are compared per participant, participants that were added or removed have an empty old or new share
+	oldShares := make(map[uuid.UUID]string)
+	newShares := make(map[uuid.UUID]string)
+	participantIds := make([]*uuid.UUID, 0)
+	for _, contribution := range current.Contributions {
+		newShares[*contribution.UserID] = contribution.Amount.String()
+		participantIds = append(participantIds, contribution.UserID)
+	}
+
+	if previous == nil {
+		previous = &models.CostVersionSnapshot{}
+		addChange(models.CostFieldAmount, "", "", current.Amount.String())
+	} else {
+		addChange(models.CostFieldAmount, "", previous.Amount.String(), current.Amount.String())
+
+		for _, contribution := range previous.Contributions {
+			oldShares[*contribution.UserID] = contribution.Amount.String()
+			if _, ok := newShares[*contribution.UserID]; !ok {
+				participantIds = append(participantIds, contribution.UserID)
+			}
+		}
+	}
+
+	addChange(models.CostFieldDescription, "", previous.Description, current.Description)
+	addChange(models.CostFieldDeductionDate, "", formatDate(previous.DeductionDate), formatDate(current.DeductionDate))
+	addChange(models.CostFieldEndDate, "", formatDate(previous.EndDate), formatDate(current.EndDate))
+	addChange(models.CostFieldCostCategory, "", formatId(previous.CostCategoryID), formatId(current.CostCategoryID))
+	addChange(models.CostFieldCreditor, "", getCreditor(previous), getCreditor(current))
+
+	for _, participantId := range participantIds {
+		addChange(models.CostFieldShare, getUsername(participantId), oldShares[*participantId], newShares[*participantId])
+	}
+
+	return changes
+}
+
+// getVisibleCostOfTrip returns the cost if it belongs to the trip and is visible to the user
+func (cc *CostController) getVisibleCostOfTrip(ctx context.Context, tripId *uuid.UUID, costId *uuid.UUID) (*models.CostSchema, *models.ExpenseServiceError) {
+	cost, repoErr := cc.CostRepo.GetCostByID(ctx, costId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	costCategory, repoErr := cc.CostCategoryRepo.GetCostCategoryByID(ctx, cost.CostCategoryID)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	if costCategory.TripID.String() != tripId.String() {
+		return nil, expense_errors.EXPENSE_NOT_FOUND
+	}
+
+	if serviceErr := cc.validateCostVisibility(ctx, cost); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	return cost, nil
+}
+
+// notifyCostCommentMentions sends a mail to every participant mentioned with @username in the comment.
+// Errors are only logged, as the comment has already been saved
+func (cc *CostController) notifyCostCommentMentions(ctx context.Context, tripId *uuid.UUID, cost *models.CostSchema, comment *models.CostCommentSchema) {
+	mentions := parseCostCommentMentions(comment.Content)
+	if len(mentions) == 0 {
+		return
+	}
+
+	trip, repoErr := cc.TripRepo.GetTripById(ctx, tripId)
+	if repoErr != nil {
+		log.Printf("Error while getting trip for comment mentions: %v", repoErr)
+		return
+	}
+
+	author, repoErr := cc.UserRepo.GetUserById(ctx, comment.UserID)
+	if repoErr != nil {
+		log.Printf("Error while getting author for comment mentions: %v", repoErr)
+		return
+	}
+
+	for _, username := range mentions {
+		// Unknown users, the author and users outside the trip are not notified
+		user, repoErr := cc.UserRepo.GetUserBySchema(ctx, &models.UserSchema{Username: username})
+		if repoErr != nil || user.UserID.String() == author.UserID.String() {
+			continue
+		}
+
+		if repoErr := cc.TripRepo.ValidateIfUserHasAccepted(ctx, tripId, user.UserID); repoErr != nil {
+			continue
+		}
+
+		mailData := &models.CostCommentMentionMail{
+			Username:        user.Username,
+			AuthorName:      author.Username,
+			TripName:        trip.Name,
+			CostDescription: cost.Description,
+			Comment:         comment.Content,
+			Subject:         author.Username + " mentioned you in " + trip.Name,
+			Recipients:      []string{user.Email},
+		}
+
+		if mailErr := cc.MailMgr.SendCostCommentMentionMail(ctx, mailData); mailErr != nil {
+			log.Printf("Error while sending comment mention mail to %v: %v", user.Username, mailErr)
+		}
+	}
+}
+
+// parseCostCommentMentions returns the distinct usernames mentioned with @username in the content
+func parseCostCommentMentions(content string) []string {
+	mentions := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, word := range strings.Fields(content) {
+		if !strings.HasPrefix(word, "@") {
+			continue
+		}
+
+		// Punctuation directly after a mention is not part of the username
+		username := strings.TrimRight(strings.TrimPrefix(word, "@"), ".,;:!?)")
+		if username == "" || seen[username] {
+			continue
+		}
+		seen[username] = true
+		mentions = append(mentions, username)
+	}
+
+	return mentions
+}
+
+func (cc *CostController) mapCostCommentToResponse(ctx context.Context, comment *models.CostCommentSchema) *models.CostCommentDTO {
+	response := &models.CostCommentDTO{
+		CostCommentID: comment.CostCommentID,
+		CostID:        comment.CostID,
+		Content:       comment.Content,
+		CreationDate:  comment.CreationDate.String(),
+	}
+
+	if author, repoErr := cc.UserRepo.GetUserById(ctx, comment.UserID); repoErr == nil {
+		response.Author = author.Username
+	}
+
+	return response
+}
+
+// publishCostEvent publishes a change of a cost, e.g. for the activity feed of the trip. Private costs are not shared with the
+// other participants. The previous amount is only part of the event if the amount changed
+func (cc *CostController) publishCostEvent(ctx context.Context, eventType string, tripId *uuid.UUID, cost *models.CostSchema, userId *uuid.UUID, previousAmount *decimal.Decimal) {
+	if cost.IsPrivate {
+		return
+	}
+
+	details := &models.TripEventDetails{
+		Name:   cost.Description,
+		Amount: cost.Amount.String(),
+	}
+
+	if previousAmount != nil && !previousAmount.Equal(cost.Amount) {
+		details.PreviousAmount = previousAmount.String()
+	}
+
+	cc.EventMgr.Publish(ctx, &models.TripEvent{
+		Type:      eventType,
+		TripID:    tripId,
+		UserID:    userId,
+		SubjectID: cost.CostID,
+		Details:   details,
+	})
+}
+
+// notifyIfBudgetThresholdCrossed sends a budget alert to all trip participants if the cost category passed its alert threshold.
+// Errors are only logged, as the cost entry has already been committed
+func (cc *CostController) notifyIfBudgetThresholdCrossed(ctx context.Context, tripId *uuid.UUID, costCategoryId *uuid.UUID, previousTotal decimal.Decimal) {
+	costCategory, repoErr := cc.CostCategoryRepo.GetCostCategoryByID(ctx, costCategoryId)
+	if repoErr != nil {
+		log.Printf("Error while getting cost category for budget alert: %v", repoErr)
+		return
+	}
+
+	currentTotal, repoErr := cc.CostRepo.GetTotalCostByCostCategoryID(ctx, costCategoryId)
+	if repoErr != nil {
+		log.Printf("Error while getting total cost for budget alert: %v", repoErr)
+		return
+	}
+
+	cc.sendBudgetAlertIfThresholdCrossed(ctx, tripId, costCategory, previousTotal, *currentTotal)
+
+	// The costs of a subcategory also count towards the budget of its parent, which changed by the same amount
+	if costCategory.ParentID == nil {
+		return
+	}
+
+	parent, repoErr := cc.CostCategoryRepo.GetCostCategoryByID(ctx, costCategory.ParentID)
+	if repoErr != nil {
+		log.Printf("Error while getting parent cost category for budget alert: %v", repoErr)
+		return
+	}
+
+	parentTotal, repoErr := cc.CostRepo.GetTotalCostByCostCategoryID(ctx, parent.CostCategoryID)
+	if repoErr != nil {
+		log.Printf("Error while getting total cost for budget alert: %v", repoErr)
+		return
+	}
+
+	previousParentTotal := parentTotal.Sub(currentTotal.Sub(previousTotal))
+	cc.sendBudgetAlertIfThresholdCrossed(ctx, tripId, parent, previousParentTotal, *parentTotal)
+}
+
+// sendBudgetAlertIfThresholdCrossed mails all participants of the trip in the background if the threshold was crossed
+func (cc *CostController) sendBudgetAlertIfThresholdCrossed(ctx context.Context, tripId *uuid.UUID, costCategory *models.CostCategorySchema, previousTotal decimal.Decimal, currentTotal decimal.Decimal) {
+	if !hasCrossedBudgetThreshold(costCategory, previousTotal, currentTotal) {
+		return
+	}
+
+	cc.JobMgr.RunJob("budget-alert", func(ctx context.Context) {
+		cc.sendBudgetAlertMails(ctx, tripId, costCategory, currentTotal)
+	})
+}
+
+func (cc *CostController) sendBudgetAlertMails(ctx context.Context, tripId *uuid.UUID, costCategory *models.CostCategorySchema, currentTotal decimal.Decimal) {
+	trip, repoErr := cc.TripRepo.GetTripById(ctx, tripId)
+	if repoErr != nil {
+		log.Printf("Error while getting trip for budget alert: %v", repoErr)
+		return
+	}
+
+	participants, repoErr := cc.TripRepo.GetAcceptedTripParticipants(ctx, tripId)
+	if repoErr != nil {
+		log.Printf("Error while getting trip participants for budget alert: %v", repoErr)
+		return
+	}
+
+	for _, participant := range participants {
+		user, repoErr := cc.UserRepo.GetUserById(ctx, participant.UserID)
+		if repoErr != nil {
+			log.Printf("Error while getting user for budget alert: %v", repoErr)
+			continue
+		}
+
+		mailData := &models.BudgetAlertMail{
+			Username:         user.Username,
+			TripName:         trip.Name,
+			CostCategoryName: costCategory.Name,
+			Budget:           costCategory.Budget.String(),
+			Spent:            currentTotal.String(),
+			PercentUsed:      calculateBudgetPercentUsed(*costCategory.Budget, currentTotal).String(),
+			Subject:          "Budget alert for " + costCategory.Name,
+			Recipients:       []string{user.Email},
+		}
+
+		if mailErr := cc.MailMgr.SendBudgetAlertMail(ctx, mailData); mailErr != nil {
+			log.Printf("Error while sending budget alert mail to %v: %v", user.Username, mailErr)
+		}
+	}
+}
+
+// bookCostTx applies the debts of a cost unless it is above the approval threshold of the trip. In that case the cost
+// stays pending and every debtor except the current user, who implicitly agrees, has to approve it first
+func (cc *CostController) bookCostTx(ctx context.Context, tx pgx.Tx, tripId *uuid.UUID, cost *models.CostSchema, creditor *models.UserSchema, contributions []*models.CostContributionSchema) *models.ExpenseServiceError {
+	trip, repoErr := cc.TripRepo.GetTripById(ctx, tripId)
+	if repoErr != nil {
+		return repoErr
+	}
+
+	// Reset decisions on previous versions of the cost
+	if repoErr := cc.CostApprovalRepo.DeleteCostApprovalsTx(ctx, tx, cost.CostID); repoErr != nil {
+		return repoErr
+	}
+
+	approvalPending := false
+	if !cost.IsPrivate && trip.ApprovalThreshold != nil && cost.Amount.GreaterThan(*trip.ApprovalThreshold) {
+		userId := ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID)
+		now := time.Now()
+
+		for _, contribution := range contributions {
+			if contribution.IsCreditor || contribution.Amount.IsZero() {
+				continue
+			}
+
+			// Guests cannot log in to decide on the cost
+			debtor, repoErr := cc.UserRepo.GetUserById(ctx, contribution.UserID)
+			if repoErr != nil {
+				return repoErr
+			}
+
+			if debtor.IsGuest {
+				continue
+			}
+
+			approval := &models.CostApprovalSchema{
+				CostID: cost.CostID,
+				UserID: contribution.UserID,
+				Status: models.CostStatusPending,
+			}
+
+			if contribution.UserID.String() == userId.String() {
+				approval.Status = models.CostStatusApproved
+				approval.DecisionDate = &now
+			} else {
+				approvalPending = true
+			}
+
+			if repoErr := cc.CostApprovalRepo.AddCostApprovalTx(ctx, tx, approval); repoErr != nil {
+				return repoErr
+			}
+		}
+	}
+
+	cost.Status = models.CostStatusApproved
+	if approvalPending {
+		cost.Status = models.CostStatusPending
+	}
+
+	if repoErr := cc.CostRepo.UpdateCostStatusTx(ctx, tx, cost.CostID, cost.Status); repoErr != nil {
+		return repoErr
+	}
+
+	if approvalPending {
+		return nil
+	}
+
+	return cc.applyCostDebtsTx(ctx, tx, tripId, cost, creditor, contributions, false)
+}
+
+// applyCostDebtsTx books the shares of the contributors of a cost. Shares are added to the debts towards the creditor,
+// for a cost paid from the kitty the kitty is the creditor. If revert is set, previously booked shares are taken back
+func (cc *CostController) applyCostDebtsTx(ctx context.Context, tx pgx.Tx, tripId *uuid.UUID, cost *models.CostSchema, creditor *models.UserSchema, contributions []*models.CostContributionSchema, revert bool) *models.ExpenseServiceError {
+	// Private costs do not create any debts
+	if cost.IsPrivate {
+		return nil
+	}
+
+	var kitty *models.KittySchema
+	if cost.PaidFromKitty {
+		var repoErr *models.ExpenseServiceError
+		kitty, repoErr = getOrCreateKittyTx(ctx, tx, cc.KittyRepo, cc.UserRepo, tripId)
+		if repoErr != nil {
+			return repoErr
+		}
+
+		if kitty.SettledAt != nil {
+			return expense_errors.EXPENSE_KITTY_SETTLED
+		}
+	}
+
+	for _, contribution := range contributions {
+		if contribution.IsCreditor {
+			continue
+		}
+
+		amount := contribution.Amount
+		if revert {
+			amount = amount.Neg()
+		}
+
+		if kitty != nil {
+			if repoErr := bookKittyDebtTx(ctx, tx, cc.DebtRepo, kitty, kitty.UserID, contribution.UserID, amount); repoErr != nil {
+				return repoErr
+			}
+			continue
+		}
+
+		if repoErr := cc.DebtRepo.CalculateDebt(ctx, tx, creditor.UserID, contribution.UserID, tripId, amount); repoErr != nil {
+			return repoErr
+		}
+	}
+
+	return nil
+}
+
+// getPresenceDebtorsTx returns the accepted participants of the trip that are present during the cost, read within the
+// transaction so that changed presence dates are taken into account
+func (cc *CostController) getPresenceDebtorsTx(ctx context.Context, tx pgx.Tx, tripId *uuid.UUID, cost *models.CostSchema) ([]*models.Contributor, *models.ExpenseServiceError) {
+	participants, repoErr := cc.TripRepo.GetAcceptedTripParticipantsTx(ctx, tx, tripId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	return cc.mapPresentParticipantsToDebtors(ctx, participants, cost)
+}
+
+// mapPresentParticipantsToDebtors returns the participants whose presence overlaps the period of the cost as debtors
+// without an amount, so that the cost is split equally between them
+func (cc *CostController) mapPresentParticipantsToDebtors(ctx context.Context, participants []*models.UserTripSchema, cost *models.CostSchema) ([]*models.Contributor, *models.ExpenseServiceError) {
+	costStart := truncateToDay(*cost.DeductionDate)
+	costEnd := costStart
+	if cost.EndDate != nil {
+		costEnd = truncateToDay(*cost.EndDate)
+	}
+
+	debtors := make([]*models.Contributor, 0, len(participants))
+	for _, participant := range participants {
+		if participant.PresenceStartDate != nil && truncateToDay(*participant.PresenceStartDate).After(costEnd) {
+			continue
+		}
+		if participant.PresenceEndDate != nil && truncateToDay(*participant.PresenceEndDate).Before(costStart) {
+			continue
+		}
+
+		user, repoErr := cc.UserRepo.GetUserById(ctx, participant.UserID)
+		if repoErr != nil {
+			return nil, repoErr
+		}
+
+		debtors = append(debtors, &models.Contributor{Username: user.Username})
+	}
+
+	// Nobody to split the cost between
+	if len(debtors) == 0 {
+		return nil, expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	return debtors, nil
+}
+
+// validateCostVisibility returns EXPENSE_NOT_FOUND if the cost is private and the user is not its creator
+func (cc *CostController) validateCostVisibility(ctx context.Context, cost *models.CostSchema) *models.ExpenseServiceError {
+	if !cost.IsPrivate {
+		return nil
+	}
+
+	userId, ok := ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID)
+	if !ok {
 		return expense_errors.EXPENSE_INTERNAL_ERROR
 	}
 
+	creator, repoErr := cc.CostRepo.GetCostCreditor(ctx, cost.CostID)
+	if repoErr != nil {
+		return repoErr
+	}
+
+	if creator.UserID.String() != userId.String() {
+		return expense_errors.EXPENSE_NOT_FOUND
+	}
+
+	return nil
+}
+
+// validateCostWritePermission checks if the user is allowed to change the cost.
+// Owners and admins can change all costs, members only the costs they created or paid for
+func (cc *CostController) validateCostWritePermission(ctx context.Context, tripId *uuid.UUID, cost *models.CostSchema) *models.ExpenseServiceError {
+	participant, serviceErr := validateTripPermission(ctx, cc.TripRepo, tripId, models.TripPermissionWriteCosts)
+	if serviceErr != nil {
+		return serviceErr
+	}
+
+	if utils.TripRoleHasPermission(participant.Role, models.TripPermissionManageCosts) {
+		return nil
+	}
+
+	if cost.CreatedBy != nil && cost.CreatedBy.String() == participant.UserID.String() {
+		return nil
+	}
+
+	// Costs paid from the kitty belong to no single participant
+	if cost.PaidFromKitty {
+		return expense_errors.EXPENSE_FORBIDDEN
+	}
+
+	creditor, repoErr := cc.CostRepo.GetCostCreditor(ctx, cost.CostID)
+	if repoErr != nil {
+		return repoErr
+	}
+
+	if creditor.UserID.String() != participant.UserID.String() {
+		return expense_errors.EXPENSE_FORBIDDEN
+	}
+
 	return nil
 }
 
 // You can add optional parameters with: func (cc *CostController) GetCostDetails(ctx context.Context, costId *uuid.UUID, optionalParam string) (*models.CostDTO, *models.ExpenseServiceError) {
 func (cc *CostController) mapCostToResponse(ctx context.Context, cost *models.CostSchema) *models.CostDTO {
 	response := &models.CostDTO{
-		CostID:         cost.CostID,
-		Amount:         cost.Amount.String(),
-		Description:    cost.Description,
-		CreationDate:   cost.CreationDate.String(),
-		DeductionDate:  cost.DeductionDate.String(),
-		CostCategoryID: cost.CostCategoryID,
+		CostID:          cost.CostID,
+		Amount:          cost.Amount.String(),
+		Description:     cost.Description,
+		CreationDate:    cost.CreationDate.String(),
+		DeductionDate:   cost.DeductionDate.String(),
+		CostCategoryID:  cost.CostCategoryID,
+		IsPrivate:       cost.IsPrivate,
+		PaidFromKitty:   cost.PaidFromKitty,
+		SplitByPresence: cost.SplitByPresence,
+		Status:          cost.Status,
 	}
 
 	if cost.EndDate != nil {
 		response.EndDate = cost.EndDate.String()
 	}
 
+	if cost.CreatedBy != nil {
+		if creator, repoErr := cc.UserRepo.GetUserById(ctx, cost.CreatedBy); repoErr == nil {
+			response.CreatedBy = creator.Username
+		}
+	}
+
+	if cost.UpdatedBy != nil {
+		if updater, repoErr := cc.UserRepo.GetUserById(ctx, cost.UpdatedBy); repoErr == nil {
+			response.UpdatedBy = updater.Username
+		}
+	}
+
+	approvals, _ := cc.CostApprovalRepo.GetCostApprovals(ctx, cost.CostID)
+	for _, approval := range approvals {
+		user, repoErr := cc.UserRepo.GetUserById(ctx, approval.UserID)
+		if repoErr != nil {
+			continue
+		}
+
+		approvalResponse := &models.CostApprovalDTO{
+			Username: user.Username,
+			Status:   approval.Status,
+		}
+
+		if approval.DecisionDate != nil {
+			approvalResponse.DecisionDate = approval.DecisionDate.String()
+		}
+
+		response.Approvals = append(response.Approvals, approvalResponse)
+	}
+
+	if tags, repoErr := cc.CostRepo.GetCostTags(ctx, cost.CostID); repoErr == nil && len(tags) > 0 {
+		response.Tags = tags
+	}
+
+	if commentCount, repoErr := cc.CostCommentRepo.CountCostComments(ctx, cost.CostID); repoErr == nil {
+		response.CommentCount = commentCount
+	}
+
 	contributions, _ := cc.CostRepo.GetCostContributors(ctx, cost.CostID)
 
 	response.Debtors = make([]*models.Contributor, len(contributions))
@@ -564,6 +2189,80 @@ func (cc *CostController) mapCostToResponse(ctx context.Context, cost *models.Co
 	return response
 }
 
+// applyCostCategoryRule assigns the cost category of the first matching rule of the trip to the request.
+// If the request has no contributors, the cost is split evenly between the default debtors of the rule
+func (cc *CostController) applyCostCategoryRule(ctx context.Context, tripId *uuid.UUID, request *models.CostDTO) *models.ExpenseServiceError {
+	amount, serviceErr := ValidateAmount(request.Amount)
+	if serviceErr != nil {
+		return serviceErr
+	}
+
+	costCategoryRule, serviceErr := findMatchingCostCategoryRule(ctx, cc.CostCategoryRuleRepo, tripId, request.Description, amount)
+	if serviceErr != nil {
+		return serviceErr
+	}
+
+	if costCategoryRule == nil {
+		return expense_errors.EXPENSE_NO_MATCHING_COST_CATEGORY_RULE
+	}
+	request.CostCategoryID = costCategoryRule.CostCategoryID
+
+	// Given contributors take precedence, costs split by presence determine their debtors themselves
+	if len(request.Debtors) > 0 || request.SplitByPresence {
+		return nil
+	}
+
+	debtorIds, repoErr := cc.CostCategoryRuleRepo.GetCostCategoryRuleDebtors(ctx, costCategoryRule.CostCategoryRuleID)
+	if repoErr != nil {
+		return repoErr
+	}
+
+	for _, debtorId := range debtorIds {
+		user, repoErr := cc.UserRepo.GetUserById(ctx, debtorId)
+		if repoErr != nil {
+			return repoErr
+		}
+		request.Debtors = append(request.Debtors, &models.Contributor{Username: user.Username})
+	}
+
+	return nil
+}
+
+// getCostCategoryOfTripTx returns the cost category if it belongs to the trip and is not in the trash. The category stays
+// share-locked until the end of the transaction, so it cannot be moved to the trash in the meantime
+func getCostCategoryOfTripTx(ctx context.Context, tx pgx.Tx, costCategoryRepo repositories.CostCategoryRepo, tripId *uuid.UUID, costCategoryId *uuid.UUID) (*models.CostCategorySchema, *models.ExpenseServiceError) {
+	costCategory, repoErr := costCategoryRepo.GetCostCategoryByIDForShareTx(ctx, tx, costCategoryId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	if costCategory.TripID.String() != tripId.String() {
+		return nil, expense_errors.EXPENSE_NOT_FOUND
+	}
+
+	return costCategory, nil
+}
+
+// normalizeCostTags trims and lowercases the tags of a cost and removes duplicates
+func normalizeCostTags(tags []string) ([]string, *models.ExpenseServiceError) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || len(tag) > maxCostTagLength {
+			return nil, expense_errors.EXPENSE_BAD_REQUEST
+		}
+
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	return normalized, nil
+}
+
 // ValidateAmount validates the amount of a cost entry, converts it to decimal and rounds it down to 2 decimal places
 func ValidateAmount(amount string) (decimal.Decimal, *models.ExpenseServiceError) {
 	amountDecimal, err := decimal.NewFromString(amount)
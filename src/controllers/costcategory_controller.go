@@ -2,29 +2,49 @@ package controllers
 
 import (
 	"context"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/expense_errors"
 	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/managers"
 	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
 	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/repositories"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"log"
 )
 
+// defaultBudgetAlertThresholdPercent is used if a budget is set without an alert threshold
+const defaultBudgetAlertThresholdPercent = 100
+
 // CostCategoryCtl Exposed interface to the handler-package
 type CostCategoryCtl interface {
 	CreateCostCategory(ctx context.Context, tripId *uuid.UUID, costCategoryRequest models.CostCategoryPostRequest) (*models.CostCategoryResponse, *models.ExpenseServiceError)
-	PatchCostCategory(ctx context.Context, costCategoryId *uuid.UUID, costCategoryRequest models.CostCategoryPatchRequest) (*models.CostCategoryResponse, *models.ExpenseServiceError)
+	PatchCostCategory(ctx context.Context, tripId *uuid.UUID, costCategoryId *uuid.UUID, costCategoryRequest models.CostCategoryPatchRequest) (*models.CostCategoryResponse, *models.ExpenseServiceError)
 	GetCostCategoryDetails(ctx context.Context, costCategoryId *uuid.UUID) (*models.CostCategoryResponse, *models.ExpenseServiceError)
-	DeleteCostCategory(ctx context.Context, costCategoryId *uuid.UUID) *models.ExpenseServiceError
+	DeleteCostCategory(ctx context.Context, tripId *uuid.UUID, costCategoryId *uuid.UUID, moveToCostCategoryId *uuid.UUID) *models.ExpenseServiceError
+	MergeCostCategory(ctx context.Context, tripId *uuid.UUID, costCategoryId *uuid.UUID, mergeRequest models.CostCategoryMergeRequest) (*models.CostCategoryResponse, *models.ExpenseServiceError)
 	GetCostCategoryEntries(ctx context.Context, tripId *uuid.UUID) ([]*models.CostCategoryResponse, *models.ExpenseServiceError)
+	GetCostCategoryPresets(ctx context.Context, locale string) []*models.CostCategoryPresetDTO
+	RestoreCostCategory(ctx context.Context, tripId *uuid.UUID, costCategoryId *uuid.UUID) (*models.CostCategoryResponse, *models.ExpenseServiceError)
 }
 
 // CostCategoryController Cost Category Controller structure
 type CostCategoryController struct {
 	DatabaseMgr      managers.DatabaseMgr
+	EventMgr         managers.EventMgr
 	CostCategoryRepo repositories.CostCategoryRepo
 	CostRepo         repositories.CostRepo
+	PlannedCostRepo  repositories.PlannedCostRepo
+	TripRepo         repositories.TripRepo
+
+	CostCategoryRuleRepo repositories.CostCategoryRuleRepo
 }
 
 func (ccc *CostCategoryController) CreateCostCategory(ctx context.Context, tripId *uuid.UUID, createCostCategoryRequest models.CostCategoryPostRequest) (*models.CostCategoryResponse, *models.ExpenseServiceError) {
+	// Check if user is allowed to manage cost categories
+	if _, serviceErr := validateTripPermission(ctx, ccc.TripRepo, tripId, models.TripPermissionManageCostCategories); serviceErr != nil {
+		return nil, serviceErr
+	}
+
 	// Generate cost category id
 	costCategoryId := uuid.New()
 
@@ -38,20 +58,54 @@ func (ccc *CostCategoryController) CreateCostCategory(ctx context.Context, tripI
 		TripID:         tripId,
 	}
 
+	// Validate optional parent
+	if createCostCategoryRequest.ParentId != nil {
+		if serviceErr := ccc.validateCostCategoryParent(ctx, tripId, &costCategoryId, createCostCategoryRequest.ParentId); serviceErr != nil {
+			return nil, serviceErr
+		}
+		costCategory.ParentID = createCostCategoryRequest.ParentId
+	}
+
+	// Validate optional budget
+	budget, serviceErr := validateCostCategoryBudget(createCostCategoryRequest.Budget, createCostCategoryRequest.AlertThresholdPercent)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+	costCategory.Budget = budget
+	costCategory.AlertThresholdPercent = createCostCategoryRequest.AlertThresholdPercent
+
 	if err := ccc.CostCategoryRepo.CreateCostCategory(ctx, costCategory); err != nil {
 		return nil, err
 	}
 
+	ccc.EventMgr.Publish(ctx, &models.TripEvent{
+		Type:      models.TripEventCostCategoryCreated,
+		TripID:    tripId,
+		UserID:    ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID),
+		SubjectID: costCategory.CostCategoryID,
+		Details:   &models.TripEventDetails{Name: costCategory.Name},
+	})
+
 	return ccc.responseBuilder(ctx, costCategory), nil
 }
 
-func (ccc *CostCategoryController) PatchCostCategory(ctx context.Context, costCategoryId *uuid.UUID, costCategoryPatchRequest models.CostCategoryPatchRequest) (*models.CostCategoryResponse, *models.ExpenseServiceError) {
+func (ccc *CostCategoryController) PatchCostCategory(ctx context.Context, tripId *uuid.UUID, costCategoryId *uuid.UUID, costCategoryPatchRequest models.CostCategoryPatchRequest) (*models.CostCategoryResponse, *models.ExpenseServiceError) {
+	// Check if user is allowed to manage cost categories
+	if _, serviceErr := validateTripPermission(ctx, ccc.TripRepo, tripId, models.TripPermissionManageCostCategories); serviceErr != nil {
+		return nil, serviceErr
+	}
+
 	// Get cost category from database
 	costCategory, err := ccc.CostCategoryRepo.GetCostCategoryByID(ctx, costCategoryId)
 	if err != nil {
 		return nil, err
 	}
 
+	// Cost category has to belong to the trip
+	if costCategory.TripID.String() != tripId.String() {
+		return nil, expense_errors.EXPENSE_NOT_FOUND
+	}
+
 	// Update cost category object
 	if costCategoryPatchRequest.Name != "" {
 		costCategory.Name = costCategoryPatchRequest.Name
@@ -69,6 +123,49 @@ func (ccc *CostCategoryController) PatchCostCategory(ctx context.Context, costCa
 		costCategory.Color = costCategoryPatchRequest.Color
 	}
 
+	if costCategoryPatchRequest.ParentId != nil && costCategoryPatchRequest.RemoveParent {
+		return nil, expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	if costCategoryPatchRequest.ParentId != nil {
+		if serviceErr := ccc.validateCostCategoryParent(ctx, tripId, costCategoryId, costCategoryPatchRequest.ParentId); serviceErr != nil {
+			return nil, serviceErr
+		}
+		costCategory.ParentID = costCategoryPatchRequest.ParentId
+	}
+
+	if costCategoryPatchRequest.RemoveParent {
+		costCategory.ParentID = nil
+	}
+
+	if costCategoryPatchRequest.Budget != nil && *costCategoryPatchRequest.Budget == "" {
+		// An alert threshold without a budget is meaningless
+		if costCategoryPatchRequest.AlertThresholdPercent != nil {
+			return nil, expense_errors.EXPENSE_BAD_REQUEST
+		}
+
+		costCategory.Budget = nil
+		costCategory.AlertThresholdPercent = nil
+	} else if costCategoryPatchRequest.Budget != nil || costCategoryPatchRequest.AlertThresholdPercent != nil {
+		var budgetRequest string
+		if costCategoryPatchRequest.Budget != nil {
+			budgetRequest = *costCategoryPatchRequest.Budget
+		}
+
+		budget, serviceErr := validateCostCategoryBudget(budgetRequest, costCategoryPatchRequest.AlertThresholdPercent)
+		if serviceErr != nil {
+			return nil, serviceErr
+		}
+
+		if budget != nil {
+			costCategory.Budget = budget
+		}
+
+		if costCategoryPatchRequest.AlertThresholdPercent != nil {
+			costCategory.AlertThresholdPercent = costCategoryPatchRequest.AlertThresholdPercent
+		}
+	}
+
 	// Update cost category in database
 	if err := ccc.CostCategoryRepo.UpdateCostCategory(ctx, costCategory); err != nil {
 		return nil, err
@@ -86,8 +183,214 @@ func (ccc *CostCategoryController) GetCostCategoryDetails(ctx context.Context, c
 	return ccc.responseBuilder(ctx, costCategory), nil
 }
 
-func (ccc *CostCategoryController) DeleteCostCategory(ctx context.Context, costCategoryId *uuid.UUID) *models.ExpenseServiceError {
-	return ccc.CostCategoryRepo.DeleteCostCategory(ctx, costCategoryId)
+// DeleteCostCategory moves a cost category to the trash. Categories with costs or subcategories can only be deleted if they are moved
+// to another category of the trip, deleting the costs would leave the debts of the trip behind
+func (ccc *CostCategoryController) DeleteCostCategory(ctx context.Context, tripId *uuid.UUID, costCategoryId *uuid.UUID, moveToCostCategoryId *uuid.UUID) *models.ExpenseServiceError {
+	// Check if user is allowed to manage cost categories
+	if _, serviceErr := validateTripPermission(ctx, ccc.TripRepo, tripId, models.TripPermissionManageCostCategories); serviceErr != nil {
+		return serviceErr
+	}
+
+	// Cost category has to belong to the trip
+	costCategory, repoErr := ccc.CostCategoryRepo.GetCostCategoryByID(ctx, costCategoryId)
+	if repoErr != nil {
+		return repoErr
+	}
+
+	if costCategory.TripID.String() != tripId.String() {
+		return expense_errors.EXPENSE_NOT_FOUND
+	}
+
+	if moveToCostCategoryId != nil {
+		_, serviceErr := ccc.mergeCostCategory(ctx, tripId, costCategoryId, moveToCostCategoryId)
+		return serviceErr
+	}
+
+	// Begin transaction
+	tx, err := ccc.DatabaseMgr.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Error while beginning transaction: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// Make sure to rollback the transaction if it fails
+	defer func(tx pgx.Tx) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			log.Printf("Error while rolling back transaction: %v", err)
+		}
+	}(tx)
+
+	costCount, repoErr := ccc.CostRepo.CountCostsByCostCategoryIDTx(ctx, tx, costCategoryId)
+	if repoErr != nil {
+		return repoErr
+	}
+
+	if costCount > 0 {
+		return expense_errors.EXPENSE_COST_CATEGORY_NOT_EMPTY
+	}
+
+	subcategoryCount, repoErr := ccc.CostCategoryRepo.CountSubcategoriesTx(ctx, tx, costCategoryId)
+	if repoErr != nil {
+		return repoErr
+	}
+
+	if subcategoryCount > 0 {
+		return expense_errors.EXPENSE_COST_CATEGORY_NOT_EMPTY
+	}
+
+	if repoErr := ccc.CostCategoryRepo.DeleteCostCategoryTx(ctx, tx, costCategoryId); repoErr != nil {
+		return repoErr
+	}
+
+	// If everything went well, commit the transaction
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error while committing transaction: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return nil
+}
+
+// RestoreCostCategory moves a deleted cost category out of the trash. A subcategory can only be restored while its parent
+// is a top-level category that is not in the trash, the costs deleted with the category have to be restored one by one
+func (ccc *CostCategoryController) RestoreCostCategory(ctx context.Context, tripId *uuid.UUID, costCategoryId *uuid.UUID) (*models.CostCategoryResponse, *models.ExpenseServiceError) {
+	// Check if user is allowed to manage cost categories
+	if _, serviceErr := validateTripPermission(ctx, ccc.TripRepo, tripId, models.TripPermissionManageCostCategories); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	// Cost category has to belong to the trip
+	costCategory, repoErr := ccc.CostCategoryRepo.GetDeletedCostCategoryByID(ctx, costCategoryId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	if costCategory.TripID.String() != tripId.String() {
+		return nil, expense_errors.EXPENSE_NOT_FOUND
+	}
+
+	if costCategory.ParentID != nil {
+		parent, repoErr := ccc.CostCategoryRepo.GetCostCategoryByID(ctx, costCategory.ParentID)
+		if repoErr != nil {
+			if repoErr == expense_errors.EXPENSE_NOT_FOUND {
+				return nil, expense_errors.EXPENSE_CONFLICT
+			}
+			return nil, repoErr
+		}
+
+		// The parent may have become a subcategory itself while this category was in the trash
+		if parent.ParentID != nil {
+			return nil, expense_errors.EXPENSE_CONFLICT
+		}
+	}
+
+	if repoErr := ccc.CostCategoryRepo.RestoreCostCategory(ctx, costCategoryId); repoErr != nil {
+		return nil, repoErr
+	}
+
+	costCategory.DeletedAt = nil
+	ccc.EventMgr.Publish(ctx, &models.TripEvent{
+		Type:      models.TripEventCostCategoryRestored,
+		TripID:    tripId,
+		UserID:    ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID),
+		SubjectID: costCategory.CostCategoryID,
+		Details:   &models.TripEventDetails{Name: costCategory.Name},
+	})
+
+	return ccc.responseBuilder(ctx, costCategory), nil
+}
+
+// MergeCostCategory moves all costs, planned costs and subcategories of a cost category to the target category and deletes it.
+// The amounts and debtors of the costs stay the same, so the debts of the trip are not affected
+func (ccc *CostCategoryController) MergeCostCategory(ctx context.Context, tripId *uuid.UUID, costCategoryId *uuid.UUID, mergeRequest models.CostCategoryMergeRequest) (*models.CostCategoryResponse, *models.ExpenseServiceError) {
+	// Check if user is allowed to manage cost categories
+	if _, serviceErr := validateTripPermission(ctx, ccc.TripRepo, tripId, models.TripPermissionManageCostCategories); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	// Cost category has to belong to the trip
+	costCategory, repoErr := ccc.CostCategoryRepo.GetCostCategoryByID(ctx, costCategoryId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	if costCategory.TripID.String() != tripId.String() {
+		return nil, expense_errors.EXPENSE_NOT_FOUND
+	}
+
+	return ccc.mergeCostCategory(ctx, tripId, costCategoryId, mergeRequest.TargetCostCategoryId)
+}
+
+func (ccc *CostCategoryController) mergeCostCategory(ctx context.Context, tripId *uuid.UUID, sourceCostCategoryId *uuid.UUID, targetCostCategoryId *uuid.UUID) (*models.CostCategoryResponse, *models.ExpenseServiceError) {
+	if *sourceCostCategoryId == *targetCostCategoryId {
+		return nil, expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	// Begin transaction
+	tx, err := ccc.DatabaseMgr.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Error while beginning transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// Make sure to rollback the transaction if it fails
+	defer func(tx pgx.Tx) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			log.Printf("Error while rolling back transaction: %v", err)
+		}
+	}(tx)
+
+	// Target has to belong to the same trip and must not be in the trash
+	target, serviceErr := getCostCategoryOfTripTx(ctx, tx, ccc.CostCategoryRepo, tripId, targetCostCategoryId)
+	if serviceErr != nil {
+		if serviceErr == expense_errors.EXPENSE_NOT_FOUND {
+			return nil, expense_errors.EXPENSE_BAD_REQUEST
+		}
+		return nil, serviceErr
+	}
+
+	if repoErr := ccc.CostRepo.MoveCostsToCostCategoryTx(ctx, tx, sourceCostCategoryId, targetCostCategoryId); repoErr != nil {
+		return nil, repoErr
+	}
+
+	if repoErr := ccc.PlannedCostRepo.MovePlannedCostsToCostCategoryTx(ctx, tx, sourceCostCategoryId, targetCostCategoryId); repoErr != nil {
+		return nil, repoErr
+	}
+
+	// Rules of the merged category assign their costs to the target from now on
+	if repoErr := ccc.CostCategoryRuleRepo.MoveCostCategoryRulesTx(ctx, tx, sourceCostCategoryId, targetCostCategoryId); repoErr != nil {
+		return nil, repoErr
+	}
+
+	// Subcategories can only be moved to a top-level category
+	subcategoryCount, repoErr := ccc.CostCategoryRepo.CountSubcategoriesTx(ctx, tx, sourceCostCategoryId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	if subcategoryCount > 0 {
+		if target.ParentID != nil {
+			return nil, expense_errors.EXPENSE_BAD_REQUEST
+		}
+
+		if repoErr := ccc.CostCategoryRepo.MoveSubcategoriesTx(ctx, tx, sourceCostCategoryId, targetCostCategoryId); repoErr != nil {
+			return nil, repoErr
+		}
+	}
+
+	if repoErr := ccc.CostCategoryRepo.DeleteCostCategoryTx(ctx, tx, sourceCostCategoryId); repoErr != nil {
+		return nil, repoErr
+	}
+
+	// If everything went well, commit the transaction
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error while committing transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return ccc.responseBuilder(ctx, target), nil
 }
 
 func (ccc *CostCategoryController) GetCostCategoryEntries(ctx context.Context, tripId *uuid.UUID) ([]*models.CostCategoryResponse, *models.ExpenseServiceError) {
@@ -111,12 +414,115 @@ func (ccc *CostCategoryController) responseBuilder(ctx context.Context, costCate
 		return nil
 	}
 
-	return &models.CostCategoryResponse{
+	response := &models.CostCategoryResponse{
 		CostCategoryId: costCategories.CostCategoryID,
 		Name:           costCategories.Name,
 		Description:    costCategories.Description,
 		Icon:           costCategories.Icon,
 		Color:          costCategories.Color,
+		ParentId:       costCategories.ParentID,
 		TotalCost:      totalCost.String(),
 	}
+	mapCostCategoryBudget(response, costCategories, *totalCost)
+
+	return response
+}
+
+// validateCostCategoryParent checks that the parent is a top-level category of the same trip. Categories are nested one
+// level deep, so a category that has subcategories itself cannot get a parent
+func (ccc *CostCategoryController) validateCostCategoryParent(ctx context.Context, tripId *uuid.UUID, costCategoryId *uuid.UUID, parentId *uuid.UUID) *models.ExpenseServiceError {
+	if *parentId == *costCategoryId {
+		return expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	parent, repoErr := ccc.CostCategoryRepo.GetCostCategoryByID(ctx, parentId)
+	if repoErr != nil {
+		if repoErr == expense_errors.EXPENSE_NOT_FOUND {
+			return expense_errors.EXPENSE_BAD_REQUEST
+		}
+		return repoErr
+	}
+
+	if parent.TripID.String() != tripId.String() || parent.ParentID != nil {
+		return expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	costCategories, repoErr := ccc.CostCategoryRepo.GetCostCategoriesByTripID(ctx, tripId)
+	if repoErr != nil {
+		return repoErr
+	}
+
+	for _, costCategory := range costCategories {
+		if costCategory.ParentID != nil && *costCategory.ParentID == *costCategoryId {
+			return expense_errors.EXPENSE_BAD_REQUEST
+		}
+	}
+
+	return nil
+}
+
+// validateCostCategoryBudget validates the optional budget and alert threshold of a cost category.
+// Returns nil if no budget was given
+func validateCostCategoryBudget(budget string, alertThresholdPercent *int) (*decimal.Decimal, *models.ExpenseServiceError) {
+	if alertThresholdPercent != nil && (*alertThresholdPercent < 1 || *alertThresholdPercent > 100) {
+		return nil, expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	if budget == "" {
+		return nil, nil
+	}
+
+	budgetDecimal, err := ValidateAmount(budget)
+	if err != nil {
+		return nil, err
+	}
+
+	return &budgetDecimal, nil
+}
+
+// mapCostCategoryBudget adds the budget information to the response if the cost category has a budget
+func mapCostCategoryBudget(response *models.CostCategoryResponse, costCategory *models.CostCategorySchema, totalCost decimal.Decimal) {
+	if costCategory.Budget == nil {
+		return
+	}
+
+	response.Budget = costCategory.Budget.String()
+	response.AlertThresholdPercent = costCategory.AlertThresholdPercent
+	response.Spent = totalCost.String()
+	response.Remaining = costCategory.Budget.Sub(totalCost).String()
+	response.PercentUsed = calculateBudgetPercentUsed(*costCategory.Budget, totalCost).String()
+}
+
+// calculateBudgetPercentUsed returns the used percentage of the budget rounded to 2 decimal places
+func calculateBudgetPercentUsed(budget decimal.Decimal, spent decimal.Decimal) decimal.Decimal {
+	if budget.IsZero() {
+		return decimal.Zero
+	}
+
+	return spent.Div(budget).Mul(decimal.NewFromInt(100)).Round(2)
+}
+
+// hasCrossedBudgetThreshold checks if the spent amount of a cost category passed its alert threshold
+func hasCrossedBudgetThreshold(costCategory *models.CostCategorySchema, previousTotal decimal.Decimal, currentTotal decimal.Decimal) bool {
+	if costCategory.Budget == nil || costCategory.Budget.IsZero() {
+		return false
+	}
+
+	thresholdPercent := defaultBudgetAlertThresholdPercent
+	if costCategory.AlertThresholdPercent != nil {
+		thresholdPercent = *costCategory.AlertThresholdPercent
+	}
+
+	thresholdAmount := costCategory.Budget.Mul(decimal.NewFromInt(int64(thresholdPercent))).Div(decimal.NewFromInt(100))
+	return previousTotal.LessThan(thresholdAmount) && currentTotal.GreaterThanOrEqual(thresholdAmount)
+}
+
+// GetCostCategoryPresets returns the catalogue of cost category presets with names in the given locale
+func (ccc *CostCategoryController) GetCostCategoryPresets(ctx context.Context, locale string) []*models.CostCategoryPresetDTO {
+	presets := make([]*models.CostCategoryPresetDTO, len(costCategoryPresets))
+	for i := range costCategoryPresets {
+		presets[i] = mapCostCategoryPresetToResponse(&costCategoryPresets[i], locale)
+	}
+
+	return presets
 }
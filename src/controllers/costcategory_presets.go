@@ -0,0 +1,105 @@
+package controllers
+
+import "github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
+
+const defaultPresetLocale = "en"
+
+// costCategoryPreset A set of cost categories a trip can be created with. Names are keyed by locale
+type costCategoryPreset struct {
+	Key        string
+	Names      map[string]string
+	Categories []costCategoryPresetEntry
+}
+
+type costCategoryPresetEntry struct {
+	Names map[string]string
+	Icon  string
+	Color string
+}
+
+var (
+	presetFood          = costCategoryPresetEntry{Names: map[string]string{"en": "Food & Drinks", "de": "Essen & Trinken"}, Icon: "restaurant", Color: "#E57373"}
+	presetAccommodation = costCategoryPresetEntry{Names: map[string]string{"en": "Accommodation", "de": "Unterkunft"}, Icon: "hotel", Color: "#64B5F6"}
+	presetTransport     = costCategoryPresetEntry{Names: map[string]string{"en": "Transport", "de": "Transport"}, Icon: "directions_car", Color: "#FFB74D"}
+	presetActivities    = costCategoryPresetEntry{Names: map[string]string{"en": "Activities", "de": "Aktivitäten"}, Icon: "local_activity", Color: "#81C784"}
+	presetGroceries     = costCategoryPresetEntry{Names: map[string]string{"en": "Groceries", "de": "Lebensmittel"}, Icon: "shopping_cart", Color: "#A1887F"}
+	presetOther         = costCategoryPresetEntry{Names: map[string]string{"en": "Other", "de": "Sonstiges"}, Icon: "more_horiz", Color: "#90A4AE"}
+)
+
+// costCategoryPresets is the catalogue of presets that can be chosen when creating a trip
+var costCategoryPresets = []costCategoryPreset{
+	{
+		Key:   "city-trip",
+		Names: map[string]string{"en": "City trip", "de": "Städtereise"},
+		Categories: []costCategoryPresetEntry{
+			presetFood,
+			presetAccommodation,
+			presetTransport,
+			{Names: map[string]string{"en": "Sightseeing", "de": "Sehenswürdigkeiten"}, Icon: "museum", Color: "#BA68C8"},
+			{Names: map[string]string{"en": "Shopping", "de": "Einkaufen"}, Icon: "shopping_bag", Color: "#F06292"},
+			presetOther,
+		},
+	},
+	{
+		Key:   "camping",
+		Names: map[string]string{"en": "Camping", "de": "Camping"},
+		Categories: []costCategoryPresetEntry{
+			presetGroceries,
+			{Names: map[string]string{"en": "Campsite", "de": "Campingplatz"}, Icon: "cabin", Color: "#4DB6AC"},
+			{Names: map[string]string{"en": "Fuel", "de": "Treibstoff"}, Icon: "local_gas_station", Color: "#FFB74D"},
+			{Names: map[string]string{"en": "Equipment", "de": "Ausrüstung"}, Icon: "backpack", Color: "#7986CB"},
+			presetActivities,
+			presetOther,
+		},
+	},
+	{
+		Key:   "ski",
+		Names: map[string]string{"en": "Ski trip", "de": "Skiurlaub"},
+		Categories: []costCategoryPresetEntry{
+			presetAccommodation,
+			{Names: map[string]string{"en": "Ski pass", "de": "Skipass"}, Icon: "downhill_skiing", Color: "#4FC3F7"},
+			{Names: map[string]string{"en": "Equipment rental", "de": "Ausrüstungsverleih"}, Icon: "snowboarding", Color: "#7986CB"},
+			presetFood,
+			presetTransport,
+			presetOther,
+		},
+	},
+}
+
+// getCostCategoryPreset returns the preset with the given key
+func getCostCategoryPreset(key string) (*costCategoryPreset, bool) {
+	for i := range costCategoryPresets {
+		if costCategoryPresets[i].Key == key {
+			return &costCategoryPresets[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// localizePresetName returns the name in the given locale, falling back to the default locale
+func localizePresetName(names map[string]string, locale string) string {
+	if name, ok := names[locale]; ok {
+		return name
+	}
+
+	return names[defaultPresetLocale]
+}
+
+func mapCostCategoryPresetToResponse(preset *costCategoryPreset, locale string) *models.CostCategoryPresetDTO {
+	response := &models.CostCategoryPresetDTO{
+		Key:        preset.Key,
+		Name:       localizePresetName(preset.Names, locale),
+		Categories: make([]models.CostCategoryPresetEntryDTO, len(preset.Categories)),
+	}
+
+	for i, entry := range preset.Categories {
+		response.Categories[i] = models.CostCategoryPresetEntryDTO{
+			Name:  localizePresetName(entry.Names, locale),
+			Icon:  entry.Icon,
+			Color: entry.Color,
+		}
+	}
+
+	return response
+}
@@ -0,0 +1,443 @@
+package controllers
+
+import (
+	"context"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/expense_errors"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/managers"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/repositories"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"log"
+	"strings"
+	"time"
+)
+
+// CostCategoryRuleCtl Exposed interface to the handler-package
+type CostCategoryRuleCtl interface {
+	CreateCostCategoryRule(ctx context.Context, tripId *uuid.UUID, request models.CostCategoryRuleRequest) (*models.CostCategoryRuleDTO, *models.ExpenseServiceError)
+	GetCostCategoryRules(ctx context.Context, tripId *uuid.UUID) ([]*models.CostCategoryRuleDTO, *models.ExpenseServiceError)
+	PatchCostCategoryRule(ctx context.Context, tripId *uuid.UUID, costCategoryRuleId *uuid.UUID, request models.CostCategoryRuleRequest) (*models.CostCategoryRuleDTO, *models.ExpenseServiceError)
+	DeleteCostCategoryRule(ctx context.Context, tripId *uuid.UUID, costCategoryRuleId *uuid.UUID) *models.ExpenseServiceError
+	TestCostCategoryRules(ctx context.Context, tripId *uuid.UUID) ([]*models.CostCategoryRuleTestDTO, *models.ExpenseServiceError)
+}
+
+// CostCategoryRuleController Cost Category Rule Controller structure
+type CostCategoryRuleController struct {
+	DatabaseMgr          managers.DatabaseMgr
+	CostCategoryRuleRepo repositories.CostCategoryRuleRepo
+	CostCategoryRepo     repositories.CostCategoryRepo
+	CostRepo             repositories.CostRepo
+	UserRepo             repositories.UserRepo
+	TripRepo             repositories.TripRepo
+}
+
+// CreateCostCategoryRule Creates a rule that assigns costs without a cost category to the category of the rule
+func (ccrc *CostCategoryRuleController) CreateCostCategoryRule(ctx context.Context, tripId *uuid.UUID, request models.CostCategoryRuleRequest) (*models.CostCategoryRuleDTO, *models.ExpenseServiceError) {
+	// Check if user is allowed to manage cost categories
+	if _, serviceErr := validateTripPermission(ctx, ccrc.TripRepo, tripId, models.TripPermissionManageCostCategories); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	costCategoryRuleId := uuid.New()
+	now := time.Now()
+
+	costCategoryRule := &models.CostCategoryRuleSchema{
+		CostCategoryRuleID: &costCategoryRuleId,
+		TripID:             tripId,
+		CreationDate:       &now,
+	}
+
+	keywords, debtorIds, serviceErr := ccrc.applyCostCategoryRuleRequest(ctx, tripId, costCategoryRule, make([]string, 0), make([]*uuid.UUID, 0), request)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	// Begin transaction
+	tx, err := ccrc.DatabaseMgr.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Error while beginning transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// Make sure to rollback the transaction if it fails
+	defer func(tx pgx.Tx) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			log.Printf("Error while rolling back transaction: %v", err)
+		}
+	}(tx)
+
+	if repoErr := ccrc.CostCategoryRuleRepo.AddCostCategoryRuleTx(ctx, tx, costCategoryRule); repoErr != nil {
+		return nil, repoErr
+	}
+
+	if repoErr := ccrc.CostCategoryRuleRepo.ReplaceCostCategoryRuleKeywordsTx(ctx, tx, costCategoryRule.CostCategoryRuleID, keywords); repoErr != nil {
+		return nil, repoErr
+	}
+
+	if repoErr := ccrc.CostCategoryRuleRepo.ReplaceCostCategoryRuleDebtorsTx(ctx, tx, costCategoryRule.CostCategoryRuleID, debtorIds); repoErr != nil {
+		return nil, repoErr
+	}
+
+	// If everything went well, commit the transaction
+	if err = tx.Commit(ctx); err != nil {
+		log.Printf("Error while committing transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return ccrc.mapCostCategoryRuleToResponse(ctx, costCategoryRule, keywords, debtorIds)
+}
+
+// GetCostCategoryRules Returns the rules of a trip in the order they are checked
+func (ccrc *CostCategoryRuleController) GetCostCategoryRules(ctx context.Context, tripId *uuid.UUID) ([]*models.CostCategoryRuleDTO, *models.ExpenseServiceError) {
+	costCategoryRules, repoErr := ccrc.CostCategoryRuleRepo.GetCostCategoryRulesByTripID(ctx, tripId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	response := make([]*models.CostCategoryRuleDTO, 0, len(costCategoryRules))
+	for _, costCategoryRule := range costCategoryRules {
+		keywords, debtorIds, repoErr := ccrc.getCostCategoryRuleConditions(ctx, costCategoryRule.CostCategoryRuleID)
+		if repoErr != nil {
+			return nil, repoErr
+		}
+
+		ruleResponse, serviceErr := ccrc.mapCostCategoryRuleToResponse(ctx, costCategoryRule, keywords, debtorIds)
+		if serviceErr != nil {
+			return nil, serviceErr
+		}
+		response = append(response, ruleResponse)
+	}
+
+	return response, nil
+}
+
+// PatchCostCategoryRule Updates a rule of the trip, fields that are not set in the request stay unchanged
+func (ccrc *CostCategoryRuleController) PatchCostCategoryRule(ctx context.Context, tripId *uuid.UUID, costCategoryRuleId *uuid.UUID, request models.CostCategoryRuleRequest) (*models.CostCategoryRuleDTO, *models.ExpenseServiceError) {
+	// Check if user is allowed to manage cost categories
+	if _, serviceErr := validateTripPermission(ctx, ccrc.TripRepo, tripId, models.TripPermissionManageCostCategories); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	costCategoryRule, serviceErr := ccrc.getCostCategoryRuleOfTrip(ctx, tripId, costCategoryRuleId)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	keywords, debtorIds, repoErr := ccrc.getCostCategoryRuleConditions(ctx, costCategoryRuleId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	keywords, debtorIds, serviceErr = ccrc.applyCostCategoryRuleRequest(ctx, tripId, costCategoryRule, keywords, debtorIds, request)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	// Begin transaction
+	tx, err := ccrc.DatabaseMgr.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Error while beginning transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// Make sure to rollback the transaction if it fails
+	defer func(tx pgx.Tx) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			log.Printf("Error while rolling back transaction: %v", err)
+		}
+	}(tx)
+
+	if repoErr := ccrc.CostCategoryRuleRepo.UpdateCostCategoryRuleTx(ctx, tx, costCategoryRule); repoErr != nil {
+		return nil, repoErr
+	}
+
+	if request.Keywords != nil {
+		if repoErr := ccrc.CostCategoryRuleRepo.ReplaceCostCategoryRuleKeywordsTx(ctx, tx, costCategoryRuleId, keywords); repoErr != nil {
+			return nil, repoErr
+		}
+	}
+
+	if request.Debtors != nil {
+		if repoErr := ccrc.CostCategoryRuleRepo.ReplaceCostCategoryRuleDebtorsTx(ctx, tx, costCategoryRuleId, debtorIds); repoErr != nil {
+			return nil, repoErr
+		}
+	}
+
+	// If everything went well, commit the transaction
+	if err = tx.Commit(ctx); err != nil {
+		log.Printf("Error while committing transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return ccrc.mapCostCategoryRuleToResponse(ctx, costCategoryRule, keywords, debtorIds)
+}
+
+func (ccrc *CostCategoryRuleController) DeleteCostCategoryRule(ctx context.Context, tripId *uuid.UUID, costCategoryRuleId *uuid.UUID) *models.ExpenseServiceError {
+	// Check if user is allowed to manage cost categories
+	if _, serviceErr := validateTripPermission(ctx, ccrc.TripRepo, tripId, models.TripPermissionManageCostCategories); serviceErr != nil {
+		return serviceErr
+	}
+
+	if _, serviceErr := ccrc.getCostCategoryRuleOfTrip(ctx, tripId, costCategoryRuleId); serviceErr != nil {
+		return serviceErr
+	}
+
+	return ccrc.CostCategoryRuleRepo.DeleteCostCategoryRule(ctx, costCategoryRuleId)
+}
+
+// TestCostCategoryRules Reports for every rule of the trip which of the existing shared costs it would match.
+// Each rule is checked on its own, so a cost can show up for several rules
+func (ccrc *CostCategoryRuleController) TestCostCategoryRules(ctx context.Context, tripId *uuid.UUID) ([]*models.CostCategoryRuleTestDTO, *models.ExpenseServiceError) {
+	costCategoryRules, repoErr := ccrc.CostCategoryRuleRepo.GetCostCategoryRulesByTripID(ctx, tripId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	costs, repoErr := ccrc.CostRepo.GetCostsByTripID(ctx, tripId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	response := make([]*models.CostCategoryRuleTestDTO, 0, len(costCategoryRules))
+	for _, costCategoryRule := range costCategoryRules {
+		keywords, repoErr := ccrc.CostCategoryRuleRepo.GetCostCategoryRuleKeywords(ctx, costCategoryRule.CostCategoryRuleID)
+		if repoErr != nil {
+			return nil, repoErr
+		}
+
+		result := &models.CostCategoryRuleTestDTO{
+			CostCategoryRuleId: costCategoryRule.CostCategoryRuleID,
+			CostCategoryId:     costCategoryRule.CostCategoryID,
+			MatchedCosts:       make([]*models.CostCategoryRuleMatchDTO, 0),
+		}
+
+		for _, cost := range costs {
+			if !costCategoryRuleMatches(costCategoryRule, keywords, cost.Description, cost.Amount) {
+				continue
+			}
+
+			result.MatchedCosts = append(result.MatchedCosts, &models.CostCategoryRuleMatchDTO{
+				CostId:         cost.CostID,
+				Description:    cost.Description,
+				Amount:         cost.Amount.String(),
+				CostCategoryId: cost.CostCategoryID,
+			})
+		}
+
+		response = append(response, result)
+	}
+
+	return response, nil
+}
+
+// applyCostCategoryRuleRequest validates the request and applies it to the rule. Returns the resulting keywords and debtors
+func (ccrc *CostCategoryRuleController) applyCostCategoryRuleRequest(ctx context.Context, tripId *uuid.UUID, costCategoryRule *models.CostCategoryRuleSchema, keywords []string, debtorIds []*uuid.UUID, request models.CostCategoryRuleRequest) ([]string, []*uuid.UUID, *models.ExpenseServiceError) {
+	if request.CostCategoryId != nil {
+		costCategory, repoErr := ccrc.CostCategoryRepo.GetCostCategoryByID(ctx, request.CostCategoryId)
+		if repoErr != nil {
+			if repoErr == expense_errors.EXPENSE_NOT_FOUND {
+				return nil, nil, expense_errors.EXPENSE_BAD_REQUEST
+			}
+			return nil, nil, repoErr
+		}
+
+		if costCategory.TripID.String() != tripId.String() {
+			return nil, nil, expense_errors.EXPENSE_BAD_REQUEST
+		}
+		costCategoryRule.CostCategoryID = request.CostCategoryId
+	}
+
+	if costCategoryRule.CostCategoryID == nil {
+		return nil, nil, expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	if request.Priority != nil {
+		costCategoryRule.Priority = *request.Priority
+	}
+
+	if request.MinAmount != nil {
+		minAmount, serviceErr := parseCostCategoryRuleAmount(*request.MinAmount)
+		if serviceErr != nil {
+			return nil, nil, serviceErr
+		}
+		costCategoryRule.MinAmount = minAmount
+	}
+
+	if request.MaxAmount != nil {
+		maxAmount, serviceErr := parseCostCategoryRuleAmount(*request.MaxAmount)
+		if serviceErr != nil {
+			return nil, nil, serviceErr
+		}
+		costCategoryRule.MaxAmount = maxAmount
+	}
+
+	if costCategoryRule.MinAmount != nil && costCategoryRule.MaxAmount != nil && costCategoryRule.MinAmount.GreaterThan(*costCategoryRule.MaxAmount) {
+		return nil, nil, expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	if request.Keywords != nil {
+		keywords = make([]string, 0, len(request.Keywords))
+		seen := make(map[string]bool, len(request.Keywords))
+		for _, keyword := range request.Keywords {
+			keyword = strings.ToLower(strings.TrimSpace(keyword))
+			if keyword == "" {
+				return nil, nil, expense_errors.EXPENSE_BAD_REQUEST
+			}
+
+			if seen[keyword] {
+				continue
+			}
+			seen[keyword] = true
+			keywords = append(keywords, keyword)
+		}
+	}
+
+	// A rule without any condition would match every cost
+	if len(keywords) == 0 && costCategoryRule.MinAmount == nil && costCategoryRule.MaxAmount == nil {
+		return nil, nil, expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	if request.Debtors != nil {
+		debtorIds = make([]*uuid.UUID, 0, len(request.Debtors))
+		seen := make(map[uuid.UUID]bool, len(request.Debtors))
+		for _, username := range request.Debtors {
+			user, repoErr := ccrc.UserRepo.GetUserBySchema(ctx, &models.UserSchema{Username: username})
+			if repoErr != nil {
+				return nil, nil, repoErr
+			}
+
+			// Default debtors have to be part of the trip
+			if repoErr := ccrc.TripRepo.ValidateIfUserHasAccepted(ctx, tripId, user.UserID); repoErr != nil {
+				return nil, nil, repoErr
+			}
+
+			if seen[*user.UserID] {
+				continue
+			}
+			seen[*user.UserID] = true
+			debtorIds = append(debtorIds, user.UserID)
+		}
+	}
+
+	return keywords, debtorIds, nil
+}
+
+// parseCostCategoryRuleAmount parses an amount bound of a rule, an empty string removes the bound
+func parseCostCategoryRuleAmount(amount string) (*decimal.Decimal, *models.ExpenseServiceError) {
+	if amount == "" {
+		return nil, nil
+	}
+
+	amountDecimal, serviceErr := ValidateAmount(amount)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	return &amountDecimal, nil
+}
+
+func (ccrc *CostCategoryRuleController) getCostCategoryRuleOfTrip(ctx context.Context, tripId *uuid.UUID, costCategoryRuleId *uuid.UUID) (*models.CostCategoryRuleSchema, *models.ExpenseServiceError) {
+	costCategoryRule, repoErr := ccrc.CostCategoryRuleRepo.GetCostCategoryRuleByID(ctx, costCategoryRuleId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	if costCategoryRule.TripID.String() != tripId.String() {
+		return nil, expense_errors.EXPENSE_NOT_FOUND
+	}
+
+	return costCategoryRule, nil
+}
+
+// getCostCategoryRuleConditions returns the keywords and the default debtors of a rule
+func (ccrc *CostCategoryRuleController) getCostCategoryRuleConditions(ctx context.Context, costCategoryRuleId *uuid.UUID) ([]string, []*uuid.UUID, *models.ExpenseServiceError) {
+	keywords, repoErr := ccrc.CostCategoryRuleRepo.GetCostCategoryRuleKeywords(ctx, costCategoryRuleId)
+	if repoErr != nil {
+		return nil, nil, repoErr
+	}
+
+	debtorIds, repoErr := ccrc.CostCategoryRuleRepo.GetCostCategoryRuleDebtors(ctx, costCategoryRuleId)
+	if repoErr != nil {
+		return nil, nil, repoErr
+	}
+
+	return keywords, debtorIds, nil
+}
+
+func (ccrc *CostCategoryRuleController) mapCostCategoryRuleToResponse(ctx context.Context, costCategoryRule *models.CostCategoryRuleSchema, keywords []string, debtorIds []*uuid.UUID) (*models.CostCategoryRuleDTO, *models.ExpenseServiceError) {
+	response := &models.CostCategoryRuleDTO{
+		CostCategoryRuleId: costCategoryRule.CostCategoryRuleID,
+		CostCategoryId:     costCategoryRule.CostCategoryID,
+		Keywords:           keywords,
+		Priority:           costCategoryRule.Priority,
+		Debtors:            make([]string, len(debtorIds)),
+		CreationDate:       costCategoryRule.CreationDate.String(),
+	}
+
+	if costCategoryRule.MinAmount != nil {
+		response.MinAmount = costCategoryRule.MinAmount.String()
+	}
+
+	if costCategoryRule.MaxAmount != nil {
+		response.MaxAmount = costCategoryRule.MaxAmount.String()
+	}
+
+	for i, debtorId := range debtorIds {
+		user, repoErr := ccrc.UserRepo.GetUserById(ctx, debtorId)
+		if repoErr != nil {
+			return nil, repoErr
+		}
+		response.Debtors[i] = user.Username
+	}
+
+	return response, nil
+}
+
+// findMatchingCostCategoryRule returns the first rule of the trip the cost matches or nil if none matches
+func findMatchingCostCategoryRule(ctx context.Context, costCategoryRuleRepo repositories.CostCategoryRuleRepo, tripId *uuid.UUID, description string, amount decimal.Decimal) (*models.CostCategoryRuleSchema, *models.ExpenseServiceError) {
+	costCategoryRules, repoErr := costCategoryRuleRepo.GetCostCategoryRulesByTripID(ctx, tripId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	for _, costCategoryRule := range costCategoryRules {
+		keywords, repoErr := costCategoryRuleRepo.GetCostCategoryRuleKeywords(ctx, costCategoryRule.CostCategoryRuleID)
+		if repoErr != nil {
+			return nil, repoErr
+		}
+
+		if costCategoryRuleMatches(costCategoryRule, keywords, description, amount) {
+			return costCategoryRule, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// costCategoryRuleMatches checks if the description contains one of the keywords and the amount lies within the bounds of the rule
+func costCategoryRuleMatches(costCategoryRule *models.CostCategoryRuleSchema, keywords []string, description string, amount decimal.Decimal) bool {
+	if costCategoryRule.MinAmount != nil && amount.LessThan(*costCategoryRule.MinAmount) {
+		return false
+	}
+
+	if costCategoryRule.MaxAmount != nil && amount.GreaterThan(*costCategoryRule.MaxAmount) {
+		return false
+	}
+
+	if len(keywords) == 0 {
+		return true
+	}
+
+	description = strings.ToLower(description)
+	for _, keyword := range keywords {
+		if strings.Contains(description, keyword) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -6,9 +6,12 @@ import (
 	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/managers"
 	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
 	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/repositories"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/utils"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/shopspring/decimal"
 	"log"
+	"time"
 )
 
 // DebtCtl Exposed interface to the handler-package
@@ -16,6 +19,7 @@ type DebtCtl interface {
 	GetDebtOverview(ctx context.Context, userId *uuid.UUID) (*models.DebtOverviewDTO, *models.ExpenseServiceError)
 	GetDebtEntries(ctx context.Context, tripId *uuid.UUID) ([]*models.DebtDTO, *models.ExpenseServiceError)
 	GetDebtDetails(ctx context.Context, debtId *uuid.UUID) (*models.DebtDTO, *models.ExpenseServiceError)
+	ForgiveDebt(ctx context.Context, tripId *uuid.UUID, debtId *uuid.UUID, forgivenessRequest models.DebtForgivenessRequest) (*models.DebtDTO, *models.ExpenseServiceError)
 }
 
 // DebtController Debt Controller structure
@@ -45,6 +49,11 @@ func (dc *DebtController) GetDebtOverview(ctx context.Context, userId *uuid.UUID
 	var totalAmountSpent decimal.Decimal
 	var totalAmountReceived decimal.Decimal
 	for _, transaction := range transactions {
+		// No money changes hands when a debt is forgiven
+		if transaction.Type == models.TransactionTypeForgiveness {
+			continue
+		}
+
 		if transaction.DebtorId == userId {
 			totalAmountReceived = totalAmountReceived.Add(transaction.Amount)
 		} else {
@@ -110,6 +119,7 @@ func (dc *DebtController) GetDebtEntries(ctx context.Context, tripId *uuid.UUID)
 			CurrencyCode: debtEntry.CurrencyCode,
 			CreationDate: debtEntry.CreationDate.String(),
 			UpdateDate:   debtEntry.UpdateDate.String(),
+			Reference:    utils.GenerateSettlementReference(debtEntry.DebtID),
 		}
 
 		creditor, err := dc.UserRepo.GetUserById(ctx, debtEntry.CreditorId)
@@ -170,6 +180,7 @@ func (dc *DebtController) GetDebtDetails(ctx context.Context, debtId *uuid.UUID)
 		CurrencyCode: debtEntry.CurrencyCode,
 		CreationDate: debtEntry.CreationDate.String(),
 		UpdateDate:   debtEntry.UpdateDate.String(),
+		Reference:    utils.GenerateSettlementReference(debtEntry.DebtID),
 	}
 
 	creditor, err := dc.UserRepo.GetUserById(ctx, debtEntry.CreditorId)
@@ -196,3 +207,97 @@ func (dc *DebtController) GetDebtDetails(ctx context.Context, debtId *uuid.UUID)
 
 	return debtDto, nil
 }
+
+// ForgiveDebt lets the creditor forgive a debt completely or partially. The forgiveness is recorded as a ledger entry in
+// the transaction history of both users instead of a payment
+func (dc *DebtController) ForgiveDebt(ctx context.Context, tripId *uuid.UUID, debtId *uuid.UUID, forgivenessRequest models.DebtForgivenessRequest) (*models.DebtDTO, *models.ExpenseServiceError) {
+	// Begin transaction
+	tx, err := dc.DatabaseMgr.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Error while beginning transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// Make sure to rollback the transaction if it fails
+	defer func(tx pgx.Tx) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			log.Printf("Error while rolling back transaction: %v", err)
+		}
+	}(tx)
+
+	// The debt is locked, so a concurrent payment cannot change its amount after it was checked
+	debt, repoErr := dc.DebtRepo.GetDebtByIdForUpdateTx(ctx, tx, debtId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	if debt.TripId.String() != tripId.String() {
+		return nil, expense_errors.EXPENSE_NOT_FOUND
+	}
+
+	// Only the creditor can forgive a debt
+	userId := ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID)
+	if debt.CreditorId.String() != userId.String() {
+		return nil, expense_errors.EXPENSE_FORBIDDEN
+	}
+
+	// Nothing to forgive, the user is not owed anything
+	if !debt.Amount.IsPositive() {
+		return nil, expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	amount := debt.Amount
+	if forgivenessRequest.Amount != "" {
+		var serviceErr *models.ExpenseServiceError
+		amount, serviceErr = ValidateAmount(forgivenessRequest.Amount)
+		if serviceErr != nil {
+			return nil, serviceErr
+		}
+
+		if amount.IsZero() || amount.GreaterThan(debt.Amount) {
+			return nil, expense_errors.EXPENSE_BAD_REQUEST
+		}
+	}
+
+	if repoErr := forgiveDebtTx(ctx, tx, dc.DebtRepo, dc.TransactionRepo, debt, amount, forgivenessRequest.Note); repoErr != nil {
+		return nil, repoErr
+	}
+
+	// If everything went well, commit the transaction
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error while committing transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return dc.GetDebtDetails(ctx, debtId)
+}
+
+// forgiveDebtTx records the forgiveness of the amount as a ledger entry and reduces the debt accordingly. Like a payment,
+// the entry is booked from the debtor to the creditor of the debt, so that it zeroes the debt through CalculateDebt
+func forgiveDebtTx(ctx context.Context, tx pgx.Tx, debtRepo repositories.DebtRepo, transactionRepo repositories.TransactionRepo, debt *models.DebtSchema, amount decimal.Decimal, note string) *models.ExpenseServiceError {
+	transactionId := uuid.New()
+	now := time.Now()
+
+	transaction := &models.TransactionSchema{
+		TransactionId: &transactionId,
+		CreditorId:    debt.DebtorId,
+		DebtorId:      debt.CreditorId,
+		TripId:        debt.TripId,
+		Amount:        amount,
+		CreationDate:  &now,
+		CurrencyCode:  debt.CurrencyCode,
+		IsConfirmed:   true, // The creditor forgave the debt themselves
+		Type:          models.TransactionTypeForgiveness,
+	}
+
+	if note != "" {
+		transaction.Note = &note
+	}
+
+	if repoErr := transactionRepo.AddTx(ctx, tx, transaction); repoErr != nil {
+		return repoErr
+	}
+
+	return debtRepo.CalculateDebt(ctx, tx, transaction.CreditorId, transaction.DebtorId, transaction.TripId, amount)
+}
@@ -0,0 +1,458 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/expense_errors"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/managers"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/repositories"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"log"
+	"time"
+)
+
+const kittySettlementDescription = "Settlement"
+
+const (
+	kittyName           = "Kitty"
+	kittyUsernameFormat = "Kitty-%s"
+)
+
+// KittyCtl Exposed interface to the handler-package
+type KittyCtl interface {
+	GetKitty(ctx context.Context, tripId *uuid.UUID) (*models.KittyDTO, *models.ExpenseServiceError)
+	CreateKittyDeposit(ctx context.Context, tripId *uuid.UUID, request models.KittyDepositRequest) (*models.KittyDTO, *models.ExpenseServiceError)
+	SettleKitty(ctx context.Context, tripId *uuid.UUID) (*models.KittySettlementDTO, *models.ExpenseServiceError)
+}
+
+// KittyController Kitty Controller structure
+type KittyController struct {
+	DatabaseMgr managers.DatabaseMgr
+	KittyRepo   repositories.KittyRepo
+	UserRepo    repositories.UserRepo
+	TripRepo    repositories.TripRepo
+	DebtRepo    repositories.DebtRepo
+}
+
+func (kc *KittyController) GetKitty(ctx context.Context, tripId *uuid.UUID) (*models.KittyDTO, *models.ExpenseServiceError) {
+	// Pending invitees cannot see the deposits yet
+	if repoErr := kc.TripRepo.ValidateIfUserHasAccepted(ctx, tripId, ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID)); repoErr != nil {
+		return nil, repoErr
+	}
+
+	kitty, repoErr := kc.KittyRepo.GetKittyByTripID(ctx, tripId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	return kc.mapKittyToResponse(ctx, kitty)
+}
+
+// CreateKittyDeposit Pays money of the current user into the kitty. The kitty is created with the first deposit
+func (kc *KittyController) CreateKittyDeposit(ctx context.Context, tripId *uuid.UUID, request models.KittyDepositRequest) (*models.KittyDTO, *models.ExpenseServiceError) {
+	userId, ok := ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID)
+	if !ok {
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// Check if user is allowed to add deposits
+	if _, serviceErr := validateTripPermission(ctx, kc.TripRepo, tripId, models.TripPermissionWriteCosts); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	amount, serviceErr := ValidateAmount(request.Amount)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	if amount.IsZero() {
+		return nil, expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	// Begin transaction
+	tx, err := kc.DatabaseMgr.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Error while beginning transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// Make sure to rollback the transaction if it fails
+	defer func(tx pgx.Tx) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			log.Printf("Error while rolling back transaction: %v", err)
+		}
+	}(tx)
+
+	kitty, repoErr := getOrCreateKittyTx(ctx, tx, kc.KittyRepo, kc.UserRepo, tripId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	if kitty.SettledAt != nil {
+		return nil, expense_errors.EXPENSE_KITTY_SETTLED
+	}
+
+	entryId := uuid.New()
+	now := time.Now()
+	entry := &models.KittyEntrySchema{
+		KittyEntryID: &entryId,
+		KittyID:      kitty.KittyID,
+		UserID:       userId,
+		Amount:       amount,
+		Description:  request.Description,
+		CreationDate: &now,
+	}
+
+	if repoErr := kc.KittyRepo.AddKittyEntryTx(ctx, tx, entry); repoErr != nil {
+		return nil, repoErr
+	}
+
+	// The kitty owes the deposit to the user until it is spent
+	if repoErr := bookKittyDebtTx(ctx, tx, kc.DebtRepo, kitty, userId, kitty.UserID, amount); repoErr != nil {
+		return nil, repoErr
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		log.Printf("Error while committing transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return kc.mapKittyToResponse(ctx, kitty)
+}
+
+// SettleKitty Closes the kitty. The leftover money (or the shortfall) is split among the members in proportion to their
+// deposits. As members may have consumed a different share of the kitty than they paid into it, the remaining differences
+// are moved from the debts with the kitty to debts between the members, so the kitty owes and is owed nothing afterwards
+func (kc *KittyController) SettleKitty(ctx context.Context, tripId *uuid.UUID) (*models.KittySettlementDTO, *models.ExpenseServiceError) {
+	// Only owners and admins can settle the kitty
+	if _, serviceErr := validateTripPermission(ctx, kc.TripRepo, tripId, models.TripPermissionManageCosts); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	// Begin transaction
+	tx, err := kc.DatabaseMgr.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Error while beginning transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// Make sure to rollback the transaction if it fails
+	defer func(tx pgx.Tx) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			log.Printf("Error while rolling back transaction: %v", err)
+		}
+	}(tx)
+
+	// Lock the kitty, so that deposits and costs paid from it wait until the settlement is done
+	kitty, repoErr := kc.KittyRepo.GetKittyByTripIDForUpdateTx(ctx, tx, tripId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	if kitty.SettledAt != nil {
+		return nil, expense_errors.EXPENSE_KITTY_SETTLED
+	}
+
+	balances, repoErr := kc.KittyRepo.GetKittyBalancesTx(ctx, tx, kitty)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	totalDeposited, totalSpent := decimal.Zero, decimal.Zero
+	deposits := make([]decimal.Decimal, len(balances))
+	spendings := make([]decimal.Decimal, len(balances))
+	for i, balance := range balances {
+		totalDeposited = totalDeposited.Add(balance.Deposited)
+		totalSpent = totalSpent.Add(balance.Spent)
+		deposits[i] = balance.Deposited
+		spendings[i] = balance.Spent
+	}
+
+	// If nobody paid into the kitty, the shortfall is split by the shares of the costs instead
+	weights := deposits
+	if totalDeposited.IsZero() {
+		weights = spendings
+	}
+
+	leftover := totalDeposited.Sub(totalSpent)
+	payouts := distributeProportionally(leftover, weights)
+
+	now := time.Now()
+	response := &models.KittySettlementDTO{
+		Leftover: leftover.String(),
+		Payouts:  make([]*models.KittyPayoutDTO, len(balances)),
+	}
+
+	residuals := make([]decimal.Decimal, len(balances))
+	for i, balance := range balances {
+		user, repoErr := kc.UserRepo.GetUserById(ctx, balance.UserID)
+		if repoErr != nil {
+			return nil, repoErr
+		}
+
+		response.Payouts[i] = &models.KittyPayoutDTO{
+			Username: user.Username,
+			Amount:   payouts[i].String(),
+		}
+
+		// What the kitty owes the member is paid out, what is left after the payout has been consumed by other members
+		// or was consumed on their behalf
+		position := balance.Deposited.Sub(balance.Spent)
+		residuals[i] = position.Sub(payouts[i])
+
+		if repoErr := bookKittyDebtTx(ctx, tx, kc.DebtRepo, kitty, balance.UserID, kitty.UserID, position.Neg()); repoErr != nil {
+			return nil, repoErr
+		}
+
+		if payouts[i].IsZero() {
+			continue
+		}
+
+		// A payout leaves the kitty, a member covering the shortfall pays into it
+		entryId := uuid.New()
+		entry := &models.KittyEntrySchema{
+			KittyEntryID: &entryId,
+			KittyID:      kitty.KittyID,
+			UserID:       balance.UserID,
+			Amount:       payouts[i].Neg(),
+			Description:  kittySettlementDescription,
+			CreationDate: &now,
+		}
+
+		if repoErr := kc.KittyRepo.AddKittyEntryTx(ctx, tx, entry); repoErr != nil {
+			return nil, repoErr
+		}
+	}
+
+	if serviceErr := kc.bookResidualDebtsTx(ctx, tx, tripId, balances, residuals); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	if repoErr := kc.KittyRepo.SettleKittyTx(ctx, tx, kitty.KittyID, &now); repoErr != nil {
+		return nil, repoErr
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		log.Printf("Error while committing transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	kitty.SettledAt = &now
+	response.Kitty, repoErr = kc.mapKittyToResponse(ctx, kitty)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	return response, nil
+}
+
+// bookResidualDebtsTx turns the residuals of the settlement into debts. Members with a positive residual become creditors
+// of members with a negative residual. The residuals always sum up to zero
+func (kc *KittyController) bookResidualDebtsTx(ctx context.Context, tx pgx.Tx, tripId *uuid.UUID, balances []*models.KittyBalanceSchema, residuals []decimal.Decimal) *models.ExpenseServiceError {
+	remaining := make([]decimal.Decimal, len(residuals))
+	copy(remaining, residuals)
+
+	for c := range remaining {
+		for d := range remaining {
+			if !remaining[c].IsPositive() {
+				break
+			}
+
+			if !remaining[d].IsNegative() {
+				continue
+			}
+
+			amount := decimal.Min(remaining[c], remaining[d].Neg())
+			if repoErr := kc.DebtRepo.CalculateDebt(ctx, tx, balances[c].UserID, balances[d].UserID, tripId, amount); repoErr != nil {
+				return repoErr
+			}
+
+			remaining[c] = remaining[c].Sub(amount)
+			remaining[d] = remaining[d].Add(amount)
+		}
+	}
+
+	return nil
+}
+
+func (kc *KittyController) mapKittyToResponse(ctx context.Context, kitty *models.KittySchema) (*models.KittyDTO, *models.ExpenseServiceError) {
+	response := &models.KittyDTO{
+		KittyID:      kitty.KittyID,
+		CreationDate: kitty.CreationDate.String(),
+	}
+
+	if kitty.SettledAt != nil {
+		response.SettledAt = kitty.SettledAt.String()
+	}
+
+	// Usernames are cached, as most members have several entries
+	usernames := make(map[uuid.UUID]string)
+	getUsername := func(userId *uuid.UUID) (string, *models.ExpenseServiceError) {
+		if username, ok := usernames[*userId]; ok {
+			return username, nil
+		}
+
+		user, repoErr := kc.UserRepo.GetUserById(ctx, userId)
+		if repoErr != nil {
+			return "", repoErr
+		}
+		usernames[*userId] = user.Username
+		return user.Username, nil
+	}
+
+	// The kitty shows up under this name in the debts of the trip
+	kittyUsername, repoErr := getUsername(kitty.UserID)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+	response.Username = kittyUsername
+
+	balances, repoErr := kc.KittyRepo.GetKittyBalances(ctx, kitty)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	totalDeposited, totalSpent := decimal.Zero, decimal.Zero
+	response.Members = make([]*models.KittyMemberDTO, len(balances))
+	for i, balance := range balances {
+		username, repoErr := getUsername(balance.UserID)
+		if repoErr != nil {
+			return nil, repoErr
+		}
+
+		totalDeposited = totalDeposited.Add(balance.Deposited)
+		totalSpent = totalSpent.Add(balance.Spent)
+		response.Members[i] = &models.KittyMemberDTO{
+			Username:  username,
+			Deposited: balance.Deposited.String(),
+			Spent:     balance.Spent.String(),
+			Balance:   balance.Deposited.Sub(balance.Spent).String(),
+		}
+	}
+
+	entries, repoErr := kc.KittyRepo.GetKittyEntries(ctx, kitty.KittyID)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	// The money in the kitty includes the payouts of the settlement
+	balance := totalSpent.Neg()
+	response.Entries = make([]*models.KittyEntryDTO, len(entries))
+	for i, entry := range entries {
+		username, repoErr := getUsername(entry.UserID)
+		if repoErr != nil {
+			return nil, repoErr
+		}
+
+		balance = balance.Add(entry.Amount)
+		response.Entries[i] = &models.KittyEntryDTO{
+			KittyEntryID: entry.KittyEntryID,
+			Username:     username,
+			Amount:       entry.Amount.String(),
+			Description:  entry.Description,
+			CreationDate: entry.CreationDate.String(),
+		}
+	}
+
+	response.Balance = balance.String()
+	response.TotalDeposits = totalDeposited.String()
+	response.TotalSpent = totalSpent.String()
+
+	return response, nil
+}
+
+// getOrCreateKittyTx returns the share-locked kitty of a trip. The kitty is created with the first deposit or cost paid
+// from it, together with the placeholder user that takes part in the debts of the trip on behalf of the kitty
+func getOrCreateKittyTx(ctx context.Context, tx pgx.Tx, kittyRepo repositories.KittyRepo, userRepo repositories.UserRepo, tripId *uuid.UUID) (*models.KittySchema, *models.ExpenseServiceError) {
+	kitty, repoErr := kittyRepo.GetKittyByTripIDForShareTx(ctx, tx, tripId)
+	if repoErr != expense_errors.EXPENSE_NOT_FOUND {
+		return kitty, repoErr
+	}
+
+	// Like guests, the kitty cannot log in and has an address that can never receive mail
+	kittyId := uuid.New()
+	kittyUserId := uuid.New()
+	creationDate := time.Now()
+	kittyUser := &models.UserSchema{
+		UserID:    &kittyUserId,
+		Username:  fmt.Sprintf(kittyUsernameFormat, utils.GenerateRandomString(6)),
+		FirstName: kittyName,
+		Email:     fmt.Sprintf(guestEmailFormat, kittyUserId),
+		CreatedAt: &creationDate,
+		IsGuest:   true,
+	}
+
+	if repoErr := userRepo.CreateGuestUserTx(ctx, tx, kittyUser); repoErr != nil {
+		return nil, repoErr
+	}
+
+	kitty = &models.KittySchema{
+		KittyID:      &kittyId,
+		TripID:       tripId,
+		UserID:       kittyUser.UserID,
+		CreationDate: &creationDate,
+	}
+
+	// Fails with a conflict if a concurrent booking has created the kitty in the meantime
+	if repoErr := kittyRepo.AddKittyTx(ctx, tx, kitty); repoErr != nil {
+		return nil, repoErr
+	}
+
+	return kitty, nil
+}
+
+// bookKittyDebtTx adds an amount the debtor owes the creditor, one of which is the kitty. The kitty is no participant of
+// the trip, so its debts with a member are created with their first booking
+func bookKittyDebtTx(ctx context.Context, tx pgx.Tx, debtRepo repositories.DebtRepo, kitty *models.KittySchema, creditorId *uuid.UUID, debtorId *uuid.UUID, amount decimal.Decimal) *models.ExpenseServiceError {
+	if repoErr := debtRepo.AddMissingDebtsTx(ctx, tx, kitty.TripID, creditorId, debtorId); repoErr != nil {
+		return repoErr
+	}
+
+	return debtRepo.CalculateDebt(ctx, tx, creditorId, debtorId, kitty.TripID, amount)
+}
+
+// distributeProportionally splits the amount according to the weights and rounds the shares down to 2 decimal places.
+// The rounding difference is distributed cent by cent, like in DistributeRemainingCosts
+func distributeProportionally(amount decimal.Decimal, weights []decimal.Decimal) []decimal.Decimal {
+	shares := make([]decimal.Decimal, len(weights))
+
+	totalWeight := decimal.Zero
+	for _, weight := range weights {
+		totalWeight = totalWeight.Add(weight)
+	}
+
+	if !totalWeight.IsPositive() {
+		return shares
+	}
+
+	absAmount := amount.Abs()
+	distributedAmount := decimal.Zero
+	for i, weight := range weights {
+		shares[i] = absAmount.Mul(weight).Div(totalWeight).RoundDown(2)
+		distributedAmount = distributedAmount.Add(shares[i])
+	}
+
+	cent := decimal.NewFromFloat(0.01)
+	for i := 0; distributedAmount.LessThan(absAmount); i = (i + 1) % len(shares) {
+		if weights[i].IsPositive() {
+			shares[i] = shares[i].Add(cent)
+			distributedAmount = distributedAmount.Add(cent)
+		}
+	}
+
+	if amount.IsNegative() {
+		for i := range shares {
+			shares[i] = shares[i].Neg()
+		}
+	}
+
+	return shares
+}
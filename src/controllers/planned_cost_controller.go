@@ -0,0 +1,733 @@
+package controllers
+
+import (
+	"context"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/expense_errors"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/managers"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/repositories"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"log"
+	"sort"
+	"time"
+)
+
+// PlannedCostCtl Exposed interface to the handler-package
+type PlannedCostCtl interface {
+	CreatePlannedCost(ctx context.Context, tripId *uuid.UUID, request models.PlannedCostDTO) (*models.PlannedCostDTO, *models.ExpenseServiceError)
+	GetPlannedCostEntries(ctx context.Context, tripId *uuid.UUID, costCategoryId *uuid.UUID) ([]*models.PlannedCostDTO, *models.ExpenseServiceError)
+	GetPlannedCostDetails(ctx context.Context, tripId *uuid.UUID, plannedCostId *uuid.UUID) (*models.PlannedCostDTO, *models.ExpenseServiceError)
+	PatchPlannedCost(ctx context.Context, tripId *uuid.UUID, plannedCostId *uuid.UUID, request models.PlannedCostDTO) (*models.PlannedCostDTO, *models.ExpenseServiceError)
+	DeletePlannedCost(ctx context.Context, tripId *uuid.UUID, plannedCostId *uuid.UUID) *models.ExpenseServiceError
+	LinkCosts(ctx context.Context, tripId *uuid.UUID, plannedCostId *uuid.UUID, request models.PlannedCostLinkRequest) (*models.PlannedCostDTO, *models.ExpenseServiceError)
+	UnlinkCost(ctx context.Context, tripId *uuid.UUID, plannedCostId *uuid.UUID, costId *uuid.UUID) *models.ExpenseServiceError
+	GetCostComparison(ctx context.Context, params *models.CostQueryParams) (*models.CostComparisonDTO, *models.ExpenseServiceError)
+}
+
+// PlannedCostController Planned Cost Controller structure
+type PlannedCostController struct {
+	DatabaseMgr      managers.DatabaseMgr
+	PlannedCostRepo  repositories.PlannedCostRepo
+	CostRepo         repositories.CostRepo
+	CostCategoryRepo repositories.CostCategoryRepo
+	UserRepo         repositories.UserRepo
+	TripRepo         repositories.TripRepo
+	CostController   *CostController // Checks that linked costs are visible to the user
+}
+
+// CreatePlannedCost Creates a planned cost entry. Planned costs are distributed like actual costs but never create debts
+func (pcc *PlannedCostController) CreatePlannedCost(ctx context.Context, tripId *uuid.UUID, request models.PlannedCostDTO) (*models.PlannedCostDTO, *models.ExpenseServiceError) {
+	// Check if user is allowed to plan costs
+	if _, serviceErr := validateTripPermission(ctx, pcc.TripRepo, tripId, models.TripPermissionWriteCosts); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	// Check if cost category belongs to trip
+	if serviceErr := pcc.validateCostCategoryOfTrip(ctx, tripId, request.CostCategoryID); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	// Begin transaction
+	tx, err := pcc.DatabaseMgr.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Error while beginning transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// Make sure to rollback the transaction if it fails
+	defer func(tx pgx.Tx) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			log.Printf("Error while rolling back transaction: %v", err)
+		}
+	}(tx)
+
+	plannedCostId := uuid.New()
+	now := time.Now()
+
+	if serviceErr := pcc.distributePlannedCost(ctx, tripId, &request); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	plannedCost := &models.PlannedCostSchema{
+		PlannedCostID:  &plannedCostId,
+		Amount:         decimal.RequireFromString(request.Amount),
+		Description:    request.Description,
+		CreationDate:   &now,
+		CostCategoryID: request.CostCategoryID,
+	}
+
+	if repoErr := pcc.PlannedCostRepo.AddTx(ctx, tx, plannedCost); repoErr != nil {
+		return nil, repoErr
+	}
+
+	if serviceErr := pcc.addPlannedCostContributorsTx(ctx, tx, tripId, plannedCost.PlannedCostID, request.Participants); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		log.Printf("Error while committing transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return pcc.mapPlannedCostToResponse(ctx, plannedCost)
+}
+
+func (pcc *PlannedCostController) GetPlannedCostEntries(ctx context.Context, tripId *uuid.UUID, costCategoryId *uuid.UUID) ([]*models.PlannedCostDTO, *models.ExpenseServiceError) {
+	plannedCosts, repoErr := pcc.PlannedCostRepo.GetPlannedCostsByTripID(ctx, tripId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	response := make([]*models.PlannedCostDTO, 0, len(plannedCosts))
+	for _, plannedCost := range plannedCosts {
+		if costCategoryId != nil && plannedCost.CostCategoryID.String() != costCategoryId.String() {
+			continue
+		}
+
+		plannedCostDto, serviceErr := pcc.mapPlannedCostToResponse(ctx, plannedCost)
+		if serviceErr != nil {
+			return nil, serviceErr
+		}
+		response = append(response, plannedCostDto)
+	}
+
+	return response, nil
+}
+
+func (pcc *PlannedCostController) GetPlannedCostDetails(ctx context.Context, tripId *uuid.UUID, plannedCostId *uuid.UUID) (*models.PlannedCostDTO, *models.ExpenseServiceError) {
+	plannedCost, serviceErr := pcc.getPlannedCostOfTrip(ctx, tripId, plannedCostId)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	return pcc.mapPlannedCostToResponse(ctx, plannedCost)
+}
+
+func (pcc *PlannedCostController) PatchPlannedCost(ctx context.Context, tripId *uuid.UUID, plannedCostId *uuid.UUID, request models.PlannedCostDTO) (*models.PlannedCostDTO, *models.ExpenseServiceError) {
+	// Check if user is allowed to plan costs
+	if _, serviceErr := validateTripPermission(ctx, pcc.TripRepo, tripId, models.TripPermissionWriteCosts); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	plannedCost, serviceErr := pcc.getPlannedCostOfTrip(ctx, tripId, plannedCostId)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	// Begin transaction
+	tx, err := pcc.DatabaseMgr.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Error while beginning transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// Make sure to rollback the transaction if it fails
+	defer func(tx pgx.Tx) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			log.Printf("Error while rolling back transaction: %v", err)
+		}
+	}(tx)
+
+	amountChanged := request.Amount != "" && request.Amount != plannedCost.Amount.String()
+	participantsChanged := len(request.Participants) > 0
+
+	if request.Description != "" {
+		plannedCost.Description = request.Description
+	}
+
+	if request.CostCategoryID != nil {
+		if serviceErr := pcc.validateCostCategoryOfTrip(ctx, tripId, request.CostCategoryID); serviceErr != nil {
+			return nil, serviceErr
+		}
+		plannedCost.CostCategoryID = request.CostCategoryID
+	}
+
+	if amountChanged || participantsChanged {
+		if request.Amount == "" {
+			request.Amount = plannedCost.Amount.String()
+		}
+
+		// If only the amount has changed, the existing participants share the new amount
+		if !participantsChanged {
+			contributions, repoErr := pcc.PlannedCostRepo.GetPlannedCostContributors(ctx, plannedCostId)
+			if repoErr != nil {
+				return nil, repoErr
+			}
+
+			for _, contribution := range contributions {
+				user, repoErr := pcc.UserRepo.GetUserById(ctx, contribution.UserID)
+				if repoErr != nil {
+					return nil, repoErr
+				}
+				request.Participants = append(request.Participants, &models.Contributor{Username: user.Username})
+			}
+		}
+
+		if serviceErr := pcc.distributePlannedCost(ctx, tripId, &request); serviceErr != nil {
+			return nil, serviceErr
+		}
+		plannedCost.Amount = decimal.RequireFromString(request.Amount)
+
+		if repoErr := pcc.PlannedCostRepo.DeletePlannedCostContributionsTx(ctx, tx, plannedCostId); repoErr != nil {
+			return nil, repoErr
+		}
+
+		if serviceErr := pcc.addPlannedCostContributorsTx(ctx, tx, tripId, plannedCostId, request.Participants); serviceErr != nil {
+			return nil, serviceErr
+		}
+	}
+
+	if repoErr := pcc.PlannedCostRepo.UpdateTx(ctx, tx, plannedCost); repoErr != nil {
+		return nil, repoErr
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		log.Printf("Error while committing transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return pcc.mapPlannedCostToResponse(ctx, plannedCost)
+}
+
+func (pcc *PlannedCostController) DeletePlannedCost(ctx context.Context, tripId *uuid.UUID, plannedCostId *uuid.UUID) *models.ExpenseServiceError {
+	// Check if user is allowed to plan costs
+	if _, serviceErr := validateTripPermission(ctx, pcc.TripRepo, tripId, models.TripPermissionWriteCosts); serviceErr != nil {
+		return serviceErr
+	}
+
+	if _, serviceErr := pcc.getPlannedCostOfTrip(ctx, tripId, plannedCostId); serviceErr != nil {
+		return serviceErr
+	}
+
+	// Begin transaction
+	tx, err := pcc.DatabaseMgr.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Error while beginning transaction: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// Make sure to rollback the transaction if it fails
+	defer func(tx pgx.Tx) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			log.Printf("Error while rolling back transaction: %v", err)
+		}
+	}(tx)
+
+	// Contributions and links are deleted by the database (ON DELETE CASCADE)
+	if repoErr := pcc.PlannedCostRepo.DeleteTx(ctx, tx, plannedCostId); repoErr != nil {
+		return repoErr
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		log.Printf("Error while committing transaction: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return nil
+}
+
+// LinkCosts Links actual costs of the same trip to a planned cost
+func (pcc *PlannedCostController) LinkCosts(ctx context.Context, tripId *uuid.UUID, plannedCostId *uuid.UUID, request models.PlannedCostLinkRequest) (*models.PlannedCostDTO, *models.ExpenseServiceError) {
+	// Check if user is allowed to plan costs
+	if _, serviceErr := validateTripPermission(ctx, pcc.TripRepo, tripId, models.TripPermissionWriteCosts); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	plannedCost, serviceErr := pcc.getPlannedCostOfTrip(ctx, tripId, plannedCostId)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	// Begin transaction
+	tx, err := pcc.DatabaseMgr.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Error while beginning transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// Make sure to rollback the transaction if it fails
+	defer func(tx pgx.Tx) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			log.Printf("Error while rolling back transaction: %v", err)
+		}
+	}(tx)
+
+	for _, costId := range request.CostIDs {
+		// Check if cost belongs to trip
+		cost, repoErr := pcc.CostRepo.GetCostByID(ctx, costId)
+		if repoErr != nil {
+			return nil, repoErr
+		}
+
+		if serviceErr := pcc.validateCostCategoryOfTrip(ctx, tripId, cost.CostCategoryID); serviceErr != nil {
+			return nil, serviceErr
+		}
+
+		// Private costs of other participants must not be revealed through the planned cost
+		if serviceErr := pcc.CostController.validateCostVisibility(ctx, cost); serviceErr != nil {
+			return nil, serviceErr
+		}
+
+		if repoErr := pcc.PlannedCostRepo.AddPlannedCostLinkTx(ctx, tx, plannedCostId, costId); repoErr != nil {
+			return nil, repoErr
+		}
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		log.Printf("Error while committing transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return pcc.mapPlannedCostToResponse(ctx, plannedCost)
+}
+
+func (pcc *PlannedCostController) UnlinkCost(ctx context.Context, tripId *uuid.UUID, plannedCostId *uuid.UUID, costId *uuid.UUID) *models.ExpenseServiceError {
+	// Check if user is allowed to plan costs
+	if _, serviceErr := validateTripPermission(ctx, pcc.TripRepo, tripId, models.TripPermissionWriteCosts); serviceErr != nil {
+		return serviceErr
+	}
+
+	if _, serviceErr := pcc.getPlannedCostOfTrip(ctx, tripId, plannedCostId); serviceErr != nil {
+		return serviceErr
+	}
+
+	return pcc.PlannedCostRepo.DeletePlannedCostLink(ctx, plannedCostId, costId)
+}
+
+// GetCostComparison Compares planned and actual costs of a trip per cost category, per participant and per planned cost.
+// The filters of the cost query params are applied to the actual costs; category, user and amount filters also apply to planned costs.
+// A category filter includes the subcategories of the category, tag filters only apply to actual costs.
+// If a user filter is set, all amounts are the shares of that user
+func (pcc *PlannedCostController) GetCostComparison(ctx context.Context, params *models.CostQueryParams) (*models.CostComparisonDTO, *models.ExpenseServiceError) {
+	costCategoryFilter := params.CostCategoryId
+	if params.CostCategoryName != nil {
+		costCategory, repoErr := pcc.CostCategoryRepo.GetCostCategoryByTripIdAndName(ctx, params.TripId, *params.CostCategoryName)
+		if repoErr != nil {
+			return nil, repoErr
+		}
+		costCategoryFilter = costCategory.CostCategoryID
+	}
+
+	userFilter := params.UserId
+	if params.Username != nil {
+		user, repoErr := pcc.UserRepo.GetUserBySchema(ctx, &models.UserSchema{Username: *params.Username})
+		if repoErr != nil {
+			return nil, repoErr
+		}
+		userFilter = user.UserID
+	}
+
+	costCategories, repoErr := pcc.CostCategoryRepo.GetCostCategoriesByTripID(ctx, params.TripId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	// Filtering by a top-level category includes its subcategories
+	includedCategories := make(map[uuid.UUID]bool)
+	for _, costCategory := range costCategories {
+		if costCategoryFilter == nil || *costCategory.CostCategoryID == *costCategoryFilter || (costCategory.ParentID != nil && *costCategory.ParentID == *costCategoryFilter) {
+			includedCategories[*costCategory.CostCategoryID] = true
+		}
+	}
+
+	plannedByCategory := make(map[uuid.UUID]decimal.Decimal)
+	actualByCategory := make(map[uuid.UUID]decimal.Decimal)
+	plannedByUser := make(map[uuid.UUID]decimal.Decimal)
+	actualByUser := make(map[uuid.UUID]decimal.Decimal)
+	actualByCost := make(map[uuid.UUID]decimal.Decimal)
+
+	// Sum up actual costs
+	costs, repoErr := pcc.CostRepo.GetCostsByTripID(ctx, params.TripId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	for _, cost := range costs {
+		// Only approved costs have been spent, pending costs may still be rejected
+		if cost.Status != models.CostStatusApproved {
+			continue
+		}
+
+		if !includedCategories[*cost.CostCategoryID] || !costMatchesQueryParams(cost, params) {
+			continue
+		}
+
+		if len(params.Tags) > 0 {
+			tags, repoErr := pcc.CostRepo.GetCostTags(ctx, cost.CostID)
+			if repoErr != nil {
+				return nil, repoErr
+			}
+
+			if !hasAllTags(tags, params.Tags) {
+				continue
+			}
+		}
+
+		contributions, repoErr := pcc.CostRepo.GetCostContributors(ctx, cost.CostID)
+		if repoErr != nil {
+			return nil, repoErr
+		}
+
+		amount, included := cost.Amount, userFilter == nil
+		for _, contribution := range contributions {
+			if userFilter != nil && contribution.UserID.String() != userFilter.String() {
+				continue
+			}
+
+			if userFilter != nil {
+				amount, included = contribution.Amount, true
+			}
+			actualByUser[*contribution.UserID] = actualByUser[*contribution.UserID].Add(contribution.Amount)
+		}
+
+		if !included {
+			continue
+		}
+
+		actualByCategory[*cost.CostCategoryID] = actualByCategory[*cost.CostCategoryID].Add(amount)
+		actualByCost[*cost.CostID] = amount
+	}
+
+	// Sum up planned costs
+	plannedCosts, repoErr := pcc.PlannedCostRepo.GetPlannedCostsByTripID(ctx, params.TripId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	response := &models.CostComparisonDTO{
+		CostCategories: make([]*models.CostCategoryComparisonDTO, 0, len(costCategories)),
+		Participants:   make([]*models.ParticipantComparisonDTO, 0),
+		PlannedCosts:   make([]*models.PlannedCostComparisonDTO, 0, len(plannedCosts)),
+	}
+
+	for _, plannedCost := range plannedCosts {
+		if !includedCategories[*plannedCost.CostCategoryID] {
+			continue
+		}
+
+		if !amountMatchesQueryParams(plannedCost.Amount, params) {
+			continue
+		}
+
+		contributions, repoErr := pcc.PlannedCostRepo.GetPlannedCostContributors(ctx, plannedCost.PlannedCostID)
+		if repoErr != nil {
+			return nil, repoErr
+		}
+
+		amount, included := plannedCost.Amount, userFilter == nil
+		for _, contribution := range contributions {
+			if userFilter != nil && contribution.UserID.String() != userFilter.String() {
+				continue
+			}
+
+			if userFilter != nil {
+				amount, included = contribution.Amount, true
+			}
+			plannedByUser[*contribution.UserID] = plannedByUser[*contribution.UserID].Add(contribution.Amount)
+		}
+
+		if !included {
+			continue
+		}
+
+		plannedByCategory[*plannedCost.CostCategoryID] = plannedByCategory[*plannedCost.CostCategoryID].Add(amount)
+
+		// Compare planned cost with its linked actual costs
+		linkedCostIds, repoErr := pcc.PlannedCostRepo.GetLinkedCostIDs(ctx, plannedCost.PlannedCostID)
+		if repoErr != nil {
+			return nil, repoErr
+		}
+
+		linkedActual := decimal.Zero
+		for _, costId := range linkedCostIds {
+			linkedActual = linkedActual.Add(actualByCost[*costId])
+		}
+
+		response.PlannedCosts = append(response.PlannedCosts, &models.PlannedCostComparisonDTO{
+			PlannedCostID: plannedCost.PlannedCostID,
+			Description:   plannedCost.Description,
+			Planned:       amount.String(),
+			Actual:        linkedActual.String(),
+			Variance:      linkedActual.Sub(amount).String(),
+		})
+	}
+
+	totalPlanned, totalActual := decimal.Zero, decimal.Zero
+	for _, costCategory := range costCategories {
+		if !includedCategories[*costCategory.CostCategoryID] {
+			continue
+		}
+
+		planned := plannedByCategory[*costCategory.CostCategoryID]
+		actual := actualByCategory[*costCategory.CostCategoryID]
+		totalPlanned = totalPlanned.Add(planned)
+		totalActual = totalActual.Add(actual)
+
+		response.CostCategories = append(response.CostCategories, &models.CostCategoryComparisonDTO{
+			CostCategoryId: costCategory.CostCategoryID,
+			Name:           costCategory.Name,
+			Planned:        planned.String(),
+			Actual:         actual.String(),
+			Variance:       actual.Sub(planned).String(),
+		})
+	}
+
+	response.Planned = totalPlanned.String()
+	response.Actual = totalActual.String()
+	response.Variance = totalActual.Sub(totalPlanned).String()
+
+	// Collect all users with a planned or an actual share
+	userIds := make(map[uuid.UUID]bool)
+	for userId := range plannedByUser {
+		userIds[userId] = true
+	}
+	for userId := range actualByUser {
+		userIds[userId] = true
+	}
+
+	for userId := range userIds {
+		userId := userId
+		user, repoErr := pcc.UserRepo.GetUserById(ctx, &userId)
+		if repoErr != nil {
+			return nil, repoErr
+		}
+
+		response.Participants = append(response.Participants, &models.ParticipantComparisonDTO{
+			Username: user.Username,
+			Planned:  plannedByUser[userId].String(),
+			Actual:   actualByUser[userId].String(),
+			Variance: actualByUser[userId].Sub(plannedByUser[userId]).String(),
+		})
+	}
+
+	sort.Slice(response.Participants, func(i, j int) bool {
+		return response.Participants[i].Username < response.Participants[j].Username
+	})
+
+	return response, nil
+}
+
+// getPlannedCostOfTrip returns the planned cost if it belongs to the trip, otherwise EXPENSE_NOT_FOUND
+func (pcc *PlannedCostController) getPlannedCostOfTrip(ctx context.Context, tripId *uuid.UUID, plannedCostId *uuid.UUID) (*models.PlannedCostSchema, *models.ExpenseServiceError) {
+	plannedCost, repoErr := pcc.PlannedCostRepo.GetPlannedCostByID(ctx, plannedCostId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	if serviceErr := pcc.validateCostCategoryOfTrip(ctx, tripId, plannedCost.CostCategoryID); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	return plannedCost, nil
+}
+
+// validateCostCategoryOfTrip checks if the cost category exists and belongs to the trip
+func (pcc *PlannedCostController) validateCostCategoryOfTrip(ctx context.Context, tripId *uuid.UUID, costCategoryId *uuid.UUID) *models.ExpenseServiceError {
+	if costCategoryId == nil {
+		return expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	costCategory, repoErr := pcc.CostCategoryRepo.GetCostCategoryByID(ctx, costCategoryId)
+	if repoErr != nil {
+		return repoErr
+	}
+
+	if costCategory.TripID.String() != tripId.String() {
+		return expense_errors.EXPENSE_NOT_FOUND
+	}
+
+	return nil
+}
+
+// distributePlannedCost distributes the planned amount among the participants the same way as actual costs.
+// If no participants are given, the amount is split evenly among all accepted trip participants
+func (pcc *PlannedCostController) distributePlannedCost(ctx context.Context, tripId *uuid.UUID, request *models.PlannedCostDTO) *models.ExpenseServiceError {
+	if len(request.Participants) == 0 {
+		participants, repoErr := pcc.TripRepo.GetAcceptedTripParticipants(ctx, tripId)
+		if repoErr != nil {
+			return repoErr
+		}
+
+		for _, participant := range participants {
+			user, repoErr := pcc.UserRepo.GetUserById(ctx, participant.UserID)
+			if repoErr != nil {
+				return repoErr
+			}
+			request.Participants = append(request.Participants, &models.Contributor{Username: user.Username})
+		}
+	}
+
+	costRequest := &models.CostDTO{
+		Amount:  request.Amount,
+		Debtors: request.Participants,
+	}
+
+	if serviceErr := DistributeCosts(costRequest); serviceErr != nil {
+		return serviceErr
+	}
+	request.Amount = costRequest.Amount
+
+	return nil
+}
+
+func (pcc *PlannedCostController) addPlannedCostContributorsTx(ctx context.Context, tx pgx.Tx, tripId *uuid.UUID, plannedCostId *uuid.UUID, participants []*models.Contributor) *models.ExpenseServiceError {
+	for _, participant := range participants {
+		user, repoErr := pcc.UserRepo.GetUserBySchema(ctx, &models.UserSchema{Username: participant.Username})
+		if repoErr != nil {
+			return repoErr
+		}
+
+		// Check if user is part of the trip
+		if repoErr = pcc.TripRepo.ValidateIfUserHasAccepted(ctx, tripId, user.UserID); repoErr != nil {
+			return repoErr
+		}
+
+		contribution := &models.PlannedCostContributionSchema{
+			UserID:        user.UserID,
+			PlannedCostID: plannedCostId,
+			Amount:        decimal.RequireFromString(participant.Amount),
+		}
+
+		if repoErr := pcc.PlannedCostRepo.AddPlannedCostContributorTx(ctx, tx, contribution); repoErr != nil {
+			return repoErr
+		}
+	}
+
+	return nil
+}
+
+func (pcc *PlannedCostController) mapPlannedCostToResponse(ctx context.Context, plannedCost *models.PlannedCostSchema) (*models.PlannedCostDTO, *models.ExpenseServiceError) {
+	response := &models.PlannedCostDTO{
+		PlannedCostID:  plannedCost.PlannedCostID,
+		CostCategoryID: plannedCost.CostCategoryID,
+		Amount:         plannedCost.Amount.String(),
+		Description:    plannedCost.Description,
+		CreationDate:   plannedCost.CreationDate.String(),
+	}
+
+	contributions, repoErr := pcc.PlannedCostRepo.GetPlannedCostContributors(ctx, plannedCost.PlannedCostID)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	response.Participants = make([]*models.Contributor, len(contributions))
+	for i, contribution := range contributions {
+		user, repoErr := pcc.UserRepo.GetUserById(ctx, contribution.UserID)
+		if repoErr != nil {
+			return nil, repoErr
+		}
+
+		response.Participants[i] = &models.Contributor{
+			Username: user.Username,
+			Amount:   contribution.Amount.String(),
+		}
+	}
+
+	response.LinkedCostIDs, repoErr = pcc.PlannedCostRepo.GetLinkedCostIDs(ctx, plannedCost.PlannedCostID)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	return response, nil
+}
+
+// costMatchesQueryParams applies the amount and date filters of the cost query params to a cost
+func costMatchesQueryParams(cost *models.CostSchema, params *models.CostQueryParams) bool {
+	if !amountMatchesQueryParams(cost.Amount, params) {
+		return false
+	}
+
+	return dateMatchesQueryParams(cost.DeductionDate, params.MinDeductionDate, params.MaxDeductionDate) &&
+		dateMatchesQueryParams(cost.EndDate, params.MinEndDate, params.MaxEndDate) &&
+		dateMatchesQueryParams(cost.CreationDate, params.MinCreationDate, params.MaxCreationDate)
+}
+
+func amountMatchesQueryParams(amount decimal.Decimal, params *models.CostQueryParams) bool {
+	if params.MinAmount != nil {
+		if minAmount, err := decimal.NewFromString(*params.MinAmount); err == nil && amount.LessThan(minAmount) {
+			return false
+		}
+	}
+
+	if params.MaxAmount != nil {
+		if maxAmount, err := decimal.NewFromString(*params.MaxAmount); err == nil && amount.GreaterThan(maxAmount) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasAllTags checks if every required tag is among the tags of a cost
+func hasAllTags(tags []string, requiredTags []string) bool {
+	for _, requiredTag := range requiredTags {
+		found := false
+		for _, tag := range tags {
+			if tag == requiredTag {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// dateMatchesQueryParams checks if the date is within the optional bounds. Dates that are not set never match a bound
+func dateMatchesQueryParams(date *time.Time, min *string, max *string) bool {
+	if min == nil && max == nil {
+		return true
+	}
+
+	if date == nil {
+		return false
+	}
+
+	if min != nil {
+		if minDate, err := time.Parse(time.RFC3339, *min); err == nil && date.Before(minDate) {
+			return false
+		}
+	}
+
+	if max != nil {
+		if maxDate, err := time.Parse(time.RFC3339, *max); err == nil && date.After(maxDate) {
+			return false
+		}
+	}
+
+	return true
+}
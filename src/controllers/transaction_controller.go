@@ -6,11 +6,15 @@ import (
 	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/managers"
 	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
 	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/repositories"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/utils"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/shopspring/decimal"
+	"io"
 	"log"
+	"mime/multipart"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -21,10 +25,12 @@ type TransactionCtl interface {
 	DeleteTransactionEntry(ctx context.Context, transactionId *uuid.UUID) *models.ExpenseServiceError
 	AcceptTransaction(ctx context.Context, transactionId *uuid.UUID) (*models.TransactionDTO, *models.ExpenseServiceError)
 	GetUserTransactions(ctx context.Context, params *models.TransactionQueryParams) ([]*models.TransactionDTO, *models.ExpenseServiceError)
+	ImportBankStatement(ctx context.Context, file *multipart.FileHeader) (*models.BankStatementImportDTO, *models.ExpenseServiceError)
 }
 
 type TransactionController struct {
 	DatabaseMgr     managers.DatabaseMgr
+	EventMgr        managers.EventMgr
 	TransactionRepo repositories.TransactionRepo
 	UserRepo        repositories.UserRepo
 	TripRepo        repositories.TripRepo
@@ -80,7 +86,7 @@ func (tc *TransactionController) GetUserTransactions(ctx context.Context, params
 	userId := ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID)
 
 	var args []interface{}
-	query := `SELECT DISTINCT t.id, t.id_creditor, t.id_debtor, t.id_trip, t.amount, t.created_at, t.currency_code, t.is_confirmed FROM transaction t WHERE (id_creditor = $1 OR id_debtor = $1)`
+	query := `SELECT DISTINCT t.id, t.id_creditor, t.id_debtor, t.id_trip, t.amount, t.created_at, t.currency_code, t.is_confirmed, t.type, t.note FROM transaction t WHERE (id_creditor = $1 OR id_debtor = $1) AND id_trip IN (SELECT id FROM trip WHERE deleted_at IS NULL)`
 	args = append(args, userId)
 
 	if params.DebtorId != nil {
@@ -115,7 +121,7 @@ func (tc *TransactionController) GetUserTransactions(ctx context.Context, params
 	transactionResponses := make([]*models.TransactionDTO, 0)
 	for rows.Next() {
 		var transaction models.TransactionSchema
-		err := rows.Scan(&transaction.TransactionId, &transaction.CreditorId, &transaction.DebtorId, &transaction.TripId, &transaction.Amount, &transaction.CreationDate, &transaction.CurrencyCode, &transaction.IsConfirmed)
+		err := rows.Scan(&transaction.TransactionId, &transaction.CreditorId, &transaction.DebtorId, &transaction.TripId, &transaction.Amount, &transaction.CreationDate, &transaction.CurrencyCode, &transaction.IsConfirmed, &transaction.Type, &transaction.Note)
 		if err != nil {
 			log.Printf("Error while scanning row: %v", err)
 			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
@@ -155,9 +161,29 @@ func (tc *TransactionController) CreateTransactionEntry(ctx context.Context, tri
 		return nil, repoErr
 	}
 
-	// Check if user is part of trip
-	if repoErr := tc.TripRepo.ValidateIfUserHasAccepted(ctx, tripId, userId); repoErr != nil {
-		return nil, repoErr
+	// Check if user is allowed to add transactions
+	if _, serviceErr := validateTripPermission(ctx, tc.TripRepo, tripId, models.TripPermissionWriteCosts); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	// Guests cannot log in, so owners and admins record their payments on their behalf
+	if transactionRequest.CreditorId != nil && transactionRequest.CreditorId.String() != userId.String() {
+		if _, serviceErr := validateTripPermission(ctx, tc.TripRepo, tripId, models.TripPermissionManageParticipants); serviceErr != nil {
+			return nil, serviceErr
+		}
+
+		creditor, repoErr = tc.UserRepo.GetUserById(ctx, transactionRequest.CreditorId)
+		if repoErr != nil {
+			return nil, repoErr
+		}
+
+		if !creditor.IsGuest {
+			return nil, expense_errors.EXPENSE_FORBIDDEN
+		}
+
+		if repoErr = tc.TripRepo.ValidateIfUserHasAccepted(ctx, tripId, creditor.UserID); repoErr != nil {
+			return nil, repoErr
+		}
 	}
 
 	// Get debtor from request
@@ -177,13 +203,14 @@ func (tc *TransactionController) CreateTransactionEntry(ctx context.Context, tri
 
 	transaction := &models.TransactionSchema{
 		TransactionId: &transactionId,
-		CreditorId:    userId,
+		CreditorId:    creditor.UserID,
 		DebtorId:      debtor.UserID,
 		TripId:        tripId,
 		Amount:        decimal.RequireFromString(transactionRequest.Amount),
 		CreationDate:  &now,
 		CurrencyCode:  "EUR",
 		IsConfirmed:   false,
+		Type:          models.TransactionTypePayment,
 	}
 
 	if repoErr := tc.TransactionRepo.AddTx(ctx, tx, transaction); repoErr != nil {
@@ -201,6 +228,17 @@ func (tc *TransactionController) CreateTransactionEntry(ctx context.Context, tri
 		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
 	}
 
+	tc.EventMgr.Publish(ctx, &models.TripEvent{
+		Type:      models.TripEventPaymentRecorded,
+		TripID:    tripId,
+		UserID:    userId,
+		SubjectID: transaction.TransactionId,
+		Details: &models.TripEventDetails{
+			Amount:       transaction.Amount.String(),
+			Counterparty: debtor.Username,
+		},
+	})
+
 	response, repoErr := tc.mapTransactionToDto(ctx, transaction)
 	if repoErr != nil {
 		return nil, repoErr
@@ -218,6 +256,12 @@ func (tc *TransactionController) mapTransactionToDto(ctx context.Context, transa
 		Amount:        transaction.Amount.String(),
 		CreationDate:  transaction.CreationDate.String(),
 		IsConfirmed:   transaction.IsConfirmed,
+		Type:          transaction.Type,
+		Reference:     utils.GenerateSettlementReference(transaction.TransactionId),
+	}
+
+	if transaction.Note != nil {
+		response.Note = *transaction.Note
 	}
 
 	// Get creditor from database
@@ -297,6 +341,16 @@ func (tc *TransactionController) DeleteTransactionEntry(ctx context.Context, tra
 		return expense_errors.EXPENSE_UNAUTHORIZED
 	}
 
+	// Forgiven debts stay part of the history
+	if transaction.Type == models.TransactionTypeForgiveness {
+		return expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	// Viewers cannot delete transactions, even their own
+	if _, serviceErr := validateTripPermission(ctx, tc.TripRepo, transaction.TripId, models.TripPermissionWriteCosts); serviceErr != nil {
+		return serviceErr
+	}
+
 	// Delete transaction
 	if repoErr := tc.TransactionRepo.DeleteTx(ctx, tx, transactionId); repoErr != nil {
 		return repoErr
@@ -353,6 +407,20 @@ func (tc *TransactionController) AcceptTransaction(ctx context.Context, transact
 		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
 	}
 
+	// The creditor confirms that the payment of the debtor arrived
+	details := &models.TripEventDetails{Amount: transaction.Amount.String()}
+	if debtor, repoErr := tc.UserRepo.GetUserById(ctx, transaction.DebtorId); repoErr == nil {
+		details.Counterparty = debtor.Username
+	}
+
+	tc.EventMgr.Publish(ctx, &models.TripEvent{
+		Type:      models.TripEventPaymentConfirmed,
+		TripID:    transaction.TripId,
+		UserID:    ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID),
+		SubjectID: transaction.TransactionId,
+		Details:   details,
+	})
+
 	response, serviceErr := tc.mapTransactionToDto(ctx, transaction)
 	if serviceErr != nil {
 		return nil, serviceErr
@@ -360,3 +428,212 @@ func (tc *TransactionController) AcceptTransaction(ctx context.Context, transact
 
 	return response, nil
 }
+
+// bankStatementCandidate is an open debt or pending transaction a bank statement entry can be matched against
+type bankStatementCandidate struct {
+	matchType       string
+	transactionId   *uuid.UUID
+	debtId          *uuid.UUID
+	tripId          *uuid.UUID
+	counterpartyId  *uuid.UUID
+	amount          decimal.Decimal
+	currencyCode    string
+	reference       string
+	suggestedAction string
+	direction       string
+	used            bool
+}
+
+func (tc *TransactionController) ImportBankStatement(ctx context.Context, file *multipart.FileHeader) (*models.BankStatementImportDTO, *models.ExpenseServiceError) {
+	// Get user id from context
+	userId := ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID)
+
+	// Read statement file
+	statementFile, err := file.Open()
+	if err != nil {
+		log.Printf("Error while opening bank statement: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	defer statementFile.Close()
+
+	data, err := io.ReadAll(statementFile)
+	if err != nil {
+		log.Printf("Error while reading bank statement: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	format, entries, err := utils.ParseBankStatement(data)
+	if err != nil {
+		log.Printf("Error while parsing bank statement: %v", err)
+		return nil, expense_errors.EXPENSE_INVALID_BANK_STATEMENT
+	}
+
+	candidates := make([]*bankStatementCandidate, 0)
+
+	// Money received for a pending transaction can be confirmed directly
+	transactions, repoErr := tc.TransactionRepo.GetPendingTransactionsByDebtorId(ctx, userId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	for _, transaction := range transactions {
+		candidates = append(candidates, &bankStatementCandidate{
+			matchType:       models.BankStatementMatchTypeTransaction,
+			transactionId:   transaction.TransactionId,
+			tripId:          transaction.TripId,
+			counterpartyId:  transaction.CreditorId,
+			amount:          transaction.Amount,
+			currencyCode:    transaction.CurrencyCode,
+			reference:       utils.GenerateSettlementReference(transaction.TransactionId),
+			suggestedAction: models.BankStatementActionAcceptTransaction,
+			direction:       models.BankStatementDirectionIncoming,
+		})
+	}
+
+	// Open debts were settled without a transaction being recorded yet
+	debts, repoErr := tc.DebtRepo.GetOpenDebtsByUserId(ctx, userId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	for _, debt := range debts {
+		candidate := &bankStatementCandidate{
+			matchType:    models.BankStatementMatchTypeDebt,
+			debtId:       debt.DebtID,
+			tripId:       debt.TripId,
+			amount:       debt.Amount,
+			currencyCode: debt.CurrencyCode,
+			reference:    utils.GenerateSettlementReference(debt.DebtID),
+		}
+
+		if debt.DebtorId.String() == userId.String() {
+			// User paid the creditor, the transaction can be created by the user
+			candidate.counterpartyId = debt.CreditorId
+			candidate.direction = models.BankStatementDirectionOutgoing
+			candidate.suggestedAction = models.BankStatementActionCreateTransaction
+		} else {
+			// User got paid by the debtor, only the debtor can create the transaction
+			candidate.counterpartyId = debt.DebtorId
+			candidate.direction = models.BankStatementDirectionIncoming
+			candidate.suggestedAction = models.BankStatementActionAwaitTransaction
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	response := &models.BankStatementImportDTO{
+		Format:    format,
+		Entries:   len(entries),
+		Matches:   make([]*models.BankStatementMatchDTO, 0),
+		Unmatched: make([]*models.BankStatementEntryDTO, 0),
+	}
+
+	counterparties := make(map[uuid.UUID]*models.UserSchema)
+	for _, entry := range entries {
+		entryDto := mapBankStatementEntryToDto(entry)
+
+		var bestCandidate *bankStatementCandidate
+		var bestConfidence string
+		var bestCounterparty *models.UserSchema
+		for _, candidate := range candidates {
+			if candidate.used || candidate.direction != entry.Direction || !candidate.amount.Equal(entry.Amount) {
+				continue
+			}
+
+			if entry.CurrencyCode != "" && !strings.EqualFold(entry.CurrencyCode, candidate.currencyCode) {
+				continue
+			}
+
+			// Get counterparty from database, users are cached as they are often part of multiple debts
+			counterparty, ok := counterparties[*candidate.counterpartyId]
+			if !ok {
+				counterparty, repoErr = tc.UserRepo.GetUserById(ctx, candidate.counterpartyId)
+				if repoErr != nil {
+					return nil, repoErr
+				}
+				counterparties[*candidate.counterpartyId] = counterparty
+			}
+
+			confidence := rateBankStatementMatch(entry, candidate, counterparty)
+			if bestCandidate == nil || bankStatementConfidenceRank(confidence) > bankStatementConfidenceRank(bestConfidence) {
+				bestCandidate = candidate
+				bestConfidence = confidence
+				bestCounterparty = counterparty
+			}
+		}
+
+		if bestCandidate == nil {
+			response.Unmatched = append(response.Unmatched, entryDto)
+			continue
+		}
+
+		// Every debt or transaction can only be matched once
+		bestCandidate.used = true
+		response.Matches = append(response.Matches, &models.BankStatementMatchDTO{
+			Entry:         entryDto,
+			MatchType:     bestCandidate.matchType,
+			Confidence:    bestConfidence,
+			TransactionId: bestCandidate.transactionId,
+			DebtId:        bestCandidate.debtId,
+			TripId:        bestCandidate.tripId,
+			Counterparty: &models.UserDto{
+				UserID:   bestCounterparty.UserID,
+				Username: bestCounterparty.Username,
+				Email:    bestCounterparty.Email,
+			},
+			Reference:       bestCandidate.reference,
+			SuggestedAction: bestCandidate.suggestedAction,
+		})
+	}
+
+	return response, nil
+}
+
+// rateBankStatementMatch rates how likely an entry belongs to a candidate with the same amount
+func rateBankStatementMatch(entry *models.BankStatementEntry, candidate *bankStatementCandidate, counterparty *models.UserSchema) string {
+	// Banks tend to insert line breaks and spaces into the remittance information
+	remittance := strings.ToUpper(strings.Join(strings.Fields(entry.RemittanceInfo), ""))
+	if strings.Contains(remittance, candidate.reference) {
+		return models.BankStatementConfidenceHigh
+	}
+
+	name := strings.ToLower(entry.CounterpartyName)
+	if name != "" {
+		fullName := strings.ToLower(strings.TrimSpace(counterparty.FirstName + " " + counterparty.LastName))
+		reversedName := strings.ToLower(strings.TrimSpace(counterparty.LastName + " " + counterparty.FirstName))
+		if strings.Contains(name, strings.ToLower(counterparty.Username)) ||
+			(counterparty.LastName != "" && (strings.Contains(name, fullName) || strings.Contains(name, reversedName))) {
+			return models.BankStatementConfidenceMedium
+		}
+	}
+
+	return models.BankStatementConfidenceLow
+}
+
+func bankStatementConfidenceRank(confidence string) int {
+	switch confidence {
+	case models.BankStatementConfidenceHigh:
+		return 3
+	case models.BankStatementConfidenceMedium:
+		return 2
+	case models.BankStatementConfidenceLow:
+		return 1
+	}
+	return 0
+}
+
+func mapBankStatementEntryToDto(entry *models.BankStatementEntry) *models.BankStatementEntryDTO {
+	entryDto := &models.BankStatementEntryDTO{
+		Amount:           entry.Amount.String(),
+		CurrencyCode:     entry.CurrencyCode,
+		Direction:        entry.Direction,
+		CounterpartyName: entry.CounterpartyName,
+		RemittanceInfo:   entry.RemittanceInfo,
+	}
+
+	if entry.BookingDate != nil {
+		entryDto.BookingDate = entry.BookingDate.Format(time.DateOnly)
+	}
+
+	return entryDto
+}
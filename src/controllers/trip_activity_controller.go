@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"context"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/expense_errors"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/repositories"
+	"github.com/google/uuid"
+	"log"
+)
+
+// TripActivityCtl Exposed interface to the handler-package
+type TripActivityCtl interface {
+	GetTripActivity(ctx context.Context, tripId *uuid.UUID, page int, pageSize int) ([]*models.TripActivityDTO, *models.ExpenseServiceError)
+	RecordTripEvent(ctx context.Context, event *models.TripEvent)
+}
+
+// TripActivityController Trip Activity Controller structure
+type TripActivityController struct {
+	TripActivityRepo repositories.TripActivityRepo
+	TripRepo         repositories.TripRepo
+	UserRepo         repositories.UserRepo
+}
+
+const (
+	defaultTripActivityPageSize = 50
+	maxTripActivityPageSize     = 100
+)
+
+// GetTripActivity returns a page of the activity feed of a trip, newest first. Page and page size default to the
+// first page of 50 activities if they are not set
+func (tac *TripActivityController) GetTripActivity(ctx context.Context, tripId *uuid.UUID, page int, pageSize int) ([]*models.TripActivityDTO, *models.ExpenseServiceError) {
+	// Pending invitees cannot see the activity of the trip yet
+	if repoErr := tac.TripRepo.ValidateIfUserHasAccepted(ctx, tripId, ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID)); repoErr != nil {
+		return nil, repoErr
+	}
+
+	if page == 0 {
+		page = 1
+	}
+
+	if pageSize == 0 {
+		pageSize = defaultTripActivityPageSize
+	}
+
+	if page < 1 || pageSize < 1 || pageSize > maxTripActivityPageSize {
+		return nil, expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	tripActivities, repoErr := tac.TripActivityRepo.GetTripActivitiesByTripID(ctx, tripId, pageSize, (page-1)*pageSize)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	// Usernames are looked up once per user, deleted users stay empty
+	usernames := make(map[uuid.UUID]string)
+	response := make([]*models.TripActivityDTO, len(tripActivities))
+	for i, tripActivity := range tripActivities {
+		activityResponse := &models.TripActivityDTO{
+			TripActivityID: tripActivity.TripActivityID,
+			Type:           tripActivity.Type,
+			SubjectID:      tripActivity.SubjectID,
+			CreationDate:   tripActivity.CreationDate.String(),
+		}
+
+		if tripActivity.UserID != nil {
+			if _, ok := usernames[*tripActivity.UserID]; !ok {
+				if user, repoErr := tac.UserRepo.GetUserById(ctx, tripActivity.UserID); repoErr == nil {
+					usernames[*tripActivity.UserID] = user.Username
+				}
+			}
+			activityResponse.Username = usernames[*tripActivity.UserID]
+		}
+
+		if tripActivity.Details != nil {
+			activityResponse.Name = tripActivity.Details.Name
+			activityResponse.Amount = tripActivity.Details.Amount
+			activityResponse.PreviousAmount = tripActivity.Details.PreviousAmount
+			activityResponse.Counterparty = tripActivity.Details.Counterparty
+		}
+
+		response[i] = activityResponse
+	}
+
+	return response, nil
+}
+
+// RecordTripEvent stores a published trip event in the activity feed of its trip
+func (tac *TripActivityController) RecordTripEvent(ctx context.Context, event *models.TripEvent) {
+	tripActivityId := uuid.New()
+	tripActivity := &models.TripActivitySchema{
+		TripActivityID: &tripActivityId,
+		TripID:         event.TripID,
+		UserID:         event.UserID,
+		Type:           event.Type,
+		SubjectID:      event.SubjectID,
+		Details:        event.Details,
+		CreationDate:   &event.OccurredAt,
+	}
+
+	if repoErr := tac.TripActivityRepo.AddTripActivity(ctx, tripActivity); repoErr != nil {
+		log.Printf("Error while recording %s event of trip %s: %v", event.Type, event.TripID, repoErr)
+	}
+}
@@ -2,15 +2,20 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/repositories"
 	"github.com/jackc/pgx/v5"
 	"github.com/shopspring/decimal"
 	"log"
+	"sort"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/expense_errors"
 	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/managers"
 	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/utils"
 	"github.com/google/uuid"
 )
 
@@ -20,22 +25,78 @@ type TripCtl interface {
 	UpdateTripEntry(ctx context.Context, tripID *uuid.UUID, tripUpdateData models.TripDTO) (*models.TripDTO, *models.ExpenseServiceError)
 	GetTripDetails(ctx context.Context, tripID *uuid.UUID) (*models.TripDTO, *models.ExpenseServiceError)
 	DeleteTripEntry(ctx context.Context, tripID *uuid.UUID) *models.ExpenseServiceError
-	GetTripEntries(ctx context.Context) ([]*models.TripDTO, *models.ExpenseServiceError)
+	GetTripEntries(ctx context.Context, includeArchived bool) ([]*models.TripDTO, *models.ExpenseServiceError)
 	InviteUserToTrip(ctx context.Context, tripId *uuid.UUID, inviteUserRequest models.UserDto) (*models.TripDTO, *models.ExpenseServiceError)
 	AcceptTripInvite(ctx context.Context, tripId *uuid.UUID, acceptRequest models.TripParticipationDTO) (*models.TripDTO, *models.ExpenseServiceError)
 	DeclineTripInvite(ctx context.Context, tripId *uuid.UUID) *models.ExpenseServiceError
+	GetTripForecast(ctx context.Context, tripId *uuid.UUID) (*models.TripForecastDTO, *models.ExpenseServiceError)
+	UpdateParticipantRole(ctx context.Context, tripId *uuid.UUID, userId *uuid.UUID, roleRequest models.TripRoleRequest) (*models.TripDTO, *models.ExpenseServiceError)
+	TransferTripOwnership(ctx context.Context, tripId *uuid.UUID, transferRequest models.TripOwnershipTransferRequest) (*models.TripDTO, *models.ExpenseServiceError)
+	CreateTripInvite(ctx context.Context, tripId *uuid.UUID, inviteRequest models.TripInviteRequest) (*models.TripInviteDTO, *models.ExpenseServiceError)
+	GetTripInvites(ctx context.Context, tripId *uuid.UUID) ([]*models.TripInviteDTO, *models.ExpenseServiceError)
+	RevokeTripInvite(ctx context.Context, tripId *uuid.UUID, tripInviteId *uuid.UUID) *models.ExpenseServiceError
+	JoinTripWithInvite(ctx context.Context, acceptRequest models.TripInviteAcceptRequest) (*models.TripDTO, *models.ExpenseServiceError)
+	LeaveTrip(ctx context.Context, tripId *uuid.UUID) *models.ExpenseServiceError
+	RemoveTripParticipant(ctx context.Context, tripId *uuid.UUID, userId *uuid.UUID) *models.ExpenseServiceError
+	AddGuestToTrip(ctx context.Context, tripId *uuid.UUID, guestRequest models.TripGuestRequest) (*models.TripDTO, *models.ExpenseServiceError)
+	UpdateOwnPresence(ctx context.Context, tripId *uuid.UUID, presenceRequest models.TripPresenceRequest) (*models.TripPresenceDTO, *models.ExpenseServiceError)
+	UpdateTripStatus(ctx context.Context, tripId *uuid.UUID, statusRequest models.TripStatusRequest) (*models.TripDTO, *models.ExpenseServiceError)
+	RemindPendingTripInvites(ctx context.Context)
+	DeleteExpiredTripInvites(ctx context.Context)
+	GetDeletedTrips(ctx context.Context) ([]*models.TrashItemDTO, *models.ExpenseServiceError)
+	RestoreTrip(ctx context.Context, tripId *uuid.UUID) (*models.TripDTO, *models.ExpenseServiceError)
+	GetTripTrash(ctx context.Context, tripId *uuid.UUID) ([]*models.TrashItemDTO, *models.ExpenseServiceError)
+	PurgeTrash(ctx context.Context)
 }
 
 // TripController Trip Controller structure
 type TripController struct {
+	MailMgr          managers.MailMgr
 	DatabaseMgr      managers.DatabaseMgr
+	EventMgr         managers.EventMgr
 	TripRepo         repositories.TripRepo
 	UserRepo         repositories.UserRepo
 	CostRepo         repositories.CostRepo
 	CostCategoryRepo repositories.CostCategoryRepo
 	DebtRepo         repositories.DebtRepo
+	TripInviteRepo   repositories.TripInviteRepo
+	TransactionRepo  repositories.TransactionRepo
+	KittyRepo        repositories.KittyRepo
+	CostController   *CostController // Rebooks the costs split by presence when presence dates change
 }
 
+const tripInviteMailSubject = "You have been invited to a trip on Costventures!"
+const tripInvitationReminderMailSubject = "Your trip invitation expires soon!"
+
+const (
+	tripInviteTokenLength = 32
+	tripInviteLinkFormat  = "https://costventures.works/invite?token=%s"
+
+	// Invitations expire after TRIP_INVITE_EXPIRY, a reminder is sent TRIP_INVITE_REMINDER before they expire
+	defaultTripInviteExpiry   = 7 * 24 * time.Hour
+	defaultTripInviteReminder = 24 * time.Hour
+)
+
+// Deleted trips, cost categories and costs stay in the trash for TRASH_RETENTION before they are purged
+const defaultTrashRetention = 30 * 24 * time.Hour
+
+// tripWriteOffNote is the note of the ledger entries that write off open debts when a trip is closed
+const tripWriteOffNote = "Written off when the trip was closed"
+
+// tripStatusTransitions lists the statuses a trip can be moved to from each status
+var tripStatusTransitions = map[string][]string{
+	models.TripStatusActive:   {models.TripStatusSettling, models.TripStatusClosed},
+	models.TripStatusSettling: {models.TripStatusActive, models.TripStatusClosed},
+	models.TripStatusClosed:   {models.TripStatusSettling, models.TripStatusArchived},
+	models.TripStatusArchived: {models.TripStatusClosed},
+}
+
+const (
+	maxGuestNameLength        = 25 // Length of the firstname column
+	guestUsernamePrefixLength = 13 // Leaves room for the random suffix within the 20 characters of a username
+	guestEmailFormat          = "guest-%s@guest.costventures.works"
+)
+
 func (tc *TripController) CreateTripEntry(ctx context.Context, tripRequest models.TripDTO) (*models.TripDTO, *models.ExpenseServiceError) {
 	// Create new trip
 	tripID := uuid.New()
@@ -56,45 +117,104 @@ func (tc *TripController) CreateTripEntry(ctx context.Context, tripRequest model
 		Location:    tripRequest.Location,
 		StartDate:   &tripStartDate,
 		EndDate:     &tripEndDate,
+		Status:      models.TripStatusActive,
 	}
 
-	// Insert trip into database
-	if repoErr := tc.TripRepo.CreateTrip(ctx, trip); repoErr != nil {
-		return nil, repoErr
+	if tripRequest.Budget != nil && *tripRequest.Budget != "" {
+		budget, serviceErr := ValidateAmount(*tripRequest.Budget)
+		if serviceErr != nil {
+			return nil, serviceErr
+		}
+		trip.Budget = &budget
 	}
 
-	var deleteTripError *models.ExpenseServiceError
+	if tripRequest.ApprovalThreshold != nil && *tripRequest.ApprovalThreshold != "" {
+		approvalThreshold, serviceErr := ValidateAmount(*tripRequest.ApprovalThreshold)
+		if serviceErr != nil {
+			return nil, serviceErr
+		}
+		trip.ApprovalThreshold = &approvalThreshold
+	}
 
-	// Delete trip from database if user is not added to trip
-	defer func() {
-		if deleteTripError != nil {
-			tc.TripRepo.DeleteTrip(ctx, &tripID)
+	// Resolve the optional cost category preset before anything is written
+	var preset *costCategoryPreset
+	if tripRequest.CategoryPreset != "" {
+		var ok bool
+		if preset, ok = getCostCategoryPreset(tripRequest.CategoryPreset); !ok {
+			return nil, expense_errors.EXPENSE_BAD_REQUEST
 		}
-	}()
+	}
 
-	// Insert user-trip association into database
 	userId, ok := ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID)
 	if !ok {
 		log.Println("User ID not found in context")
-		return nil, deleteTripError
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// Begin transaction
+	tx, err := tc.DatabaseMgr.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Error while beginning transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// Make sure to rollback the transaction if it fails
+	defer func(tx pgx.Tx) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			log.Printf("Error while rolling back transaction: %v", err)
+		}
+	}(tx)
+
+	// Insert trip into database
+	if repoErr := tc.TripRepo.CreateTripTx(ctx, tx, trip); repoErr != nil {
+		return nil, repoErr
+	}
+
+	// The creator of a trip is its owner
+	owner := &models.UserTripSchema{
+		UserID:            userId,
+		TripID:            &tripID,
+		HasAccepted:       true,
+		Role:              models.TripRoleOwner,
+		PresenceStartDate: &tripStartDate,
+		PresenceEndDate:   &tripEndDate,
 	}
 
-	if repoErr := tc.TripRepo.AddUserToTrip(ctx, trip, userId, true); repoErr != nil {
-		deleteTripError = repoErr
+	if repoErr := tc.TripRepo.AddTripParticipantTx(ctx, tx, owner); repoErr != nil {
 		return nil, repoErr
 	}
 
-	if deleteTripError != nil {
-		return nil, deleteTripError
+	if preset != nil {
+		for _, entry := range preset.Categories {
+			costCategoryId := uuid.New()
+			costCategory := &models.CostCategorySchema{
+				CostCategoryID: &costCategoryId,
+				Name:           localizePresetName(entry.Names, tripRequest.Locale),
+				Icon:           entry.Icon,
+				Color:          entry.Color,
+				TripID:         &tripID,
+			}
+
+			if repoErr := tc.CostCategoryRepo.CreateCostCategoryTx(ctx, tx, costCategory); repoErr != nil {
+				return nil, repoErr
+			}
+		}
+	}
+
+	// If everything went well, commit the transaction
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error while committing transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
 	}
 
 	return tc.mapTripToResponse(ctx, trip)
 }
 
 func (tc *TripController) UpdateTripEntry(ctx context.Context, tripID *uuid.UUID, tripRequest models.TripDTO) (*models.TripDTO, *models.ExpenseServiceError) {
-	// Check if user accepted trip invite
-	if repoErr := tc.TripRepo.ValidateIfUserHasAccepted(ctx, tripID, ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID)); repoErr != nil {
-		return nil, repoErr
+	// Check if user is allowed to edit the trip
+	if _, serviceErr := validateTripPermission(ctx, tc.TripRepo, tripID, models.TripPermissionEditTrip); serviceErr != nil {
+		return nil, serviceErr
 	}
 
 	// Get trip from database
@@ -124,6 +244,31 @@ func (tc *TripController) UpdateTripEntry(ctx context.Context, tripID *uuid.UUID
 		*trip.EndDate, _ = time.Parse(time.DateOnly, tripRequest.EndDate)
 	}
 
+	// An empty budget or approval threshold removes it
+	if tripRequest.Budget != nil {
+		if *tripRequest.Budget == "" {
+			trip.Budget = nil
+		} else {
+			budget, serviceErr := ValidateAmount(*tripRequest.Budget)
+			if serviceErr != nil {
+				return nil, serviceErr
+			}
+			trip.Budget = &budget
+		}
+	}
+
+	if tripRequest.ApprovalThreshold != nil {
+		if *tripRequest.ApprovalThreshold == "" {
+			trip.ApprovalThreshold = nil
+		} else {
+			approvalThreshold, serviceErr := ValidateAmount(*tripRequest.ApprovalThreshold)
+			if serviceErr != nil {
+				return nil, serviceErr
+			}
+			trip.ApprovalThreshold = &approvalThreshold
+		}
+	}
+
 	// Update trip in database
 	repoErr = tc.TripRepo.UpdateTrip(ctx, trip)
 	if repoErr != nil {
@@ -133,7 +278,8 @@ func (tc *TripController) UpdateTripEntry(ctx context.Context, tripID *uuid.UUID
 	return tc.mapTripToResponse(ctx, trip)
 }
 
-func (tc *TripController) GetTripEntries(ctx context.Context) ([]*models.TripDTO, *models.ExpenseServiceError) {
+// GetTripEntries returns the trips of the user. Archived trips are only included if requested
+func (tc *TripController) GetTripEntries(ctx context.Context, includeArchived bool) ([]*models.TripDTO, *models.ExpenseServiceError) {
 	// Get trips from database
 	trips, repoErr := tc.TripRepo.GetTripsByUserId(ctx, ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID))
 	if repoErr != nil {
@@ -141,13 +287,18 @@ func (tc *TripController) GetTripEntries(ctx context.Context) ([]*models.TripDTO
 	}
 
 	// Iterate over rows and create trip response
-	tripResponses := make([]*models.TripDTO, len(trips))
-	for i, trip := range trips {
+	tripResponses := make([]*models.TripDTO, 0, len(trips))
+	for _, trip := range trips {
+		if trip.Status == models.TripStatusArchived && !includeArchived {
+			continue
+		}
+
 		// Append trip response to response array
-		var serviceErr *models.ExpenseServiceError
-		if tripResponses[i], serviceErr = tc.mapTripToResponse(ctx, trip); serviceErr != nil {
+		tripResponse, serviceErr := tc.mapTripToResponse(ctx, trip)
+		if serviceErr != nil {
 			return nil, serviceErr
 		}
+		tripResponses = append(tripResponses, tripResponse)
 	}
 
 	return tripResponses, nil
@@ -165,19 +316,20 @@ func (tc *TripController) GetTripDetails(ctx context.Context, tripID *uuid.UUID)
 }
 
 func (tc *TripController) DeleteTripEntry(ctx context.Context, tripID *uuid.UUID) *models.ExpenseServiceError {
-	// Check if user accepted trip invite
-	if repoErr := tc.TripRepo.ValidateIfUserHasAccepted(ctx, tripID, ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID)); repoErr != nil {
-		return repoErr
+	// Only the owner is allowed to delete the trip
+	if _, serviceErr := validateTripPermission(ctx, tc.TripRepo, tripID, models.TripPermissionDeleteTrip); serviceErr != nil {
+		return serviceErr
 	}
 
-	// Delete trip from database
+	// Move trip to the trash, it can be restored until it is purged
 	return tc.TripRepo.DeleteTrip(ctx, tripID)
 }
 
 func (tc *TripController) InviteUserToTrip(ctx context.Context, tripId *uuid.UUID, inviteUserRequest models.UserDto) (*models.TripDTO, *models.ExpenseServiceError) {
-	// Check if user accepted trip invite
-	if repoErr := tc.TripRepo.ValidateIfUserHasAccepted(ctx, tripId, ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID)); repoErr != nil {
-		return nil, repoErr
+	// Check if user is allowed to invite others
+	inviter, serviceErr := validateTripPermission(ctx, tc.TripRepo, tripId, models.TripPermissionManageParticipants)
+	if serviceErr != nil {
+		return nil, serviceErr
 	}
 
 	// Get invitedUser data from invite
@@ -191,17 +343,63 @@ func (tc *TripController) InviteUserToTrip(ctx context.Context, tripId *uuid.UUI
 		return nil, repoErr
 	}
 
+	// Guests have no account to accept the invite with
+	if invitedUser.IsGuest {
+		return nil, expense_errors.EXPENSE_USER_NOT_FOUND
+	}
+
 	// Get trip data from database
 	trip, repoErr := tc.TripRepo.GetTripById(ctx, tripId)
 	if repoErr != nil {
 		return nil, repoErr
 	}
 
+	invitationDate := time.Now()
+	expiryDate := invitationDate.Add(utils.GetDurationFromEnv("TRIP_INVITE_EXPIRY", defaultTripInviteExpiry))
+
+	invitation := &models.UserTripSchema{
+		UserID:               invitedUser.UserID,
+		TripID:               trip.TripID,
+		HasAccepted:          false,
+		Role:                 models.TripRoleMember,
+		PresenceStartDate:    trip.StartDate,
+		PresenceEndDate:      trip.EndDate,
+		InvitedBy:            inviter.UserID,
+		InvitationDate:       &invitationDate,
+		InvitationExpiryDate: &expiryDate,
+	}
+
+	// Begin transaction
+	tx, err := tc.DatabaseMgr.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Error while beginning transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// Make sure to rollback the transaction if it fails
+	defer func(tx pgx.Tx) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			log.Printf("Error while rolling back transaction: %v", err)
+		}
+	}(tx)
+
 	// Invite invitedUser to trip
-	if repoErr := tc.TripRepo.AddUserToTrip(ctx, trip, invitedUser.UserID, false); repoErr != nil {
+	if repoErr := tc.TripRepo.AddTripParticipantTx(ctx, tx, invitation); repoErr != nil {
 		return nil, repoErr
 	}
 
+	// If everything went well, commit the transaction
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error while committing transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// The invitation stays valid even if the mail could not be sent
+	if mailErr := tc.sendTripInvitationMail(ctx, trip, invitation, false); mailErr != nil {
+		log.Printf("Error while sending trip invitation mail to %v: %v", invitedUser.Username, mailErr)
+	}
+
 	return tc.mapTripToResponse(ctx, trip)
 }
 
@@ -238,6 +436,11 @@ func (tc *TripController) AcceptTripInvite(ctx context.Context, tripId *uuid.UUI
 		return nil, expense_errors.EXPENSE_BAD_REQUEST
 	}
 
+	// Check if the invite is still valid
+	if tripParticipant.InvitationExpiryDate != nil && tripParticipant.InvitationExpiryDate.Before(time.Now()) {
+		return nil, expense_errors.EXPENSE_INVITE_EXPIRED
+	}
+
 	// Update trip participant data
 	tripParticipant.HasAccepted = true
 
@@ -281,38 +484,9 @@ func (tc *TripController) AcceptTripInvite(ctx context.Context, tripId *uuid.UUI
 		return nil, repoErr
 	}
 
-	// Create and add debt for each accepted trip participant and invited user
-	createAndAddDebt := func(creditorId, debtorId *uuid.UUID) *models.ExpenseServiceError {
-		debtId := uuid.New()
-		creationDate := time.Now()
-
-		debt := models.DebtSchema{
-			DebtID:       &debtId,
-			CreditorId:   creditorId,
-			DebtorId:     debtorId,
-			TripId:       trip.TripID,
-			Amount:       decimal.Zero,
-			CurrencyCode: "EUR",
-			CreationDate: &creationDate,
-			UpdateDate:   &creationDate,
-		}
-
-		// Add debt to database
-		if repoErr := tc.DebtRepo.AddTx(ctx, tx, &debt); repoErr != nil {
-			return repoErr
-		}
-
-		return nil
-	}
-
-	for _, participant := range acceptedTripParticipants {
-		if err := createAndAddDebt(participant.UserID, invitedUser.UserID); err != nil {
-			return nil, err
-		}
-
-		if err := createAndAddDebt(invitedUser.UserID, participant.UserID); err != nil {
-			return nil, err
-		}
+	// Create zero debts between the invited user and every accepted trip participant
+	if repoErr := addParticipantDebtsTx(ctx, tx, tc.DebtRepo, trip.TripID, invitedUser.UserID, acceptedTripParticipants); repoErr != nil {
+		return nil, repoErr
 	}
 
 	// If everything went well, commit the transaction
@@ -321,6 +495,8 @@ func (tc *TripController) AcceptTripInvite(ctx context.Context, tripId *uuid.UUI
 		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
 	}
 
+	publishMemberJoined(ctx, tc.EventMgr, trip.TripID, invitedUser.UserID, invitedUser)
+
 	return tc.mapTripToResponse(ctx, trip)
 }
 
@@ -340,78 +516,1144 @@ func (tc *TripController) DeclineTripInvite(ctx context.Context, tripId *uuid.UU
 	return tc.TripRepo.DeclineTripInvite(ctx, tripId, ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID))
 }
 
-func (tc *TripController) mapTripToResponse(ctx context.Context, trip *models.TripSchema) (*models.TripDTO, *models.ExpenseServiceError) {
-	// Get trip participants from database
-	participants, repoErr := tc.TripRepo.GetTripParticipants(ctx, trip.TripID)
+// UpdateParticipantRole changes the role of a trip participant. Only the owner can grant or revoke the admin role,
+// the owner role itself can only be handed over with TransferTripOwnership
+func (tc *TripController) UpdateParticipantRole(ctx context.Context, tripId *uuid.UUID, userId *uuid.UUID, roleRequest models.TripRoleRequest) (*models.TripDTO, *models.ExpenseServiceError) {
+	if !utils.IsValidTripRole(roleRequest.Role) || roleRequest.Role == models.TripRoleOwner {
+		return nil, expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	// Check if user is allowed to manage participants
+	caller, serviceErr := validateTripPermission(ctx, tc.TripRepo, tripId, models.TripPermissionManageParticipants)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	// Get participant whose role should be changed
+	participant, repoErr := tc.TripRepo.GetTripParticipant(ctx, tripId, userId)
 	if repoErr != nil {
 		return nil, repoErr
 	}
 
-	// Build participant responses
-	participationResponses := make([]models.TripParticipationDTO, len(participants))
-	for i, participant := range participants {
-		user, repoErr := tc.UserRepo.GetUserById(ctx, participant.UserID)
-		if repoErr != nil {
-			return nil, repoErr
+	if participant.Role == models.TripRoleOwner {
+		return nil, expense_errors.EXPENSE_FORBIDDEN
+	}
+
+	if caller.Role != models.TripRoleOwner && (participant.Role == models.TripRoleAdmin || roleRequest.Role == models.TripRoleAdmin) {
+		return nil, expense_errors.EXPENSE_FORBIDDEN
+	}
+
+	// Begin transaction
+	tx, err := tc.DatabaseMgr.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Error while beginning transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// Make sure to rollback the transaction if it fails
+	defer func(tx pgx.Tx) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			log.Printf("Error while rolling back transaction: %v", err)
 		}
+	}(tx)
 
-		participationResponses[i] = models.TripParticipationDTO{
-			UserID:            user.UserID,
-			Username:          user.Username,
-			HasAcceptedInvite: participant.HasAccepted,
-			PresenceStartDate: participant.PresenceStartDate.Format(time.DateOnly),
-			PresenceEndDate:   participant.PresenceEndDate.Format(time.DateOnly),
+	if repoErr := tc.TripRepo.UpdateTripParticipantRoleTx(ctx, tx, tripId, userId, roleRequest.Role); repoErr != nil {
+		return nil, repoErr
+	}
+
+	// If everything went well, commit the transaction
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error while committing transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	trip, repoErr := tc.TripRepo.GetTripById(ctx, tripId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	return tc.mapTripToResponse(ctx, trip)
+}
+
+// TransferTripOwnership hands the ownership of a trip over to another accepted participant. The previous owner becomes an admin
+func (tc *TripController) TransferTripOwnership(ctx context.Context, tripId *uuid.UUID, transferRequest models.TripOwnershipTransferRequest) (*models.TripDTO, *models.ExpenseServiceError) {
+	// Only the owner holds the permission to delete a trip
+	owner, serviceErr := validateTripPermission(ctx, tc.TripRepo, tripId, models.TripPermissionDeleteTrip)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	if transferRequest.UserID.String() == owner.UserID.String() {
+		return nil, expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	// New owner must have accepted the trip invite
+	newOwner, repoErr := tc.TripRepo.GetTripParticipant(ctx, tripId, transferRequest.UserID)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	if !newOwner.HasAccepted {
+		return nil, expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	// Begin transaction
+	tx, err := tc.DatabaseMgr.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Error while beginning transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// Make sure to rollback the transaction if it fails
+	defer func(tx pgx.Tx) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			log.Printf("Error while rolling back transaction: %v", err)
 		}
+	}(tx)
+
+	if repoErr := tc.TripRepo.UpdateTripParticipantRoleTx(ctx, tx, tripId, owner.UserID, models.TripRoleAdmin); repoErr != nil {
+		return nil, repoErr
 	}
 
-	// Get total cost of trip
-	totalCostOfTrip, repoErr := tc.CostRepo.GetTotalCostByTripID(ctx, trip.TripID)
+	if repoErr := tc.TripRepo.UpdateTripParticipantRoleTx(ctx, tx, tripId, newOwner.UserID, models.TripRoleOwner); repoErr != nil {
+		return nil, repoErr
+	}
+
+	// If everything went well, commit the transaction
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error while committing transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	trip, repoErr := tc.TripRepo.GetTripById(ctx, tripId)
 	if repoErr != nil {
 		return nil, repoErr
 	}
 
-	// Get costcategories from database
-	costCategories, repoErr := tc.CostCategoryRepo.GetCostCategoriesByTripID(ctx, trip.TripID)
+	return tc.mapTripToResponse(ctx, trip)
+}
+
+// CreateTripInvite creates a signed invite for the trip. Without an email the invite is a shareable link,
+// with an email of someone who has no account yet, the invitation is sent to that address
+func (tc *TripController) CreateTripInvite(ctx context.Context, tripId *uuid.UUID, inviteRequest models.TripInviteRequest) (*models.TripInviteDTO, *models.ExpenseServiceError) {
+	// Check if user is allowed to invite others
+	inviter, serviceErr := validateTripPermission(ctx, tc.TripRepo, tripId, models.TripPermissionManageParticipants)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	if inviteRequest.Role == "" {
+		inviteRequest.Role = models.TripRoleMember
+	}
+
+	// Nobody can be invited as owner and only the owner can invite admins
+	if !utils.IsValidTripRole(inviteRequest.Role) || inviteRequest.Role == models.TripRoleOwner {
+		return nil, expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	if inviteRequest.Role == models.TripRoleAdmin && inviter.Role != models.TripRoleOwner {
+		return nil, expense_errors.EXPENSE_FORBIDDEN
+	}
+
+	trip, repoErr := tc.TripRepo.GetTripById(ctx, tripId)
 	if repoErr != nil {
 		return nil, repoErr
 	}
 
-	// Build cost category responses
-	costCategoryResponses := make([]models.CostCategoryResponse, len(costCategories))
-	for i, costCategory := range costCategories {
-		// Get total cost for cost category
-		totalCostOfCategory, repoErr := tc.CostRepo.GetTotalCostByCostCategoryID(ctx, costCategory.CostCategoryID)
+	inviteId := uuid.New()
+	creationDate := time.Now()
+	expiryDate := creationDate.Add(utils.GetDurationFromEnv("TRIP_INVITE_EXPIRY", defaultTripInviteExpiry))
+
+	invite := &models.TripInviteSchema{
+		TripInviteID: &inviteId,
+		TripID:       tripId,
+		InviterID:    inviter.UserID,
+		Token:        utils.GenerateSignedToken(tripInviteTokenLength),
+		Role:         inviteRequest.Role,
+		CreationDate: &creationDate,
+		ExpiryDate:   &expiryDate,
+	}
+
+	// The guest to be claimed has to be a participant of the trip
+	if inviteRequest.GuestID != nil {
+		if _, repoErr := tc.TripRepo.GetTripParticipant(ctx, tripId, inviteRequest.GuestID); repoErr != nil {
+			return nil, repoErr
+		}
+
+		guest, repoErr := tc.UserRepo.GetUserById(ctx, inviteRequest.GuestID)
 		if repoErr != nil {
 			return nil, repoErr
 		}
 
-		costCategoryResponses[i] = models.CostCategoryResponse{
-			CostCategoryId: costCategory.CostCategoryID,
-			Name:           costCategory.Name,
-			Description:    costCategory.Description,
-			Color:          costCategory.Color,
-			Icon:           costCategory.Icon,
-			TotalCost:      totalCostOfCategory.String(),
+		if !guest.IsGuest {
+			return nil, expense_errors.EXPENSE_BAD_REQUEST
 		}
 
+		invite.GuestID = guest.UserID
 	}
 
-	// Get commulative debt for user in trip
-	userDebtTotal, repoErr := tc.DebtRepo.GetCumulativeDebtByUserIDAndTripID(ctx, ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID), trip.TripID)
+	if inviteRequest.Email != "" {
+		// Registered users are invited directly, email invitations are meant for addresses without an account
+		_, repoErr := tc.UserRepo.GetUserBySchema(ctx, &models.UserSchema{Email: inviteRequest.Email})
+		if repoErr == nil {
+			return nil, expense_errors.EXPENSE_USER_EXISTS
+		}
+		if repoErr != expense_errors.EXPENSE_USER_NOT_FOUND {
+			return nil, repoErr
+		}
+
+		invite.Email = &inviteRequest.Email
+	}
+
+	if repoErr := tc.TripInviteRepo.AddTripInvite(ctx, invite); repoErr != nil {
+		return nil, repoErr
+	}
+
+	inviterUser, repoErr := tc.UserRepo.GetUserById(ctx, inviter.UserID)
 	if repoErr != nil {
 		return nil, repoErr
 	}
 
-	// Get commulative credit for user in trip
-	userCreditTotal, repoErr := tc.DebtRepo.GetCumulativeCreditByUserIDAndTripID(ctx, ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID), trip.TripID)
+	response := mapTripInviteToResponse(invite, inviterUser.Username)
+
+	if invite.Email != nil {
+		inviteMail := &models.TripInviteMail{
+			InviterName: inviterUser.Username,
+			TripName:    trip.Name,
+			InviteLink:  response.Link,
+			Subject:     tripInviteMailSubject,
+			Recipients:  []string{*invite.Email},
+		}
+
+		// The invite stays valid even if the mail could not be sent, its link can still be shared by hand
+		if mailErr := tc.MailMgr.SendTripInviteMail(ctx, inviteMail); mailErr != nil {
+			log.Printf("Error while sending trip invite mail to %v: %v", *invite.Email, mailErr)
+		}
+	}
+
+	return response, nil
+}
+
+// GetTripInvites returns all invites of the trip that can still be used
+func (tc *TripController) GetTripInvites(ctx context.Context, tripId *uuid.UUID) ([]*models.TripInviteDTO, *models.ExpenseServiceError) {
+	if _, serviceErr := validateTripPermission(ctx, tc.TripRepo, tripId, models.TripPermissionManageParticipants); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	invites, repoErr := tc.TripInviteRepo.GetOutstandingTripInvites(ctx, tripId)
 	if repoErr != nil {
 		return nil, repoErr
 	}
 
-	// Build trip response
-	return &models.TripDTO{
-		TripID:         trip.TripID,
-		Name:           trip.Name,
+	response := make([]*models.TripInviteDTO, len(invites))
+	for i, invite := range invites {
+		inviter, repoErr := tc.UserRepo.GetUserById(ctx, invite.InviterID)
+		if repoErr != nil {
+			return nil, repoErr
+		}
+
+		response[i] = mapTripInviteToResponse(invite, inviter.Username)
+	}
+
+	return response, nil
+}
+
+// RevokeTripInvite invalidates an outstanding invite of the trip
+func (tc *TripController) RevokeTripInvite(ctx context.Context, tripId *uuid.UUID, tripInviteId *uuid.UUID) *models.ExpenseServiceError {
+	if _, serviceErr := validateTripPermission(ctx, tc.TripRepo, tripId, models.TripPermissionManageParticipants); serviceErr != nil {
+		return serviceErr
+	}
+
+	invite, repoErr := tc.TripInviteRepo.GetTripInviteByID(ctx, tripInviteId)
+	if repoErr != nil {
+		return repoErr
+	}
+
+	// Make sure the invite belongs to the trip
+	if *invite.TripID != *tripId {
+		return expense_errors.EXPENSE_NOT_FOUND
+	}
+
+	revokeDate := time.Now()
+	return tc.TripInviteRepo.RevokeTripInvite(ctx, tripInviteId, &revokeDate)
+}
+
+// JoinTripWithInvite adds the user of the request to the trip of the invite
+func (tc *TripController) JoinTripWithInvite(ctx context.Context, acceptRequest models.TripInviteAcceptRequest) (*models.TripDTO, *models.ExpenseServiceError) {
+	user, repoErr := tc.UserRepo.GetUserById(ctx, ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID))
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	invite, serviceErr := getRedeemableTripInvite(ctx, tc.TripInviteRepo, acceptRequest.Token, user.Email)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	// Begin transaction
+	tx, err := tc.DatabaseMgr.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Error while beginning transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// Make sure to rollback the transaction if it fails
+	defer func(tx pgx.Tx) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			log.Printf("Error while rolling back transaction: %v", err)
+		}
+	}(tx)
+
+	trip, repoErr := joinTripWithInviteTx(ctx, tx, tc.TripRepo, tc.UserRepo, tc.DebtRepo, tc.TripInviteRepo, invite, user.UserID)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	// If everything went well, commit the transaction
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error while committing transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	publishMemberJoined(ctx, tc.EventMgr, trip.TripID, user.UserID, user)
+
+	return tc.mapTripToResponse(ctx, trip)
+}
+
+// LeaveTrip removes the user of the request from the trip. The owner has to transfer the ownership first
+func (tc *TripController) LeaveTrip(ctx context.Context, tripId *uuid.UUID) *models.ExpenseServiceError {
+	participant, repoErr := tc.TripRepo.GetTripParticipant(ctx, tripId, ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID))
+	if repoErr != nil {
+		return repoErr
+	}
+
+	// Pending invites are declined instead
+	if !participant.HasAccepted {
+		return expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	if participant.Role == models.TripRoleOwner {
+		return expense_errors.EXPENSE_FORBIDDEN
+	}
+
+	return tc.removeTripParticipant(ctx, participant)
+}
+
+// RemoveTripParticipant removes another participant from the trip. Only the owner can remove admins
+func (tc *TripController) RemoveTripParticipant(ctx context.Context, tripId *uuid.UUID, userId *uuid.UUID) *models.ExpenseServiceError {
+	caller, serviceErr := validateTripPermission(ctx, tc.TripRepo, tripId, models.TripPermissionManageParticipants)
+	if serviceErr != nil {
+		return serviceErr
+	}
+
+	// Participants leave the trip on their own
+	if *caller.UserID == *userId {
+		return expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	participant, repoErr := tc.TripRepo.GetTripParticipant(ctx, tripId, userId)
+	if repoErr != nil {
+		return repoErr
+	}
+
+	if participant.Role == models.TripRoleOwner {
+		return expense_errors.EXPENSE_FORBIDDEN
+	}
+
+	if participant.Role == models.TripRoleAdmin && caller.Role != models.TripRoleOwner {
+		return expense_errors.EXPENSE_FORBIDDEN
+	}
+
+	return tc.removeTripParticipant(ctx, participant)
+}
+
+// removeTripParticipant ends the participation of a participant. Participants with open debts, costs awaiting their
+// approval or shares in pending costs have to settle first. Their debts and contributions are kept, so the costs of the
+// trip can still be changed and booked afterwards
+func (tc *TripController) removeTripParticipant(ctx context.Context, participant *models.UserTripSchema) *models.ExpenseServiceError {
+	awaitingApproval, repoErr := tc.CostRepo.GetCostsAwaitingApproval(ctx, participant.TripID, participant.UserID)
+	if repoErr != nil {
+		return repoErr
+	}
+
+	if len(awaitingApproval) > 0 {
+		return expense_errors.EXPENSE_OPEN_BALANCE
+	}
+
+	// Begin transaction
+	tx, err := tc.DatabaseMgr.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Error while beginning transaction: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// Make sure to rollback the transaction if it fails
+	defer func(tx pgx.Tx) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			log.Printf("Error while rolling back transaction: %v", err)
+		}
+	}(tx)
+
+	openDebts, repoErr := tc.DebtRepo.CountOpenDebtsByUserIdAndTripIdTx(ctx, tx, participant.UserID, participant.TripID)
+	if repoErr != nil {
+		return repoErr
+	}
+
+	if openDebts > 0 {
+		return expense_errors.EXPENSE_OPEN_BALANCE
+	}
+
+	// Shares of pending costs become debts once the cost is approved
+	pendingContributions, repoErr := tc.CostRepo.CountPendingCostContributionsByUserIdAndTripIdTx(ctx, tx, participant.UserID, participant.TripID)
+	if repoErr != nil {
+		return repoErr
+	}
+
+	if pendingContributions > 0 {
+		return expense_errors.EXPENSE_OPEN_BALANCE
+	}
+
+	// Only the membership ends, the settled debts and the contributions to costs stay for the history of the trip
+	if repoErr := tc.TripRepo.DeleteTripParticipantTx(ctx, tx, participant.TripID, participant.UserID); repoErr != nil {
+		return repoErr
+	}
+
+	// If everything went well, commit the transaction
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error while committing transaction: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return nil
+}
+
+// UpdateOwnPresence changes the presence dates of the current user after joining the trip. If requested, the costs split
+// by presence are split again with the new dates. A preview only calculates how contributions and balances would change
+func (tc *TripController) UpdateOwnPresence(ctx context.Context, tripId *uuid.UUID, presenceRequest models.TripPresenceRequest) (*models.TripPresenceDTO, *models.ExpenseServiceError) {
+	userId := ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID)
+
+	participant, repoErr := tc.TripRepo.GetTripParticipant(ctx, tripId, userId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	// Pending invites set their presence dates when they are accepted
+	if !participant.HasAccepted {
+		return nil, expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	trip, repoErr := tc.TripRepo.GetTripById(ctx, tripId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	// Presence dates have to be within the trip
+	if presenceRequest.PresenceStartDate != "" {
+		newPresenceStartDate, err := time.Parse(time.DateOnly, presenceRequest.PresenceStartDate)
+		if err != nil || newPresenceStartDate.Before(*trip.StartDate) || newPresenceStartDate.After(*trip.EndDate) {
+			return nil, expense_errors.EXPENSE_BAD_REQUEST
+		}
+		participant.PresenceStartDate = &newPresenceStartDate
+	}
+
+	if presenceRequest.PresenceEndDate != "" {
+		newPresenceEndDate, err := time.Parse(time.DateOnly, presenceRequest.PresenceEndDate)
+		if err != nil || newPresenceEndDate.Before(*trip.StartDate) || newPresenceEndDate.After(*trip.EndDate) {
+			return nil, expense_errors.EXPENSE_BAD_REQUEST
+		}
+		participant.PresenceEndDate = &newPresenceEndDate
+	}
+
+	// Check if presence start date is before presence end date
+	if participant.PresenceStartDate != nil && participant.PresenceEndDate != nil && participant.PresenceStartDate.After(*participant.PresenceEndDate) {
+		return nil, expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	response := &models.TripPresenceDTO{
+		Costs:          make([]*models.PresenceCostChangeDTO, 0),
+		BalanceChanges: make([]*models.PresenceBalanceChangeDTO, 0),
+	}
+
+	if participant.PresenceStartDate != nil {
+		response.PresenceStartDate = participant.PresenceStartDate.Format(time.DateOnly)
+	}
+
+	if participant.PresenceEndDate != nil {
+		response.PresenceEndDate = participant.PresenceEndDate.Format(time.DateOnly)
+	}
+
+	var changedCosts []*models.CostSchema
+	if presenceRequest.Recalculate || presenceRequest.Preview {
+		var serviceErr *models.ExpenseServiceError
+		changedCosts, serviceErr = tc.previewPresenceSplit(ctx, tripId, participant, response)
+		if serviceErr != nil {
+			return nil, serviceErr
+		}
+	}
+
+	if presenceRequest.Preview {
+		return response, nil
+	}
+
+	// Begin transaction
+	tx, err := tc.DatabaseMgr.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Error while beginning transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// Make sure to rollback the transaction if it fails
+	defer func(tx pgx.Tx) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			log.Printf("Error while rolling back transaction: %v", err)
+		}
+	}(tx)
+
+	if repoErr := tc.TripRepo.UpdateTripParticipantTx(ctx, tx, participant); repoErr != nil {
+		return nil, repoErr
+	}
+
+	// Costs are split again within the transaction so that the new presence dates are taken into account
+	if presenceRequest.Recalculate {
+		for _, cost := range changedCosts {
+			cost.UpdatedBy = userId
+			if serviceErr := tc.CostController.updateCostTx(ctx, tx, tripId, cost, models.CostDTO{SplitByPresence: true}); serviceErr != nil {
+				return nil, serviceErr
+			}
+		}
+	}
+
+	// If everything went well, commit the transaction
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error while committing transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// The amounts of the costs stay the same, only their contributions were split again
+	if presenceRequest.Recalculate {
+		for _, cost := range changedCosts {
+			tc.CostController.publishCostEvent(ctx, models.TripEventCostEdited, tripId, cost, userId, nil)
+		}
+	}
+
+	response.Applied = true
+	return response, nil
+}
+
+// previewPresenceSplit splits the costs split by presence again with the changed dates of the participant and adds every
+// cost whose contributions change, and the resulting balance changes, to the response. The changed costs are returned
+func (tc *TripController) previewPresenceSplit(ctx context.Context, tripId *uuid.UUID, participant *models.UserTripSchema, response *models.TripPresenceDTO) ([]*models.CostSchema, *models.ExpenseServiceError) {
+	participants, repoErr := tc.TripRepo.GetAcceptedTripParticipants(ctx, tripId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	// Replace the stored presence dates with the changed ones
+	for i, tripParticipant := range participants {
+		if *tripParticipant.UserID == *participant.UserID {
+			participants[i] = participant
+		}
+	}
+
+	costs, repoErr := tc.CostRepo.GetCostsByTripID(ctx, tripId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	changedCosts := make([]*models.CostSchema, 0)
+	balanceChanges := make(map[string]decimal.Decimal)
+	for _, cost := range costs {
+		if !cost.SplitByPresence {
+			continue
+		}
+
+		debtors, serviceErr := tc.CostController.mapPresentParticipantsToDebtors(ctx, participants, cost)
+		if serviceErr != nil {
+			return nil, serviceErr
+		}
+
+		contributions, repoErr := tc.CostRepo.GetCostContributors(ctx, cost.CostID)
+		if repoErr != nil {
+			return nil, repoErr
+		}
+
+		recalculated := models.CostDTO{Amount: cost.Amount.String(), Debtors: debtors}
+		previousAmounts := make(map[string]decimal.Decimal, len(contributions))
+		previousContributors := make([]*models.Contributor, len(contributions))
+		for i, contribution := range contributions {
+			user, repoErr := tc.UserRepo.GetUserById(ctx, contribution.UserID)
+			if repoErr != nil {
+				return nil, repoErr
+			}
+
+			previousAmounts[user.Username] = contribution.Amount
+			previousContributors[i] = &models.Contributor{Username: user.Username, Amount: contribution.Amount.String()}
+
+			// The creditor stays a contributor even if absent
+			if contribution.IsCreditor {
+				creditorFound := false
+				for _, debtor := range debtors {
+					if debtor.Username == user.Username {
+						creditorFound = true
+						break
+					}
+				}
+				if !creditorFound {
+					recalculated.Debtors = append(recalculated.Debtors, &models.Contributor{Username: user.Username, Amount: "0.0"})
+				}
+			}
+		}
+
+		if serviceErr := DistributeCosts(&recalculated); serviceErr != nil {
+			return nil, serviceErr
+		}
+
+		changed := len(recalculated.Debtors) != len(contributions)
+		for _, debtor := range recalculated.Debtors {
+			previousAmount, found := previousAmounts[debtor.Username]
+			if !found || !previousAmount.Equal(decimal.RequireFromString(debtor.Amount)) {
+				changed = true
+			}
+		}
+
+		if !changed {
+			continue
+		}
+
+		// A smaller share improves the balance of a participant by the difference
+		for username, amount := range previousAmounts {
+			balanceChanges[username] = balanceChanges[username].Add(amount)
+		}
+		for _, debtor := range recalculated.Debtors {
+			balanceChanges[debtor.Username] = balanceChanges[debtor.Username].Sub(decimal.RequireFromString(debtor.Amount))
+		}
+
+		response.Costs = append(response.Costs, &models.PresenceCostChangeDTO{
+			CostID:       cost.CostID,
+			Description:  cost.Description,
+			Amount:       cost.Amount.String(),
+			Contributors: previousContributors,
+			Recalculated: recalculated.Debtors,
+		})
+		changedCosts = append(changedCosts, cost)
+	}
+
+	usernames := make([]string, 0, len(balanceChanges))
+	for username, amount := range balanceChanges {
+		if !amount.IsZero() {
+			usernames = append(usernames, username)
+		}
+	}
+	sort.Strings(usernames)
+
+	for _, username := range usernames {
+		response.BalanceChanges = append(response.BalanceChanges, &models.PresenceBalanceChangeDTO{
+			Username: username,
+			Amount:   balanceChanges[username].String(),
+		})
+	}
+
+	return changedCosts, nil
+}
+
+// UpdateTripStatus moves the trip through its lifecycle. Closing requires all debts to be settled, unless they are
+// explicitly written off. Reopening a closed trip moves it back to settling
+func (tc *TripController) UpdateTripStatus(ctx context.Context, tripId *uuid.UUID, statusRequest models.TripStatusRequest) (*models.TripDTO, *models.ExpenseServiceError) {
+	if _, serviceErr := validateTripPermission(ctx, tc.TripRepo, tripId, models.TripPermissionEditTrip); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	trip, repoErr := tc.TripRepo.GetTripById(ctx, tripId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	allowed := false
+	for _, status := range tripStatusTransitions[trip.Status] {
+		if status == statusRequest.Status {
+			allowed = true
+			break
+		}
+	}
+
+	if !allowed {
+		return nil, expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	// Begin transaction
+	tx, err := tc.DatabaseMgr.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Error while beginning transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// Make sure to rollback the transaction if it fails
+	defer func(tx pgx.Tx) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			log.Printf("Error while rolling back transaction: %v", err)
+		}
+	}(tx)
+
+	// Archiving keeps the date the trip was closed
+	closedAt := trip.ClosedAt
+	switch statusRequest.Status {
+	case models.TripStatusClosed:
+		if trip.Status != models.TripStatusArchived {
+			if serviceErr := tc.validateTripClosableTx(ctx, tx, tripId); serviceErr != nil {
+				return nil, serviceErr
+			}
+
+			if serviceErr := tc.settleTripDebtsTx(ctx, tx, tripId, statusRequest.WriteOffDebts); serviceErr != nil {
+				return nil, serviceErr
+			}
+
+			now := time.Now()
+			closedAt = &now
+		}
+	case models.TripStatusActive, models.TripStatusSettling:
+		closedAt = nil
+	}
+
+	if repoErr := tc.TripRepo.UpdateTripStatusTx(ctx, tx, tripId, statusRequest.Status, closedAt); repoErr != nil {
+		return nil, repoErr
+	}
+
+	// If everything went well, commit the transaction
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error while committing transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	trip.Status = statusRequest.Status
+	trip.ClosedAt = closedAt
+	return tc.mapTripToResponse(ctx, trip)
+}
+
+// validateTripClosableTx makes sure that no cost of the trip is still awaiting approval and that its kitty has been
+// settled, since neither can change the debts once the trip is closed
+func (tc *TripController) validateTripClosableTx(ctx context.Context, tx pgx.Tx, tripId *uuid.UUID) *models.ExpenseServiceError {
+	pendingCosts, repoErr := tc.CostRepo.CountPendingCostsByTripIdTx(ctx, tx, tripId)
+	if repoErr != nil {
+		return repoErr
+	}
+
+	if pendingCosts > 0 {
+		return expense_errors.EXPENSE_CONFLICT
+	}
+
+	// Trips without a kitty have nothing to settle. The kitty stays locked, so no deposit can slip in before the trip is closed
+	kitty, repoErr := tc.KittyRepo.GetKittyByTripIDForUpdateTx(ctx, tx, tripId)
+	if repoErr == expense_errors.EXPENSE_NOT_FOUND {
+		return nil
+	} else if repoErr != nil {
+		return repoErr
+	}
+
+	if kitty.SettledAt == nil {
+		return expense_errors.EXPENSE_CONFLICT
+	}
+
+	return nil
+}
+
+// settleTripDebtsTx makes sure no debts of the trip are open before it is closed. Open debts are only written off if
+// explicitly requested, every creditor then forgives what they are still owed
+func (tc *TripController) settleTripDebtsTx(ctx context.Context, tx pgx.Tx, tripId *uuid.UUID, writeOff bool) *models.ExpenseServiceError {
+	if writeOff {
+		debts, repoErr := tc.DebtRepo.GetDebtEntriesByTripIdForUpdateTx(ctx, tx, tripId)
+		if repoErr != nil {
+			return repoErr
+		}
+
+		// Debts are stored for both directions, only the positive side is owed
+		for _, debt := range debts {
+			if !debt.Amount.IsPositive() {
+				continue
+			}
+
+			if repoErr := forgiveDebtTx(ctx, tx, tc.DebtRepo, tc.TransactionRepo, debt, debt.Amount, tripWriteOffNote); repoErr != nil {
+				return repoErr
+			}
+		}
+	}
+
+	openDebts, repoErr := tc.DebtRepo.CountOpenDebtsByTripIdTx(ctx, tx, tripId)
+	if repoErr != nil {
+		return repoErr
+	}
+
+	if openDebts > 0 {
+		return expense_errors.EXPENSE_OPEN_BALANCE
+	}
+
+	return nil
+}
+
+// AddGuestToTrip adds a named placeholder participant for someone without an account. Guests take part in costs and
+// debts like everyone else and can later be claimed by a real account through an invite
+func (tc *TripController) AddGuestToTrip(ctx context.Context, tripId *uuid.UUID, guestRequest models.TripGuestRequest) (*models.TripDTO, *models.ExpenseServiceError) {
+	if _, serviceErr := validateTripPermission(ctx, tc.TripRepo, tripId, models.TripPermissionManageParticipants); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	name := strings.TrimSpace(guestRequest.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxGuestNameLength {
+		return nil, expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	trip, repoErr := tc.TripRepo.GetTripById(ctx, tripId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	// Guests get a unique username derived from their name and an address that can never receive mail
+	guestId := uuid.New()
+	creationDate := time.Now()
+	usernamePrefix := []rune(name)
+	if len(usernamePrefix) > guestUsernamePrefixLength {
+		usernamePrefix = usernamePrefix[:guestUsernamePrefixLength]
+	}
+
+	guest := &models.UserSchema{
+		UserID:    &guestId,
+		Username:  fmt.Sprintf("%s-%s", string(usernamePrefix), utils.GenerateRandomString(6)),
+		FirstName: name,
+		Email:     fmt.Sprintf(guestEmailFormat, guestId),
+		CreatedAt: &creationDate,
+		IsGuest:   true,
+	}
+
+	// Get accepted participants before the guest joins
+	acceptedTripParticipants, repoErr := tc.TripRepo.GetAcceptedTripParticipants(ctx, tripId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	// Begin transaction
+	tx, err := tc.DatabaseMgr.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Error while beginning transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// Make sure to rollback the transaction if it fails
+	defer func(tx pgx.Tx) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			log.Printf("Error while rolling back transaction: %v", err)
+		}
+	}(tx)
+
+	if repoErr := tc.UserRepo.CreateGuestUserTx(ctx, tx, guest); repoErr != nil {
+		return nil, repoErr
+	}
+
+	participant := &models.UserTripSchema{
+		UserID:            guest.UserID,
+		TripID:            trip.TripID,
+		HasAccepted:       true,
+		Role:              models.TripRoleMember,
+		PresenceStartDate: trip.StartDate,
+		PresenceEndDate:   trip.EndDate,
+	}
+
+	if repoErr := tc.TripRepo.AddTripParticipantTx(ctx, tx, participant); repoErr != nil {
+		return nil, repoErr
+	}
+
+	if repoErr := addParticipantDebtsTx(ctx, tx, tc.DebtRepo, trip.TripID, guest.UserID, acceptedTripParticipants); repoErr != nil {
+		return nil, repoErr
+	}
+
+	// If everything went well, commit the transaction
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error while committing transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	publishMemberJoined(ctx, tc.EventMgr, trip.TripID, ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID), guest)
+
+	return tc.mapTripToResponse(ctx, trip)
+}
+
+// RemindPendingTripInvites sends a reminder to every invited user whose invitation expires soon. It runs as a background job
+func (tc *TripController) RemindPendingTripInvites(ctx context.Context) {
+	remindBefore := time.Now().Add(utils.GetDurationFromEnv("TRIP_INVITE_REMINDER", defaultTripInviteReminder))
+
+	invitations, repoErr := tc.TripRepo.GetTripInvitesDueForReminder(ctx, &remindBefore)
+	if repoErr != nil {
+		log.Printf("Error while getting trip invitations due for reminder: %v", repoErr)
+		return
+	}
+
+	for _, invitation := range invitations {
+		trip, repoErr := tc.TripRepo.GetTripById(ctx, invitation.TripID)
+		if repoErr != nil {
+			log.Printf("Error while getting trip for invitation reminder: %v", repoErr)
+			continue
+		}
+
+		if mailErr := tc.sendTripInvitationMail(ctx, trip, invitation, true); mailErr != nil {
+			log.Printf("Error while sending trip invitation reminder to %v: %v", invitation.UserID, mailErr)
+			continue
+		}
+
+		reminderDate := time.Now()
+		if repoErr := tc.TripRepo.UpdateTripInviteReminderDate(ctx, invitation.TripID, invitation.UserID, &reminderDate); repoErr != nil {
+			log.Printf("Error while updating trip invitation reminder date: %v", repoErr)
+		}
+	}
+}
+
+// DeleteExpiredTripInvites removes invitations that were not accepted in time. It runs as a background job
+func (tc *TripController) DeleteExpiredTripInvites(ctx context.Context) {
+	deleted, repoErr := tc.TripRepo.DeleteExpiredTripInvites(ctx)
+	if repoErr != nil {
+		log.Printf("Error while deleting expired trip invitations: %v", repoErr)
+		return
+	}
+
+	if deleted > 0 {
+		log.Printf("Deleted %d expired trip invitations", deleted)
+	}
+}
+
+// GetDeletedTrips returns the trips of the user that are in the trash, most recently deleted first
+func (tc *TripController) GetDeletedTrips(ctx context.Context) ([]*models.TrashItemDTO, *models.ExpenseServiceError) {
+	userId, ok := ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID)
+	if !ok {
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	trips, repoErr := tc.TripRepo.GetDeletedTripsByUserId(ctx, userId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	retention := utils.GetDurationFromEnv("TRASH_RETENTION", defaultTrashRetention)
+	response := make([]*models.TrashItemDTO, len(trips))
+	for i, trip := range trips {
+		response[i] = mapToTrashItem(trip.TripID, models.TrashItemTypeTrip, trip.Name, "", trip.DeletedAt, retention)
+	}
+
+	return response, nil
+}
+
+// RestoreTrip moves a deleted trip out of the trash. Only participants that are allowed to delete the trip can restore it
+func (tc *TripController) RestoreTrip(ctx context.Context, tripId *uuid.UUID) (*models.TripDTO, *models.ExpenseServiceError) {
+	if _, serviceErr := validateTripPermission(ctx, tc.TripRepo, tripId, models.TripPermissionDeleteTrip); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	if repoErr := tc.TripRepo.RestoreTrip(ctx, tripId); repoErr != nil {
+		return nil, repoErr
+	}
+
+	trip, repoErr := tc.TripRepo.GetTripById(ctx, tripId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	return tc.mapTripToResponse(ctx, trip)
+}
+
+// GetTripTrash returns the deleted cost categories and costs of a trip, most recently deleted first. Private costs are
+// only listed for their creator
+func (tc *TripController) GetTripTrash(ctx context.Context, tripId *uuid.UUID) ([]*models.TrashItemDTO, *models.ExpenseServiceError) {
+	userId, ok := ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID)
+	if !ok {
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	costCategories, repoErr := tc.CostCategoryRepo.GetDeletedCostCategoriesByTripID(ctx, tripId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	costs, repoErr := tc.CostRepo.GetDeletedCostsByTripID(ctx, tripId, userId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	retention := utils.GetDurationFromEnv("TRASH_RETENTION", defaultTrashRetention)
+	response := make([]*models.TrashItemDTO, 0, len(costCategories)+len(costs))
+	deletionDates := make(map[*models.TrashItemDTO]time.Time)
+	for _, costCategory := range costCategories {
+		item := mapToTrashItem(costCategory.CostCategoryID, models.TrashItemTypeCostCategory, costCategory.Name, "", costCategory.DeletedAt, retention)
+		deletionDates[item] = *costCategory.DeletedAt
+		response = append(response, item)
+	}
+
+	for _, cost := range costs {
+		item := mapToTrashItem(cost.CostID, models.TrashItemTypeCost, cost.Description, cost.Amount.String(), cost.DeletedAt, retention)
+		deletionDates[item] = *cost.DeletedAt
+		response = append(response, item)
+	}
+
+	sort.SliceStable(response, func(i, j int) bool {
+		return deletionDates[response[i]].After(deletionDates[response[j]])
+	})
+
+	return response, nil
+}
+
+// PurgeTrash removes deleted costs, cost categories and trips for good once they have been in the trash for longer than
+// TRASH_RETENTION. It runs as a background job
+func (tc *TripController) PurgeTrash(ctx context.Context) {
+	deletedBefore := time.Now().Add(-utils.GetDurationFromEnv("TRASH_RETENTION", defaultTrashRetention))
+
+	purgedCosts, repoErr := tc.CostRepo.PurgeDeletedCosts(ctx, &deletedBefore)
+	if repoErr != nil {
+		log.Printf("Error while purging deleted costs: %v", repoErr)
+		return
+	}
+
+	purgedCostCategories, repoErr := tc.CostCategoryRepo.PurgeDeletedCostCategories(ctx, &deletedBefore)
+	if repoErr != nil {
+		log.Printf("Error while purging deleted cost categories: %v", repoErr)
+		return
+	}
+
+	purgedTrips, repoErr := tc.TripRepo.PurgeDeletedTrips(ctx, &deletedBefore)
+	if repoErr != nil {
+		log.Printf("Error while purging deleted trips: %v", repoErr)
+		return
+	}
+
+	if purgedCosts+purgedCostCategories+purgedTrips > 0 {
+		log.Printf("Purged %d costs, %d cost categories and %d trips from the trash", purgedCosts, purgedCostCategories, purgedTrips)
+	}
+}
+
+// mapToTrashItem maps a deleted trip, cost category or cost to a trash item that is purged once the retention has passed
+func mapToTrashItem(id *uuid.UUID, itemType string, name string, amount string, deletedAt *time.Time, retention time.Duration) *models.TrashItemDTO {
+	return &models.TrashItemDTO{
+		ID:        id,
+		Type:      itemType,
+		Name:      name,
+		Amount:    amount,
+		DeletedAt: deletedAt.String(),
+		PurgeAt:   deletedAt.Add(retention).String(),
+	}
+}
+
+// sendTripInvitationMail notifies an invited user about the invitation or reminds them before it expires
+func (tc *TripController) sendTripInvitationMail(ctx context.Context, trip *models.TripSchema, invitation *models.UserTripSchema, reminder bool) *models.ExpenseServiceError {
+	invitedUser, repoErr := tc.UserRepo.GetUserById(ctx, invitation.UserID)
+	if repoErr != nil {
+		return repoErr
+	}
+
+	mailData := &models.TripInvitationMail{
+		Username:    invitedUser.Username,
+		InviterName: "A fellow traveller",
+		TripName:    trip.Name,
+		StartDate:   trip.StartDate.Format(time.DateOnly),
+		EndDate:     trip.EndDate.Format(time.DateOnly),
+		Subject:     tripInviteMailSubject,
+		Recipients:  []string{invitedUser.Email},
+	}
+
+	if invitation.InvitedBy != nil {
+		if inviter, repoErr := tc.UserRepo.GetUserById(ctx, invitation.InvitedBy); repoErr == nil {
+			mailData.InviterName = inviter.Username
+		}
+	}
+
+	if invitation.InvitationExpiryDate != nil {
+		mailData.ExpiresAt = invitation.InvitationExpiryDate.Format(time.DateOnly)
+	}
+
+	if reminder {
+		mailData.Subject = tripInvitationReminderMailSubject
+		return tc.MailMgr.SendTripInvitationReminderMail(ctx, mailData)
+	}
+
+	return tc.MailMgr.SendTripInvitationMail(ctx, mailData)
+}
+
+func (tc *TripController) mapTripToResponse(ctx context.Context, trip *models.TripSchema) (*models.TripDTO, *models.ExpenseServiceError) {
+	// Get trip participants from database
+	participants, repoErr := tc.TripRepo.GetTripParticipants(ctx, trip.TripID)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	// Build participant responses
+	participationResponses := make([]models.TripParticipationDTO, len(participants))
+	for i, participant := range participants {
+		user, repoErr := tc.UserRepo.GetUserById(ctx, participant.UserID)
+		if repoErr != nil {
+			return nil, repoErr
+		}
+
+		participationResponses[i] = models.TripParticipationDTO{
+			UserID:            user.UserID,
+			Username:          user.Username,
+			HasAcceptedInvite: participant.HasAccepted,
+			IsGuest:           user.IsGuest,
+			Role:              participant.Role,
+			PresenceStartDate: participant.PresenceStartDate.Format(time.DateOnly),
+			PresenceEndDate:   participant.PresenceEndDate.Format(time.DateOnly),
+		}
+	}
+
+	// Get total cost of trip
+	totalCostOfTrip, repoErr := tc.CostRepo.GetTotalCostByTripID(ctx, trip.TripID)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	// Get costcategories from database
+	costCategories, repoErr := tc.CostCategoryRepo.GetCostCategoriesByTripID(ctx, trip.TripID)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	// Build cost category responses
+	costCategoryResponses := make([]models.CostCategoryResponse, len(costCategories))
+	for i, costCategory := range costCategories {
+		// Get total cost for cost category
+		totalCostOfCategory, repoErr := tc.CostRepo.GetTotalCostByCostCategoryID(ctx, costCategory.CostCategoryID)
+		if repoErr != nil {
+			return nil, repoErr
+		}
+
+		costCategoryResponses[i] = models.CostCategoryResponse{
+			CostCategoryId: costCategory.CostCategoryID,
+			Name:           costCategory.Name,
+			Description:    costCategory.Description,
+			Color:          costCategory.Color,
+			Icon:           costCategory.Icon,
+			ParentId:       costCategory.ParentID,
+			TotalCost:      totalCostOfCategory.String(),
+		}
+		mapCostCategoryBudget(&costCategoryResponses[i], &costCategory, *totalCostOfCategory)
+
+	}
+
+	// Get commulative debt for user in trip
+	userDebtTotal, repoErr := tc.DebtRepo.GetCumulativeDebtByUserIDAndTripID(ctx, ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID), trip.TripID)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	// Get commulative credit for user in trip
+	userCreditTotal, repoErr := tc.DebtRepo.GetCumulativeCreditByUserIDAndTripID(ctx, ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID), trip.TripID)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	// Build trip response
+	response := &models.TripDTO{
+		TripID:         trip.TripID,
+		Name:           trip.Name,
 		Description:    trip.Description,
 		Location:       trip.Location,
 		StartDate:      trip.StartDate.Format(time.DateOnly),
@@ -421,5 +1663,363 @@ func (tc *TripController) mapTripToResponse(ctx context.Context, trip *models.Tr
 		UserDebt:       userDebtTotal.String(),
 		UserCredit:     userCreditTotal.String(),
 		Participants:   participationResponses,
-	}, nil
+		Status:         trip.Status,
+	}
+
+	if trip.ClosedAt != nil {
+		response.ClosedAt = trip.ClosedAt.String()
+	}
+
+	if trip.Budget != nil {
+		budget := trip.Budget.String()
+		response.Budget = &budget
+	}
+
+	if trip.ApprovalThreshold != nil {
+		approvalThreshold := trip.ApprovalThreshold.String()
+		response.ApprovalThreshold = &approvalThreshold
+	}
+
+	return response, nil
+}
+
+// GetTripForecast projects the total cost of a trip based on the spending so far
+func (tc *TripController) GetTripForecast(ctx context.Context, tripId *uuid.UUID) (*models.TripForecastDTO, *models.ExpenseServiceError) {
+	// Get trip from database
+	trip, repoErr := tc.TripRepo.GetTripById(ctx, tripId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	costs, repoErr := tc.CostRepo.GetCostsByTripID(ctx, tripId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	costCategories, repoErr := tc.CostCategoryRepo.GetCostCategoriesByTripID(ctx, tripId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	participants, repoErr := tc.TripRepo.GetAcceptedTripParticipants(ctx, tripId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	// Trip dates are inclusive, so a trip from monday to monday has one day
+	startDate := truncateToDay(*trip.StartDate)
+	endDate := truncateToDay(*trip.EndDate)
+	today := truncateToDay(time.Now())
+	totalDays := daysBetween(startDate, endDate) + 1
+
+	elapsedDays := daysBetween(startDate, today) + 1
+	if elapsedDays < 0 {
+		elapsedDays = 0
+	} else if elapsedDays > totalDays {
+		elapsedDays = totalDays
+	}
+	remainingDays := totalDays - elapsedDays
+
+	// Sum up costs by day and by cost category. Only costs deducted between the start of the trip and today count towards
+	// the burn rate, costs deducted before the trip or in the future are part of the projection exactly once
+	burnRateEndDate := today
+	if burnRateEndDate.After(endDate) {
+		burnRateEndDate = endDate
+	}
+
+	totalCost := decimal.Zero
+	preTripCost := decimal.Zero
+	runningCost := decimal.Zero
+	spendingByDay := make(map[string]decimal.Decimal)
+	spendingByCategory := make(map[uuid.UUID]decimal.Decimal)
+	runningByCategory := make(map[uuid.UUID]decimal.Decimal)
+	for _, cost := range costs {
+		totalCost = totalCost.Add(cost.Amount)
+		spendingByCategory[*cost.CostCategoryID] = spendingByCategory[*cost.CostCategoryID].Add(cost.Amount)
+
+		if cost.DeductionDate != nil && truncateToDay(*cost.DeductionDate).Before(startDate) {
+			preTripCost = preTripCost.Add(cost.Amount)
+		}
+
+		if cost.DeductionDate == nil || isWithinDays(truncateToDay(*cost.DeductionDate), startDate, burnRateEndDate) {
+			runningCost = runningCost.Add(cost.Amount)
+			runningByCategory[*cost.CostCategoryID] = runningByCategory[*cost.CostCategoryID].Add(cost.Amount)
+		}
+
+		if cost.DeductionDate != nil {
+			day := cost.DeductionDate.Format(time.DateOnly)
+			spendingByDay[day] = spendingByDay[day].Add(cost.Amount)
+		}
+	}
+
+	// Every day of the trip is listed, costs outside the trip dates are appended
+	dailySpending := make([]*models.DailySpendingDTO, 0, totalDays)
+	for day := startDate; !day.After(endDate); day = day.AddDate(0, 0, 1) {
+		date := day.Format(time.DateOnly)
+		dailySpending = append(dailySpending, &models.DailySpendingDTO{
+			Date:   date,
+			Amount: spendingByDay[date].String(),
+		})
+		delete(spendingByDay, date)
+	}
+
+	outsideTripDates := make([]string, 0, len(spendingByDay))
+	for date := range spendingByDay {
+		outsideTripDates = append(outsideTripDates, date)
+	}
+	sort.Strings(outsideTripDates)
+	for _, date := range outsideTripDates {
+		dailySpending = append(dailySpending, &models.DailySpendingDTO{
+			Date:   date,
+			Amount: spendingByDay[date].String(),
+		})
+	}
+
+	dailyBurnRate, projectedTotalCost := forecastSpending(totalCost, runningCost, elapsedDays, remainingDays)
+
+	spendPerPersonPerDay := decimal.Zero
+	if len(participants) > 0 {
+		spendPerPersonPerDay = dailyBurnRate.Div(decimal.NewFromInt(int64(len(participants)))).Round(2)
+	}
+
+	response := &models.TripForecastDTO{
+		TripID:               trip.TripID,
+		StartDate:            startDate.Format(time.DateOnly),
+		EndDate:              endDate.Format(time.DateOnly),
+		TotalDays:            totalDays,
+		ElapsedDays:          elapsedDays,
+		RemainingDays:        remainingDays,
+		Participants:         len(participants),
+		TotalCost:            totalCost.String(),
+		PreTripCost:          preTripCost.String(),
+		DailyBurnRate:        dailyBurnRate.String(),
+		SpendPerPersonPerDay: spendPerPersonPerDay.String(),
+		ProjectedTotalCost:   projectedTotalCost.String(),
+		DailySpending:        dailySpending,
+		CostCategories:       make([]*models.CostCategoryForecastDTO, 0, len(costCategories)),
+	}
+
+	if trip.Budget != nil {
+		response.Budget = trip.Budget.String()
+		response.ProjectedRemaining = trip.Budget.Sub(projectedTotalCost).String()
+	}
+
+	for _, costCategory := range costCategories {
+		spent := spendingByCategory[*costCategory.CostCategoryID]
+		categoryBurnRate, categoryProjectedTotal := forecastSpending(spent, runningByCategory[*costCategory.CostCategoryID], elapsedDays, remainingDays)
+
+		categoryForecast := &models.CostCategoryForecastDTO{
+			CostCategoryId:     costCategory.CostCategoryID,
+			Name:               costCategory.Name,
+			Spent:              spent.String(),
+			DailyBurnRate:      categoryBurnRate.String(),
+			ProjectedTotalCost: categoryProjectedTotal.String(),
+		}
+
+		if costCategory.Budget != nil {
+			categoryForecast.Budget = costCategory.Budget.String()
+		}
+
+		response.CostCategories = append(response.CostCategories, categoryForecast)
+	}
+
+	return response, nil
+}
+
+// forecastSpending returns the average spending per elapsed day and the projected spending at the end of the trip.
+// The burn rate is based on the running costs of the elapsed days only, everything else that was spent is added once.
+// Before the trip has started there is no burn rate, so the projection equals the amount spent in advance
+func forecastSpending(spent decimal.Decimal, running decimal.Decimal, elapsedDays int, remainingDays int) (decimal.Decimal, decimal.Decimal) {
+	if elapsedDays <= 0 {
+		return decimal.Zero, spent
+	}
+
+	burnRate := running.Div(decimal.NewFromInt(int64(elapsedDays))).Round(2)
+	projected := spent.Add(burnRate.Mul(decimal.NewFromInt(int64(remainingDays))))
+
+	return burnRate, projected
+}
+
+// isWithinDays checks if the day lies between from and to, both inclusive
+func isWithinDays(day time.Time, from time.Time, to time.Time) bool {
+	return !day.Before(from) && !day.After(to)
+}
+
+func truncateToDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func daysBetween(from time.Time, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// validateTripPermission checks if the user of the request accepted the trip invite and holds a role granting the permission.
+// The participant data of the user is returned so callers can decide on ownership of single entries
+func validateTripPermission(ctx context.Context, tripRepo repositories.TripRepo, tripId *uuid.UUID, permission string) (*models.UserTripSchema, *models.ExpenseServiceError) {
+	userId, ok := ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID)
+	if !ok {
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	participant, repoErr := tripRepo.GetTripParticipant(ctx, tripId, userId)
+	if repoErr != nil {
+		if repoErr == expense_errors.EXPENSE_NOT_FOUND {
+			return nil, expense_errors.EXPENSE_FORBIDDEN
+		}
+		return nil, repoErr
+	}
+
+	if !participant.HasAccepted || !utils.TripRoleHasPermission(participant.Role, permission) {
+		return nil, expense_errors.EXPENSE_FORBIDDEN
+	}
+
+	return participant, nil
+}
+
+// addParticipantDebtsTx creates zero debts in both directions between a new participant and the already accepted participants.
+// Debts from an earlier membership of the participant are kept as they are
+func addParticipantDebtsTx(ctx context.Context, tx pgx.Tx, debtRepo repositories.DebtRepo, tripId *uuid.UUID, userId *uuid.UUID, participants []*models.UserTripSchema) *models.ExpenseServiceError {
+	for _, participant := range participants {
+		if *participant.UserID == *userId {
+			continue
+		}
+
+		if err := debtRepo.AddMissingDebtsTx(ctx, tx, tripId, participant.UserID, userId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getRedeemableTripInvite looks up the invite of a signed token and checks that it can still be used by the given email
+func getRedeemableTripInvite(ctx context.Context, tripInviteRepo repositories.TripInviteRepo, token string, email string) (*models.TripInviteSchema, *models.ExpenseServiceError) {
+	// Reject tampered tokens before hitting the database
+	if !utils.VerifySignedToken(token) {
+		return nil, expense_errors.EXPENSE_INVALID_INVITE_TOKEN
+	}
+
+	invite, repoErr := tripInviteRepo.GetTripInviteByToken(ctx, token)
+	if repoErr != nil {
+		if repoErr == expense_errors.EXPENSE_NOT_FOUND {
+			return nil, expense_errors.EXPENSE_INVALID_INVITE_TOKEN
+		}
+		return nil, repoErr
+	}
+
+	if invite.AcceptDate != nil || invite.RevokeDate != nil || invite.ExpiryDate.Before(time.Now()) {
+		return nil, expense_errors.EXPENSE_INVALID_INVITE_TOKEN
+	}
+
+	// Email invitations can only be used by the invited address
+	if invite.Email != nil && !strings.EqualFold(*invite.Email, email) {
+		return nil, expense_errors.EXPENSE_FORBIDDEN
+	}
+
+	return invite, nil
+}
+
+// publishMemberJoined publishes that a member joined the trip. Guests are added by another participant, everyone else
+// joins on their own
+func publishMemberJoined(ctx context.Context, eventMgr managers.EventMgr, tripId *uuid.UUID, userId *uuid.UUID, member *models.UserSchema) {
+	eventMgr.Publish(ctx, &models.TripEvent{
+		Type:      models.TripEventMemberJoined,
+		TripID:    tripId,
+		UserID:    userId,
+		SubjectID: member.UserID,
+		Details:   &models.TripEventDetails{Name: member.Username},
+	})
+}
+
+// joinTripWithInviteTx adds the user as accepted participant with the role of the invite. If the invite names a guest,
+// the user takes over the guest instead. Email and guest invites can only be used once
+func joinTripWithInviteTx(ctx context.Context, tx pgx.Tx, tripRepo repositories.TripRepo, userRepo repositories.UserRepo, debtRepo repositories.DebtRepo, tripInviteRepo repositories.TripInviteRepo, invite *models.TripInviteSchema, userId *uuid.UUID) (*models.TripSchema, *models.ExpenseServiceError) {
+	trip, repoErr := tripRepo.GetTripById(ctx, invite.TripID)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	if invite.GuestID != nil {
+		if repoErr := claimGuestTx(ctx, tx, tripRepo, userRepo, trip.TripID, invite.GuestID, userId, invite.Role); repoErr != nil {
+			return nil, repoErr
+		}
+	} else {
+		// Get accepted participants before the user joins
+		acceptedTripParticipants, repoErr := tripRepo.GetAcceptedTripParticipants(ctx, trip.TripID)
+		if repoErr != nil {
+			return nil, repoErr
+		}
+
+		participant := &models.UserTripSchema{
+			UserID:            userId,
+			TripID:            trip.TripID,
+			HasAccepted:       true,
+			Role:              invite.Role,
+			PresenceStartDate: trip.StartDate,
+			PresenceEndDate:   trip.EndDate,
+		}
+
+		if repoErr := tripRepo.AddTripParticipantTx(ctx, tx, participant); repoErr != nil {
+			return nil, repoErr
+		}
+
+		if repoErr := addParticipantDebtsTx(ctx, tx, debtRepo, trip.TripID, userId, acceptedTripParticipants); repoErr != nil {
+			return nil, repoErr
+		}
+	}
+
+	// Shareable links stay valid until they expire or are revoked
+	if invite.Email != nil || invite.GuestID != nil {
+		acceptDate := time.Now()
+		if repoErr := tripInviteRepo.AcceptTripInviteTx(ctx, tx, invite.TripInviteID, &acceptDate); repoErr != nil {
+			return nil, repoErr
+		}
+	}
+
+	return trip, nil
+}
+
+// claimGuestTx moves the participation, costs, debts and transactions of a guest over to the user and removes the guest
+func claimGuestTx(ctx context.Context, tx pgx.Tx, tripRepo repositories.TripRepo, userRepo repositories.UserRepo, tripId *uuid.UUID, guestId *uuid.UUID, userId *uuid.UUID, role string) *models.ExpenseServiceError {
+	// Merging the debts of two participants is not supported, so the user must not be part of the trip yet
+	if _, repoErr := tripRepo.GetTripParticipant(ctx, tripId, userId); repoErr == nil {
+		return expense_errors.EXPENSE_CONFLICT
+	} else if repoErr != expense_errors.EXPENSE_NOT_FOUND {
+		return repoErr
+	}
+
+	guest, repoErr := userRepo.GetUserById(ctx, guestId)
+	if repoErr != nil {
+		return repoErr
+	}
+
+	if !guest.IsGuest {
+		return expense_errors.EXPENSE_CONFLICT
+	}
+
+	if repoErr := userRepo.TransferGuestUserTx(ctx, tx, guestId, userId); repoErr != nil {
+		return repoErr
+	}
+
+	return tripRepo.UpdateTripParticipantRoleTx(ctx, tx, tripId, userId, role)
+}
+
+func mapTripInviteToResponse(invite *models.TripInviteSchema, inviterName string) *models.TripInviteDTO {
+	response := &models.TripInviteDTO{
+		TripInviteID: invite.TripInviteID,
+		TripID:       invite.TripID,
+		InvitedBy:    inviterName,
+		GuestID:      invite.GuestID,
+		Role:         invite.Role,
+		Link:         fmt.Sprintf(tripInviteLinkFormat, invite.Token),
+		Token:        invite.Token,
+		CreatedAt:    invite.CreationDate.String(),
+		ExpiresAt:    invite.ExpiryDate.String(),
+	}
+
+	if invite.Email != nil {
+		response.Email = *invite.Email
+	}
+
+	return response
 }
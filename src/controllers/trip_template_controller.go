@@ -0,0 +1,497 @@
+package controllers
+
+import (
+	"context"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/expense_errors"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/managers"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/repositories"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/utils"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"log"
+	"time"
+)
+
+// TripTemplateCtl Exposed interface to the handler-package
+type TripTemplateCtl interface {
+	CloneTrip(ctx context.Context, tripId *uuid.UUID, cloneRequest models.TripCloneRequest) (*models.TripDTO, *models.ExpenseServiceError)
+	CreateTripTemplate(ctx context.Context, tripId *uuid.UUID, templateRequest models.TripTemplateRequest) (*models.TripTemplateDTO, *models.ExpenseServiceError)
+	GetTripTemplates(ctx context.Context) ([]*models.TripTemplateDTO, *models.ExpenseServiceError)
+	DeleteTripTemplate(ctx context.Context, tripTemplateId *uuid.UUID) *models.ExpenseServiceError
+	CreateTripFromTemplate(ctx context.Context, tripTemplateId *uuid.UUID, cloneRequest models.TripCloneRequest) (*models.TripDTO, *models.ExpenseServiceError)
+}
+
+// TripTemplateController Trip Template Controller structure
+type TripTemplateController struct {
+	DatabaseMgr      managers.DatabaseMgr
+	TripTemplateRepo repositories.TripTemplateRepo
+	TripRepo         repositories.TripRepo
+	UserRepo         repositories.UserRepo
+	CostCategoryRepo repositories.CostCategoryRepo
+	TripController   *TripController // Sends the invitations and builds the trip responses
+}
+
+// CloneTrip creates a new trip with the metadata and cost categories of an existing trip. Costs are never copied
+func (ttc *TripTemplateController) CloneTrip(ctx context.Context, tripId *uuid.UUID, cloneRequest models.TripCloneRequest) (*models.TripDTO, *models.ExpenseServiceError) {
+	if _, serviceErr := validateTripPermission(ctx, ttc.TripRepo, tripId, models.TripPermissionEditTrip); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	trip, repoErr := ttc.TripRepo.GetTripById(ctx, tripId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	template := &models.TripTemplateSchema{
+		Name:              trip.Name,
+		Description:       trip.Description,
+		Location:          trip.Location,
+		Budget:            trip.Budget,
+		ApprovalThreshold: trip.ApprovalThreshold,
+	}
+
+	costCategories, serviceErr := ttc.getTripCostCategories(ctx, tripId)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	var participants []*models.TripTemplateParticipantSchema
+	if cloneRequest.IncludeParticipants {
+		if participants, serviceErr = ttc.getTripParticipants(ctx, tripId); serviceErr != nil {
+			return nil, serviceErr
+		}
+	}
+
+	return ttc.createTripFromBlueprint(ctx, template, costCategories, participants, cloneRequest)
+}
+
+// CreateTripTemplate saves the metadata, cost categories and optionally the participants of a trip as template of the current user
+func (ttc *TripTemplateController) CreateTripTemplate(ctx context.Context, tripId *uuid.UUID, templateRequest models.TripTemplateRequest) (*models.TripTemplateDTO, *models.ExpenseServiceError) {
+	participant, serviceErr := validateTripPermission(ctx, ttc.TripRepo, tripId, models.TripPermissionEditTrip)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	trip, repoErr := ttc.TripRepo.GetTripById(ctx, tripId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	templateId := uuid.New()
+	now := time.Now()
+	template := &models.TripTemplateSchema{
+		TripTemplateID:    &templateId,
+		UserID:            participant.UserID,
+		Name:              trip.Name,
+		Description:       trip.Description,
+		Location:          trip.Location,
+		Budget:            trip.Budget,
+		ApprovalThreshold: trip.ApprovalThreshold,
+		CreationDate:      &now,
+	}
+
+	if templateRequest.Name != "" {
+		template.Name = templateRequest.Name
+	}
+
+	costCategories, serviceErr := ttc.getTripCostCategories(ctx, tripId)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	participants := make([]*models.TripTemplateParticipantSchema, 0)
+	if templateRequest.IncludeParticipants {
+		if participants, serviceErr = ttc.getTripParticipants(ctx, tripId); serviceErr != nil {
+			return nil, serviceErr
+		}
+	}
+
+	// Begin transaction
+	tx, err := ttc.DatabaseMgr.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Error while beginning transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// Make sure to rollback the transaction if it fails
+	defer func(tx pgx.Tx) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			log.Printf("Error while rolling back transaction: %v", err)
+		}
+	}(tx)
+
+	if repoErr := ttc.TripTemplateRepo.AddTripTemplateTx(ctx, tx, template); repoErr != nil {
+		return nil, repoErr
+	}
+
+	for _, costCategory := range costCategories {
+		costCategoryId := uuid.New()
+		costCategory.TripTemplateCostCategoryID = &costCategoryId
+		costCategory.TripTemplateID = &templateId
+		if repoErr := ttc.TripTemplateRepo.AddTripTemplateCostCategoryTx(ctx, tx, costCategory); repoErr != nil {
+			return nil, repoErr
+		}
+	}
+
+	for _, templateParticipant := range participants {
+		templateParticipant.TripTemplateID = &templateId
+		if repoErr := ttc.TripTemplateRepo.AddTripTemplateParticipantTx(ctx, tx, templateParticipant); repoErr != nil {
+			return nil, repoErr
+		}
+	}
+
+	// If everything went well, commit the transaction
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error while committing transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return ttc.mapTripTemplateToResponse(ctx, template, costCategories, participants)
+}
+
+// GetTripTemplates returns the templates saved by the current user
+func (ttc *TripTemplateController) GetTripTemplates(ctx context.Context) ([]*models.TripTemplateDTO, *models.ExpenseServiceError) {
+	templates, repoErr := ttc.TripTemplateRepo.GetTripTemplatesByUserId(ctx, ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID))
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	responses := make([]*models.TripTemplateDTO, 0, len(templates))
+	for _, template := range templates {
+		costCategories, participants, repoErr := ttc.getTripTemplateContent(ctx, template.TripTemplateID)
+		if repoErr != nil {
+			return nil, repoErr
+		}
+
+		response, serviceErr := ttc.mapTripTemplateToResponse(ctx, template, costCategories, participants)
+		if serviceErr != nil {
+			return nil, serviceErr
+		}
+		responses = append(responses, response)
+	}
+
+	return responses, nil
+}
+
+// DeleteTripTemplate deletes a template. Templates of other users are treated as not existing
+func (ttc *TripTemplateController) DeleteTripTemplate(ctx context.Context, tripTemplateId *uuid.UUID) *models.ExpenseServiceError {
+	if _, serviceErr := ttc.getOwnTripTemplate(ctx, tripTemplateId); serviceErr != nil {
+		return serviceErr
+	}
+
+	return ttc.TripTemplateRepo.DeleteTripTemplate(ctx, tripTemplateId)
+}
+
+// CreateTripFromTemplate creates a new trip from a template of the current user
+func (ttc *TripTemplateController) CreateTripFromTemplate(ctx context.Context, tripTemplateId *uuid.UUID, cloneRequest models.TripCloneRequest) (*models.TripDTO, *models.ExpenseServiceError) {
+	template, serviceErr := ttc.getOwnTripTemplate(ctx, tripTemplateId)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	costCategories, participants, repoErr := ttc.getTripTemplateContent(ctx, tripTemplateId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	if !cloneRequest.IncludeParticipants {
+		participants = nil
+	}
+
+	return ttc.createTripFromBlueprint(ctx, template, costCategories, participants, cloneRequest)
+}
+
+// createTripFromBlueprint creates a trip owned by the current user with the given metadata and cost categories.
+// The participants are invited again, their previous acceptance is not carried over
+func (ttc *TripTemplateController) createTripFromBlueprint(ctx context.Context, template *models.TripTemplateSchema, costCategories []*models.TripTemplateCostCategorySchema, participants []*models.TripTemplateParticipantSchema, cloneRequest models.TripCloneRequest) (*models.TripDTO, *models.ExpenseServiceError) {
+	userId, ok := ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID)
+	if !ok {
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	startDate, err := time.Parse(time.DateOnly, cloneRequest.StartDate)
+	if err != nil {
+		return nil, expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	endDate, err := time.Parse(time.DateOnly, cloneRequest.EndDate)
+	if err != nil || endDate.Before(startDate) {
+		return nil, expense_errors.EXPENSE_BAD_REQUEST
+	}
+
+	tripId := uuid.New()
+	trip := &models.TripSchema{
+		TripID:            &tripId,
+		Name:              template.Name,
+		Description:       template.Description,
+		Location:          template.Location,
+		StartDate:         &startDate,
+		EndDate:           &endDate,
+		Budget:            template.Budget,
+		ApprovalThreshold: template.ApprovalThreshold,
+		Status:            models.TripStatusActive,
+	}
+
+	if cloneRequest.Name != "" {
+		trip.Name = cloneRequest.Name
+	}
+
+	if cloneRequest.Description != "" {
+		trip.Description = cloneRequest.Description
+	}
+
+	if cloneRequest.Location != "" {
+		trip.Location = cloneRequest.Location
+	}
+
+	invitationDate := time.Now()
+	expiryDate := invitationDate.Add(utils.GetDurationFromEnv("TRIP_INVITE_EXPIRY", defaultTripInviteExpiry))
+
+	// Begin transaction
+	tx, err := ttc.DatabaseMgr.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Error while beginning transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// Make sure to rollback the transaction if it fails
+	defer func(tx pgx.Tx) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			log.Printf("Error while rolling back transaction: %v", err)
+		}
+	}(tx)
+
+	if repoErr := ttc.TripRepo.CreateTripTx(ctx, tx, trip); repoErr != nil {
+		return nil, repoErr
+	}
+
+	owner := &models.UserTripSchema{
+		UserID:            userId,
+		TripID:            &tripId,
+		HasAccepted:       true,
+		Role:              models.TripRoleOwner,
+		PresenceStartDate: &startDate,
+		PresenceEndDate:   &endDate,
+	}
+
+	if repoErr := ttc.TripRepo.AddTripParticipantTx(ctx, tx, owner); repoErr != nil {
+		return nil, repoErr
+	}
+
+	// Top-level categories are created first so that subcategories can reference them by name
+	createdCategoryIds := make(map[string]*uuid.UUID)
+	for _, isSubcategory := range []bool{false, true} {
+		for _, templateCategory := range costCategories {
+			if (templateCategory.ParentName != nil) != isSubcategory {
+				continue
+			}
+
+			costCategoryId := uuid.New()
+			costCategory := &models.CostCategorySchema{
+				CostCategoryID:        &costCategoryId,
+				Name:                  templateCategory.Name,
+				Description:           templateCategory.Description,
+				Icon:                  templateCategory.Icon,
+				Color:                 templateCategory.Color,
+				TripID:                &tripId,
+				Budget:                templateCategory.Budget,
+				AlertThresholdPercent: templateCategory.AlertThresholdPercent,
+			}
+			if isSubcategory {
+				costCategory.ParentID = createdCategoryIds[*templateCategory.ParentName]
+			}
+
+			if repoErr := ttc.CostCategoryRepo.CreateCostCategoryTx(ctx, tx, costCategory); repoErr != nil {
+				return nil, repoErr
+			}
+			createdCategoryIds[costCategory.Name] = &costCategoryId
+		}
+	}
+
+	invitations := make([]*models.UserTripSchema, 0, len(participants))
+	for _, participant := range participants {
+		if *participant.UserID == *userId {
+			continue
+		}
+
+		// There is only one owner per trip, the previous owner is invited as admin
+		role := participant.Role
+		if role == models.TripRoleOwner {
+			role = models.TripRoleAdmin
+		}
+
+		invitation := &models.UserTripSchema{
+			UserID:               participant.UserID,
+			TripID:               &tripId,
+			HasAccepted:          false,
+			Role:                 role,
+			PresenceStartDate:    &startDate,
+			PresenceEndDate:      &endDate,
+			InvitedBy:            userId,
+			InvitationDate:       &invitationDate,
+			InvitationExpiryDate: &expiryDate,
+		}
+
+		if repoErr := ttc.TripRepo.AddTripParticipantTx(ctx, tx, invitation); repoErr != nil {
+			return nil, repoErr
+		}
+		invitations = append(invitations, invitation)
+	}
+
+	// If everything went well, commit the transaction
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error while committing transaction: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// The invitations stay valid even if a mail could not be sent
+	for _, invitation := range invitations {
+		if mailErr := ttc.TripController.sendTripInvitationMail(ctx, trip, invitation, false); mailErr != nil {
+			log.Printf("Error while sending trip invitation mail to %v: %v", invitation.UserID, mailErr)
+		}
+	}
+
+	return ttc.TripController.mapTripToResponse(ctx, trip)
+}
+
+// getTripCostCategories converts the cost categories of a trip to template cost categories
+func (ttc *TripTemplateController) getTripCostCategories(ctx context.Context, tripId *uuid.UUID) ([]*models.TripTemplateCostCategorySchema, *models.ExpenseServiceError) {
+	costCategories, repoErr := ttc.CostCategoryRepo.GetCostCategoriesByTripID(ctx, tripId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	// Parents are stored by name since the ids of the categories are not kept
+	categoryNames := make(map[uuid.UUID]string, len(costCategories))
+	for _, costCategory := range costCategories {
+		categoryNames[*costCategory.CostCategoryID] = costCategory.Name
+	}
+
+	templateCategories := make([]*models.TripTemplateCostCategorySchema, len(costCategories))
+	for i, costCategory := range costCategories {
+		templateCategories[i] = &models.TripTemplateCostCategorySchema{
+			Name:                  costCategory.Name,
+			Description:           costCategory.Description,
+			Icon:                  costCategory.Icon,
+			Color:                 costCategory.Color,
+			Budget:                costCategory.Budget,
+			AlertThresholdPercent: costCategory.AlertThresholdPercent,
+		}
+
+		if costCategory.ParentID != nil {
+			if parentName, ok := categoryNames[*costCategory.ParentID]; ok {
+				templateCategories[i].ParentName = &parentName
+			}
+		}
+	}
+
+	return templateCategories, nil
+}
+
+// getTripParticipants returns the accepted participants of a trip. Guests are left out since they cannot accept an invitation
+func (ttc *TripTemplateController) getTripParticipants(ctx context.Context, tripId *uuid.UUID) ([]*models.TripTemplateParticipantSchema, *models.ExpenseServiceError) {
+	participants, repoErr := ttc.TripRepo.GetAcceptedTripParticipants(ctx, tripId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	templateParticipants := make([]*models.TripTemplateParticipantSchema, 0, len(participants))
+	for _, participant := range participants {
+		user, repoErr := ttc.UserRepo.GetUserById(ctx, participant.UserID)
+		if repoErr != nil {
+			return nil, repoErr
+		}
+
+		if user.IsGuest {
+			continue
+		}
+
+		templateParticipants = append(templateParticipants, &models.TripTemplateParticipantSchema{
+			UserID: participant.UserID,
+			Role:   participant.Role,
+		})
+	}
+
+	return templateParticipants, nil
+}
+
+// getOwnTripTemplate returns the template if it belongs to the current user
+func (ttc *TripTemplateController) getOwnTripTemplate(ctx context.Context, tripTemplateId *uuid.UUID) (*models.TripTemplateSchema, *models.ExpenseServiceError) {
+	template, repoErr := ttc.TripTemplateRepo.GetTripTemplateByID(ctx, tripTemplateId)
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	if *template.UserID != *ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID) {
+		return nil, expense_errors.EXPENSE_NOT_FOUND
+	}
+
+	return template, nil
+}
+
+func (ttc *TripTemplateController) getTripTemplateContent(ctx context.Context, tripTemplateId *uuid.UUID) ([]*models.TripTemplateCostCategorySchema, []*models.TripTemplateParticipantSchema, *models.ExpenseServiceError) {
+	costCategories, repoErr := ttc.TripTemplateRepo.GetTripTemplateCostCategories(ctx, tripTemplateId)
+	if repoErr != nil {
+		return nil, nil, repoErr
+	}
+
+	participants, repoErr := ttc.TripTemplateRepo.GetTripTemplateParticipants(ctx, tripTemplateId)
+	if repoErr != nil {
+		return nil, nil, repoErr
+	}
+
+	return costCategories, participants, nil
+}
+
+func (ttc *TripTemplateController) mapTripTemplateToResponse(ctx context.Context, template *models.TripTemplateSchema, costCategories []*models.TripTemplateCostCategorySchema, participants []*models.TripTemplateParticipantSchema) (*models.TripTemplateDTO, *models.ExpenseServiceError) {
+	response := &models.TripTemplateDTO{
+		TripTemplateID: template.TripTemplateID,
+		Name:           template.Name,
+		Description:    template.Description,
+		Location:       template.Location,
+		CreatedAt:      template.CreationDate.Format(time.RFC3339),
+		CostCategories: make([]*models.TripTemplateCostCategoryDTO, len(costCategories)),
+		Participants:   make([]*models.TripTemplateParticipantDTO, len(participants)),
+	}
+
+	if template.Budget != nil {
+		response.Budget = template.Budget.String()
+	}
+
+	if template.ApprovalThreshold != nil {
+		response.ApprovalThreshold = template.ApprovalThreshold.String()
+	}
+
+	for i, costCategory := range costCategories {
+		response.CostCategories[i] = &models.TripTemplateCostCategoryDTO{
+			Name:                  costCategory.Name,
+			Description:           costCategory.Description,
+			Icon:                  costCategory.Icon,
+			Color:                 costCategory.Color,
+			AlertThresholdPercent: costCategory.AlertThresholdPercent,
+			ParentName:            costCategory.ParentName,
+		}
+
+		if costCategory.Budget != nil {
+			response.CostCategories[i].Budget = costCategory.Budget.String()
+		}
+	}
+
+	for i, participant := range participants {
+		user, repoErr := ttc.UserRepo.GetUserById(ctx, participant.UserID)
+		if repoErr != nil {
+			return nil, repoErr
+		}
+
+		response.Participants[i] = &models.TripTemplateParticipantDTO{
+			UserID:   participant.UserID,
+			Username: user.Username,
+			Role:     participant.Role,
+		}
+	}
+
+	return response, nil
+}
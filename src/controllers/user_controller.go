@@ -9,6 +9,7 @@ import (
 	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/repositories"
 	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/utils"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"log"
 	"mime/multipart"
 	"time"
@@ -28,6 +29,7 @@ type UserCtl interface {
 
 	DeleteUser(ctx context.Context) *models.ExpenseServiceError
 	GetUserDetails(ctx context.Context) (*models.UserDetailsResponse, *models.ExpenseServiceError)
+	GetPendingTripInvites(ctx context.Context) ([]*models.PendingTripInviteDTO, *models.ExpenseServiceError)
 	SuggestUsers(ctx context.Context, query string) (*[]models.UserSuggestion, *models.ExpenseServiceError)
 	CheckEmail(ctx context.Context, email string) *models.ExpenseServiceError
 	CheckUsername(ctx context.Context, username string) *models.ExpenseServiceError
@@ -35,10 +37,14 @@ type UserCtl interface {
 
 // UserController User Controller structure
 type UserController struct {
-	MailMgr     managers.MailMgr
-	DatabaseMgr managers.DatabaseMgr
-	ImageMgr    managers.ImageMgr
-	UserRepo    repositories.UserRepo
+	MailMgr        managers.MailMgr
+	DatabaseMgr    managers.DatabaseMgr
+	EventMgr       managers.EventMgr
+	ImageMgr       managers.ImageMgr
+	UserRepo       repositories.UserRepo
+	TripRepo       repositories.TripRepo
+	DebtRepo       repositories.DebtRepo
+	TripInviteRepo repositories.TripInviteRepo
 }
 
 const activationMailSubject = "Welcome to Costventures!"
@@ -54,6 +60,16 @@ const (
 
 // RegisterUser creates a new user entry in the database
 func (uc *UserController) RegisterUser(ctx context.Context, registrationData models.RegistrationRequest, form *multipart.Form) *models.ExpenseServiceError {
+	// Validate the trip invite before creating the user, registering through an invite link joins the trip
+	var invite *models.TripInviteSchema
+	if registrationData.InviteToken != "" {
+		var serviceErr *models.ExpenseServiceError
+		invite, serviceErr = getRedeemableTripInvite(ctx, uc.TripInviteRepo, registrationData.InviteToken, registrationData.Email)
+		if serviceErr != nil {
+			return serviceErr
+		}
+	}
+
 	// Create user object
 	userId := uuid.New()
 	hashedPassword, err := utils.HashPassword(registrationData.Password)
@@ -103,6 +119,14 @@ func (uc *UserController) RegisterUser(ctx context.Context, registrationData mod
 		return repoErr
 	}
 
+	// The account exists from here on, so a failed join must not fail the registration. The invite stays
+	// redeemable and the user can still join the trip with it after logging in
+	if invite != nil {
+		if serviceErr := uc.joinTripWithInvite(ctx, invite, user); serviceErr != nil {
+			log.Printf("Error while joining trip %s with invite of user %s: %v", invite.TripID, user.UserID, serviceErr)
+		}
+	}
+
 	// Insert token into database
 	token, repoErr := uc.UserRepo.CreateTokenByUserIdAndType(ctx, user.UserID, activationToken)
 	if repoErr != nil {
@@ -119,6 +143,39 @@ func (uc *UserController) RegisterUser(ctx context.Context, registrationData mod
 	return uc.MailMgr.SendActivationMail(ctx, *activationMail)
 }
 
+// joinTripWithInvite adds a newly registered user to the trip of the invite they registered with
+func (uc *UserController) joinTripWithInvite(ctx context.Context, invite *models.TripInviteSchema, user *models.UserSchema) *models.ExpenseServiceError {
+	// Begin transaction
+	tx, err := uc.DatabaseMgr.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Error while beginning transaction: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	// Make sure to rollback the transaction if it fails
+	defer func(tx pgx.Tx) {
+		err := tx.Rollback(ctx)
+		if err != nil {
+			log.Printf("Error while rolling back transaction: %v", err)
+		}
+	}(tx)
+
+	trip, repoErr := joinTripWithInviteTx(ctx, tx, uc.TripRepo, uc.UserRepo, uc.DebtRepo, uc.TripInviteRepo, invite, user.UserID)
+	if repoErr != nil {
+		return repoErr
+	}
+
+	// If everything went well, commit the transaction
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Error while committing transaction: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	publishMemberJoined(ctx, uc.EventMgr, trip.TripID, user.UserID, user)
+
+	return nil
+}
+
 // LoginUser checks if the user exists and if the password is correct
 func (uc *UserController) LoginUser(ctx context.Context, loginData models.LoginRequest) (*models.LoginResponse, *models.ExpenseServiceError) {
 	user := &models.UserSchema{
@@ -414,6 +471,50 @@ func (uc *UserController) GetUserDetails(ctx context.Context) (*models.UserDetai
 	return buildUserResponse(user), nil
 }
 
+// GetPendingTripInvites returns the trip invitations of the user that were neither accepted nor have expired
+func (uc *UserController) GetPendingTripInvites(ctx context.Context) ([]*models.PendingTripInviteDTO, *models.ExpenseServiceError) {
+	invitations, repoErr := uc.TripRepo.GetPendingTripInvitesByUserId(ctx, ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID))
+	if repoErr != nil {
+		return nil, repoErr
+	}
+
+	response := make([]*models.PendingTripInviteDTO, len(invitations))
+	for i, invitation := range invitations {
+		trip, repoErr := uc.TripRepo.GetTripById(ctx, invitation.TripID)
+		if repoErr != nil {
+			return nil, repoErr
+		}
+
+		response[i] = &models.PendingTripInviteDTO{
+			Trip: models.SlimTripDTO{
+				TripID:      trip.TripID,
+				Name:        trip.Name,
+				Description: trip.Description,
+				Location:    trip.Location,
+				StartDate:   trip.StartDate.Format(time.DateOnly),
+				EndDate:     trip.EndDate.Format(time.DateOnly),
+			},
+			Role: invitation.Role,
+		}
+
+		if invitation.InvitedBy != nil {
+			if inviter, repoErr := uc.UserRepo.GetUserById(ctx, invitation.InvitedBy); repoErr == nil {
+				response[i].InvitedBy = inviter.Username
+			}
+		}
+
+		if invitation.InvitationDate != nil {
+			response[i].InvitedAt = invitation.InvitationDate.String()
+		}
+
+		if invitation.InvitationExpiryDate != nil {
+			response[i].ExpiresAt = invitation.InvitationExpiryDate.String()
+		}
+	}
+
+	return response, nil
+}
+
 func (uc *UserController) SuggestUsers(ctx context.Context, query string) (*[]models.UserSuggestion, *models.ExpenseServiceError) {
 	// Find users like query
 	users, repoErr := uc.UserRepo.FindUsersLikeUsername(ctx, query)
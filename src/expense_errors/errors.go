@@ -43,4 +43,20 @@ var (
 	EXPENSE_USERNAME_EXISTS = &models.ExpenseServiceError{ErrorMessage: "USERNAME_EXISTS", ErrorCode: "EM-018", Status: 409}
 	// EXPENSE_INVALID_ACTIVATION_TOKEN is used to indicate that the activation token is invalid
 	EXPENSE_INVALID_ACTIVATION_TOKEN = &models.ExpenseServiceError{ErrorMessage: "INVALID_ACTIVATION_TOKEN", ErrorCode: "EM-019", Status: 400}
+	// EXPENSE_INVALID_BANK_STATEMENT is used to indicate that the uploaded bank statement could not be parsed
+	EXPENSE_INVALID_BANK_STATEMENT = &models.ExpenseServiceError{ErrorMessage: "INVALID_BANK_STATEMENT", ErrorCode: "EM-020", Status: 400}
+	// EXPENSE_KITTY_SETTLED is used to indicate that the kitty of a trip was already settled and cannot be changed anymore
+	EXPENSE_KITTY_SETTLED = &models.ExpenseServiceError{ErrorMessage: "KITTY_SETTLED", ErrorCode: "EM-021", Status: 409}
+	// EXPENSE_INVALID_INVITE_TOKEN is used to indicate that a trip invite token is invalid, expired, revoked or already used
+	EXPENSE_INVALID_INVITE_TOKEN = &models.ExpenseServiceError{ErrorMessage: "INVALID_INVITE_TOKEN", ErrorCode: "EM-022", Status: 400}
+	// EXPENSE_INVITE_EXPIRED is used to indicate that a trip invitation was not accepted in time
+	EXPENSE_INVITE_EXPIRED = &models.ExpenseServiceError{ErrorMessage: "INVITE_EXPIRED", ErrorCode: "EM-023", Status: 410}
+	// EXPENSE_OPEN_BALANCE is used to indicate that a participant still has non-zero debts or costs awaiting their approval and has to settle first
+	EXPENSE_OPEN_BALANCE = &models.ExpenseServiceError{ErrorMessage: "OPEN_BALANCE", ErrorCode: "EM-024", Status: 409}
+	// EXPENSE_TRIP_LOCKED is used to indicate that the finances of a trip cannot be changed in its current status
+	EXPENSE_TRIP_LOCKED = &models.ExpenseServiceError{ErrorMessage: "TRIP_LOCKED", ErrorCode: "EM-025", Status: 409}
+	// EXPENSE_COST_CATEGORY_NOT_EMPTY is used to indicate that a cost category still has costs and cannot be deleted without moving them
+	EXPENSE_COST_CATEGORY_NOT_EMPTY = &models.ExpenseServiceError{ErrorMessage: "COST_CATEGORY_NOT_EMPTY", ErrorCode: "EM-026", Status: 409}
+	// EXPENSE_NO_MATCHING_COST_CATEGORY_RULE is used to indicate that a cost without a cost category matches none of the rules of the trip
+	EXPENSE_NO_MATCHING_COST_CATEGORY_RULE = &models.ExpenseServiceError{ErrorMessage: "NO_MATCHING_COST_CATEGORY_RULE", ErrorCode: "EM-027", Status: 400}
 )
@@ -18,8 +18,8 @@ func GetCostOverviewHandler(costCtl controllers.CostCtl) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx := c.Request.Context()
 
-		// Get cost overview
-		response, serviceErr := costCtl.GetCostOverview(ctx)
+		// Get cost overview, grouped by cost category unless requested otherwise
+		response, serviceErr := costCtl.GetCostOverview(ctx, c.Query(models.ExpenseQueryKeyGroupBy))
 		if serviceErr != nil {
 			utils.HandleErrorAndAbort(c, *serviceErr)
 			return
@@ -48,8 +48,9 @@ func CreateCostEntryHandler(costCtl controllers.CostCtl) gin.HandlerFunc {
 			return
 		}
 
-		// Check if cost entry has empty fields
-		if utils.ContainsEmptyString(costData.Amount, costData.CurrencyCode, costData.Creditor, costData.CostCategoryID.String()) {
+		// Check if cost entry has empty fields, private costs are always paid by their creator and kitty costs have no creditor.
+		// The cost category is optional since the rules of the trip assign it otherwise
+		if utils.ContainsEmptyString(costData.Amount, costData.CurrencyCode) || (!costData.IsPrivate && !costData.PaidFromKitty && costData.Creditor == "") {
 			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
 			return
 		}
@@ -128,174 +129,281 @@ func GetCostEntriesHandler(costCtl controllers.CostCtl) gin.HandlerFunc {
 		// Get tripId from request params
 		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
 
-		// Create query params
-		queryParams := models.CostQueryParams{
-			TripId:           &tripId,
-			CostCategoryId:   nil,
-			CostCategoryName: nil,
-			UserId:           nil,
-			Username:         nil,
-			MinAmount:        nil,
-			MaxAmount:        nil,
-			MinDeductionDate: nil,
-			MaxDeductionDate: nil,
-			MinEndDate:       nil,
-			MaxEndDate:       nil,
-			MinCreationDate:  nil,
-			MaxCreationDate:  nil,
-			Page:             0,
-			PageSize:         0,
-			SortBy:           "deducted_at",
-			SortOrder:        "DESC",
-		}
-
-		// Get all query params from request
-		CostCategoryIdStr := c.Query("costCategoryId")
-		CostCategoryNameStr := c.Query("costCategoryName")
-		UserIdStr := c.Query("userId")
-		UsernameStr := c.Query("username")
-		MinAmountStr := c.Query("minAmount")
-		MaxAmountStr := c.Query("maxAmount")
-		MinDeductionDateStr := c.Query("minDeductionDate")
-		MaxDeductionDateStr := c.Query("maxDeductionDate")
-		MinEndDateStr := c.Query("minEndDate")
-		MaxEndDateStr := c.Query("maxEndDate")
-		MinCreationDateStr := c.Query("minCreationDate")
-		MaxCreationDateStr := c.Query("maxCreationDate")
-		PageStr := c.Query("page")
-		PageSizeStr := c.Query("pageSize")
-		SortByStr := c.Query("sortBy")
-		SortOrderStr := c.Query("sortOrder")
-
-		if CostCategoryIdStr != "" {
-			id, err := uuid.Parse(CostCategoryIdStr)
-			if err != nil {
-				utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
-				return
-			}
-			queryParams.CostCategoryId = &id
+		queryParams, serviceErr := parseCostQueryParams(c, &tripId)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
 		}
 
-		if CostCategoryNameStr != "" {
-			queryParams.CostCategoryName = &CostCategoryNameStr
+		// Pass query params to the controller
+		response, err := costCtl.GetCostEntries(ctx, queryParams)
+		if err != nil {
+			utils.HandleErrorAndAbort(c, *err)
+			return
 		}
 
-		if UserIdStr != "" {
-			userId, err := uuid.Parse(UserIdStr)
-			if err != nil {
-				utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
-				return
-			}
-			queryParams.UserId = &userId
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func GetCostDetailsHandler(costCtl controllers.CostCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Get costId from request params
+		costId, err := uuid.Parse(c.Param(models.ExpenseParamKeyCostId))
+		if err != nil {
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
 		}
 
-		if UsernameStr != "" {
-			queryParams.Username = &UsernameStr
+		response, serviceErr := costCtl.GetCostDetails(ctx, &costId)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
 		}
 
-		if MinAmountStr != "" {
-			_, err := strconv.ParseFloat(MinAmountStr, 64)
-			if err != nil {
-				utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
-				return
-			}
-			queryParams.MinAmount = &MinAmountStr
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func DeleteCostEntryHandler(costCtl controllers.CostCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Get tripId from request params
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+
+		// Get costId from request params
+		costId, err := uuid.Parse(c.Param(models.ExpenseParamKeyCostId))
+		if err != nil {
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
 		}
 
-		if MaxAmountStr != "" {
-			_, err := strconv.ParseFloat(MaxAmountStr, 64)
-			if err != nil {
-				utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
-				return
-			}
-			queryParams.MaxAmount = &MaxAmountStr
+		serviceErr := costCtl.DeleteCostEntry(ctx, &tripId, &costId)
+		if err != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
 		}
 
-		if MinDeductionDateStr != "" {
-			_, err := time.Parse(time.RFC3339, MinDeductionDateStr)
-			if err != nil {
-				utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
-				return
-			}
-			queryParams.MinDeductionDate = &MinDeductionDateStr
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+// parseCostQueryParams reads the filter, pagination and sorting query params of cost entry requests
+func parseCostQueryParams(c *gin.Context, tripId *uuid.UUID) (*models.CostQueryParams, *models.ExpenseServiceError) {
+	// Create query params
+	queryParams := models.CostQueryParams{
+		TripId:           tripId,
+		CostCategoryId:   nil,
+		CostCategoryName: nil,
+		UserId:           nil,
+		Username:         nil,
+		MinAmount:        nil,
+		MaxAmount:        nil,
+		MinDeductionDate: nil,
+		MaxDeductionDate: nil,
+		MinEndDate:       nil,
+		MaxEndDate:       nil,
+		MinCreationDate:  nil,
+		MaxCreationDate:  nil,
+		Page:             0,
+		PageSize:         0,
+		SortBy:           "deducted_at",
+		SortOrder:        "DESC",
+	}
+
+	// Get all query params from request
+	CostCategoryIdStr := c.Query("costCategoryId")
+	CostCategoryNameStr := c.Query("costCategoryName")
+	UserIdStr := c.Query("userId")
+	UsernameStr := c.Query("username")
+	MinAmountStr := c.Query("minAmount")
+	MaxAmountStr := c.Query("maxAmount")
+	MinDeductionDateStr := c.Query("minDeductionDate")
+	MaxDeductionDateStr := c.Query("maxDeductionDate")
+	MinEndDateStr := c.Query("minEndDate")
+	MaxEndDateStr := c.Query("maxEndDate")
+	MinCreationDateStr := c.Query("minCreationDate")
+	MaxCreationDateStr := c.Query("maxCreationDate")
+	TagsStr := c.Query("tags")
+	PageStr := c.Query("page")
+	PageSizeStr := c.Query("pageSize")
+	SortByStr := c.Query("sortBy")
+	SortOrderStr := c.Query("sortOrder")
+
+	if CostCategoryIdStr != "" {
+		id, err := uuid.Parse(CostCategoryIdStr)
+		if err != nil {
+			return nil, expense_errors.EXPENSE_BAD_REQUEST
 		}
+		queryParams.CostCategoryId = &id
+	}
 
-		if MaxDeductionDateStr != "" {
-			_, err := time.Parse(time.RFC3339, MaxDeductionDateStr)
-			if err != nil {
-				log.Printf("Error while parsing date: %v", err)
-				utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
-				return
-			}
-			queryParams.MaxDeductionDate = &MaxDeductionDateStr
+	if CostCategoryNameStr != "" {
+		queryParams.CostCategoryName = &CostCategoryNameStr
+	}
+
+	if UserIdStr != "" {
+		userId, err := uuid.Parse(UserIdStr)
+		if err != nil {
+			return nil, expense_errors.EXPENSE_BAD_REQUEST
 		}
+		queryParams.UserId = &userId
+	}
 
-		if MinEndDateStr != "" {
-			_, err := time.Parse(time.RFC3339, MinEndDateStr)
-			if err != nil {
-				utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
-				return
-			}
-			queryParams.MinEndDate = &MinEndDateStr
+	if UsernameStr != "" {
+		queryParams.Username = &UsernameStr
+	}
+
+	if MinAmountStr != "" {
+		_, err := strconv.ParseFloat(MinAmountStr, 64)
+		if err != nil {
+			return nil, expense_errors.EXPENSE_BAD_REQUEST
 		}
+		queryParams.MinAmount = &MinAmountStr
+	}
 
-		if MaxEndDateStr != "" {
-			_, err := time.Parse(time.RFC3339, MaxEndDateStr)
-			if err != nil {
-				utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
-				return
-			}
-			queryParams.MaxEndDate = &MaxEndDateStr
+	if MaxAmountStr != "" {
+		_, err := strconv.ParseFloat(MaxAmountStr, 64)
+		if err != nil {
+			return nil, expense_errors.EXPENSE_BAD_REQUEST
 		}
+		queryParams.MaxAmount = &MaxAmountStr
+	}
 
-		if MinCreationDateStr != "" {
-			_, err := time.Parse(time.RFC3339, MinCreationDateStr)
-			if err != nil {
-				utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
-				return
-			}
-			queryParams.MinCreationDate = &MinCreationDateStr
+	if MinDeductionDateStr != "" {
+		_, err := time.Parse(time.RFC3339, MinDeductionDateStr)
+		if err != nil {
+			return nil, expense_errors.EXPENSE_BAD_REQUEST
 		}
+		queryParams.MinDeductionDate = &MinDeductionDateStr
+	}
 
-		if MaxCreationDateStr != "" {
-			_, err := time.Parse(time.RFC3339, MaxCreationDateStr)
-			if err != nil {
-				utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
-				return
-			}
-			queryParams.MaxCreationDate = &MaxCreationDateStr
+	if MaxDeductionDateStr != "" {
+		_, err := time.Parse(time.RFC3339, MaxDeductionDateStr)
+		if err != nil {
+			log.Printf("Error while parsing date: %v", err)
+			return nil, expense_errors.EXPENSE_BAD_REQUEST
 		}
+		queryParams.MaxDeductionDate = &MaxDeductionDateStr
+	}
 
-		if PageStr != "" {
-			page, err := strconv.Atoi(PageStr)
-			if err != nil {
-				utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
-				return
-			}
-			queryParams.Page = page
+	if MinEndDateStr != "" {
+		_, err := time.Parse(time.RFC3339, MinEndDateStr)
+		if err != nil {
+			return nil, expense_errors.EXPENSE_BAD_REQUEST
 		}
+		queryParams.MinEndDate = &MinEndDateStr
+	}
 
-		if PageSizeStr != "" {
-			pageSize, err := strconv.Atoi(PageSizeStr)
-			if err != nil {
-				utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
-				return
-			}
-			queryParams.PageSize = pageSize
+	if MaxEndDateStr != "" {
+		_, err := time.Parse(time.RFC3339, MaxEndDateStr)
+		if err != nil {
+			return nil, expense_errors.EXPENSE_BAD_REQUEST
 		}
+		queryParams.MaxEndDate = &MaxEndDateStr
+	}
 
-		if SortByStr != "" {
-			queryParams.SortBy = SortByStr
+	if MinCreationDateStr != "" {
+		_, err := time.Parse(time.RFC3339, MinCreationDateStr)
+		if err != nil {
+			return nil, expense_errors.EXPENSE_BAD_REQUEST
 		}
+		queryParams.MinCreationDate = &MinCreationDateStr
+	}
 
-		if strings.ToUpper(SortOrderStr) == "ASC" {
-			queryParams.SortOrder = "ASC"
+	if MaxCreationDateStr != "" {
+		_, err := time.Parse(time.RFC3339, MaxCreationDateStr)
+		if err != nil {
+			return nil, expense_errors.EXPENSE_BAD_REQUEST
 		}
+		queryParams.MaxCreationDate = &MaxCreationDateStr
+	}
 
-		// Pass query params to the controller
-		response, err := costCtl.GetCostEntries(ctx, &queryParams)
+	if TagsStr != "" {
+		for _, tag := range strings.Split(TagsStr, ",") {
+			if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+				queryParams.Tags = append(queryParams.Tags, tag)
+			}
+		}
+	}
+
+	if PageStr != "" {
+		page, err := strconv.Atoi(PageStr)
 		if err != nil {
-			utils.HandleErrorAndAbort(c, *err)
+			return nil, expense_errors.EXPENSE_BAD_REQUEST
+		}
+		queryParams.Page = page
+	}
+
+	if PageSizeStr != "" {
+		pageSize, err := strconv.Atoi(PageSizeStr)
+		if err != nil {
+			return nil, expense_errors.EXPENSE_BAD_REQUEST
+		}
+		queryParams.PageSize = pageSize
+	}
+
+	if SortByStr != "" {
+		queryParams.SortBy = SortByStr
+	}
+
+	if strings.ToUpper(SortOrderStr) == "ASC" {
+		queryParams.SortOrder = "ASC"
+	}
+
+	return &queryParams, nil
+}
+
+func CreateCostSuggestionHandler(costCtl controllers.CostCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Get tripId and costId from request params
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+		costId := uuid.MustParse(c.Param(models.ExpenseParamKeyCostId))
+
+		// Get suggested changes from request body
+		var suggestionRequest models.CostSuggestionRequest
+		if err := c.ShouldBindJSON(&suggestionRequest); err != nil || suggestionRequest.Changes == nil {
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
+		}
+
+		// Check if dates are valid
+		if !utils.IsValidDate(time.RFC3339, suggestionRequest.Changes.DeductionDate, suggestionRequest.Changes.EndDate) {
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
+		}
+
+		// Check if currency code is valid
+		if !utils.ContainsEmptyString(suggestionRequest.Changes.CurrencyCode) && !utils.IsValidCurrencyCode(suggestionRequest.Changes.CurrencyCode) {
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
+		}
+
+		response, serviceErr := costCtl.CreateCostSuggestion(ctx, &tripId, &costId, suggestionRequest)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusCreated, response)
+	}
+}
+
+func GetCostSuggestionsHandler(costCtl controllers.CostCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Get tripId and costId from request params
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+		costId := uuid.MustParse(c.Param(models.ExpenseParamKeyCostId))
+
+		response, serviceErr := costCtl.GetCostSuggestions(ctx, &tripId, &costId)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
 			return
 		}
 
@@ -303,18 +411,35 @@ func GetCostEntriesHandler(costCtl controllers.CostCtl) gin.HandlerFunc {
 	}
 }
 
-func GetCostDetailsHandler(costCtl controllers.CostCtl) gin.HandlerFunc {
+func ApproveCostSuggestionHandler(costCtl controllers.CostCtl) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx := c.Request.Context()
 
-		// Get costId from request params
-		costId, err := uuid.Parse(c.Param(models.ExpenseParamKeyCostId))
-		if err != nil {
-			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+		// Get tripId, costId and costSuggestionId from request params
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+		costId := uuid.MustParse(c.Param(models.ExpenseParamKeyCostId))
+		costSuggestionId := uuid.MustParse(c.Param(models.ExpenseParamKeyCostSuggestionId))
+
+		response, serviceErr := costCtl.ApproveCostSuggestion(ctx, &tripId, &costId, &costSuggestionId)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
 			return
 		}
 
-		response, serviceErr := costCtl.GetCostDetails(ctx, &costId)
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func RejectCostSuggestionHandler(costCtl controllers.CostCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Get tripId, costId and costSuggestionId from request params
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+		costId := uuid.MustParse(c.Param(models.ExpenseParamKeyCostId))
+		costSuggestionId := uuid.MustParse(c.Param(models.ExpenseParamKeyCostSuggestionId))
+
+		response, serviceErr := costCtl.RejectCostSuggestion(ctx, &tripId, &costId, &costSuggestionId)
 		if serviceErr != nil {
 			utils.HandleErrorAndAbort(c, *serviceErr)
 			return
@@ -324,25 +449,171 @@ func GetCostDetailsHandler(costCtl controllers.CostCtl) gin.HandlerFunc {
 	}
 }
 
-func DeleteCostEntryHandler(costCtl controllers.CostCtl) gin.HandlerFunc {
+func ApproveCostHandler(costCtl controllers.CostCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Get tripId and costId from request params
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+		costId := uuid.MustParse(c.Param(models.ExpenseParamKeyCostId))
+
+		response, serviceErr := costCtl.ApproveCost(ctx, &tripId, &costId)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func RejectCostHandler(costCtl controllers.CostCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Get tripId and costId from request params
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+		costId := uuid.MustParse(c.Param(models.ExpenseParamKeyCostId))
+
+		response, serviceErr := costCtl.RejectCost(ctx, &tripId, &costId)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func GetCostsAwaitingApprovalHandler(costCtl controllers.CostCtl) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx := c.Request.Context()
 
 		// Get tripId from request params
 		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
 
-		// Get costId from request params
-		costId, err := uuid.Parse(c.Param(models.ExpenseParamKeyCostId))
-		if err != nil {
+		response, serviceErr := costCtl.GetCostsAwaitingApproval(ctx, &tripId)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func CreateCostCommentHandler(costCtl controllers.CostCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Get tripId and costId from request params
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+		costId := uuid.MustParse(c.Param(models.ExpenseParamKeyCostId))
+
+		// Get comment from request body
+		var commentRequest models.CostCommentRequest
+		if err := c.ShouldBindJSON(&commentRequest); err != nil {
 			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
 		}
 
-		serviceErr := costCtl.DeleteCostEntry(ctx, &tripId, &costId)
-		if err != nil {
+		response, serviceErr := costCtl.CreateCostComment(ctx, &tripId, &costId, commentRequest)
+		if serviceErr != nil {
 			utils.HandleErrorAndAbort(c, *serviceErr)
 			return
 		}
 
-		c.AbortWithStatus(http.StatusNoContent)
+		c.JSON(http.StatusCreated, response)
+	}
+}
+
+func GetCostCommentsHandler(costCtl controllers.CostCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Get tripId and costId from request params
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+		costId := uuid.MustParse(c.Param(models.ExpenseParamKeyCostId))
+
+		response, serviceErr := costCtl.GetCostComments(ctx, &tripId, &costId)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func DeleteCostCommentHandler(costCtl controllers.CostCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Get tripId, costId and costCommentId from request params
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+		costId := uuid.MustParse(c.Param(models.ExpenseParamKeyCostId))
+		costCommentId := uuid.MustParse(c.Param(models.ExpenseParamKeyCostCommentId))
+
+		if serviceErr := costCtl.DeleteCostComment(ctx, &tripId, &costId, &costCommentId); serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func GetCostVersionsHandler(costCtl controllers.CostCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Get tripId and costId from request params
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+		costId := uuid.MustParse(c.Param(models.ExpenseParamKeyCostId))
+
+		response, serviceErr := costCtl.GetCostVersions(ctx, &tripId, &costId)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func RevertCostVersionHandler(costCtl controllers.CostCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Get tripId, costId and costVersionId from request params
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+		costId := uuid.MustParse(c.Param(models.ExpenseParamKeyCostId))
+		costVersionId := uuid.MustParse(c.Param(models.ExpenseParamKeyCostVersionId))
+
+		response, serviceErr := costCtl.RevertCostVersion(ctx, &tripId, &costId, &costVersionId)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func RestoreCostHandler(costCtl controllers.CostCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Get tripId and costId from request params
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+		costId := uuid.MustParse(c.Param(models.ExpenseParamKeyCostId))
+
+		response, serviceErr := costCtl.RestoreCost(ctx, &tripId, &costId)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
 	}
 }
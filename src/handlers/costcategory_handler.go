@@ -43,7 +43,8 @@ func CreateCostCategoryEntryHandler(costCategoryCtl controllers.CostCategoryCtl)
 
 func UpdateCostCategoryEntryHandler(costCategoryCtl controllers.CostCategoryCtl) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Get costCategoryId from path
+		// Get tripId and costCategoryId from path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
 		costCategoryId := uuid.MustParse(c.Param(models.ExpenseParamKeyCostCategoryId))
 
 		// Get cost category entry from request body
@@ -55,7 +56,7 @@ func UpdateCostCategoryEntryHandler(costCategoryCtl controllers.CostCategoryCtl)
 
 		// Update cost category entry
 		ctx := c.Request.Context()
-		response, serviceErr := costCategoryCtl.PatchCostCategory(ctx, &costCategoryId, costCategoryData)
+		response, serviceErr := costCategoryCtl.PatchCostCategory(ctx, &tripId, &costCategoryId, costCategoryData)
 		if serviceErr != nil {
 			utils.HandleErrorAndAbort(c, *serviceErr)
 			return
@@ -84,12 +85,24 @@ func GetCostCategoryDetailsHandler(costCategoryCtl controllers.CostCategoryCtl)
 
 func DeleteCostCategoryEntryHandler(costCategoryCtl controllers.CostCategoryCtl) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Get costCategoryId from path
+		// Get tripId and costCategoryId from path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
 		costCategoryId := uuid.MustParse(c.Param(models.ExpenseParamKeyCostCategoryId))
 
+		// Get optional cost category the costs are moved to
+		var moveToCostCategoryId *uuid.UUID
+		if moveTo := c.Query(models.ExpenseQueryKeyMoveTo); moveTo != "" {
+			parsedId, err := uuid.Parse(moveTo)
+			if err != nil {
+				utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+				return
+			}
+			moveToCostCategoryId = &parsedId
+		}
+
 		// Delete cost category entry
 		ctx := c.Request.Context()
-		serviceErr := costCategoryCtl.DeleteCostCategory(ctx, &costCategoryId)
+		serviceErr := costCategoryCtl.DeleteCostCategory(ctx, &tripId, &costCategoryId, moveToCostCategoryId)
 		if serviceErr != nil {
 			utils.HandleErrorAndAbort(c, *serviceErr)
 			return
@@ -115,3 +128,54 @@ func GetCostCategoryEntriesHandler(costCategoryCtl controllers.CostCategoryCtl)
 		c.JSON(http.StatusOK, response)
 	}
 }
+
+func GetCostCategoryPresetsHandler(costCategoryCtl controllers.CostCategoryCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Get optional locale from query
+		locale := c.Query(models.ExpenseQueryKeyLocale)
+
+		ctx := c.Request.Context()
+		c.JSON(http.StatusOK, costCategoryCtl.GetCostCategoryPresets(ctx, locale))
+	}
+}
+
+func MergeCostCategoryHandler(costCategoryCtl controllers.CostCategoryCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Get tripId and costCategoryId from path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+		costCategoryId := uuid.MustParse(c.Param(models.ExpenseParamKeyCostCategoryId))
+
+		var mergeRequest models.CostCategoryMergeRequest
+		if err := c.ShouldBindJSON(&mergeRequest); err != nil || mergeRequest.TargetCostCategoryId == nil {
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
+		}
+
+		// Merge cost category into target
+		ctx := c.Request.Context()
+		response, serviceErr := costCategoryCtl.MergeCostCategory(ctx, &tripId, &costCategoryId, mergeRequest)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func RestoreCostCategoryHandler(costCategoryCtl controllers.CostCategoryCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Get tripId and costCategoryId from path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+		costCategoryId := uuid.MustParse(c.Param(models.ExpenseParamKeyCostCategoryId))
+
+		ctx := c.Request.Context()
+		response, serviceErr := costCategoryCtl.RestoreCostCategory(ctx, &tripId, &costCategoryId)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/controllers"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/expense_errors"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func CreateCostCategoryRuleHandler(costCategoryRuleCtl controllers.CostCategoryRuleCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Get tripId from path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+
+		// Get cost category rule from request body
+		var ruleData models.CostCategoryRuleRequest
+		if err := c.ShouldBindJSON(&ruleData); err != nil {
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
+		}
+
+		// Create cost category rule
+		ctx := c.Request.Context()
+		response, serviceErr := costCategoryRuleCtl.CreateCostCategoryRule(ctx, &tripId, ruleData)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusCreated, response)
+	}
+}
+
+func GetCostCategoryRulesHandler(costCategoryRuleCtl controllers.CostCategoryRuleCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Get tripId from path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+
+		// Get cost category rules
+		ctx := c.Request.Context()
+		response, serviceErr := costCategoryRuleCtl.GetCostCategoryRules(ctx, &tripId)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func UpdateCostCategoryRuleHandler(costCategoryRuleCtl controllers.CostCategoryRuleCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Get tripId and costCategoryRuleId from path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+		costCategoryRuleId := uuid.MustParse(c.Param(models.ExpenseParamKeyCostCategoryRuleId))
+
+		// Get cost category rule from request body
+		var ruleData models.CostCategoryRuleRequest
+		if err := c.ShouldBindJSON(&ruleData); err != nil {
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
+		}
+
+		// Update cost category rule
+		ctx := c.Request.Context()
+		response, serviceErr := costCategoryRuleCtl.PatchCostCategoryRule(ctx, &tripId, &costCategoryRuleId, ruleData)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func DeleteCostCategoryRuleHandler(costCategoryRuleCtl controllers.CostCategoryRuleCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Get tripId and costCategoryRuleId from path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+		costCategoryRuleId := uuid.MustParse(c.Param(models.ExpenseParamKeyCostCategoryRuleId))
+
+		// Delete cost category rule
+		ctx := c.Request.Context()
+		if serviceErr := costCategoryRuleCtl.DeleteCostCategoryRule(ctx, &tripId, &costCategoryRuleId); serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func TestCostCategoryRulesHandler(costCategoryRuleCtl controllers.CostCategoryRuleCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Get tripId from path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+
+		// Check which existing costs the rules of the trip match
+		ctx := c.Request.Context()
+		response, serviceErr := costCategoryRuleCtl.TestCostCategoryRules(ctx, &tripId)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
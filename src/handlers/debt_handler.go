@@ -73,3 +73,27 @@ func GetDebtDetailsHandler(DebtCtl controllers.DebtCtl) gin.HandlerFunc {
 		c.JSON(http.StatusOK, response)
 	}
 }
+
+func ForgiveDebtHandler(DebtCtl controllers.DebtCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Get tripId and debtId from request params
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+		debtId := uuid.MustParse(c.Param(models.ExpenseParamKeyDebtId))
+
+		var forgivenessRequest models.DebtForgivenessRequest
+		if err := c.ShouldBindJSON(&forgivenessRequest); err != nil {
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
+		}
+
+		response, serviceErr := DebtCtl.ForgiveDebt(ctx, &tripId, &debtId, forgivenessRequest)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/controllers"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/expense_errors"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func GetKittyHandler(kittyCtl controllers.KittyCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Get tripId from path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+
+		// Get kitty
+		ctx := c.Request.Context()
+		response, serviceErr := kittyCtl.GetKitty(ctx, &tripId)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func CreateKittyDepositHandler(kittyCtl controllers.KittyCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Get tripId from path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+
+		// Get deposit from request body
+		var depositData models.KittyDepositRequest
+		if err := c.ShouldBindJSON(&depositData); err != nil {
+			log.Printf("Error while binding JSON: %v", err)
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
+		}
+
+		if utils.ContainsEmptyString(depositData.Amount) {
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
+		}
+
+		// Pay into kitty
+		ctx := c.Request.Context()
+		response, serviceErr := kittyCtl.CreateKittyDeposit(ctx, &tripId, depositData)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusCreated, response)
+	}
+}
+
+func SettleKittyHandler(kittyCtl controllers.KittyCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Get tripId from path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+
+		// Settle kitty
+		ctx := c.Request.Context()
+		response, serviceErr := kittyCtl.SettleKitty(ctx, &tripId)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
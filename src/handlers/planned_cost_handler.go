@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/controllers"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/expense_errors"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func CreatePlannedCostHandler(plannedCostCtl controllers.PlannedCostCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Get tripId from path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+
+		// Get planned cost from request body
+		var plannedCostData models.PlannedCostDTO
+		if err := c.ShouldBindJSON(&plannedCostData); err != nil {
+			log.Printf("Error while binding JSON: %v", err)
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
+		}
+
+		// Check if planned cost has empty fields
+		if plannedCostData.CostCategoryID == nil || utils.ContainsEmptyString(plannedCostData.Amount) {
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
+		}
+
+		// Create planned cost
+		ctx := c.Request.Context()
+		response, serviceErr := plannedCostCtl.CreatePlannedCost(ctx, &tripId, plannedCostData)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusCreated, response)
+	}
+}
+
+func GetPlannedCostEntriesHandler(plannedCostCtl controllers.PlannedCostCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Get tripId from path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+
+		// Get optional cost category filter from query
+		var costCategoryId *uuid.UUID
+		if costCategoryIdStr := c.Query("costCategoryId"); costCategoryIdStr != "" {
+			id, err := uuid.Parse(costCategoryIdStr)
+			if err != nil {
+				utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+				return
+			}
+			costCategoryId = &id
+		}
+
+		// Get planned cost entries
+		ctx := c.Request.Context()
+		response, serviceErr := plannedCostCtl.GetPlannedCostEntries(ctx, &tripId, costCategoryId)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func GetPlannedCostDetailsHandler(plannedCostCtl controllers.PlannedCostCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Get tripId and plannedCostId from path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+		plannedCostId := uuid.MustParse(c.Param(models.ExpenseParamKeyPlannedCostId))
+
+		// Get planned cost
+		ctx := c.Request.Context()
+		response, serviceErr := plannedCostCtl.GetPlannedCostDetails(ctx, &tripId, &plannedCostId)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func UpdatePlannedCostHandler(plannedCostCtl controllers.PlannedCostCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Get tripId and plannedCostId from path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+		plannedCostId := uuid.MustParse(c.Param(models.ExpenseParamKeyPlannedCostId))
+
+		// Get planned cost from request body
+		var plannedCostData models.PlannedCostDTO
+		if err := c.ShouldBindJSON(&plannedCostData); err != nil {
+			log.Printf("Error while binding JSON: %v", err)
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
+		}
+
+		// Update planned cost
+		ctx := c.Request.Context()
+		response, serviceErr := plannedCostCtl.PatchPlannedCost(ctx, &tripId, &plannedCostId, plannedCostData)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func DeletePlannedCostHandler(plannedCostCtl controllers.PlannedCostCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Get tripId and plannedCostId from path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+		plannedCostId := uuid.MustParse(c.Param(models.ExpenseParamKeyPlannedCostId))
+
+		// Delete planned cost
+		ctx := c.Request.Context()
+		if serviceErr := plannedCostCtl.DeletePlannedCost(ctx, &tripId, &plannedCostId); serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func LinkPlannedCostHandler(plannedCostCtl controllers.PlannedCostCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Get tripId and plannedCostId from path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+		plannedCostId := uuid.MustParse(c.Param(models.ExpenseParamKeyPlannedCostId))
+
+		// Get cost ids from request body
+		var linkData models.PlannedCostLinkRequest
+		if err := c.ShouldBindJSON(&linkData); err != nil {
+			log.Printf("Error while binding JSON: %v", err)
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
+		}
+
+		if len(linkData.CostIDs) == 0 {
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
+		}
+
+		// Link costs
+		ctx := c.Request.Context()
+		response, serviceErr := plannedCostCtl.LinkCosts(ctx, &tripId, &plannedCostId, linkData)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func UnlinkPlannedCostHandler(plannedCostCtl controllers.PlannedCostCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Get tripId, plannedCostId and costId from path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+		plannedCostId := uuid.MustParse(c.Param(models.ExpenseParamKeyPlannedCostId))
+		costId := uuid.MustParse(c.Param(models.ExpenseParamKeyCostId))
+
+		// Unlink cost
+		ctx := c.Request.Context()
+		if serviceErr := plannedCostCtl.UnlinkCost(ctx, &tripId, &plannedCostId, &costId); serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func GetCostComparisonHandler(plannedCostCtl controllers.PlannedCostCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Get tripId from path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+
+		// The same filters as for cost entries are supported
+		queryParams, serviceErr := parseCostQueryParams(c, &tripId)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		// Compare planned and actual costs
+		ctx := c.Request.Context()
+		response, serviceErr := plannedCostCtl.GetCostComparison(ctx, queryParams)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
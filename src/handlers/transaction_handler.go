@@ -51,11 +51,14 @@ func CreateTransactionHandler(transactionCtl controllers.TransactionCtl) gin.Han
 		// Get tripId from path
 		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
 
-		// Get user id from context
-		userId := ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID)
+		// Get creditor id from request, the context user pays unless a payment is recorded on behalf of a guest
+		creditorId := ctx.Value(models.ExpenseContextKeyUserID).(*uuid.UUID)
+		if transactionRequest.CreditorId != nil {
+			creditorId = transactionRequest.CreditorId
+		}
 
 		// Check if creditor and debtor are the same
-		if userId.String() == transactionRequest.DebtorId.String() {
+		if creditorId.String() == transactionRequest.DebtorId.String() {
 			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
 			return
 		}
@@ -252,3 +255,24 @@ func GetUserTransactionsHandler(transactionCtl controllers.TransactionCtl) gin.H
 		c.JSON(http.StatusOK, response)
 	}
 }
+
+func ImportBankStatementHandler(transactionCtl controllers.TransactionCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Get statement file from multipart form
+		file, err := c.FormFile(models.ExpenseQueryKeyStatement)
+		if err != nil {
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
+		}
+
+		response, serviceErr := transactionCtl.ImportBankStatement(ctx, file)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
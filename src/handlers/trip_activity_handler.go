@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/controllers"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/expense_errors"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func GetTripActivityHandler(tripActivityCtl controllers.TripActivityCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Get the tripId from the path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+
+		// Page and page size are optional, the controller falls back to the first page
+		var page, pageSize int
+		if pageStr := c.Query("page"); pageStr != "" {
+			var err error
+			if page, err = strconv.Atoi(pageStr); err != nil {
+				utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+				return
+			}
+		}
+
+		if pageSizeStr := c.Query("pageSize"); pageSizeStr != "" {
+			var err error
+			if pageSize, err = strconv.Atoi(pageSizeStr); err != nil {
+				utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+				return
+			}
+		}
+
+		response, serviceErr := tripActivityCtl.GetTripActivity(ctx, &tripId, page, pageSize)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
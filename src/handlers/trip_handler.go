@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/controllers"
@@ -42,7 +43,17 @@ func GetTripEntriesHandler(tripCtl controllers.TripCtl) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx := c.Request.Context()
 
-		response, serviceErr := tripCtl.GetTripEntries(ctx)
+		// Archived trips are hidden unless requested
+		includeArchived := false
+		if includeArchivedStr := c.Query("includeArchived"); includeArchivedStr != "" {
+			var err error
+			if includeArchived, err = strconv.ParseBool(includeArchivedStr); err != nil {
+				utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+				return
+			}
+		}
+
+		response, serviceErr := tripCtl.GetTripEntries(ctx, includeArchived)
 		if serviceErr != nil {
 			utils.HandleErrorAndAbort(c, *serviceErr)
 			return
@@ -196,3 +207,298 @@ func DeclineTripInviteHandler(TripCtl controllers.TripCtl) gin.HandlerFunc {
 		c.AbortWithStatus(http.StatusNoContent)
 	}
 }
+
+func GetTripForecastHandler(TripCtl controllers.TripCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Get the tripId from the path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+
+		response, serviceErr := TripCtl.GetTripForecast(ctx, &tripId)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func UpdateParticipantRoleHandler(TripCtl controllers.TripCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Get the tripId and userId from the path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+		userId := uuid.MustParse(c.Param(models.ExpenseParamKeyUserId))
+
+		var roleRequest models.TripRoleRequest
+		if err := c.ShouldBindJSON(&roleRequest); err != nil {
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
+		}
+
+		response, serviceErr := TripCtl.UpdateParticipantRole(ctx, &tripId, &userId, roleRequest)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func TransferTripOwnershipHandler(TripCtl controllers.TripCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Get the tripId from the path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+
+		var transferRequest models.TripOwnershipTransferRequest
+		if err := c.ShouldBindJSON(&transferRequest); err != nil || transferRequest.UserID == nil {
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
+		}
+
+		response, serviceErr := TripCtl.TransferTripOwnership(ctx, &tripId, transferRequest)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func CreateTripInviteHandler(TripCtl controllers.TripCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Get the tripId from the path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+
+		var inviteRequest models.TripInviteRequest
+		if err := c.ShouldBindJSON(&inviteRequest); err != nil {
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
+		}
+
+		response, serviceErr := TripCtl.CreateTripInvite(ctx, &tripId, inviteRequest)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusCreated, response)
+	}
+}
+
+func GetTripInvitesHandler(TripCtl controllers.TripCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Get the tripId from the path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+
+		response, serviceErr := TripCtl.GetTripInvites(ctx, &tripId)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func RevokeTripInviteHandler(TripCtl controllers.TripCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Get the tripId and tripInviteId from the path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+		tripInviteId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripInviteId))
+
+		if serviceErr := TripCtl.RevokeTripInvite(ctx, &tripId, &tripInviteId); serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+func JoinTripWithInviteHandler(TripCtl controllers.TripCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		var acceptRequest models.TripInviteAcceptRequest
+		if err := c.ShouldBindJSON(&acceptRequest); err != nil || acceptRequest.Token == "" {
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
+		}
+
+		response, serviceErr := TripCtl.JoinTripWithInvite(ctx, acceptRequest)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func AddGuestToTripHandler(TripCtl controllers.TripCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Get the tripId from the path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+
+		var guestRequest models.TripGuestRequest
+		if err := c.ShouldBindJSON(&guestRequest); err != nil {
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
+		}
+
+		response, serviceErr := TripCtl.AddGuestToTrip(ctx, &tripId, guestRequest)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusCreated, response)
+	}
+}
+
+func LeaveTripHandler(TripCtl controllers.TripCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Get the tripId from the path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+
+		if serviceErr := TripCtl.LeaveTrip(ctx, &tripId); serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+func RemoveTripParticipantHandler(TripCtl controllers.TripCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Get the tripId and userId from the path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+		userId := uuid.MustParse(c.Param(models.ExpenseParamKeyUserId))
+
+		if serviceErr := TripCtl.RemoveTripParticipant(ctx, &tripId, &userId); serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+func UpdateOwnPresenceHandler(TripCtl controllers.TripCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Get the tripId from the path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+
+		var presenceRequest models.TripPresenceRequest
+		if err := c.ShouldBindJSON(&presenceRequest); err != nil {
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
+		}
+
+		response, serviceErr := TripCtl.UpdateOwnPresence(ctx, &tripId, presenceRequest)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func UpdateTripStatusHandler(TripCtl controllers.TripCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Get the tripId from the path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+
+		var statusRequest models.TripStatusRequest
+		if err := c.ShouldBindJSON(&statusRequest); err != nil {
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
+		}
+
+		response, serviceErr := TripCtl.UpdateTripStatus(ctx, &tripId, statusRequest)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func GetDeletedTripsHandler(tripCtl controllers.TripCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		response, serviceErr := tripCtl.GetDeletedTrips(ctx)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func RestoreTripHandler(tripCtl controllers.TripCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// The trip is in the trash, so the path is not validated by the trip middleware
+		tripId, err := uuid.Parse(c.Param(models.ExpenseParamKeyTripId))
+		if err != nil {
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
+		}
+
+		response, serviceErr := tripCtl.RestoreTrip(ctx, &tripId)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func GetTripTrashHandler(tripCtl controllers.TripCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Get the tripId from the path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+
+		response, serviceErr := tripCtl.GetTripTrash(ctx, &tripId)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
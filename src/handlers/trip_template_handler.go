@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/controllers"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/expense_errors"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func CloneTripHandler(tripTemplateCtl controllers.TripTemplateCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Get tripId from path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+
+		var cloneRequest models.TripCloneRequest
+		if err := c.ShouldBindJSON(&cloneRequest); err != nil {
+			log.Printf("Error while binding JSON: %v", err)
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
+		}
+
+		if utils.ContainsEmptyString(cloneRequest.StartDate, cloneRequest.EndDate) {
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
+		}
+
+		ctx := c.Request.Context()
+		response, serviceErr := tripTemplateCtl.CloneTrip(ctx, &tripId, cloneRequest)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusCreated, response)
+	}
+}
+
+func CreateTripTemplateHandler(tripTemplateCtl controllers.TripTemplateCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Get tripId from path
+		tripId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripId))
+
+		var templateRequest models.TripTemplateRequest
+		if err := c.ShouldBindJSON(&templateRequest); err != nil {
+			log.Printf("Error while binding JSON: %v", err)
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
+		}
+
+		ctx := c.Request.Context()
+		response, serviceErr := tripTemplateCtl.CreateTripTemplate(ctx, &tripId, templateRequest)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusCreated, response)
+	}
+}
+
+func GetTripTemplatesHandler(tripTemplateCtl controllers.TripTemplateCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		response, serviceErr := tripTemplateCtl.GetTripTemplates(ctx)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func DeleteTripTemplateHandler(tripTemplateCtl controllers.TripTemplateCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Get tripTemplateId from path
+		tripTemplateId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripTemplateId))
+
+		ctx := c.Request.Context()
+		if serviceErr := tripTemplateCtl.DeleteTripTemplate(ctx, &tripTemplateId); serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+func CreateTripFromTemplateHandler(tripTemplateCtl controllers.TripTemplateCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Get tripTemplateId from path
+		tripTemplateId := uuid.MustParse(c.Param(models.ExpenseParamKeyTripTemplateId))
+
+		var cloneRequest models.TripCloneRequest
+		if err := c.ShouldBindJSON(&cloneRequest); err != nil {
+			log.Printf("Error while binding JSON: %v", err)
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
+		}
+
+		if utils.ContainsEmptyString(cloneRequest.StartDate, cloneRequest.EndDate) {
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
+		}
+
+		ctx := c.Request.Context()
+		response, serviceErr := tripTemplateCtl.CreateTripFromTemplate(ctx, &tripTemplateId, cloneRequest)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusCreated, response)
+	}
+}
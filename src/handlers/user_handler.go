@@ -277,6 +277,20 @@ func GetUserDetailsHandler(userCtl controllers.UserCtl) gin.HandlerFunc {
 	}
 }
 
+func GetPendingTripInvitesHandler(userCtl controllers.UserCtl) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		response, serviceErr := userCtl.GetPendingTripInvites(ctx)
+		if serviceErr != nil {
+			utils.HandleErrorAndAbort(c, *serviceErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
 func SuggestUsersHandler(userCtl controllers.UserCtl) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx := c.Request.Context()
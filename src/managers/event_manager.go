@@ -0,0 +1,55 @@
+package managers
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
+)
+
+type EventMgr interface {
+	Subscribe(handler func(ctx context.Context, event *models.TripEvent))
+	Publish(ctx context.Context, event *models.TripEvent)
+}
+
+type EventManager struct {
+	mutex    sync.RWMutex
+	handlers []func(ctx context.Context, event *models.TripEvent)
+}
+
+// Subscribe registers a handler that receives every published event
+func (em *EventManager) Subscribe(handler func(ctx context.Context, event *models.TripEvent)) {
+	em.mutex.Lock()
+	defer em.mutex.Unlock()
+
+	em.handlers = append(em.handlers, handler)
+}
+
+// Publish passes the event to all handlers one after another. The change the event describes is already committed,
+// so handlers log their errors instead of returning them
+func (em *EventManager) Publish(ctx context.Context, event *models.TripEvent) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	em.mutex.RLock()
+	handlers := em.handlers
+	em.mutex.RUnlock()
+
+	for _, handler := range handlers {
+		em.runHandler(ctx, handler, event)
+	}
+}
+
+func (*EventManager) runHandler(ctx context.Context, handler func(ctx context.Context, event *models.TripEvent), event *models.TripEvent) {
+	// A panicking handler must not fail the request that caused the event
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Handler of event %s panicked: %v", event.Type, r)
+		}
+	}()
+
+	handler(ctx, event)
+}
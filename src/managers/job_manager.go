@@ -0,0 +1,51 @@
+package managers
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+type JobMgr interface {
+	ScheduleJob(name string, interval time.Duration, job func(ctx context.Context))
+	RunJob(name string, job func(ctx context.Context))
+}
+
+type JobManager struct{}
+
+// jobTimeout limits how long a single run of a job may take
+const jobTimeout = 5 * time.Minute
+
+// ScheduleJob runs the job in the background once right away and then every interval until the process exits
+func (jm *JobManager) ScheduleJob(name string, interval time.Duration, job func(ctx context.Context)) {
+	log.Printf("Scheduling job %s every %v", name, interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			jm.runJob(name, job)
+			<-ticker.C
+		}
+	}()
+}
+
+// RunJob runs the job once in the background, e.g. to send notifications without delaying the request that caused them
+func (jm *JobManager) RunJob(name string, job func(ctx context.Context)) {
+	go jm.runJob(name, job)
+}
+
+func (*JobManager) runJob(name string, job func(ctx context.Context)) {
+	ctx, cancel := context.WithTimeout(context.Background(), jobTimeout)
+	defer cancel()
+
+	// A panicking job must not take down the server
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Job %s panicked: %v", name, r)
+		}
+	}()
+
+	job(ctx)
+}
@@ -18,6 +18,11 @@ type MailMgr interface {
 	SendPasswordResetMail(ctx context.Context, mailData *models.PasswordResetMail) *models.ExpenseServiceError
 	SendResetPasswordConfirmationMail(ctx context.Context, mailData *models.ResetPasswordConfirmationMail) *models.ExpenseServiceError
 	SendContactMail(ctx context.Context, data *models.SendContactMailRequest) *models.ExpenseServiceError
+	SendBudgetAlertMail(ctx context.Context, mailData *models.BudgetAlertMail) *models.ExpenseServiceError
+	SendTripInviteMail(ctx context.Context, mailData *models.TripInviteMail) *models.ExpenseServiceError
+	SendTripInvitationMail(ctx context.Context, mailData *models.TripInvitationMail) *models.ExpenseServiceError
+	SendTripInvitationReminderMail(ctx context.Context, mailData *models.TripInvitationMail) *models.ExpenseServiceError
+	SendCostCommentMentionMail(ctx context.Context, mailData *models.CostCommentMentionMail) *models.ExpenseServiceError
 }
 
 type MailManager struct {
@@ -120,6 +125,101 @@ func (mm *MailManager) SendContactMail(ctx context.Context, data *models.SendCon
 	return nil
 }
 
+func (mm *MailManager) SendBudgetAlertMail(ctx context.Context, mailData *models.BudgetAlertMail) *models.ExpenseServiceError {
+	mailBody := utils.PrepareBudgetAlertMailBody(mailData.Username, mailData.TripName, mailData.CostCategoryName, mailData.Budget, mailData.Spent, mailData.PercentUsed)
+
+	// try sending mail 3 times
+	for i := 0; i < retryMailCount; i++ {
+		err := mm.sendMail(ctx, mailData.Recipients, emailSender, mailData.Subject, mailBody)
+		if err == nil {
+			break
+		}
+
+		if i == retryMailCount-1 {
+			log.Printf("Error in MailManager.SendBudgetAlertMail().SendMail(): %v", err.Error())
+			return expense_errors.EXPENSE_MAIL_NOT_SENT
+		}
+	}
+
+	return nil
+}
+
+func (mm *MailManager) SendTripInviteMail(ctx context.Context, mailData *models.TripInviteMail) *models.ExpenseServiceError {
+	mailBody := utils.PrepareTripInviteMailBody(mailData.InviterName, mailData.TripName, mailData.InviteLink)
+
+	// try sending mail 3 times
+	for i := 0; i < retryMailCount; i++ {
+		err := mm.sendMail(ctx, mailData.Recipients, emailSender, mailData.Subject, mailBody)
+		if err == nil {
+			break
+		}
+
+		if i == retryMailCount-1 {
+			log.Printf("Error in MailManager.SendTripInviteMail().SendMail(): %v", err.Error())
+			return expense_errors.EXPENSE_MAIL_NOT_SENT
+		}
+	}
+
+	return nil
+}
+
+func (mm *MailManager) SendTripInvitationMail(ctx context.Context, mailData *models.TripInvitationMail) *models.ExpenseServiceError {
+	mailBody := utils.PrepareTripInvitationMailBody(mailData.Username, mailData.InviterName, mailData.TripName, mailData.StartDate, mailData.EndDate, mailData.ExpiresAt)
+
+	// try sending mail 3 times
+	for i := 0; i < retryMailCount; i++ {
+		err := mm.sendMail(ctx, mailData.Recipients, emailSender, mailData.Subject, mailBody)
+		if err == nil {
+			break
+		}
+
+		if i == retryMailCount-1 {
+			log.Printf("Error in MailManager.SendTripInvitationMail().SendMail(): %v", err.Error())
+			return expense_errors.EXPENSE_MAIL_NOT_SENT
+		}
+	}
+
+	return nil
+}
+
+func (mm *MailManager) SendTripInvitationReminderMail(ctx context.Context, mailData *models.TripInvitationMail) *models.ExpenseServiceError {
+	mailBody := utils.PrepareTripInvitationReminderMailBody(mailData.Username, mailData.InviterName, mailData.TripName, mailData.StartDate, mailData.EndDate, mailData.ExpiresAt)
+
+	// try sending mail 3 times
+	for i := 0; i < retryMailCount; i++ {
+		err := mm.sendMail(ctx, mailData.Recipients, emailSender, mailData.Subject, mailBody)
+		if err == nil {
+			break
+		}
+
+		if i == retryMailCount-1 {
+			log.Printf("Error in MailManager.SendTripInvitationReminderMail().SendMail(): %v", err.Error())
+			return expense_errors.EXPENSE_MAIL_NOT_SENT
+		}
+	}
+
+	return nil
+}
+
+func (mm *MailManager) SendCostCommentMentionMail(ctx context.Context, mailData *models.CostCommentMentionMail) *models.ExpenseServiceError {
+	mailBody := utils.PrepareCostCommentMentionMailBody(mailData.Username, mailData.AuthorName, mailData.TripName, mailData.CostDescription, mailData.Comment)
+
+	// try sending mail 3 times
+	for i := 0; i < retryMailCount; i++ {
+		err := mm.sendMail(ctx, mailData.Recipients, emailSender, mailData.Subject, mailBody)
+		if err == nil {
+			break
+		}
+
+		if i == retryMailCount-1 {
+			log.Printf("Error in MailManager.SendCostCommentMentionMail().SendMail(): %v", err.Error())
+			return expense_errors.EXPENSE_MAIL_NOT_SENT
+		}
+	}
+
+	return nil
+}
+
 func (mm *MailManager) sendMail(ctx context.Context, to []string, from, subject, body string) error {
 	message := mm.MailgunInstance.NewMessage(from, subject, "", to...)
 	message.AddHeader("Content-Type", "text/html")
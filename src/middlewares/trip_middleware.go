@@ -30,7 +30,7 @@ func TripValidationMiddleware(databaseMgr managers.DatabaseMgr) gin.HandlerFunc
 
 		// Check if trip exists
 		ctx := c.Request.Context()
-		exists, err := databaseMgr.CheckIfExists(ctx, "SELECT COUNT(*) FROM trip WHERE id = $1", &tripId)
+		exists, err := databaseMgr.CheckIfExists(ctx, "SELECT COUNT(*) FROM trip WHERE id = $1 AND deleted_at IS NULL", &tripId)
 
 		if err != nil {
 			log.Printf("Error while checking if trip %s exists", tripId.String())
@@ -60,3 +60,30 @@ func TripValidationMiddleware(databaseMgr managers.DatabaseMgr) gin.HandlerFunc
 		}
 	}
 }
+
+// TripStatusLockMiddleware rejects the request if the trip is in one of the locked statuses. It has to run after the
+// TripValidationMiddleware, which makes sure the trip exists
+func TripStatusLockMiddleware(databaseMgr managers.DatabaseMgr, lockedStatuses ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tripId, err := uuid.Parse(c.Param("tripId"))
+		if err != nil {
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_BAD_REQUEST)
+			return
+		}
+
+		var status string
+		if err := databaseMgr.ExecuteQueryRow(c.Request.Context(), "SELECT status FROM trip WHERE id = $1 AND deleted_at IS NULL", &tripId).Scan(&status); err != nil {
+			log.Printf("Error while getting status of trip %s: %v", tripId.String(), err)
+			utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_INTERNAL_ERROR)
+			return
+		}
+
+		for _, lockedStatus := range lockedStatuses {
+			if status == lockedStatus {
+				log.Printf("Trip %s is %s and cannot be changed", tripId.String(), status)
+				utils.HandleErrorAndAbort(c, *expense_errors.EXPENSE_TRIP_LOCKED)
+				return
+			}
+		}
+	}
+}
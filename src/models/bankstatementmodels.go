@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// BankStatementFormatCamt053 is the ISO 20022 CAMT.053 XML statement format
+	BankStatementFormatCamt053 = "CAMT.053"
+	// BankStatementFormatMT940 is the SWIFT MT940 statement format
+	BankStatementFormatMT940 = "MT940"
+
+	// BankStatementDirectionIncoming is used for transfers that were credited to the account of the user
+	BankStatementDirectionIncoming = "incoming"
+	// BankStatementDirectionOutgoing is used for transfers that were debited from the account of the user
+	BankStatementDirectionOutgoing = "outgoing"
+
+	// BankStatementMatchTypeTransaction is used for entries that match a pending transaction
+	BankStatementMatchTypeTransaction = "transaction"
+	// BankStatementMatchTypeDebt is used for entries that match an open debt without a recorded transaction
+	BankStatementMatchTypeDebt = "debt"
+
+	// BankStatementConfidenceHigh is used if the settlement reference was found in the remittance information
+	BankStatementConfidenceHigh = "high"
+	// BankStatementConfidenceMedium is used if the counterparty name matches the other user
+	BankStatementConfidenceMedium = "medium"
+	// BankStatementConfidenceLow is used if only the amount matches
+	BankStatementConfidenceLow = "low"
+
+	// BankStatementActionAcceptTransaction suggests confirming the pending transaction
+	BankStatementActionAcceptTransaction = "acceptTransaction"
+	// BankStatementActionCreateTransaction suggests recording the transfer as a new transaction
+	BankStatementActionCreateTransaction = "createTransaction"
+	// BankStatementActionAwaitTransaction is used if the counterparty still has to record the transfer
+	BankStatementActionAwaitTransaction = "awaitTransaction"
+)
+
+// BankStatementEntry A single booked transfer parsed from a bank statement file
+type BankStatementEntry struct {
+	BookingDate      *time.Time
+	Amount           decimal.Decimal // Always positive, the direction is stored separately
+	CurrencyCode     string
+	Direction        string
+	CounterpartyName string
+	RemittanceInfo   string
+}
+
+// BankStatementEntryDTO Data transfer object for a parsed bank statement entry
+type BankStatementEntryDTO struct {
+	BookingDate      string `json:"bookingDate"`
+	Amount           string `json:"amount"`
+	CurrencyCode     string `json:"currency"`
+	Direction        string `json:"direction"`
+	CounterpartyName string `json:"counterpartyName"`
+	RemittanceInfo   string `json:"remittanceInfo"`
+}
+
+// BankStatementMatchDTO A proposed match between a bank statement entry and an open debt or a pending transaction.
+// Matches of type "transaction" can be confirmed with the accept endpoint of the transaction
+type BankStatementMatchDTO struct {
+	Entry           *BankStatementEntryDTO `json:"entry"`
+	MatchType       string                 `json:"matchType"`
+	Confidence      string                 `json:"confidence"`
+	TransactionId   *uuid.UUID             `json:"transactionId,omitempty"`
+	DebtId          *uuid.UUID             `json:"debtId,omitempty"`
+	TripId          *uuid.UUID             `json:"tripId"`
+	Counterparty    *UserDto               `json:"counterparty"`
+	Reference       string                 `json:"reference"`
+	SuggestedAction string                 `json:"suggestedAction"`
+}
+
+// BankStatementImportDTO Data transfer object for the result of a bank statement import
+type BankStatementImportDTO struct {
+	Format    string                   `json:"format"`
+	Entries   int                      `json:"entries"`
+	Matches   []*BankStatementMatchDTO `json:"matches"`
+	Unmatched []*BankStatementEntryDTO `json:"unmatched"`
+}
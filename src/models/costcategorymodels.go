@@ -3,24 +3,55 @@ package models
 import "github.com/google/uuid"
 
 type CostCategoryPostRequest struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Icon        string `json:"icon"`
-	Color       string `json:"color"`
+	Name                  string     `json:"name"`
+	Description           string     `json:"description"`
+	Icon                  string     `json:"icon"`
+	Color                 string     `json:"color"`
+	ParentId              *uuid.UUID `json:"parentId,omitempty"` // Creates a subcategory of the given top-level category
+	Budget                string     `json:"budget,omitempty"`
+	AlertThresholdPercent *int       `json:"alertThresholdPercent,omitempty"`
 }
 
 type CostCategoryPatchRequest struct {
-	Name        string `json:"name,omitempty"`
-	Description string `json:"description,omitempty"`
-	Icon        string `json:"icon,omitempty"`
-	Color       string `json:"color,omitempty"`
+	Name                  string     `json:"name,omitempty"`
+	Description           string     `json:"description,omitempty"`
+	Icon                  string     `json:"icon,omitempty"`
+	Color                 string     `json:"color,omitempty"`
+	ParentId              *uuid.UUID `json:"parentId,omitempty"`
+	RemoveParent          bool       `json:"removeParent,omitempty"` // Turns a subcategory into a top-level category
+	Budget                *string    `json:"budget,omitempty"`       // An empty string removes the budget and its alert threshold
+	AlertThresholdPercent *int       `json:"alertThresholdPercent,omitempty"`
+}
+
+// CostCategoryMergeRequest Request to move all costs of a cost category to another category of the trip
+type CostCategoryMergeRequest struct {
+	TargetCostCategoryId *uuid.UUID `json:"targetCostCategoryId"`
 }
 
 type CostCategoryResponse struct {
-	CostCategoryId *uuid.UUID `json:"costCategoryId"`
-	Name           string     `json:"name"`
-	Description    string     `json:"description"`
-	Icon           string     `json:"icon"`
-	Color          string     `json:"color"`
-	TotalCost      string     `json:"totalCost"`
+	CostCategoryId        *uuid.UUID `json:"costCategoryId"`
+	Name                  string     `json:"name"`
+	Description           string     `json:"description"`
+	Icon                  string     `json:"icon"`
+	Color                 string     `json:"color"`
+	ParentId              *uuid.UUID `json:"parentId,omitempty"`
+	TotalCost             string     `json:"totalCost"` // Includes the costs of the subcategories
+	Budget                string     `json:"budget,omitempty"`
+	AlertThresholdPercent *int       `json:"alertThresholdPercent,omitempty"`
+	Spent                 string     `json:"spent,omitempty"`
+	Remaining             string     `json:"remaining,omitempty"`
+	PercentUsed           string     `json:"percentUsed,omitempty"`
+}
+
+// CostCategoryPresetDTO A set of cost categories a trip can be created with
+type CostCategoryPresetDTO struct {
+	Key        string                       `json:"key"`
+	Name       string                       `json:"name"`
+	Categories []CostCategoryPresetEntryDTO `json:"categories"`
+}
+
+type CostCategoryPresetEntryDTO struct {
+	Name  string `json:"name"`
+	Icon  string `json:"icon"`
+	Color string `json:"color"`
 }
@@ -0,0 +1,40 @@
+package models
+
+import "github.com/google/uuid"
+
+// CostCategoryRuleRequest Request to create or update a cost category rule. A cost matches a rule if its description
+// contains one of the keywords and its amount lies within the amount range. On update, fields that are not set stay unchanged
+type CostCategoryRuleRequest struct {
+	CostCategoryId *uuid.UUID `json:"costCategoryId"`
+	Keywords       []string   `json:"keywords"`            // Matched case-insensitively, an empty list removes all keywords on update
+	MinAmount      *string    `json:"minAmount,omitempty"` // An empty string removes the bound on update
+	MaxAmount      *string    `json:"maxAmount,omitempty"` // An empty string removes the bound on update
+	Priority       *int       `json:"priority,omitempty"`
+	Debtors        []string   `json:"debtors"` // Usernames the cost is split between if it has no contributors
+}
+
+// CostCategoryRuleDTO Data transfer object for cost category rule entries
+type CostCategoryRuleDTO struct {
+	CostCategoryRuleId *uuid.UUID `json:"costCategoryRuleId"`
+	CostCategoryId     *uuid.UUID `json:"costCategoryId"`
+	Keywords           []string   `json:"keywords"`
+	MinAmount          string     `json:"minAmount,omitempty"`
+	MaxAmount          string     `json:"maxAmount,omitempty"`
+	Priority           int        `json:"priority"`
+	Debtors            []string   `json:"debtors"`
+	CreationDate       string     `json:"createdAt"`
+}
+
+// CostCategoryRuleTestDTO Lists the existing costs of the trip a rule matches
+type CostCategoryRuleTestDTO struct {
+	CostCategoryRuleId *uuid.UUID                  `json:"costCategoryRuleId"`
+	CostCategoryId     *uuid.UUID                  `json:"costCategoryId"`
+	MatchedCosts       []*CostCategoryRuleMatchDTO `json:"matchedCosts"`
+}
+
+type CostCategoryRuleMatchDTO struct {
+	CostId         *uuid.UUID `json:"costId"`
+	Description    string     `json:"description"`
+	Amount         string     `json:"amount"`
+	CostCategoryId *uuid.UUID `json:"costCategoryId"` // Current cost category of the cost
+}
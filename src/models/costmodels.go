@@ -4,16 +4,25 @@ import "github.com/google/uuid"
 
 // CostDTO Data transfer object for cost entries
 type CostDTO struct {
-	CostID         *uuid.UUID     `json:"costId"`
-	CostCategoryID *uuid.UUID     `json:"costCategoryId"`
-	Amount         string         `json:"amount"`
-	CurrencyCode   string         `json:"currency"`
-	Description    string         `json:"description"`
-	CreationDate   string         `json:"createdAt"`
-	DeductionDate  string         `json:"deductedAt"`
-	EndDate        string         `json:"endDate"`
-	Creditor       string         `json:"creditor"`
-	Debtors        []*Contributor `json:"contributors"`
+	CostID          *uuid.UUID         `json:"costId"`
+	CostCategoryID  *uuid.UUID         `json:"costCategoryId"`
+	Amount          string             `json:"amount"`
+	CurrencyCode    string             `json:"currency"`
+	Description     string             `json:"description"`
+	CreationDate    string             `json:"createdAt"`
+	DeductionDate   string             `json:"deductedAt"`
+	EndDate         string             `json:"endDate"`
+	Creditor        string             `json:"creditor"`
+	Debtors         []*Contributor     `json:"contributors"`
+	IsPrivate       bool               `json:"private"`         // Private costs are only visible to their creator and do not create debts
+	PaidFromKitty   bool               `json:"paidFromKitty"`   // Costs paid from the kitty have no creditor
+	SplitByPresence bool               `json:"splitByPresence"` // Costs split by presence are shared by the participants present on their dates
+	CreatedBy       string             `json:"createdBy,omitempty"`
+	UpdatedBy       string             `json:"updatedBy,omitempty"`
+	Status          string             `json:"status"` // Costs above the approval threshold of the trip stay pending until all debtors approved
+	Approvals       []*CostApprovalDTO `json:"approvals,omitempty"`
+	Tags            []string           `json:"tags,omitempty"` // Free-form tags across categories, an empty list removes all tags on update
+	CommentCount    int                `json:"commentCount"`
 }
 
 type CostDistributionDTO struct {
@@ -41,8 +50,25 @@ type CostOverviewDTO struct {
 	AverageContributionPercentage string                 `json:"averageContributionPercentage"`
 	TripDistribution              []*TripDistributionDTO `json:"tripDistribution"`
 	CostDistribution              []*CostDistributionDTO `json:"costDistribution"`
+	TagDistribution               []*TagDistributionDTO  `json:"tagDistribution,omitempty"` // Only set if the overview is grouped by tag
 }
 
+type TagDistributionDTO struct {
+	Tag    string `json:"tag"`
+	Amount string `json:"amount"`
+}
+
+const (
+	// CostOverviewGroupByCategory Groups the cost distribution by the category of the costs
+	CostOverviewGroupByCategory = "category"
+
+	// CostOverviewGroupByTopLevelCategory Groups the cost distribution by top-level category, subcategories are rolled up
+	CostOverviewGroupByTopLevelCategory = "topLevelCategory"
+
+	// CostOverviewGroupByTag Groups the cost distribution by tag, costs with several tags count towards each of them
+	CostOverviewGroupByTag = "tag"
+)
+
 type Contributor struct {
 	Username string `json:"username"`
 	Amount   string `json:"amount"`
@@ -55,16 +81,106 @@ type CostQueryParams struct {
 	CostCategoryName *string
 	UserId           *uuid.UUID
 	Username         *string
-	MinAmount        *string // MinAmount steht für die minimale Kostenhöhe
-	MaxAmount        *string // MinAmount steht für die maximale Kostenhöhe
-	MinDeductionDate *string // MinDeductionDate steht für das früheste Datum, an dem die Kosten abgezogen wurden
-	MaxDeductionDate *string // MaxDeductionDate steht für das späteste Datum, an dem die Kosten abgezogen wurden
-	MinEndDate       *string // MinEndDate steht für das früheste Datum, an dem die Kosten enden
-	MaxEndDate       *string // MaxEndDate steht für das späteste Datum, an dem die Kosten enden
-	MinCreationDate  *string // MinCreationDate steht für das früheste Datum, an dem die Kosten erstellt wurden
-	MaxCreationDate  *string // MaxCreationDate steht für das späteste Datum, an dem die Kosten erstellt wurden
-	Page             int     // Page wird für die Paginierung verwendet
-	PageSize         int     // PageSize steht für die Anzahl der Kosten, die pro Seite angezeigt werden
-	SortBy           string  // SortBy steht für die Spalte, nach der die Kosten sortiert werden sollen
-	SortOrder        string  // SortOrder steht für die Reihenfolge, in der die Kosten sortiert werden sollen
+	MinAmount        *string  // MinAmount steht für die minimale Kostenhöhe
+	MaxAmount        *string  // MinAmount steht für die maximale Kostenhöhe
+	MinDeductionDate *string  // MinDeductionDate steht für das früheste Datum, an dem die Kosten abgezogen wurden
+	MaxDeductionDate *string  // MaxDeductionDate steht für das späteste Datum, an dem die Kosten abgezogen wurden
+	MinEndDate       *string  // MinEndDate steht für das früheste Datum, an dem die Kosten enden
+	MaxEndDate       *string  // MaxEndDate steht für das späteste Datum, an dem die Kosten enden
+	MinCreationDate  *string  // MinCreationDate steht für das früheste Datum, an dem die Kosten erstellt wurden
+	MaxCreationDate  *string  // MaxCreationDate steht für das späteste Datum, an dem die Kosten erstellt wurden
+	Tags             []string // Tags enthält die Tags, die alle Kosten haben müssen
+	Page             int      // Page wird für die Paginierung verwendet
+	PageSize         int      // PageSize steht für die Anzahl der Kosten, die pro Seite angezeigt werden
+	SortBy           string   // SortBy steht für die Spalte, nach der die Kosten sortiert werden sollen
+	SortOrder        string   // SortOrder steht für die Reihenfolge, in der die Kosten sortiert werden sollen
+}
+
+const (
+	// CostStatusPending Cost waits for the approval of its debtors and does not affect debts yet
+	CostStatusPending = "pending"
+
+	// CostStatusApproved Cost is booked and affects debts
+	CostStatusApproved = "approved"
+
+	// CostStatusRejected Cost was rejected by at least one debtor and does not affect debts
+	CostStatusRejected = "rejected"
+)
+
+const (
+	// CostSuggestionStatusPending Suggestion waits for a decision of someone allowed to edit the cost
+	CostSuggestionStatusPending = "pending"
+
+	// CostSuggestionStatusApproved Suggestion was applied to the cost
+	CostSuggestionStatusApproved = "approved"
+
+	// CostSuggestionStatusRejected Suggestion was declined and the cost left unchanged
+	CostSuggestionStatusRejected = "rejected"
+)
+
+// CostSuggestionRequest Request to suggest a change to a cost
+type CostSuggestionRequest struct {
+	Changes *CostDTO `json:"changes"`
+	Comment string   `json:"comment"`
+}
+
+// CostSuggestionDTO Data transfer object for a suggested change to a cost
+type CostSuggestionDTO struct {
+	CostSuggestionID *uuid.UUID `json:"costSuggestionId"`
+	CostID           *uuid.UUID `json:"costId"`
+	SuggestedBy      string     `json:"suggestedBy"`
+	Changes          *CostDTO   `json:"changes"`
+	Comment          string     `json:"comment"`
+	Status           string     `json:"status"`
+	CreationDate     string     `json:"createdAt"`
+	DecisionDate     string     `json:"decidedAt,omitempty"`
+	DecidedBy        string     `json:"decidedBy,omitempty"`
+}
+
+// CostCommentRequest Request to comment on a cost. Participants can be mentioned with @username
+type CostCommentRequest struct {
+	Content string `json:"content"`
+}
+
+// CostCommentDTO Data transfer object for a comment on a cost
+type CostCommentDTO struct {
+	CostCommentID *uuid.UUID `json:"costCommentId"`
+	CostID        *uuid.UUID `json:"costId"`
+	Author        string     `json:"author"`
+	Content       string     `json:"content"`
+	CreationDate  string     `json:"createdAt"`
+}
+
+// CostVersionDTO Data transfer object for a version of a cost with the changes compared to the previous version
+type CostVersionDTO struct {
+	CostVersionID *uuid.UUID            `json:"costVersionId"`
+	Version       int                   `json:"version"`
+	ChangedBy     string                `json:"changedBy"`
+	CreationDate  string                `json:"createdAt"`
+	Changes       []*CostFieldChangeDTO `json:"changes"`
+}
+
+// CostFieldChangeDTO A changed field of a cost. Changes of shares name the participant whose share changed
+type CostFieldChangeDTO struct {
+	Field    string `json:"field"`
+	Username string `json:"username,omitempty"`
+	OldValue string `json:"oldValue"`
+	NewValue string `json:"newValue"`
+}
+
+const (
+	CostFieldAmount        = "amount"
+	CostFieldDescription   = "description"
+	CostFieldDeductionDate = "deductedAt"
+	CostFieldEndDate       = "endDate"
+	CostFieldCostCategory  = "costCategoryId"
+	CostFieldCreditor      = "creditor"
+	CostFieldShare         = "share"
+)
+
+// CostApprovalDTO Data transfer object for the decision of a debtor on a cost
+type CostApprovalDTO struct {
+	Username     string `json:"username"`
+	Status       string `json:"status"`
+	DecisionDate string `json:"decidedAt,omitempty"`
 }
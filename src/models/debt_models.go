@@ -12,6 +12,13 @@ type DebtDTO struct {
 	CurrencyCode string       `json:"currency"`
 	CreationDate string       `json:"createdAt"`
 	UpdateDate   string       `json:"updatedAt"`
+	Reference    string       `json:"reference"` // Text to use as remittance information when settling the debt
+}
+
+// DebtForgivenessRequest Request to forgive a debt completely or partially
+type DebtForgivenessRequest struct {
+	Amount string `json:"amount"` // Optional, the whole debt is forgiven if empty
+	Note   string `json:"note"`
 }
 
 // DebtOverviewDTO Data transfer object for the debt overview in the UI
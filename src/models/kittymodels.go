@@ -0,0 +1,52 @@
+package models
+
+import "github.com/google/uuid"
+
+// KittyDTO Data transfer object for the kitty of a trip
+type KittyDTO struct {
+	KittyID       *uuid.UUID        `json:"kittyId"`
+	Username      string            `json:"username"` // Name of the kitty in the debts of the trip
+	Balance       string            `json:"balance"`  // Money that is currently in the kitty, negative if more was spent than deposited
+	TotalDeposits string            `json:"totalDeposits"`
+	TotalSpent    string            `json:"totalSpent"`
+	CreationDate  string            `json:"createdAt"`
+	SettledAt     string            `json:"settledAt,omitempty"`
+	Members       []*KittyMemberDTO `json:"members"`
+	Entries       []*KittyEntryDTO  `json:"entries"`
+}
+
+// KittyMemberDTO The position of a trip participant towards the kitty
+type KittyMemberDTO struct {
+	Username  string `json:"username"`
+	Deposited string `json:"deposited"` // Deposits minus the payout of the settlement
+	Spent     string `json:"spent"`     // Sum of the shares of all approved costs paid from the kitty
+	Balance   string `json:"balance"`   // Deposited minus spent, booked as debt between the member and the kitty until the settlement
+}
+
+// KittyEntryDTO A deposit into (positive amount) or a payout from (negative amount) the kitty
+type KittyEntryDTO struct {
+	KittyEntryID *uuid.UUID `json:"kittyEntryId"`
+	Username     string     `json:"username"`
+	Amount       string     `json:"amount"`
+	Description  string     `json:"description"`
+	CreationDate string     `json:"createdAt"`
+}
+
+// KittyDepositRequest Request to pay money into the kitty
+type KittyDepositRequest struct {
+	Amount      string `json:"amount"`
+	Description string `json:"description"`
+}
+
+// KittySettlementDTO Result of settling the kitty at the end of a trip
+type KittySettlementDTO struct {
+	Leftover string            `json:"leftover"` // Negative if the kitty has a shortfall
+	Payouts  []*KittyPayoutDTO `json:"payouts"`
+	Kitty    *KittyDTO         `json:"kitty"`
+}
+
+// KittyPayoutDTO Share of the leftover a member gets back (positive amount) or of the shortfall a member has to pay (negative amount)
+type KittyPayoutDTO struct {
+	Username string `json:"username"`
+	Amount   string `json:"amount"`
+}
@@ -26,8 +26,48 @@ type ResetPasswordConfirmationMail struct {
 	Recipients []string `json:"recipients"`
 }
 
+type BudgetAlertMail struct {
+	Username         string   `json:"username"`
+	TripName         string   `json:"tripName"`
+	CostCategoryName string   `json:"costCategoryName"`
+	Budget           string   `json:"budget"`
+	Spent            string   `json:"spent"`
+	PercentUsed      string   `json:"percentUsed"`
+	Subject          string   `json:"subject"`
+	Recipients       []string `json:"recipients"`
+}
+
 type SendContactMailRequest struct {
 	Email   string `json:"email"`
 	Name    string `json:"name"`
 	Message string `json:"message"`
 }
+
+type TripInviteMail struct {
+	InviterName string   `json:"inviterName"`
+	TripName    string   `json:"tripName"`
+	InviteLink  string   `json:"inviteLink"`
+	Subject     string   `json:"subject"`
+	Recipients  []string `json:"recipients"`
+}
+
+type TripInvitationMail struct {
+	Username    string   `json:"username"`
+	InviterName string   `json:"inviterName"`
+	TripName    string   `json:"tripName"`
+	StartDate   string   `json:"startDate"`
+	EndDate     string   `json:"endDate"`
+	ExpiresAt   string   `json:"expiresAt"`
+	Subject     string   `json:"subject"`
+	Recipients  []string `json:"recipients"`
+}
+
+type CostCommentMentionMail struct {
+	Username        string   `json:"username"`
+	AuthorName      string   `json:"authorName"`
+	TripName        string   `json:"tripName"`
+	CostDescription string   `json:"costDescription"`
+	Comment         string   `json:"comment"`
+	Subject         string   `json:"subject"`
+	Recipients      []string `json:"recipients"`
+}
@@ -18,4 +18,25 @@ const (
 
 	// ParamKeyDebtId is the key for the id in the params
 	ExpenseParamKeyDebtId = "debtId"
+
+	// ParamKeyPlannedCostId is the key for the id in the params
+	ExpenseParamKeyPlannedCostId = "plannedCostId"
+
+	// ParamKeyCostSuggestionId is the key for the id in the params
+	ExpenseParamKeyCostSuggestionId = "costSuggestionId"
+
+	// ParamKeyCostCommentId is the key for the id in the params
+	ExpenseParamKeyCostCommentId = "costCommentId"
+
+	// ParamKeyCostVersionId is the key for the id in the params
+	ExpenseParamKeyCostVersionId = "costVersionId"
+
+	// ParamKeyTripInviteId is the key for the id in the params
+	ExpenseParamKeyTripInviteId = "tripInviteId"
+
+	// ParamKeyTripTemplateId is the key for the id in the params
+	ExpenseParamKeyTripTemplateId = "tripTemplateId"
+
+	// ParamKeyCostCategoryRuleId is the key for the id in the params
+	ExpenseParamKeyCostCategoryRuleId = "costCategoryRuleId"
 )
@@ -0,0 +1,55 @@
+package models
+
+import "github.com/google/uuid"
+
+// PlannedCostDTO Data transfer object for planned cost entries
+type PlannedCostDTO struct {
+	PlannedCostID  *uuid.UUID     `json:"plannedCostId"`
+	CostCategoryID *uuid.UUID     `json:"costCategoryId"`
+	Amount         string         `json:"amount"`
+	Description    string         `json:"description"`
+	CreationDate   string         `json:"createdAt"`
+	Participants   []*Contributor `json:"participants"`
+	LinkedCostIDs  []*uuid.UUID   `json:"linkedCostIds"`
+}
+
+// PlannedCostLinkRequest Request to link actual costs to a planned cost
+type PlannedCostLinkRequest struct {
+	CostIDs []*uuid.UUID `json:"costIds"`
+}
+
+// CostComparisonDTO Data transfer object for the comparison of planned and actual costs of a trip
+type CostComparisonDTO struct {
+	Planned        string                       `json:"planned"`
+	Actual         string                       `json:"actual"`
+	Variance       string                       `json:"variance"` // Actual minus planned, positive if more was spent than planned
+	CostCategories []*CostCategoryComparisonDTO `json:"costCategories"`
+	Participants   []*ParticipantComparisonDTO  `json:"participants"`
+	PlannedCosts   []*PlannedCostComparisonDTO  `json:"plannedCosts"`
+}
+
+// CostCategoryComparisonDTO Planned and actual costs of a cost category
+type CostCategoryComparisonDTO struct {
+	CostCategoryId *uuid.UUID `json:"costCategoryId"`
+	Name           string     `json:"name"`
+	Planned        string     `json:"planned"`
+	Actual         string     `json:"actual"`
+	Variance       string     `json:"variance"`
+}
+
+// ParticipantComparisonDTO Planned and actual shares of a trip participant
+type ParticipantComparisonDTO struct {
+	Username string `json:"username"`
+	Planned  string `json:"planned"`
+	Actual   string `json:"actual"`
+	Variance string `json:"variance"`
+}
+
+// PlannedCostComparisonDTO A planned cost compared to the sum of its linked actual costs
+type PlannedCostComparisonDTO struct {
+	PlannedCostID *uuid.UUID `json:"plannedCostId"`
+	Description   string     `json:"description"`
+	Planned       string     `json:"planned"`
+	Actual        string     `json:"actual"`
+	Variance      string     `json:"variance"`
+}
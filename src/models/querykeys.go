@@ -9,4 +9,12 @@ const (
 	ExpenseQueryParamKeyCostCategoryId = "costCategoryId"
 	// ExpenseQueryKeyFile is the key for the file in the multiform
 	ExpenseQueryKeyFile = "image"
+	// ExpenseQueryKeyStatement is the key for the bank statement file in the multiform
+	ExpenseQueryKeyStatement = "statement"
+	// ExpenseQueryKeyLocale is the key for the language in the query
+	ExpenseQueryKeyLocale = "locale"
+	// ExpenseQueryKeyMoveTo is the key for the cost category the costs are moved to when deleting a cost category
+	ExpenseQueryKeyMoveTo = "moveTo"
+	// ExpenseQueryKeyGroupBy is the key for the grouping of the cost overview
+	ExpenseQueryKeyGroupBy = "groupBy"
 )
@@ -8,31 +8,50 @@ import (
 )
 
 type CostSchema struct {
-	CostID         *uuid.UUID      `json:"costId" db:"id"`
-	Amount         decimal.Decimal `json:"amount" db:"amount"`
-	Description    string          `json:"description" db:"description"`
-	CreationDate   *time.Time      `json:"createdAt" db:"created_at"`
-	DeductionDate  *time.Time      `json:"deductedAt" db:"deducted_at"`
-	EndDate        *time.Time      `json:"endDate,omitempty" db:"end_date"`
-	CostCategoryID *uuid.UUID      `json:"costCategoryId" db:"id_cost_category"`
+	CostID          *uuid.UUID      `json:"costId" db:"id"`
+	Amount          decimal.Decimal `json:"amount" db:"amount"`
+	Description     string          `json:"description" db:"description"`
+	CreationDate    *time.Time      `json:"createdAt" db:"created_at"`
+	DeductionDate   *time.Time      `json:"deductedAt" db:"deducted_at"`
+	EndDate         *time.Time      `json:"endDate,omitempty" db:"end_date"`
+	IsPrivate       bool            `json:"private" db:"is_private"`
+	PaidFromKitty   bool            `json:"paidFromKitty" db:"paid_from_kitty"`
+	SplitByPresence bool            `json:"splitByPresence" db:"split_by_presence"`
+	CostCategoryID  *uuid.UUID      `json:"costCategoryId" db:"id_cost_category"`
+	CreatedBy       *uuid.UUID      `json:"createdBy" db:"created_by"`
+	UpdatedBy       *uuid.UUID      `json:"updatedBy" db:"updated_by"`
+	Status          string          `json:"status" db:"status"`
+	DeletedAt       *time.Time      `json:"deletedAt,omitempty" db:"deleted_at"` // Only set for costs in the trash
 }
 
 type CostCategorySchema struct {
-	CostCategoryID *uuid.UUID `json:"costCategoryId" db:"id"`
-	Name           string     `json:"name" db:"name"`
-	Description    string     `json:"description" db:"description"`
-	Icon           string     `json:"icon" db:"icon"`
-	Color          string     `json:"color" db:"color"`
-	TripID         *uuid.UUID `json:"tripId" db:"id_trip"`
+	CostCategoryID        *uuid.UUID       `json:"costCategoryId" db:"id"`
+	Name                  string           `json:"name" db:"name"`
+	Description           string           `json:"description" db:"description"`
+	Icon                  string           `json:"icon" db:"icon"`
+	Color                 string           `json:"color" db:"color"`
+	TripID                *uuid.UUID       `json:"tripId" db:"id_trip"`
+	ParentID              *uuid.UUID       `json:"parentId" db:"id_parent"` // Subcategories have a top-level category as parent
+	Budget                *decimal.Decimal `json:"budget" db:"budget"`
+	AlertThresholdPercent *int             `json:"alertThresholdPercent" db:"alert_threshold_percent"`
+	DeletedAt             *time.Time       `json:"deletedAt,omitempty" db:"deleted_at"` // Only set for cost categories in the trash
 }
 
 type TripSchema struct {
-	TripID      *uuid.UUID `json:"tripId" db:"id"`
-	Name        string     `json:"name" db:"name"`
-	Description string     `json:"description" db:"description"`
-	Location    string     `json:"location" db:"location"`
-	StartDate   *time.Time `json:"startDate" db:"start_date"`
-	EndDate     *time.Time `json:"endDate" db:"end_date"`
+	TripID      *uuid.UUID       `json:"tripId" db:"id"`
+	Name        string           `json:"name" db:"name"`
+	Description string           `json:"description" db:"description"`
+	Location    string           `json:"location" db:"location"`
+	StartDate   *time.Time       `json:"startDate" db:"start_date"`
+	EndDate     *time.Time       `json:"endDate" db:"end_date"`
+	Budget      *decimal.Decimal `json:"budget" db:"budget"`
+
+	// Costs above the approval threshold have to be approved by their debtors before they create debts
+	ApprovalThreshold *decimal.Decimal `json:"approvalThreshold" db:"approval_threshold"`
+
+	Status    string     `json:"status" db:"status"`
+	ClosedAt  *time.Time `json:"closedAt" db:"closed_at"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty" db:"deleted_at"` // Only set for trips in the trash
 }
 
 type UserSchema struct {
@@ -47,6 +66,7 @@ type UserSchema struct {
 	ProfilePicture string     `json:"profilePicture" db:"profile_pic"`
 	Birthday       *time.Time `json:"birthday" db:"birthday"`
 	CreatedAt      *time.Time `json:"createdAt" db:"created_at"`
+	IsGuest        bool       `json:"isGuest" db:"is_guest"` // Guests are placeholders for trip participants without an account
 }
 
 type TokenSchema struct {
@@ -68,11 +88,16 @@ type CostContributionSchema struct {
 }
 
 type UserTripSchema struct {
-	UserID            *uuid.UUID `json:"id_user" db:"id_user"`
-	TripID            *uuid.UUID `json:"id_trip" db:"id_trip"`
-	HasAccepted       bool       `json:"accepted" db:"is_accepted"`
-	PresenceStartDate *time.Time `json:"startDate" db:"presence_start_date"`
-	PresenceEndDate   *time.Time `json:"endDate" db:"presence_end_date"`
+	UserID               *uuid.UUID `json:"id_user" db:"id_user"`
+	TripID               *uuid.UUID `json:"id_trip" db:"id_trip"`
+	HasAccepted          bool       `json:"accepted" db:"is_accepted"`
+	Role                 string     `json:"role" db:"role"`
+	PresenceStartDate    *time.Time `json:"startDate" db:"presence_start_date"`
+	PresenceEndDate      *time.Time `json:"endDate" db:"presence_end_date"`
+	InvitedBy            *uuid.UUID `json:"invitedBy" db:"invited_by"`
+	InvitationDate       *time.Time `json:"invitedAt" db:"invited_at"`
+	InvitationExpiryDate *time.Time `json:"inviteExpiresAt" db:"invite_expires_at"`
+	ReminderDate         *time.Time `json:"inviteRemindedAt" db:"invite_reminded_at"`
 }
 
 type DebtSchema struct {
@@ -95,4 +120,178 @@ type TransactionSchema struct {
 	CreationDate  *time.Time      `json:"createdAt" db:"created_at"`
 	CurrencyCode  string          `json:"currency" db:"currency_code"`
 	IsConfirmed   bool            `json:"isConfirmed" db:"is_confirmed"`
+	Type          string          `json:"type" db:"type"`
+	Note          *string         `json:"note" db:"note"`
+}
+
+// PlannedCostSchema A cost that is estimated before the trip. Planned costs do not create any debts
+type PlannedCostSchema struct {
+	PlannedCostID  *uuid.UUID      `json:"plannedCostId" db:"id"`
+	Amount         decimal.Decimal `json:"amount" db:"amount"`
+	Description    string          `json:"description" db:"description"`
+	CreationDate   *time.Time      `json:"createdAt" db:"created_at"`
+	CostCategoryID *uuid.UUID      `json:"costCategoryId" db:"id_cost_category"`
+}
+
+// PlannedCostContributionSchema The planned share of a user for a planned cost
+type PlannedCostContributionSchema struct {
+	UserID        *uuid.UUID      `json:"userId" db:"id_user"`
+	PlannedCostID *uuid.UUID      `json:"plannedCostId" db:"id_planned_cost"`
+	Amount        decimal.Decimal `json:"amount" db:"amount"`
+}
+
+// KittySchema The shared pot of a trip. A trip has at most one kitty
+type KittySchema struct {
+	KittyID      *uuid.UUID `json:"kittyId" db:"id"`
+	TripID       *uuid.UUID `json:"tripId" db:"id_trip"`
+	UserID       *uuid.UUID `json:"userId" db:"id_user"` // Virtual participant that holds the debts between the kitty and the members
+	CreationDate *time.Time `json:"createdAt" db:"created_at"`
+	SettledAt    *time.Time `json:"settledAt" db:"settled_at"`
+}
+
+// KittyEntrySchema Money that was paid into (positive amount) or out of (negative amount) the kitty
+type KittyEntrySchema struct {
+	KittyEntryID *uuid.UUID      `json:"kittyEntryId" db:"id"`
+	KittyID      *uuid.UUID      `json:"kittyId" db:"id_kitty"`
+	UserID       *uuid.UUID      `json:"userId" db:"id_user"`
+	Amount       decimal.Decimal `json:"amount" db:"amount"`
+	Description  string          `json:"description" db:"description"`
+	CreationDate *time.Time      `json:"createdAt" db:"created_at"`
+}
+
+// KittyBalanceSchema The position of a user towards the kitty, summed up from the entries of the user and their shares
+// of the approved costs paid from the kitty. The difference is booked as debt between the user and the kitty
+type KittyBalanceSchema struct {
+	UserID    *uuid.UUID      `json:"userId" db:"id_user"`
+	Deposited decimal.Decimal `json:"deposited" db:"deposited"`
+	Spent     decimal.Decimal `json:"spent" db:"spent"`
+}
+
+// CostSuggestionSchema A change to a cost proposed by a participant who is not allowed to edit the cost directly
+type CostSuggestionSchema struct {
+	CostSuggestionID *uuid.UUID `json:"costSuggestionId" db:"id"`
+	CostID           *uuid.UUID `json:"costId" db:"id_cost"`
+	UserID           *uuid.UUID `json:"userId" db:"id_user"`
+	Changes          *CostDTO   `json:"changes" db:"changes"`
+	Comment          string     `json:"comment" db:"comment"`
+	Status           string     `json:"status" db:"status"`
+	CreationDate     *time.Time `json:"createdAt" db:"created_at"`
+	DecisionDate     *time.Time `json:"decidedAt" db:"decided_at"`
+	DecidedBy        *uuid.UUID `json:"decidedBy" db:"decided_by"`
+}
+
+// CostApprovalSchema The decision of a debtor on a cost above the approval threshold of the trip
+type CostApprovalSchema struct {
+	CostID       *uuid.UUID `json:"costId" db:"id_cost"`
+	UserID       *uuid.UUID `json:"userId" db:"id_user"`
+	Status       string     `json:"status" db:"status"`
+	DecisionDate *time.Time `json:"decidedAt" db:"decided_at"`
+}
+
+// TripInviteSchema An invitation to a trip that can be redeemed with its signed token. Invites without an email are
+// shareable links that can be used until they expire or are revoked, invites with an email can only be used once
+type TripInviteSchema struct {
+	TripInviteID *uuid.UUID `json:"tripInviteId" db:"id"`
+	TripID       *uuid.UUID `json:"tripId" db:"id_trip"`
+	InviterID    *uuid.UUID `json:"inviterId" db:"id_inviter"`
+	GuestID      *uuid.UUID `json:"guestId" db:"id_guest"` // Guest participant that is claimed by the user accepting the invite
+	Email        *string    `json:"email" db:"email"`
+	Token        string     `json:"token" db:"token"`
+	Role         string     `json:"role" db:"role"`
+	CreationDate *time.Time `json:"createdAt" db:"created_at"`
+	ExpiryDate   *time.Time `json:"expiresAt" db:"expires_at"`
+	AcceptDate   *time.Time `json:"acceptedAt" db:"accepted_at"`
+	RevokeDate   *time.Time `json:"revokedAt" db:"revoked_at"`
+}
+
+// TripTemplateSchema A saved trip setup a user can create new trips from. Templates never contain costs
+type TripTemplateSchema struct {
+	TripTemplateID    *uuid.UUID       `json:"tripTemplateId" db:"id"`
+	UserID            *uuid.UUID       `json:"userId" db:"id_user"`
+	Name              string           `json:"name" db:"name"`
+	Description       string           `json:"description" db:"description"`
+	Location          string           `json:"location" db:"location"`
+	Budget            *decimal.Decimal `json:"budget" db:"budget"`
+	ApprovalThreshold *decimal.Decimal `json:"approvalThreshold" db:"approval_threshold"`
+	CreationDate      *time.Time       `json:"createdAt" db:"created_at"`
+}
+
+// TripTemplateCostCategorySchema A cost category that is created for every trip created from the template
+type TripTemplateCostCategorySchema struct {
+	TripTemplateCostCategoryID *uuid.UUID       `json:"tripTemplateCostCategoryId" db:"id"`
+	TripTemplateID             *uuid.UUID       `json:"tripTemplateId" db:"id_trip_template"`
+	Name                       string           `json:"name" db:"name"`
+	Description                string           `json:"description" db:"description"`
+	Icon                       string           `json:"icon" db:"icon"`
+	Color                      string           `json:"color" db:"color"`
+	Budget                     *decimal.Decimal `json:"budget" db:"budget"`
+	AlertThresholdPercent      *int             `json:"alertThresholdPercent" db:"alert_threshold_percent"`
+	ParentName                 *string          `json:"parentName" db:"parent_name"` // Name of the parent category within the template
+}
+
+// TripTemplateParticipantSchema A user that is invited to every trip created from the template
+type TripTemplateParticipantSchema struct {
+	TripTemplateID *uuid.UUID `json:"tripTemplateId" db:"id_trip_template"`
+	UserID         *uuid.UUID `json:"userId" db:"id_user"`
+	Role           string     `json:"role" db:"role"`
+}
+
+// CostCategoryRuleSchema A rule that assigns costs without a cost category to a category of the trip.
+// The keywords and the default debtors of a rule are stored in separate tables
+type CostCategoryRuleSchema struct {
+	CostCategoryRuleID *uuid.UUID       `json:"costCategoryRuleId" db:"id"`
+	TripID             *uuid.UUID       `json:"tripId" db:"id_trip"`
+	CostCategoryID     *uuid.UUID       `json:"costCategoryId" db:"id_cost_category"`
+	Priority           int              `json:"priority" db:"priority"` // Rules with a lower priority are checked first
+	MinAmount          *decimal.Decimal `json:"minAmount" db:"min_amount"`
+	MaxAmount          *decimal.Decimal `json:"maxAmount" db:"max_amount"`
+	CreationDate       *time.Time       `json:"createdAt" db:"created_at"`
+}
+
+// CostCommentSchema A comment of a trip participant on a cost
+type CostCommentSchema struct {
+	CostCommentID *uuid.UUID `json:"costCommentId" db:"id"`
+	CostID        *uuid.UUID `json:"costId" db:"id_cost"`
+	UserID        *uuid.UUID `json:"userId" db:"id_user"`
+	Content       string     `json:"content" db:"content"`
+	CreationDate  *time.Time `json:"createdAt" db:"created_at"`
+}
+
+// CostVersionSchema An immutable state of a cost, stored on every change
+type CostVersionSchema struct {
+	CostVersionID *uuid.UUID           `json:"costVersionId" db:"id"`
+	CostID        *uuid.UUID           `json:"costId" db:"id_cost"`
+	Version       int                  `json:"version" db:"version"`
+	Snapshot      *CostVersionSnapshot `json:"snapshot" db:"snapshot"`
+	CreatedBy     *uuid.UUID           `json:"createdBy" db:"created_by"`
+	CreationDate  *time.Time           `json:"createdAt" db:"created_at"`
+}
+
+// CostVersionSnapshot The state of a cost and its contributions at the time of a version
+type CostVersionSnapshot struct {
+	Amount          decimal.Decimal            `json:"amount"`
+	Description     string                     `json:"description"`
+	DeductionDate   *time.Time                 `json:"deductedAt"`
+	EndDate         *time.Time                 `json:"endDate,omitempty"`
+	CostCategoryID  *uuid.UUID                 `json:"costCategoryId"`
+	PaidFromKitty   bool                       `json:"paidFromKitty"`
+	SplitByPresence bool                       `json:"splitByPresence"`
+	Contributions   []*CostVersionContribution `json:"contributions"`
+}
+
+type CostVersionContribution struct {
+	UserID     *uuid.UUID      `json:"userId"`
+	Amount     decimal.Decimal `json:"amount"`
+	IsCreditor bool            `json:"isCreditor"`
+}
+
+// TripActivitySchema An entry of the activity feed of a trip, recorded from a trip event
+type TripActivitySchema struct {
+	TripActivityID *uuid.UUID        `json:"tripActivityId" db:"id"`
+	TripID         *uuid.UUID        `json:"tripId" db:"id_trip"`
+	UserID         *uuid.UUID        `json:"userId" db:"id_user"`
+	Type           string            `json:"type" db:"type"`
+	SubjectID      *uuid.UUID        `json:"subjectId" db:"id_subject"`
+	Details        *TripEventDetails `json:"details" db:"details"`
+	CreationDate   *time.Time        `json:"createdAt" db:"created_at"`
 }
@@ -2,16 +2,28 @@ package models
 
 import "github.com/google/uuid"
 
+const (
+	// TransactionTypePayment is a payment from the creditor of the transaction to its debtor
+	TransactionTypePayment = "payment"
+
+	// TransactionTypeForgiveness is a debt the debtor of the transaction forgave the creditor of the transaction, no
+	// money changes hands
+	TransactionTypeForgiveness = "forgiveness"
+)
+
 type TransactionDTO struct {
 	TransactionId *uuid.UUID   `json:"transactionId"`
 	Creditor      *UserDto     `json:"creditor"`
 	Debtor        *UserDto     `json:"debtor"`
 	Trip          *SlimTripDTO `json:"trip"`
-	// CreditorId    *uuid.UUID   `json:"creditorId,omitempty"` // Creditor is always context user
-	DebtorId     *uuid.UUID `json:"debtorId,omitempty"`
-	Amount       string     `json:"amount"`
-	CreationDate string     `json:"createdAt"`
-	IsConfirmed  bool       `json:"isConfirmed"`
+	CreditorId    *uuid.UUID   `json:"creditorId,omitempty"` // Defaults to the context user, owners and admins may pay for a guest
+	DebtorId      *uuid.UUID   `json:"debtorId,omitempty"`
+	Amount        string       `json:"amount"`
+	CreationDate  string       `json:"createdAt"`
+	IsConfirmed   bool         `json:"isConfirmed"`
+	Type          string       `json:"type"`
+	Note          string       `json:"note,omitempty"`
+	Reference     string       `json:"reference,omitempty"` // Text to use as remittance information for the bank transfer
 }
 
 type TransactionQueryParams struct {
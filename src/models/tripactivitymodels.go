@@ -0,0 +1,49 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	TripEventMemberJoined         = "memberJoined"
+	TripEventCostAdded            = "costAdded"
+	TripEventCostEdited           = "costEdited"
+	TripEventCostDeleted          = "costDeleted"
+	TripEventCostRestored         = "costRestored"
+	TripEventCostCategoryCreated  = "costCategoryCreated"
+	TripEventCostCategoryRestored = "costCategoryRestored"
+	TripEventPaymentRecorded      = "paymentRecorded"
+	TripEventPaymentConfirmed     = "paymentConfirmed"
+)
+
+// TripEvent A domain event that happened in a trip, published by the controllers after the change was committed
+type TripEvent struct {
+	Type       string
+	TripID     *uuid.UUID
+	UserID     *uuid.UUID // User that caused the event
+	SubjectID  *uuid.UUID // Member, cost, cost category or transaction the event is about
+	Details    *TripEventDetails
+	OccurredAt time.Time
+}
+
+// TripEventDetails What the participants need to know about an event without looking up its subject, which may be gone
+type TripEventDetails struct {
+	Name           string `json:"name,omitempty"`           // Username of the member, description of the cost or name of the cost category
+	Amount         string `json:"amount,omitempty"`         // Amount of the cost or payment
+	PreviousAmount string `json:"previousAmount,omitempty"` // Amount of an edited cost before the change
+	Counterparty   string `json:"counterparty,omitempty"`   // Username of the other participant of a payment
+}
+
+// TripActivityDTO Data transfer object for an entry of the activity feed of a trip
+type TripActivityDTO struct {
+	TripActivityID *uuid.UUID `json:"tripActivityId"`
+	Type           string     `json:"type"`
+	Username       string     `json:"username,omitempty"` // Empty if the user has been deleted
+	SubjectID      *uuid.UUID `json:"subjectId,omitempty"`
+	Name           string     `json:"name,omitempty"`
+	Amount         string     `json:"amount,omitempty"`
+	PreviousAmount string     `json:"previousAmount,omitempty"`
+	Counterparty   string     `json:"counterparty,omitempty"`
+	CreationDate   string     `json:"createdAt"`
+}
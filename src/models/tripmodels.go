@@ -4,24 +4,80 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	// TripRoleOwner can do everything in a trip, including deleting it. Every trip has exactly one owner
+	TripRoleOwner = "owner"
+
+	// TripRoleAdmin can manage the trip, its participants and all costs
+	TripRoleAdmin = "admin"
+
+	// TripRoleMember can add costs and edit the costs they created or paid for
+	TripRoleMember = "member"
+
+	// TripRoleViewer can only read the trip
+	TripRoleViewer = "viewer"
+)
+
+const (
+	// TripPermissionEditTrip allows updating the trip details
+	TripPermissionEditTrip = "trip:edit"
+
+	// TripPermissionDeleteTrip allows deleting the trip
+	TripPermissionDeleteTrip = "trip:delete"
+
+	// TripPermissionManageParticipants allows inviting users and changing the roles of participants
+	TripPermissionManageParticipants = "participants:manage"
+
+	// TripPermissionManageCostCategories allows creating, updating and deleting cost categories
+	TripPermissionManageCostCategories = "cost-categories:manage"
+
+	// TripPermissionWriteCosts allows creating costs and editing the own costs, transactions and kitty deposits
+	TripPermissionWriteCosts = "costs:write"
+
+	// TripPermissionManageCosts allows editing and deleting the costs of all participants and settling the kitty
+	TripPermissionManageCosts = "costs:manage"
+)
+
+const (
+	// TripStatusActive trips can be changed freely
+	TripStatusActive = "active"
+
+	// TripStatusSettling trips no longer accept cost and cost category changes, debts can still be settled
+	TripStatusSettling = "settling"
+
+	// TripStatusClosed trips have settled all debts, their finances are locked
+	TripStatusClosed = "closed"
+
+	// TripStatusArchived trips are closed trips that are hidden from the trip overview
+	TripStatusArchived = "archived"
+)
+
 type TripDTO struct {
-	TripID         *uuid.UUID             `json:"tripId"`
-	Name           string                 `json:"name"`
-	Description    string                 `json:"description"`
-	Location       string                 `json:"location"`
-	StartDate      string                 `json:"startDate"`
-	EndDate        string                 `json:"endDate"`
-	TotalCost      string                 `json:"totalCost"`
-	UserDebt       string                 `json:"userDebt"`   // How much the user owes
-	UserCredit     string                 `json:"userCredit"` // How much the user is owed
-	CostCategories []CostCategoryResponse `json:"costCategories"`
-	Participants   []TripParticipationDTO `json:"participants"`
+	TripID            *uuid.UUID             `json:"tripId"`
+	Name              string                 `json:"name"`
+	Description       string                 `json:"description"`
+	Location          string                 `json:"location"`
+	StartDate         string                 `json:"startDate"`
+	EndDate           string                 `json:"endDate"`
+	TotalCost         string                 `json:"totalCost"`
+	Budget            *string                `json:"budget,omitempty"`            // An empty string removes the budget
+	ApprovalThreshold *string                `json:"approvalThreshold,omitempty"` // An empty string removes the approval threshold
+	Status            string                 `json:"status"`
+	ClosedAt          string                 `json:"closedAt,omitempty"`
+	UserDebt          string                 `json:"userDebt"`   // How much the user owes
+	UserCredit        string                 `json:"userCredit"` // How much the user is owed
+	CostCategories    []CostCategoryResponse `json:"costCategories"`
+	Participants      []TripParticipationDTO `json:"participants"`
+	CategoryPreset    string                 `json:"categoryPreset,omitempty"` // Key of the cost category preset the trip is created with
+	Locale            string                 `json:"locale,omitempty"`         // Language of the preset category names, defaults to english
 }
 
 type TripParticipationDTO struct {
 	UserID            *uuid.UUID `json:"userId"`
 	Username          string     `json:"username"`
 	HasAcceptedInvite bool       `json:"hasAcceptedInvite"`
+	IsGuest           bool       `json:"isGuest"`
+	Role              string     `json:"role"`
 	PresenceStartDate string     `json:"presenceStartDate"`
 	PresenceEndDate   string     `json:"presenceEndDate"`
 }
@@ -34,3 +90,144 @@ type SlimTripDTO struct {
 	StartDate   string     `json:"startDate"`
 	EndDate     string     `json:"endDate"`
 }
+
+// TripForecastDTO Data transfer object for the spending forecast of a trip
+type TripForecastDTO struct {
+	TripID               *uuid.UUID                 `json:"tripId"`
+	StartDate            string                     `json:"startDate"`
+	EndDate              string                     `json:"endDate"`
+	TotalDays            int                        `json:"totalDays"`
+	ElapsedDays          int                        `json:"elapsedDays"`
+	RemainingDays        int                        `json:"remainingDays"`
+	Participants         int                        `json:"participants"`
+	TotalCost            string                     `json:"totalCost"`
+	PreTripCost          string                     `json:"preTripCost"` // Costs deducted before the trip started, e.g. prepaid flights, are not part of the burn rate
+	DailyBurnRate        string                     `json:"dailyBurnRate"`
+	SpendPerPersonPerDay string                     `json:"spendPerPersonPerDay"`
+	ProjectedTotalCost   string                     `json:"projectedTotalCost"`
+	Budget               string                     `json:"budget,omitempty"`
+	ProjectedRemaining   string                     `json:"projectedRemaining,omitempty"` // Budget minus projected total cost, negative if the budget will be exceeded
+	DailySpending        []*DailySpendingDTO        `json:"dailySpending"`
+	CostCategories       []*CostCategoryForecastDTO `json:"costCategories"`
+}
+
+// DailySpendingDTO Data transfer object for the sum of all costs deducted on a single day
+type DailySpendingDTO struct {
+	Date   string `json:"date"`
+	Amount string `json:"amount"`
+}
+
+// CostCategoryForecastDTO Data transfer object for the spending forecast of a cost category
+type CostCategoryForecastDTO struct {
+	CostCategoryId     *uuid.UUID `json:"costCategoryId"`
+	Name               string     `json:"name"`
+	Spent              string     `json:"spent"`
+	DailyBurnRate      string     `json:"dailyBurnRate"`
+	ProjectedTotalCost string     `json:"projectedTotalCost"`
+	Budget             string     `json:"budget,omitempty"`
+}
+
+// TripRoleRequest Request to change the role of a trip participant
+type TripRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// TripOwnershipTransferRequest Request to hand the ownership of a trip over to another participant
+type TripOwnershipTransferRequest struct {
+	UserID *uuid.UUID `json:"userId"`
+}
+
+// TripInviteRequest Request to create an invite link or to invite an email address without an account
+type TripInviteRequest struct {
+	Email   string     `json:"email"`
+	Role    string     `json:"role"`
+	GuestID *uuid.UUID `json:"guestId"` // Optional guest participant the invited user takes over
+}
+
+// TripInviteDTO Data transfer object for an outstanding trip invite
+type TripInviteDTO struct {
+	TripInviteID *uuid.UUID `json:"tripInviteId"`
+	TripID       *uuid.UUID `json:"tripId"`
+	InvitedBy    string     `json:"invitedBy"`
+	GuestID      *uuid.UUID `json:"guestId,omitempty"`
+	Email        string     `json:"email,omitempty"`
+	Role         string     `json:"role"`
+	Link         string     `json:"link"`
+	Token        string     `json:"token"`
+	CreatedAt    string     `json:"createdAt"`
+	ExpiresAt    string     `json:"expiresAt"`
+}
+
+// TripInviteAcceptRequest Request to join a trip with the token of an invite
+type TripInviteAcceptRequest struct {
+	Token string `json:"token"`
+}
+
+// PendingTripInviteDTO Data transfer object for an invitation the user has not responded to yet
+type PendingTripInviteDTO struct {
+	Trip      SlimTripDTO `json:"trip"`
+	InvitedBy string      `json:"invitedBy,omitempty"`
+	Role      string      `json:"role"`
+	InvitedAt string      `json:"invitedAt,omitempty"`
+	ExpiresAt string      `json:"expiresAt,omitempty"`
+}
+
+// TripGuestRequest Request to add a guest participant without an account to a trip
+type TripGuestRequest struct {
+	Name string `json:"name"`
+}
+
+// TripPresenceRequest Request to change the presence dates of the current user after joining a trip
+type TripPresenceRequest struct {
+	PresenceStartDate string `json:"presenceStartDate"`
+	PresenceEndDate   string `json:"presenceEndDate"`
+	Recalculate       bool   `json:"recalculate"` // Split the costs split by presence again with the new dates
+	Preview           bool   `json:"preview"`     // Only calculate the changes without applying them
+}
+
+// TripPresenceDTO Data transfer object for changed presence dates and their effect on the costs split by presence
+type TripPresenceDTO struct {
+	PresenceStartDate string                      `json:"presenceStartDate"`
+	PresenceEndDate   string                      `json:"presenceEndDate"`
+	Applied           bool                        `json:"applied"`
+	Costs             []*PresenceCostChangeDTO    `json:"costs"`
+	BalanceChanges    []*PresenceBalanceChangeDTO `json:"balanceChanges"`
+}
+
+// PresenceCostChangeDTO Data transfer object for the contributions of a cost before and after splitting it by presence
+type PresenceCostChangeDTO struct {
+	CostID       *uuid.UUID     `json:"costId"`
+	Description  string         `json:"description"`
+	Amount       string         `json:"amount"`
+	Contributors []*Contributor `json:"contributors"`
+	Recalculated []*Contributor `json:"recalculatedContributors"`
+}
+
+// PresenceBalanceChangeDTO Data transfer object for the change of the balance of a participant, positive if the
+// participant has to pay less
+type PresenceBalanceChangeDTO struct {
+	Username string `json:"username"`
+	Amount   string `json:"amount"`
+}
+
+const (
+	TrashItemTypeTrip         = "trip"
+	TrashItemTypeCostCategory = "costCategory"
+	TrashItemTypeCost         = "cost"
+)
+
+// TrashItemDTO Data transfer object for a deleted trip, cost category or cost that can be restored until it is purged
+type TrashItemDTO struct {
+	ID        *uuid.UUID `json:"id"`
+	Type      string     `json:"type"`
+	Name      string     `json:"name"`
+	Amount    string     `json:"amount,omitempty"`
+	DeletedAt string     `json:"deletedAt"`
+	PurgeAt   string     `json:"purgeAt"`
+}
+
+// TripStatusRequest Request to move a trip to another lifecycle status
+type TripStatusRequest struct {
+	Status        string `json:"status"`
+	WriteOffDebts bool   `json:"writeOffDebts"` // Close the trip even though debts are still open by writing them off
+}
@@ -0,0 +1,51 @@
+package models
+
+import "github.com/google/uuid"
+
+// TripCloneRequest Request to create a new trip from an existing trip or a trip template. Name, description and location
+// default to the ones of the source
+type TripCloneRequest struct {
+	Name                string `json:"name"`
+	Description         string `json:"description"`
+	Location            string `json:"location"`
+	StartDate           string `json:"startDate"`
+	EndDate             string `json:"endDate"`
+	IncludeParticipants bool   `json:"includeParticipants"` // Invite the participants of the source again
+}
+
+// TripTemplateRequest Request to save a trip as template
+type TripTemplateRequest struct {
+	Name                string `json:"name"` // Defaults to the name of the trip
+	IncludeParticipants bool   `json:"includeParticipants"`
+}
+
+// TripTemplateDTO Data transfer object for a saved trip template
+type TripTemplateDTO struct {
+	TripTemplateID    *uuid.UUID                     `json:"tripTemplateId"`
+	Name              string                         `json:"name"`
+	Description       string                         `json:"description"`
+	Location          string                         `json:"location"`
+	Budget            string                         `json:"budget,omitempty"`
+	ApprovalThreshold string                         `json:"approvalThreshold,omitempty"`
+	CreatedAt         string                         `json:"createdAt"`
+	CostCategories    []*TripTemplateCostCategoryDTO `json:"costCategories"`
+	Participants      []*TripTemplateParticipantDTO  `json:"participants"`
+}
+
+// TripTemplateCostCategoryDTO Data transfer object for a cost category of a trip template
+type TripTemplateCostCategoryDTO struct {
+	Name                  string  `json:"name"`
+	Description           string  `json:"description"`
+	Icon                  string  `json:"icon"`
+	Color                 string  `json:"color"`
+	Budget                string  `json:"budget,omitempty"`
+	AlertThresholdPercent *int    `json:"alertThresholdPercent,omitempty"`
+	ParentName            *string `json:"parentName,omitempty"`
+}
+
+// TripTemplateParticipantDTO Data transfer object for a participant of a trip template
+type TripTemplateParticipantDTO struct {
+	UserID   *uuid.UUID `json:"userId"`
+	Username string     `json:"username"`
+	Role     string     `json:"role"`
+}
@@ -15,6 +15,7 @@ type RegistrationRequest struct {
 	Password       string                `json:"password" form:"password" binding:"required"`
 	Birthday       string                `json:"birthday" form:"birthday" binding:"required"`
 	ProfilePicture *multipart.FileHeader `json:"profilePicture" form:"profilePicture"`
+	InviteToken    string                `json:"inviteToken" form:"inviteToken"`
 }
 
 type LoginRequest struct {
@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"context"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/expense_errors"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/managers"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"log"
+)
+
+type CostApprovalRepo interface {
+	GetCostApproval(ctx context.Context, costId *uuid.UUID, userId *uuid.UUID) (*models.CostApprovalSchema, *models.ExpenseServiceError)
+	GetCostApprovals(ctx context.Context, costId *uuid.UUID) ([]*models.CostApprovalSchema, *models.ExpenseServiceError)
+	AddCostApprovalTx(ctx context.Context, tx pgx.Tx, approval *models.CostApprovalSchema) *models.ExpenseServiceError
+	UpdateCostApprovalTx(ctx context.Context, tx pgx.Tx, approval *models.CostApprovalSchema) *models.ExpenseServiceError
+	DeleteCostApprovalsTx(ctx context.Context, tx pgx.Tx, costId *uuid.UUID) *models.ExpenseServiceError
+	CountPendingCostApprovalsTx(ctx context.Context, tx pgx.Tx, costId *uuid.UUID) (int, *models.ExpenseServiceError)
+}
+
+type CostApprovalRepository struct {
+	DatabaseMgr managers.DatabaseMgr
+}
+
+func (car *CostApprovalRepository) GetCostApproval(ctx context.Context, costId *uuid.UUID, userId *uuid.UUID) (*models.CostApprovalSchema, *models.ExpenseServiceError) {
+	row := car.DatabaseMgr.ExecuteQueryRow(ctx, "SELECT id_cost, id_user, status, decided_at FROM cost_approval WHERE id_cost = $1 AND id_user = $2", costId, userId)
+
+	var approval models.CostApprovalSchema
+	if err := row.Scan(&approval.CostID, &approval.UserID, &approval.Status, &approval.DecisionDate); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, expense_errors.EXPENSE_NOT_FOUND
+		}
+
+		log.Printf("Error while scanning cost approval: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return &approval, nil
+}
+
+func (car *CostApprovalRepository) GetCostApprovals(ctx context.Context, costId *uuid.UUID) ([]*models.CostApprovalSchema, *models.ExpenseServiceError) {
+	rows, err := car.DatabaseMgr.ExecuteQuery(ctx, "SELECT id_cost, id_user, status, decided_at FROM cost_approval WHERE id_cost = $1", costId)
+	if err != nil {
+		log.Printf("Error while querying cost approvals: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	defer rows.Close()
+
+	approvals := make([]*models.CostApprovalSchema, 0)
+	for rows.Next() {
+		var approval models.CostApprovalSchema
+		if err := rows.Scan(&approval.CostID, &approval.UserID, &approval.Status, &approval.DecisionDate); err != nil {
+			log.Printf("Error while scanning cost approval: %v", err)
+			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+		}
+		approvals = append(approvals, &approval)
+	}
+
+	return approvals, nil
+}
+
+func (*CostApprovalRepository) AddCostApprovalTx(ctx context.Context, tx pgx.Tx, approval *models.CostApprovalSchema) *models.ExpenseServiceError {
+	query := "INSERT INTO cost_approval (id_cost, id_user, status, decided_at) VALUES ($1, $2, $3, $4)"
+	if _, err := tx.Exec(ctx, query, approval.CostID, approval.UserID, approval.Status, approval.DecisionDate); err != nil {
+		log.Printf("Error while inserting cost approval: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return nil
+}
+
+// UpdateCostApprovalTx stores the decision of a debtor. Only pending approvals can be decided on
+func (*CostApprovalRepository) UpdateCostApprovalTx(ctx context.Context, tx pgx.Tx, approval *models.CostApprovalSchema) *models.ExpenseServiceError {
+	query := "UPDATE cost_approval SET status = $1, decided_at = $2 WHERE id_cost = $3 AND id_user = $4 AND status = $5"
+	result, err := tx.Exec(ctx, query, approval.Status, approval.DecisionDate, approval.CostID, approval.UserID, models.CostStatusPending)
+	if err != nil {
+		log.Printf("Error while updating cost approval: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	if rowsAffected := result.RowsAffected(); rowsAffected == 0 {
+		return expense_errors.EXPENSE_CONFLICT
+	}
+
+	return nil
+}
+
+func (*CostApprovalRepository) DeleteCostApprovalsTx(ctx context.Context, tx pgx.Tx, costId *uuid.UUID) *models.ExpenseServiceError {
+	if _, err := tx.Exec(ctx, "DELETE FROM cost_approval WHERE id_cost = $1", costId); err != nil {
+		log.Printf("Error while deleting cost approvals: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return nil
+}
+
+// CountPendingCostApprovalsTx returns the number of debtors that have not decided on the cost yet
+func (*CostApprovalRepository) CountPendingCostApprovalsTx(ctx context.Context, tx pgx.Tx, costId *uuid.UUID) (int, *models.ExpenseServiceError) {
+	var count int
+	row := tx.QueryRow(ctx, "SELECT COUNT(*) FROM cost_approval WHERE id_cost = $1 AND status = $2", costId, models.CostStatusPending)
+	if err := row.Scan(&count); err != nil {
+		log.Printf("Error while counting cost approvals: %v", err)
+		return 0, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return count, nil
+}
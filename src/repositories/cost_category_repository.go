@@ -10,14 +10,24 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"log"
+	"time"
 )
 
 type CostCategoryRepo interface {
 	CreateCostCategory(ctx context.Context, costCategory *models.CostCategorySchema) *models.ExpenseServiceError
+	CreateCostCategoryTx(ctx context.Context, tx pgx.Tx, costCategory *models.CostCategorySchema) *models.ExpenseServiceError
 	GetCostCategoryByID(ctx context.Context, uuid *uuid.UUID) (*models.CostCategorySchema, *models.ExpenseServiceError)
+	GetCostCategoryByIDForShareTx(ctx context.Context, tx pgx.Tx, costCategoryId *uuid.UUID) (*models.CostCategorySchema, *models.ExpenseServiceError)
 	GetCostCategoriesByTripID(ctx context.Context, uuid *uuid.UUID) ([]models.CostCategorySchema, *models.ExpenseServiceError)
 	UpdateCostCategory(ctx context.Context, costCategory *models.CostCategorySchema) *models.ExpenseServiceError
-	DeleteCostCategory(ctx context.Context, uuid *uuid.UUID) *models.ExpenseServiceError
+	DeleteCostCategoryTx(ctx context.Context, tx pgx.Tx, costCategoryId *uuid.UUID) *models.ExpenseServiceError
+	CountSubcategoriesTx(ctx context.Context, tx pgx.Tx, costCategoryId *uuid.UUID) (int, *models.ExpenseServiceError)
+	MoveSubcategoriesTx(ctx context.Context, tx pgx.Tx, sourceCostCategoryId *uuid.UUID, targetCostCategoryId *uuid.UUID) *models.ExpenseServiceError
+
+	GetDeletedCostCategoryByID(ctx context.Context, costCategoryId *uuid.UUID) (*models.CostCategorySchema, *models.ExpenseServiceError)
+	GetDeletedCostCategoriesByTripID(ctx context.Context, tripId *uuid.UUID) ([]*models.CostCategorySchema, *models.ExpenseServiceError)
+	RestoreCostCategory(ctx context.Context, costCategoryId *uuid.UUID) *models.ExpenseServiceError
+	PurgeDeletedCostCategories(ctx context.Context, deletedBefore *time.Time) (int64, *models.ExpenseServiceError)
 
 	GetCostCategoryByTripIdAndName(ctx context.Context, tripId *uuid.UUID, name string) (*models.CostCategorySchema, *models.ExpenseServiceError)
 }
@@ -27,7 +37,7 @@ type CostCategoryRepository struct {
 }
 
 func (ccr *CostCategoryRepository) CreateCostCategory(ctx context.Context, costCategory *models.CostCategorySchema) *models.ExpenseServiceError {
-	_, err := ccr.DatabaseMgr.ExecuteStatement(ctx, "INSERT INTO cost_category (id, name, description, icon, color, id_trip) VALUES ($1, $2, $3, $4, $5, $6)", costCategory.CostCategoryID, costCategory.Name, costCategory.Description, costCategory.Icon, costCategory.Color, costCategory.TripID)
+	_, err := ccr.DatabaseMgr.ExecuteStatement(ctx, "INSERT INTO cost_category (id, name, description, icon, color, id_trip, id_parent, budget, alert_threshold_percent) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)", costCategory.CostCategoryID, costCategory.Name, costCategory.Description, costCategory.Icon, costCategory.Color, costCategory.TripID, costCategory.ParentID, costCategory.Budget, costCategory.AlertThresholdPercent)
 	if err != nil {
 		// Check if cost category already exists
 		var pqxErr *pgconn.PgError
@@ -40,11 +50,40 @@ func (ccr *CostCategoryRepository) CreateCostCategory(ctx context.Context, costC
 	return nil
 }
 
+func (*CostCategoryRepository) CreateCostCategoryTx(ctx context.Context, tx pgx.Tx, costCategory *models.CostCategorySchema) *models.ExpenseServiceError {
+	query := "INSERT INTO cost_category (id, name, description, icon, color, id_trip, id_parent, budget, alert_threshold_percent) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)"
+	if _, err := tx.Exec(ctx, query, costCategory.CostCategoryID, costCategory.Name, costCategory.Description, costCategory.Icon, costCategory.Color, costCategory.TripID, costCategory.ParentID, costCategory.Budget, costCategory.AlertThresholdPercent); err != nil {
+		log.Printf("Error while inserting cost category into database: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return nil
+}
+
 func (ccr *CostCategoryRepository) GetCostCategoryByID(ctx context.Context, uuid *uuid.UUID) (*models.CostCategorySchema, *models.ExpenseServiceError) {
 	schema := &models.CostCategorySchema{}
 
-	row := ccr.DatabaseMgr.ExecuteQueryRow(ctx, "SELECT * FROM cost_category WHERE id = $1", uuid)
-	if err := row.Scan(&schema.CostCategoryID, &schema.Name, &schema.Description, &schema.Icon, &schema.Color, &schema.TripID); err != nil {
+	row := ccr.DatabaseMgr.ExecuteQueryRow(ctx, "SELECT id, name, description, icon, color, id_trip, id_parent, budget, alert_threshold_percent FROM cost_category WHERE id = $1 AND deleted_at IS NULL", uuid)
+	if err := row.Scan(&schema.CostCategoryID, &schema.Name, &schema.Description, &schema.Icon, &schema.Color, &schema.TripID, &schema.ParentID, &schema.Budget, &schema.AlertThresholdPercent); err != nil {
+		// Check if no cost category was found
+		if err == pgx.ErrNoRows {
+			return nil, expense_errors.EXPENSE_NOT_FOUND
+		}
+
+		log.Printf("Error while scanning cost category from database: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return schema, nil
+}
+
+// GetCostCategoryByIDForShareTx returns the cost category and share-locks it until the end of the transaction, so it
+// cannot be moved to the trash while costs are assigned to it
+func (*CostCategoryRepository) GetCostCategoryByIDForShareTx(ctx context.Context, tx pgx.Tx, costCategoryId *uuid.UUID) (*models.CostCategorySchema, *models.ExpenseServiceError) {
+	schema := &models.CostCategorySchema{}
+
+	row := tx.QueryRow(ctx, "SELECT id, name, description, icon, color, id_trip, id_parent, budget, alert_threshold_percent FROM cost_category WHERE id = $1 AND deleted_at IS NULL FOR SHARE", costCategoryId)
+	if err := row.Scan(&schema.CostCategoryID, &schema.Name, &schema.Description, &schema.Icon, &schema.Color, &schema.TripID, &schema.ParentID, &schema.Budget, &schema.AlertThresholdPercent); err != nil {
 		// Check if no cost category was found
 		if err == pgx.ErrNoRows {
 			return nil, expense_errors.EXPENSE_NOT_FOUND
@@ -60,7 +99,7 @@ func (ccr *CostCategoryRepository) GetCostCategoryByID(ctx context.Context, uuid
 func (ccr *CostCategoryRepository) GetCostCategoriesByTripID(ctx context.Context, tripId *uuid.UUID) ([]models.CostCategorySchema, *models.ExpenseServiceError) {
 	schemas := make([]models.CostCategorySchema, 0)
 
-	rows, err := ccr.DatabaseMgr.ExecuteQuery(ctx, "SELECT * FROM cost_category WHERE id_trip = $1", tripId)
+	rows, err := ccr.DatabaseMgr.ExecuteQuery(ctx, "SELECT id, name, description, icon, color, id_trip, id_parent, budget, alert_threshold_percent FROM cost_category WHERE id_trip = $1 AND deleted_at IS NULL", tripId)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, expense_errors.EXPENSE_NOT_FOUND
@@ -72,7 +111,7 @@ func (ccr *CostCategoryRepository) GetCostCategoriesByTripID(ctx context.Context
 
 	for rows.Next() {
 		schema := models.CostCategorySchema{}
-		if err := rows.Scan(&schema.CostCategoryID, &schema.Name, &schema.Description, &schema.Icon, &schema.Color, &schema.TripID); err != nil {
+		if err := rows.Scan(&schema.CostCategoryID, &schema.Name, &schema.Description, &schema.Icon, &schema.Color, &schema.TripID, &schema.ParentID, &schema.Budget, &schema.AlertThresholdPercent); err != nil {
 			log.Printf("Error while scanning cost categories from database: %v", err)
 			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
 		}
@@ -83,7 +122,7 @@ func (ccr *CostCategoryRepository) GetCostCategoriesByTripID(ctx context.Context
 }
 
 func (ccr *CostCategoryRepository) UpdateCostCategory(ctx context.Context, costCategory *models.CostCategorySchema) *models.ExpenseServiceError {
-	result, err := ccr.DatabaseMgr.ExecuteStatement(ctx, "UPDATE cost_category SET name = $1, description = $2, icon = $3, color = $4 WHERE id = $5", costCategory.Name, costCategory.Description, costCategory.Icon, costCategory.Color, costCategory.CostCategoryID)
+	result, err := ccr.DatabaseMgr.ExecuteStatement(ctx, "UPDATE cost_category SET name = $1, description = $2, icon = $3, color = $4, id_parent = $5, budget = $6, alert_threshold_percent = $7 WHERE id = $8", costCategory.Name, costCategory.Description, costCategory.Icon, costCategory.Color, costCategory.ParentID, costCategory.Budget, costCategory.AlertThresholdPercent, costCategory.CostCategoryID)
 	if err != nil {
 		// Check if cost category already exists
 		var pgxErr *pgconn.PgError
@@ -101,8 +140,9 @@ func (ccr *CostCategoryRepository) UpdateCostCategory(ctx context.Context, costC
 	return nil
 }
 
-func (ccr *CostCategoryRepository) DeleteCostCategory(ctx context.Context, uuid *uuid.UUID) *models.ExpenseServiceError {
-	result, err := ccr.DatabaseMgr.ExecuteStatement(ctx, "DELETE FROM cost_category WHERE id = $1", uuid)
+// DeleteCostCategoryTx moves the cost category to the trash of its trip, it is removed for good by PurgeDeletedCostCategories
+func (*CostCategoryRepository) DeleteCostCategoryTx(ctx context.Context, tx pgx.Tx, costCategoryId *uuid.UUID) *models.ExpenseServiceError {
+	result, err := tx.Exec(ctx, "UPDATE cost_category SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL", time.Now(), costCategoryId)
 	if err != nil {
 		log.Printf("Error while deleting cost category from database: %v", err)
 		return expense_errors.EXPENSE_INTERNAL_ERROR
@@ -115,11 +155,32 @@ func (ccr *CostCategoryRepository) DeleteCostCategory(ctx context.Context, uuid
 	return nil
 }
 
+// CountSubcategoriesTx counts the categories that have the cost category as parent, subcategories in the trash are not counted
+func (*CostCategoryRepository) CountSubcategoriesTx(ctx context.Context, tx pgx.Tx, costCategoryId *uuid.UUID) (int, *models.ExpenseServiceError) {
+	var count int
+	if err := tx.QueryRow(ctx, "SELECT COUNT(*) FROM cost_category WHERE id_parent = $1 AND deleted_at IS NULL", costCategoryId).Scan(&count); err != nil {
+		log.Printf("Error while counting subcategories: %v", err)
+		return 0, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return count, nil
+}
+
+// MoveSubcategoriesTx assigns the subcategories of the source cost category to the target cost category
+func (*CostCategoryRepository) MoveSubcategoriesTx(ctx context.Context, tx pgx.Tx, sourceCostCategoryId *uuid.UUID, targetCostCategoryId *uuid.UUID) *models.ExpenseServiceError {
+	if _, err := tx.Exec(ctx, "UPDATE cost_category SET id_parent = $1 WHERE id_parent = $2", targetCostCategoryId, sourceCostCategoryId); err != nil {
+		log.Printf("Error while moving subcategories: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return nil
+}
+
 func (ccr *CostCategoryRepository) GetCostCategoryByTripIdAndName(ctx context.Context, tripId *uuid.UUID, name string) (*models.CostCategorySchema, *models.ExpenseServiceError) {
 	schema := &models.CostCategorySchema{}
 
-	row := ccr.DatabaseMgr.ExecuteQueryRow(ctx, "SELECT * FROM cost_category WHERE id_trip = $1 AND name = $2", tripId, name)
-	if err := row.Scan(&schema.CostCategoryID, &schema.Name, &schema.Description, &schema.Icon, &schema.Color, &schema.TripID); err != nil {
+	row := ccr.DatabaseMgr.ExecuteQueryRow(ctx, "SELECT id, name, description, icon, color, id_trip, id_parent, budget, alert_threshold_percent FROM cost_category WHERE id_trip = $1 AND name = $2 AND deleted_at IS NULL", tripId, name)
+	if err := row.Scan(&schema.CostCategoryID, &schema.Name, &schema.Description, &schema.Icon, &schema.Color, &schema.TripID, &schema.ParentID, &schema.Budget, &schema.AlertThresholdPercent); err != nil {
 		// Check if no cost category was found
 		if err == pgx.ErrNoRows {
 			return nil, expense_errors.EXPENSE_NOT_FOUND
@@ -131,3 +192,74 @@ func (ccr *CostCategoryRepository) GetCostCategoryByTripIdAndName(ctx context.Co
 
 	return schema, nil
 }
+
+// GetDeletedCostCategoryByID returns a cost category from the trash
+func (ccr *CostCategoryRepository) GetDeletedCostCategoryByID(ctx context.Context, costCategoryId *uuid.UUID) (*models.CostCategorySchema, *models.ExpenseServiceError) {
+	schema := &models.CostCategorySchema{}
+
+	row := ccr.DatabaseMgr.ExecuteQueryRow(ctx, "SELECT id, name, description, icon, color, id_trip, id_parent, budget, alert_threshold_percent, deleted_at FROM cost_category WHERE id = $1 AND deleted_at IS NOT NULL", costCategoryId)
+	if err := row.Scan(&schema.CostCategoryID, &schema.Name, &schema.Description, &schema.Icon, &schema.Color, &schema.TripID, &schema.ParentID, &schema.Budget, &schema.AlertThresholdPercent, &schema.DeletedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, expense_errors.EXPENSE_NOT_FOUND
+		}
+
+		log.Printf("Error while scanning deleted cost category from database: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return schema, nil
+}
+
+// GetDeletedCostCategoriesByTripID returns the cost categories in the trash of a trip, most recently deleted first
+func (ccr *CostCategoryRepository) GetDeletedCostCategoriesByTripID(ctx context.Context, tripId *uuid.UUID) ([]*models.CostCategorySchema, *models.ExpenseServiceError) {
+	rows, err := ccr.DatabaseMgr.ExecuteQuery(ctx, "SELECT id, name, description, icon, color, id_trip, id_parent, budget, alert_threshold_percent, deleted_at FROM cost_category WHERE id_trip = $1 AND deleted_at IS NOT NULL ORDER BY deleted_at DESC", tripId)
+	if err != nil {
+		log.Printf("Error while querying deleted cost categories from database: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	defer rows.Close()
+
+	schemas := make([]*models.CostCategorySchema, 0)
+	for rows.Next() {
+		schema := &models.CostCategorySchema{}
+		if err := rows.Scan(&schema.CostCategoryID, &schema.Name, &schema.Description, &schema.Icon, &schema.Color, &schema.TripID, &schema.ParentID, &schema.Budget, &schema.AlertThresholdPercent, &schema.DeletedAt); err != nil {
+			log.Printf("Error while scanning deleted cost categories from database: %v", err)
+			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+		}
+		schemas = append(schemas, schema)
+	}
+
+	return schemas, nil
+}
+
+// RestoreCostCategory moves a cost category out of the trash. Restoring fails with a conflict if another cost category
+// of the trip took its name in the meantime
+func (ccr *CostCategoryRepository) RestoreCostCategory(ctx context.Context, costCategoryId *uuid.UUID) *models.ExpenseServiceError {
+	result, err := ccr.DatabaseMgr.ExecuteStatement(ctx, "UPDATE cost_category SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL", costCategoryId)
+	if err != nil {
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) && pgxErr.Code == "23505" {
+			return expense_errors.EXPENSE_CONFLICT
+		}
+		log.Printf("Error while restoring cost category in database: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	if rowsAffected := result.RowsAffected(); rowsAffected == 0 {
+		return expense_errors.EXPENSE_NOT_FOUND
+	}
+
+	return nil
+}
+
+// PurgeDeletedCostCategories removes the cost categories that were deleted before the given date for good, together with
+// the costs still in the trash with them
+func (ccr *CostCategoryRepository) PurgeDeletedCostCategories(ctx context.Context, deletedBefore *time.Time) (int64, *models.ExpenseServiceError) {
+	result, err := ccr.DatabaseMgr.ExecuteStatement(ctx, "DELETE FROM cost_category WHERE deleted_at <= $1", deletedBefore)
+	if err != nil {
+		log.Printf("Error while purging deleted cost categories: %v", err)
+		return 0, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,208 @@
+package repositories
+
+import (
+	"context"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/expense_errors"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/managers"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"log"
+)
+
+type CostCategoryRuleRepo interface {
+	GetCostCategoryRuleByID(ctx context.Context, costCategoryRuleId *uuid.UUID) (*models.CostCategoryRuleSchema, *models.ExpenseServiceError)
+	GetCostCategoryRulesByTripID(ctx context.Context, tripId *uuid.UUID) ([]*models.CostCategoryRuleSchema, *models.ExpenseServiceError)
+	AddCostCategoryRuleTx(ctx context.Context, tx pgx.Tx, costCategoryRule *models.CostCategoryRuleSchema) *models.ExpenseServiceError
+	UpdateCostCategoryRuleTx(ctx context.Context, tx pgx.Tx, costCategoryRule *models.CostCategoryRuleSchema) *models.ExpenseServiceError
+	DeleteCostCategoryRule(ctx context.Context, costCategoryRuleId *uuid.UUID) *models.ExpenseServiceError
+	MoveCostCategoryRulesTx(ctx context.Context, tx pgx.Tx, sourceCostCategoryId *uuid.UUID, targetCostCategoryId *uuid.UUID) *models.ExpenseServiceError
+
+	GetCostCategoryRuleKeywords(ctx context.Context, costCategoryRuleId *uuid.UUID) ([]string, *models.ExpenseServiceError)
+	ReplaceCostCategoryRuleKeywordsTx(ctx context.Context, tx pgx.Tx, costCategoryRuleId *uuid.UUID, keywords []string) *models.ExpenseServiceError
+
+	GetCostCategoryRuleDebtors(ctx context.Context, costCategoryRuleId *uuid.UUID) ([]*uuid.UUID, *models.ExpenseServiceError)
+	ReplaceCostCategoryRuleDebtorsTx(ctx context.Context, tx pgx.Tx, costCategoryRuleId *uuid.UUID, debtorIds []*uuid.UUID) *models.ExpenseServiceError
+}
+
+type CostCategoryRuleRepository struct {
+	DatabaseMgr managers.DatabaseMgr
+}
+
+//********************************************************************************************************************\\
+// Cost Category Rule																								  \\
+//********************************************************************************************************************\\
+
+// GetCostCategoryRuleByID returns a cost category rule by its id
+func (ccrr *CostCategoryRuleRepository) GetCostCategoryRuleByID(ctx context.Context, costCategoryRuleId *uuid.UUID) (*models.CostCategoryRuleSchema, *models.ExpenseServiceError) {
+	costCategoryRule := &models.CostCategoryRuleSchema{}
+
+	row := ccrr.DatabaseMgr.ExecuteQueryRow(ctx, "SELECT id, id_trip, id_cost_category, priority, min_amount, max_amount, created_at FROM cost_category_rule WHERE id = $1", costCategoryRuleId)
+	if err := row.Scan(&costCategoryRule.CostCategoryRuleID, &costCategoryRule.TripID, &costCategoryRule.CostCategoryID, &costCategoryRule.Priority, &costCategoryRule.MinAmount, &costCategoryRule.MaxAmount, &costCategoryRule.CreationDate); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, expense_errors.EXPENSE_NOT_FOUND
+		}
+
+		log.Printf("Error while scanning row: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return costCategoryRule, nil
+}
+
+// GetCostCategoryRulesByTripID returns all rules of a trip in the order they are checked
+func (ccrr *CostCategoryRuleRepository) GetCostCategoryRulesByTripID(ctx context.Context, tripId *uuid.UUID) ([]*models.CostCategoryRuleSchema, *models.ExpenseServiceError) {
+	rows, err := ccrr.DatabaseMgr.ExecuteQuery(ctx, "SELECT id, id_trip, id_cost_category, priority, min_amount, max_amount, created_at FROM cost_category_rule WHERE id_trip = $1 AND id_cost_category IN (SELECT id FROM cost_category WHERE deleted_at IS NULL) ORDER BY priority, created_at", tripId)
+	if err != nil {
+		log.Printf("Error while querying database: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	defer rows.Close()
+
+	costCategoryRules := make([]*models.CostCategoryRuleSchema, 0)
+	for rows.Next() {
+		var costCategoryRule models.CostCategoryRuleSchema
+		if err := rows.Scan(&costCategoryRule.CostCategoryRuleID, &costCategoryRule.TripID, &costCategoryRule.CostCategoryID, &costCategoryRule.Priority, &costCategoryRule.MinAmount, &costCategoryRule.MaxAmount, &costCategoryRule.CreationDate); err != nil {
+			log.Printf("Error while scanning row: %v", err)
+			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+		}
+		costCategoryRules = append(costCategoryRules, &costCategoryRule)
+	}
+
+	return costCategoryRules, nil
+}
+
+func (*CostCategoryRuleRepository) AddCostCategoryRuleTx(ctx context.Context, tx pgx.Tx, costCategoryRule *models.CostCategoryRuleSchema) *models.ExpenseServiceError {
+	query := "INSERT INTO cost_category_rule (id, id_trip, id_cost_category, priority, min_amount, max_amount, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)"
+	if _, err := tx.Exec(ctx, query, costCategoryRule.CostCategoryRuleID, costCategoryRule.TripID, costCategoryRule.CostCategoryID, costCategoryRule.Priority, costCategoryRule.MinAmount, costCategoryRule.MaxAmount, costCategoryRule.CreationDate); err != nil {
+		log.Printf("Error while inserting cost category rule into database: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return nil
+}
+
+func (*CostCategoryRuleRepository) UpdateCostCategoryRuleTx(ctx context.Context, tx pgx.Tx, costCategoryRule *models.CostCategoryRuleSchema) *models.ExpenseServiceError {
+	query := "UPDATE cost_category_rule SET id_cost_category = $1, priority = $2, min_amount = $3, max_amount = $4 WHERE id = $5"
+	result, err := tx.Exec(ctx, query, costCategoryRule.CostCategoryID, costCategoryRule.Priority, costCategoryRule.MinAmount, costCategoryRule.MaxAmount, costCategoryRule.CostCategoryRuleID)
+	if err != nil {
+		log.Printf("Error while updating cost category rule in database: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	if rowsAffected := result.RowsAffected(); rowsAffected == 0 {
+		return expense_errors.EXPENSE_NOT_FOUND
+	}
+
+	return nil
+}
+
+func (ccrr *CostCategoryRuleRepository) DeleteCostCategoryRule(ctx context.Context, costCategoryRuleId *uuid.UUID) *models.ExpenseServiceError {
+	result, err := ccrr.DatabaseMgr.ExecuteStatement(ctx, "DELETE FROM cost_category_rule WHERE id = $1", costCategoryRuleId)
+	if err != nil {
+		log.Printf("Error while deleting cost category rule from database: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	if rowsAffected := result.RowsAffected(); rowsAffected == 0 {
+		return expense_errors.EXPENSE_NOT_FOUND
+	}
+
+	return nil
+}
+
+// MoveCostCategoryRulesTx reassigns all rules of the source cost category to the target cost category
+func (*CostCategoryRuleRepository) MoveCostCategoryRulesTx(ctx context.Context, tx pgx.Tx, sourceCostCategoryId *uuid.UUID, targetCostCategoryId *uuid.UUID) *models.ExpenseServiceError {
+	if _, err := tx.Exec(ctx, "UPDATE cost_category_rule SET id_cost_category = $1 WHERE id_cost_category = $2", targetCostCategoryId, sourceCostCategoryId); err != nil {
+		log.Printf("Error while moving cost category rules to cost category: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return nil
+}
+
+//********************************************************************************************************************\\
+// Cost Category Rule Keyword																						  \\
+//********************************************************************************************************************\\
+
+// GetCostCategoryRuleKeywords returns the keywords of a rule in alphabetical order
+func (ccrr *CostCategoryRuleRepository) GetCostCategoryRuleKeywords(ctx context.Context, costCategoryRuleId *uuid.UUID) ([]string, *models.ExpenseServiceError) {
+	rows, err := ccrr.DatabaseMgr.ExecuteQuery(ctx, "SELECT keyword FROM cost_category_rule_keyword WHERE id_cost_category_rule = $1 ORDER BY keyword", costCategoryRuleId)
+	if err != nil {
+		log.Printf("Error while querying cost category rule keywords: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	defer rows.Close()
+
+	keywords := make([]string, 0)
+	for rows.Next() {
+		var keyword string
+		if err := rows.Scan(&keyword); err != nil {
+			log.Printf("Error while scanning cost category rule keyword: %v", err)
+			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+		}
+		keywords = append(keywords, keyword)
+	}
+
+	return keywords, nil
+}
+
+// ReplaceCostCategoryRuleKeywordsTx replaces all keywords of a rule with the given keywords
+func (*CostCategoryRuleRepository) ReplaceCostCategoryRuleKeywordsTx(ctx context.Context, tx pgx.Tx, costCategoryRuleId *uuid.UUID, keywords []string) *models.ExpenseServiceError {
+	if _, err := tx.Exec(ctx, "DELETE FROM cost_category_rule_keyword WHERE id_cost_category_rule = $1", costCategoryRuleId); err != nil {
+		log.Printf("Error while deleting cost category rule keywords: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	for _, keyword := range keywords {
+		if _, err := tx.Exec(ctx, "INSERT INTO cost_category_rule_keyword (id_cost_category_rule, keyword) VALUES ($1, $2)", costCategoryRuleId, keyword); err != nil {
+			log.Printf("Error while inserting cost category rule keyword: %v", err)
+			return expense_errors.EXPENSE_INTERNAL_ERROR
+		}
+	}
+
+	return nil
+}
+
+//********************************************************************************************************************\\
+// Cost Category Rule Debtor																						  \\
+//********************************************************************************************************************\\
+
+// GetCostCategoryRuleDebtors returns the ids of the users a matched cost is split between by default
+func (ccrr *CostCategoryRuleRepository) GetCostCategoryRuleDebtors(ctx context.Context, costCategoryRuleId *uuid.UUID) ([]*uuid.UUID, *models.ExpenseServiceError) {
+	rows, err := ccrr.DatabaseMgr.ExecuteQuery(ctx, "SELECT id_user FROM cost_category_rule_debtor WHERE id_cost_category_rule = $1", costCategoryRuleId)
+	if err != nil {
+		log.Printf("Error while querying cost category rule debtors: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	defer rows.Close()
+
+	debtorIds := make([]*uuid.UUID, 0)
+	for rows.Next() {
+		var debtorId uuid.UUID
+		if err := rows.Scan(&debtorId); err != nil {
+			log.Printf("Error while scanning cost category rule debtor: %v", err)
+			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+		}
+		debtorIds = append(debtorIds, &debtorId)
+	}
+
+	return debtorIds, nil
+}
+
+// ReplaceCostCategoryRuleDebtorsTx replaces the default debtors of a rule with the given users
+func (*CostCategoryRuleRepository) ReplaceCostCategoryRuleDebtorsTx(ctx context.Context, tx pgx.Tx, costCategoryRuleId *uuid.UUID, debtorIds []*uuid.UUID) *models.ExpenseServiceError {
+	if _, err := tx.Exec(ctx, "DELETE FROM cost_category_rule_debtor WHERE id_cost_category_rule = $1", costCategoryRuleId); err != nil {
+		log.Printf("Error while deleting cost category rule debtors: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	for _, debtorId := range debtorIds {
+		if _, err := tx.Exec(ctx, "INSERT INTO cost_category_rule_debtor (id_cost_category_rule, id_user) VALUES ($1, $2)", costCategoryRuleId, debtorId); err != nil {
+			log.Printf("Error while inserting cost category rule debtor: %v", err)
+			return expense_errors.EXPENSE_INTERNAL_ERROR
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/expense_errors"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/managers"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"log"
+)
+
+type CostCommentRepo interface {
+	GetCostCommentByID(ctx context.Context, costCommentId *uuid.UUID) (*models.CostCommentSchema, *models.ExpenseServiceError)
+	GetCostCommentsByCostID(ctx context.Context, costId *uuid.UUID) ([]*models.CostCommentSchema, *models.ExpenseServiceError)
+	CountCostComments(ctx context.Context, costId *uuid.UUID) (int, *models.ExpenseServiceError)
+	AddCostComment(ctx context.Context, comment *models.CostCommentSchema) *models.ExpenseServiceError
+	DeleteCostComment(ctx context.Context, costCommentId *uuid.UUID) *models.ExpenseServiceError
+}
+
+type CostCommentRepository struct {
+	DatabaseMgr managers.DatabaseMgr
+}
+
+func (ccr *CostCommentRepository) GetCostCommentByID(ctx context.Context, costCommentId *uuid.UUID) (*models.CostCommentSchema, *models.ExpenseServiceError) {
+	query := "SELECT id, id_cost, id_user, content, created_at FROM cost_comment WHERE id = $1"
+	row := ccr.DatabaseMgr.ExecuteQueryRow(ctx, query, costCommentId)
+
+	var comment models.CostCommentSchema
+	if err := row.Scan(&comment.CostCommentID, &comment.CostID, &comment.UserID, &comment.Content, &comment.CreationDate); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, expense_errors.EXPENSE_NOT_FOUND
+		}
+
+		log.Printf("Error while scanning cost comment: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return &comment, nil
+}
+
+// GetCostCommentsByCostID returns the comments of a cost, oldest first
+func (ccr *CostCommentRepository) GetCostCommentsByCostID(ctx context.Context, costId *uuid.UUID) ([]*models.CostCommentSchema, *models.ExpenseServiceError) {
+	query := "SELECT id, id_cost, id_user, content, created_at FROM cost_comment WHERE id_cost = $1 ORDER BY created_at"
+	rows, err := ccr.DatabaseMgr.ExecuteQuery(ctx, query, costId)
+	if err != nil {
+		log.Printf("Error while querying cost comments: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	defer rows.Close()
+
+	comments := make([]*models.CostCommentSchema, 0)
+	for rows.Next() {
+		var comment models.CostCommentSchema
+		if err := rows.Scan(&comment.CostCommentID, &comment.CostID, &comment.UserID, &comment.Content, &comment.CreationDate); err != nil {
+			log.Printf("Error while scanning cost comment: %v", err)
+			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+		}
+		comments = append(comments, &comment)
+	}
+
+	return comments, nil
+}
+
+func (ccr *CostCommentRepository) CountCostComments(ctx context.Context, costId *uuid.UUID) (int, *models.ExpenseServiceError) {
+	var count int
+	if err := ccr.DatabaseMgr.ExecuteQueryRow(ctx, "SELECT COUNT(*) FROM cost_comment WHERE id_cost = $1", costId).Scan(&count); err != nil {
+		log.Printf("Error while counting cost comments: %v", err)
+		return 0, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return count, nil
+}
+
+func (ccr *CostCommentRepository) AddCostComment(ctx context.Context, comment *models.CostCommentSchema) *models.ExpenseServiceError {
+	query := "INSERT INTO cost_comment (id, id_cost, id_user, content, created_at) VALUES ($1, $2, $3, $4, $5)"
+	if _, err := ccr.DatabaseMgr.ExecuteStatement(ctx, query, comment.CostCommentID, comment.CostID, comment.UserID, comment.Content, comment.CreationDate); err != nil {
+		log.Printf("Error while inserting cost comment: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return nil
+}
+
+func (ccr *CostCommentRepository) DeleteCostComment(ctx context.Context, costCommentId *uuid.UUID) *models.ExpenseServiceError {
+	result, err := ccr.DatabaseMgr.ExecuteStatement(ctx, "DELETE FROM cost_comment WHERE id = $1", costCommentId)
+	if err != nil {
+		log.Printf("Error while deleting cost comment: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	if rowsAffected := result.RowsAffected(); rowsAffected == 0 {
+		return expense_errors.EXPENSE_NOT_FOUND
+	}
+
+	return nil
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/shopspring/decimal"
 	"log"
+	"time"
 )
 
 type CostRepo interface {
@@ -19,11 +20,21 @@ type CostRepo interface {
 	AddTx(ctx context.Context, tx pgx.Tx, cost *models.CostSchema) *models.ExpenseServiceError
 	UpdateTx(ctx context.Context, tx pgx.Tx, cost *models.CostSchema) *models.ExpenseServiceError
 	DeleteTx(ctx context.Context, tx pgx.Tx, costId *uuid.UUID) *models.ExpenseServiceError
+	UpdateCostStatusTx(ctx context.Context, tx pgx.Tx, costId *uuid.UUID, status string) *models.ExpenseServiceError
+	GetCostStatusForUpdateTx(ctx context.Context, tx pgx.Tx, costId *uuid.UUID) (string, *models.ExpenseServiceError)
+	CountPendingCostsByTripIdTx(ctx context.Context, tx pgx.Tx, tripId *uuid.UUID) (int, *models.ExpenseServiceError)
+	CountPendingCostContributionsByUserIdAndTripIdTx(ctx context.Context, tx pgx.Tx, userId *uuid.UUID, tripId *uuid.UUID) (int, *models.ExpenseServiceError)
+	CountCostsByCostCategoryIDTx(ctx context.Context, tx pgx.Tx, costCategoryId *uuid.UUID) (int, *models.ExpenseServiceError)
+	MoveCostsToCostCategoryTx(ctx context.Context, tx pgx.Tx, sourceCostCategoryId *uuid.UUID, targetCostCategoryId *uuid.UUID) *models.ExpenseServiceError
+
+	GetCostTags(ctx context.Context, costId *uuid.UUID) ([]string, *models.ExpenseServiceError)
+	ReplaceCostTagsTx(ctx context.Context, tx pgx.Tx, costId *uuid.UUID, tags []string) *models.ExpenseServiceError
 
 	GetCostsByTripID(ctx context.Context, tripId *uuid.UUID) ([]*models.CostSchema, *models.ExpenseServiceError)
 	GetCostsByTripIDAndContributorID(ctx context.Context, tripId *uuid.UUID, contributorId *uuid.UUID) ([]*models.CostSchema, *models.ExpenseServiceError)
 	GetCostsByContributorID(ctx context.Context, contributorId *uuid.UUID) ([]*models.CostSchema, *models.ExpenseServiceError)
 	GetCostsByCostCategoryID(ctx context.Context, costCategoryId *uuid.UUID) ([]*models.CostSchema, *models.ExpenseServiceError)
+	GetCostsAwaitingApproval(ctx context.Context, tripId *uuid.UUID, userId *uuid.UUID) ([]*models.CostSchema, *models.ExpenseServiceError)
 
 	GetCostContributors(ctx context.Context, costId *uuid.UUID) ([]*models.CostContributionSchema, *models.ExpenseServiceError)
 	AddCostContributor(ctx context.Context, contributor *models.CostContributionSchema) *models.ExpenseServiceError
@@ -37,7 +48,12 @@ type CostRepo interface {
 	GetTotalCostByCostCategoryID(ctx context.Context, costCategoryId *uuid.UUID) (*decimal.Decimal, *models.ExpenseServiceError)
 	DeleteCostContributions(ctx context.Context, costId *uuid.UUID) *models.ExpenseServiceError
 	GetCostsByCostCategoryIDAndContributorID(ctx context.Context, costCategoryId *uuid.UUID, userId *uuid.UUID) ([]*models.CostSchema, *models.ExpenseServiceError)
-	GetCostOverview(ctx context.Context, userId *uuid.UUID) (*models.CostOverviewDTO, *models.ExpenseServiceError)
+	GetCostOverview(ctx context.Context, userId *uuid.UUID, groupBy string) (*models.CostOverviewDTO, *models.ExpenseServiceError)
+
+	GetDeletedCostByID(ctx context.Context, costId *uuid.UUID) (*models.CostSchema, *models.ExpenseServiceError)
+	GetDeletedCostsByTripID(ctx context.Context, tripId *uuid.UUID, userId *uuid.UUID) ([]*models.CostSchema, *models.ExpenseServiceError)
+	RestoreTx(ctx context.Context, tx pgx.Tx, costId *uuid.UUID) *models.ExpenseServiceError
+	PurgeDeletedCosts(ctx context.Context, deletedBefore *time.Time) (int64, *models.ExpenseServiceError)
 }
 
 type CostRepository struct {
@@ -48,18 +64,20 @@ type CostRepository struct {
 // Cost																												  \\
 //********************************************************************************************************************\\
 
-// GetCostOverview returns an overview of all costs
-func (cr *CostRepository) GetCostOverview(ctx context.Context, userId *uuid.UUID) (*models.CostOverviewDTO, *models.ExpenseServiceError) {
+// GetCostOverview returns the cost statistics of the user. The cost distribution is grouped by cost category, top-level
+// cost category or tag. Cost categories are nested one level deep, so the parent of a subcategory is its top-level category
+func (cr *CostRepository) GetCostOverview(ctx context.Context, userId *uuid.UUID, groupBy string) (*models.CostOverviewDTO, *models.ExpenseServiceError) {
 	response := &models.CostOverviewDTO{}
 	var tripDistribution []*models.TripDistributionDTO
 	var costDistribution []*models.CostDistributionDTO
+	var tagDistribution []*models.TagDistributionDTO
 	var mostExpensiveTrip *models.TripNameToIdDTO
 	var leastExpensiveTrip *models.TripNameToIdDTO
 	totalTripCosts := decimal.NewFromInt(0)
 	allCosts := decimal.NewFromInt(0)
 
 	// Get every trip the user is part of
-	rows, err := cr.DatabaseMgr.ExecuteQuery(ctx, "SELECT id, name FROM trip WHERE id IN (SELECT id_trip FROM user_trip_association WHERE id_user = $1)", userId)
+	rows, err := cr.DatabaseMgr.ExecuteQuery(ctx, "SELECT id, name FROM trip WHERE id IN (SELECT id_trip FROM user_trip_association WHERE id_user = $1) AND deleted_at IS NULL", userId)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, expense_errors.EXPENSE_NOT_FOUND
@@ -81,10 +99,10 @@ func (cr *CostRepository) GetCostOverview(ctx context.Context, userId *uuid.UUID
 			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
 		}
 
-		// Get total costs for trip
+		// Get total costs for trip, private costs only count for their creator
 		// The outer COALESCE is needed because the inner COALESCE returns NULL if there are no costs for the trip
-		queryString := "SELECT COALESCE(SUM(COALESCE(amount, 0.0)), 0.0) FROM cost WHERE id_cost_category IN (SELECT id FROM cost_category WHERE id_trip = $1)"
-		row := cr.DatabaseMgr.ExecuteQueryRow(ctx, queryString, tripId)
+		queryString := "SELECT COALESCE(SUM(COALESCE(amount, 0.0)), 0.0) FROM cost WHERE deleted_at IS NULL AND id_cost_category IN (SELECT id FROM cost_category WHERE id_trip = $1) AND (is_private = false OR id IN (SELECT id_cost FROM user_cost_association WHERE id_user = $2))"
+		row := cr.DatabaseMgr.ExecuteQueryRow(ctx, queryString, tripId, userId)
 
 		var allCostsForTrip decimal.Decimal
 		if err := row.Scan(&allCostsForTrip); err != nil {
@@ -95,48 +113,88 @@ func (cr *CostRepository) GetCostOverview(ctx context.Context, userId *uuid.UUID
 
 		// Get total costs for trip that the user is a part of grouped by the cost category
 		// The outer COALESCE is needed because the inner COALESCE returns NULL if there are no costs for the trip
-		queryString = "SELECT COALESCE(SUM(COALESCE(amount, 0.0)), 0.0), id_cost_category FROM cost WHERE id_cost_category IN (SELECT id FROM cost_category WHERE id_trip = $1) AND id IN (SELECT id_cost FROM user_cost_association WHERE id_user = $2) GROUP BY id_cost_category"
-		costRow, costErr := cr.DatabaseMgr.ExecuteQuery(ctx, queryString, tripId, userId)
-
-		if costErr != nil {
-			log.Printf("Error while executing query: %v", costErr)
-			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
-		}
-
-		for costRow.Next() {
-			var costCategoryCosts decimal.Decimal
-			var costCategoryID uuid.UUID
-
-			if err := costRow.Scan(&costCategoryCosts, &costCategoryID); err != nil {
+		switch groupBy {
+		case models.CostOverviewGroupByTag:
+			// A cost can carry several tags, so the trip total is summed up separately
+			queryString = "SELECT COALESCE(SUM(COALESCE(amount, 0.0)), 0.0) FROM cost WHERE deleted_at IS NULL AND id_cost_category IN (SELECT id FROM cost_category WHERE id_trip = $1) AND id IN (SELECT id_cost FROM user_cost_association WHERE id_user = $2)"
+			if err := cr.DatabaseMgr.ExecuteQueryRow(ctx, queryString, tripId, userId).Scan(&tripCosts); err != nil {
 				log.Printf("Error while scanning row: %v", err)
 				return nil, expense_errors.EXPENSE_INTERNAL_ERROR
 			}
 
-			queryString := "SELECT name FROM cost_category WHERE id = $1"
-			nameRow := cr.DatabaseMgr.ExecuteQueryRow(ctx, queryString, costCategoryID)
-			if err != nil {
-				if err == pgx.ErrNoRows {
-					return nil, expense_errors.EXPENSE_NOT_FOUND
-				}
-				log.Printf("Error while executing query: %v", err)
+			queryString = "SELECT COALESCE(SUM(COALESCE(c.amount, 0.0)), 0.0), ct.tag FROM cost c INNER JOIN cost_tag ct ON c.id = ct.id_cost WHERE c.deleted_at IS NULL AND c.id_cost_category IN (SELECT id FROM cost_category WHERE id_trip = $1) AND c.id IN (SELECT id_cost FROM user_cost_association WHERE id_user = $2) GROUP BY ct.tag"
+			tagRow, tagErr := cr.DatabaseMgr.ExecuteQuery(ctx, queryString, tripId, userId)
+			if tagErr != nil {
+				log.Printf("Error while executing query: %v", tagErr)
 				return nil, expense_errors.EXPENSE_INTERNAL_ERROR
 			}
 
-			var costCategoryName string
-			if err := nameRow.Scan(&costCategoryName); err != nil {
-				log.Printf("Error while scanning row: %v", err)
+			for tagRow.Next() {
+				var tagCosts decimal.Decimal
+				var tag string
+
+				if err := tagRow.Scan(&tagCosts, &tag); err != nil {
+					tagRow.Close()
+					log.Printf("Error while scanning row: %v", err)
+					return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+				}
+
+				// Add tag to tag distribution
+				tagDistribution = append(tagDistribution, &models.TagDistributionDTO{
+					Tag:    tag,
+					Amount: tagCosts.String(),
+				})
+			}
+			tagRow.Close()
+		default:
+			// Costs of subcategories are counted towards their top-level category if requested
+			queryString = "SELECT COALESCE(SUM(COALESCE(amount, 0.0)), 0.0), id_cost_category FROM cost WHERE deleted_at IS NULL AND id_cost_category IN (SELECT id FROM cost_category WHERE id_trip = $1) AND id IN (SELECT id_cost FROM user_cost_association WHERE id_user = $2) GROUP BY id_cost_category"
+			if groupBy == models.CostOverviewGroupByTopLevelCategory {
+				queryString = "SELECT COALESCE(SUM(COALESCE(c.amount, 0.0)), 0.0), COALESCE(cc.id_parent, cc.id) FROM cost c INNER JOIN cost_category cc ON c.id_cost_category = cc.id WHERE cc.id_trip = $1 AND c.deleted_at IS NULL AND c.id IN (SELECT id_cost FROM user_cost_association WHERE id_user = $2) GROUP BY COALESCE(cc.id_parent, cc.id)"
+			}
+
+			costRow, costErr := cr.DatabaseMgr.ExecuteQuery(ctx, queryString, tripId, userId)
+
+			if costErr != nil {
+				log.Printf("Error while executing query: %v", costErr)
 				return nil, expense_errors.EXPENSE_INTERNAL_ERROR
 			}
 
-			tripCosts = tripCosts.Add(costCategoryCosts)
+			for costRow.Next() {
+				var costCategoryCosts decimal.Decimal
+				var costCategoryID uuid.UUID
+
+				if err := costRow.Scan(&costCategoryCosts, &costCategoryID); err != nil {
+					log.Printf("Error while scanning row: %v", err)
+					return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+				}
+
+				queryString := "SELECT name FROM cost_category WHERE id = $1"
+				nameRow := cr.DatabaseMgr.ExecuteQueryRow(ctx, queryString, costCategoryID)
+				if err != nil {
+					if err == pgx.ErrNoRows {
+						return nil, expense_errors.EXPENSE_NOT_FOUND
+					}
+					log.Printf("Error while executing query: %v", err)
+					return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+				}
+
+				var costCategoryName string
+				if err := nameRow.Scan(&costCategoryName); err != nil {
+					log.Printf("Error while scanning row: %v", err)
+					return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+				}
+
+				tripCosts = tripCosts.Add(costCategoryCosts)
 
-			// Add cost category to cost distribution
-			costDistribution = append(costDistribution, &models.CostDistributionDTO{
-				CostCategoryName: costCategoryName,
-				Amount:           costCategoryCosts.String(),
-			})
+				// Add cost category to cost distribution
+				costDistribution = append(costDistribution, &models.CostDistributionDTO{
+					CostCategoryName: costCategoryName,
+					Amount:           costCategoryCosts.String(),
+				})
+			}
+			costRow.Close()
 		}
-		costRow.Close()
 
 		// Add trip to trip distribution
 		tripDistribution = append(tripDistribution, &models.TripDistributionDTO{
@@ -166,6 +224,7 @@ func (cr *CostRepository) GetCostOverview(ctx context.Context, userId *uuid.UUID
 
 	response.TripDistribution = tripDistribution
 	response.CostDistribution = costDistribution
+	response.TagDistribution = tagDistribution
 	response.MostExpensiveTrip = mostExpensiveTrip
 	response.LeastExpensiveTrip = leastExpensiveTrip
 	response.TotalCosts = totalTripCosts.String()
@@ -185,8 +244,8 @@ func (cr *CostRepository) GetCostOverview(ctx context.Context, userId *uuid.UUID
 func (cr *CostRepository) GetCostByID(ctx context.Context, costId *uuid.UUID) (*models.CostSchema, *models.ExpenseServiceError) {
 	cost := &models.CostSchema{}
 
-	row := cr.DatabaseMgr.ExecuteQueryRow(ctx, "SELECT id, amount, description, created_at, deducted_at, end_date, id_cost_category FROM cost WHERE id = $1", costId)
-	if err := row.Scan(&cost.CostID, &cost.Amount, &cost.Description, &cost.CreationDate, &cost.DeductionDate, &cost.EndDate, &cost.CostCategoryID); err != nil {
+	row := cr.DatabaseMgr.ExecuteQueryRow(ctx, "SELECT id, amount, description, created_at, deducted_at, end_date, is_private, paid_from_kitty, split_by_presence, id_cost_category, created_by, updated_by, status FROM cost WHERE id = $1 AND deleted_at IS NULL", costId)
+	if err := row.Scan(&cost.CostID, &cost.Amount, &cost.Description, &cost.CreationDate, &cost.DeductionDate, &cost.EndDate, &cost.IsPrivate, &cost.PaidFromKitty, &cost.SplitByPresence, &cost.CostCategoryID, &cost.CreatedBy, &cost.UpdatedBy, &cost.Status); err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, expense_errors.EXPENSE_NOT_FOUND
 		}
@@ -199,8 +258,8 @@ func (cr *CostRepository) GetCostByID(ctx context.Context, costId *uuid.UUID) (*
 }
 
 func (*CostRepository) AddTx(ctx context.Context, tx pgx.Tx, cost *models.CostSchema) *models.ExpenseServiceError {
-	query := "INSERT INTO cost (id, amount, description, created_at, deducted_at, end_date, id_cost_category) VALUES ($1, $2, $3, $4, $5, $6, $7)"
-	_, err := tx.Exec(ctx, query, cost.CostID, cost.Amount, cost.Description, cost.CreationDate, cost.DeductionDate, cost.EndDate, cost.CostCategoryID)
+	query := "INSERT INTO cost (id, amount, description, created_at, deducted_at, end_date, is_private, paid_from_kitty, split_by_presence, id_cost_category, created_by, updated_by, status) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)"
+	_, err := tx.Exec(ctx, query, cost.CostID, cost.Amount, cost.Description, cost.CreationDate, cost.DeductionDate, cost.EndDate, cost.IsPrivate, cost.PaidFromKitty, cost.SplitByPresence, cost.CostCategoryID, cost.CreatedBy, cost.UpdatedBy, cost.Status)
 	if err != nil {
 		var pgxErr *pgconn.PgError
 		if errors.As(err, &pgxErr); pgxErr.Code == "foreign_key_violation" {
@@ -214,8 +273,8 @@ func (*CostRepository) AddTx(ctx context.Context, tx pgx.Tx, cost *models.CostSc
 }
 
 func (*CostRepository) UpdateTx(ctx context.Context, tx pgx.Tx, cost *models.CostSchema) *models.ExpenseServiceError {
-	query := "UPDATE cost SET amount = $1, description = $2, deducted_at = $3, end_date = $4, id_cost_category = $5 WHERE id = $6"
-	result, err := tx.Exec(ctx, query, cost.Amount, cost.Description, cost.DeductionDate, cost.EndDate, cost.CostCategoryID, cost.CostID)
+	query := "UPDATE cost SET amount = $1, description = $2, deducted_at = $3, end_date = $4, split_by_presence = $5, id_cost_category = $6, updated_by = $7, status = $8 WHERE id = $9"
+	result, err := tx.Exec(ctx, query, cost.Amount, cost.Description, cost.DeductionDate, cost.EndDate, cost.SplitByPresence, cost.CostCategoryID, cost.UpdatedBy, cost.Status, cost.CostID)
 	if err != nil {
 		var pgxErr *pgconn.PgError
 		if errors.As(err, &pgxErr); pgxErr.Code == "foreign_key_violation" {
@@ -233,9 +292,10 @@ func (*CostRepository) UpdateTx(ctx context.Context, tx pgx.Tx, cost *models.Cos
 	return nil
 }
 
+// DeleteTx moves the cost to the trash of its trip, it is removed for good by PurgeDeletedCosts
 func (*CostRepository) DeleteTx(ctx context.Context, tx pgx.Tx, costId *uuid.UUID) *models.ExpenseServiceError {
-	query := "DELETE FROM cost WHERE id = $1"
-	result, err := tx.Exec(ctx, query, costId)
+	query := "UPDATE cost SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL"
+	result, err := tx.Exec(ctx, query, time.Now(), costId)
 	if err != nil {
 		log.Printf("Error while deleting cost from database: %v", err)
 		return expense_errors.EXPENSE_INTERNAL_ERROR
@@ -248,9 +308,143 @@ func (*CostRepository) DeleteTx(ctx context.Context, tx pgx.Tx, costId *uuid.UUI
 	return nil
 }
 
-// GetCostsByTripID returns all costs associated with a trip through the cost_category database table
+// GetCostStatusForUpdateTx returns the approval status of a cost and locks the cost until the end of the transaction,
+// so that concurrent decisions on the cost are made one after another
+func (*CostRepository) GetCostStatusForUpdateTx(ctx context.Context, tx pgx.Tx, costId *uuid.UUID) (string, *models.ExpenseServiceError) {
+	var status string
+	if err := tx.QueryRow(ctx, "SELECT status FROM cost WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", costId).Scan(&status); err != nil {
+		if err == pgx.ErrNoRows {
+			return "", expense_errors.EXPENSE_NOT_FOUND
+		}
+
+		log.Printf("Error while locking cost: %v", err)
+		return "", expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return status, nil
+}
+
+// CountPendingCostsByTripIdTx counts the costs of a trip that are still awaiting the approval of their debtors
+func (*CostRepository) CountPendingCostsByTripIdTx(ctx context.Context, tx pgx.Tx, tripId *uuid.UUID) (int, *models.ExpenseServiceError) {
+	query := "SELECT COUNT(*) FROM cost c INNER JOIN cost_category cc ON c.id_cost_category = cc.id WHERE cc.id_trip = $1 AND c.status = $2 AND c.deleted_at IS NULL"
+
+	var count int
+	if err := tx.QueryRow(ctx, query, tripId, models.CostStatusPending).Scan(&count); err != nil {
+		log.Printf("Error while counting pending costs: %v", err)
+		return 0, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return count, nil
+}
+
+// CountPendingCostContributionsByUserIdAndTripIdTx counts the shares of the user in pending costs of a trip, which are
+// not booked as debts until the cost is approved
+func (*CostRepository) CountPendingCostContributionsByUserIdAndTripIdTx(ctx context.Context, tx pgx.Tx, userId *uuid.UUID, tripId *uuid.UUID) (int, *models.ExpenseServiceError) {
+	query := "SELECT COUNT(*) FROM user_cost_association uca INNER JOIN cost c ON uca.id_cost = c.id INNER JOIN cost_category cc ON c.id_cost_category = cc.id " +
+		"WHERE cc.id_trip = $1 AND uca.id_user = $2 AND uca.is_creditor = false AND uca.amount <> 0 AND c.status = $3 AND c.deleted_at IS NULL"
+
+	var count int
+	if err := tx.QueryRow(ctx, query, tripId, userId, models.CostStatusPending).Scan(&count); err != nil {
+		log.Printf("Error while counting cost contributions: %v", err)
+		return 0, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return count, nil
+}
+
+func (*CostRepository) UpdateCostStatusTx(ctx context.Context, tx pgx.Tx, costId *uuid.UUID, status string) *models.ExpenseServiceError {
+	result, err := tx.Exec(ctx, "UPDATE cost SET status = $1 WHERE id = $2", status, costId)
+	if err != nil {
+		log.Printf("Error while updating cost in database: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	if rowsAffected := result.RowsAffected(); rowsAffected == 0 {
+		return expense_errors.EXPENSE_NOT_FOUND
+	}
+
+	return nil
+}
+
+// CountCostsByCostCategoryIDTx counts all costs of a cost category, including private ones. Costs in the trash are not counted
+func (*CostRepository) CountCostsByCostCategoryIDTx(ctx context.Context, tx pgx.Tx, costCategoryId *uuid.UUID) (int, *models.ExpenseServiceError) {
+	var count int
+	if err := tx.QueryRow(ctx, "SELECT COUNT(*) FROM cost WHERE id_cost_category = $1 AND deleted_at IS NULL", costCategoryId).Scan(&count); err != nil {
+		log.Printf("Error while counting costs: %v", err)
+		return 0, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return count, nil
+}
+
+// MoveCostsToCostCategoryTx reassigns all costs of the source cost category to the target cost category. Costs in the trash
+// are moved as well, so that they can still be restored
+func (*CostRepository) MoveCostsToCostCategoryTx(ctx context.Context, tx pgx.Tx, sourceCostCategoryId *uuid.UUID, targetCostCategoryId *uuid.UUID) *models.ExpenseServiceError {
+	if _, err := tx.Exec(ctx, "UPDATE cost SET id_cost_category = $1 WHERE id_cost_category = $2", targetCostCategoryId, sourceCostCategoryId); err != nil {
+		log.Printf("Error while moving costs to cost category: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return nil
+}
+
+// GetCostTags returns the tags of a cost in alphabetical order
+func (cr *CostRepository) GetCostTags(ctx context.Context, costId *uuid.UUID) ([]string, *models.ExpenseServiceError) {
+	rows, err := cr.DatabaseMgr.ExecuteQuery(ctx, "SELECT tag FROM cost_tag WHERE id_cost = $1 ORDER BY tag", costId)
+	if err != nil {
+		log.Printf("Error while querying cost tags: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	defer rows.Close()
+
+	tags := make([]string, 0)
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			log.Printf("Error while scanning cost tag: %v", err)
+			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+// ReplaceCostTagsTx replaces all tags of a cost with the given tags
+func (*CostRepository) ReplaceCostTagsTx(ctx context.Context, tx pgx.Tx, costId *uuid.UUID, tags []string) *models.ExpenseServiceError {
+	if _, err := tx.Exec(ctx, "DELETE FROM cost_tag WHERE id_cost = $1", costId); err != nil {
+		log.Printf("Error while deleting cost tags: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	for _, tag := range tags {
+		if _, err := tx.Exec(ctx, "INSERT INTO cost_tag (id_cost, tag) VALUES ($1, $2)", costId, tag); err != nil {
+			log.Printf("Error while inserting cost tag: %v", err)
+			return expense_errors.EXPENSE_INTERNAL_ERROR
+		}
+	}
+
+	return nil
+}
+
+// GetCostsAwaitingApproval returns all pending costs of a trip the user still has to decide on
+func (cr *CostRepository) GetCostsAwaitingApproval(ctx context.Context, tripId *uuid.UUID, userId *uuid.UUID) ([]*models.CostSchema, *models.ExpenseServiceError) {
+	query := "SELECT c.id, c.amount, c.description, c.created_at, c.deducted_at, c.end_date, c.is_private, c.paid_from_kitty, c.split_by_presence, c.id_cost_category, c.created_by, c.updated_by, c.status FROM cost c " +
+		"INNER JOIN cost_category cc ON c.id_cost_category = cc.id INNER JOIN cost_approval ca ON c.id = ca.id_cost " +
+		"WHERE cc.id_trip = $1 AND ca.id_user = $2 AND ca.status = $3 AND c.status = $3 AND c.deleted_at IS NULL ORDER BY c.created_at"
+	rows, err := cr.DatabaseMgr.ExecuteQuery(ctx, query, tripId, userId, models.CostStatusPending)
+	if err != nil {
+		log.Printf("Error while querying database: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	defer rows.Close()
+
+	return getCostsFromRows(rows)
+}
+
+// GetCostsByTripID returns all shared costs associated with a trip through the cost_category database table
 func (cr *CostRepository) GetCostsByTripID(ctx context.Context, tripId *uuid.UUID) ([]*models.CostSchema, *models.ExpenseServiceError) {
-	rows, err := cr.DatabaseMgr.ExecuteQuery(ctx, "SELECT c.id, c.amount, c.description, c.created_at, c.deducted_at, c.end_date, c.id_cost_category FROM cost c INNER JOIN cost_category cc ON c.id_cost_category = cc.id WHERE cc.id_trip = $1", tripId)
+	rows, err := cr.DatabaseMgr.ExecuteQuery(ctx, "SELECT c.id, c.amount, c.description, c.created_at, c.deducted_at, c.end_date, c.is_private, c.paid_from_kitty, c.split_by_presence, c.id_cost_category, c.created_by, c.updated_by, c.status FROM cost c INNER JOIN cost_category cc ON c.id_cost_category = cc.id WHERE cc.id_trip = $1 AND c.is_private = false AND c.deleted_at IS NULL", tripId)
 	if err != nil {
 		log.Printf("Error while querying database: %v", err)
 		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
@@ -260,9 +454,9 @@ func (cr *CostRepository) GetCostsByTripID(ctx context.Context, tripId *uuid.UUI
 	return getCostsFromRows(rows)
 }
 
-// GetCostsByCostCategoryID returns all costs associated with a cost category
+// GetCostsByCostCategoryID returns all shared costs associated with a cost category
 func (cr *CostRepository) GetCostsByCostCategoryID(ctx context.Context, costCategoryId *uuid.UUID) ([]*models.CostSchema, *models.ExpenseServiceError) {
-	rows, err := cr.DatabaseMgr.ExecuteQuery(ctx, "SELECT id, amount, description, created_at, deducted_at, end_date, id_cost_category FROM cost WHERE id_cost_category = $1", costCategoryId)
+	rows, err := cr.DatabaseMgr.ExecuteQuery(ctx, "SELECT id, amount, description, created_at, deducted_at, end_date, is_private, paid_from_kitty, split_by_presence, id_cost_category, created_by, updated_by, status FROM cost WHERE id_cost_category = $1 AND is_private = false AND deleted_at IS NULL", costCategoryId)
 	if err != nil {
 		log.Printf("Error while querying database: %v", err)
 		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
@@ -274,7 +468,7 @@ func (cr *CostRepository) GetCostsByCostCategoryID(ctx context.Context, costCate
 
 // GetCostsByTripIDAndContributorID returns all costs associated with a trip and a contributor
 func (cr *CostRepository) GetCostsByTripIDAndContributorID(ctx context.Context, tripId *uuid.UUID, contributorId *uuid.UUID) ([]*models.CostSchema, *models.ExpenseServiceError) {
-	rows, err := cr.DatabaseMgr.ExecuteQuery(ctx, "SELECT c.id, c.amount, c.description, c.created_at, c.deducted_at, c.end_date, c.id_cost_category FROM cost c INNER JOIN user_cost_association uca ON c.id = uca.id_cost INNER JOIN cost_category cc ON c.id_cost_category = cc.id WHERE cc.id_trip = $1 AND uca.id_user = $2", tripId, contributorId)
+	rows, err := cr.DatabaseMgr.ExecuteQuery(ctx, "SELECT c.id, c.amount, c.description, c.created_at, c.deducted_at, c.end_date, c.is_private, c.paid_from_kitty, c.split_by_presence, c.id_cost_category, c.created_by, c.updated_by, c.status FROM cost c INNER JOIN user_cost_association uca ON c.id = uca.id_cost INNER JOIN cost_category cc ON c.id_cost_category = cc.id WHERE cc.id_trip = $1 AND uca.id_user = $2 AND c.deleted_at IS NULL", tripId, contributorId)
 	if err != nil {
 		log.Printf("Error while querying database: %v", err)
 		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
@@ -286,7 +480,7 @@ func (cr *CostRepository) GetCostsByTripIDAndContributorID(ctx context.Context,
 
 // GetCostsByCostCategoryIDAndContributorID returns all costs associated with a cost category and a contributor
 func (cr *CostRepository) GetCostsByCostCategoryIDAndContributorID(ctx context.Context, costCategoryId *uuid.UUID, contributorId *uuid.UUID) ([]*models.CostSchema, *models.ExpenseServiceError) {
-	rows, err := cr.DatabaseMgr.ExecuteQuery(ctx, "SELECT c.id, c.amount, c.description, c.created_at, c.deducted_at, c.end_date, c.id_cost_category FROM cost c INNER JOIN user_cost_association uca ON c.id = uca.id_cost WHERE c.id_cost_category = $1 AND uca.id_user = $2", costCategoryId, contributorId)
+	rows, err := cr.DatabaseMgr.ExecuteQuery(ctx, "SELECT c.id, c.amount, c.description, c.created_at, c.deducted_at, c.end_date, c.is_private, c.paid_from_kitty, c.split_by_presence, c.id_cost_category, c.created_by, c.updated_by, c.status FROM cost c INNER JOIN user_cost_association uca ON c.id = uca.id_cost WHERE c.id_cost_category = $1 AND uca.id_user = $2 AND c.deleted_at IS NULL", costCategoryId, contributorId)
 	if err != nil {
 		log.Printf("Error while querying database: %v", err)
 		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
@@ -298,7 +492,7 @@ func (cr *CostRepository) GetCostsByCostCategoryIDAndContributorID(ctx context.C
 
 // GetCostsByContributorID returns all costs associated with a contributor
 func (cr *CostRepository) GetCostsByContributorID(ctx context.Context, contributorId *uuid.UUID) ([]*models.CostSchema, *models.ExpenseServiceError) {
-	rows, err := cr.DatabaseMgr.ExecuteQuery(ctx, "SELECT c.id, c.amount, c.description, c.created_at, c.deducted_at, c.end_date, c.id_cost_category FROM cost c INNER JOIN user_cost_association uca ON c.id = uca.id_cost WHERE uca.id_user = $1", contributorId)
+	rows, err := cr.DatabaseMgr.ExecuteQuery(ctx, "SELECT c.id, c.amount, c.description, c.created_at, c.deducted_at, c.end_date, c.is_private, c.paid_from_kitty, c.split_by_presence, c.id_cost_category, c.created_by, c.updated_by, c.status FROM cost c INNER JOIN user_cost_association uca ON c.id = uca.id_cost WHERE uca.id_user = $1 AND c.deleted_at IS NULL", contributorId)
 	if err != nil {
 		log.Printf("Error while querying database: %v", err)
 		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
@@ -308,6 +502,80 @@ func (cr *CostRepository) GetCostsByContributorID(ctx context.Context, contribut
 	return getCostsFromRows(rows)
 }
 
+//********************************************************************************************************************\\
+// Trash  																											  \\
+//********************************************************************************************************************\\
+
+// GetDeletedCostByID returns a cost from the trash
+func (cr *CostRepository) GetDeletedCostByID(ctx context.Context, costId *uuid.UUID) (*models.CostSchema, *models.ExpenseServiceError) {
+	cost := &models.CostSchema{}
+
+	row := cr.DatabaseMgr.ExecuteQueryRow(ctx, "SELECT id, amount, description, created_at, deducted_at, end_date, is_private, paid_from_kitty, split_by_presence, id_cost_category, created_by, updated_by, status, deleted_at FROM cost WHERE id = $1 AND deleted_at IS NOT NULL", costId)
+	if err := row.Scan(&cost.CostID, &cost.Amount, &cost.Description, &cost.CreationDate, &cost.DeductionDate, &cost.EndDate, &cost.IsPrivate, &cost.PaidFromKitty, &cost.SplitByPresence, &cost.CostCategoryID, &cost.CreatedBy, &cost.UpdatedBy, &cost.Status, &cost.DeletedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, expense_errors.EXPENSE_NOT_FOUND
+		}
+
+		log.Printf("Error while scanning row: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return cost, nil
+}
+
+// GetDeletedCostsByTripID returns the costs in the trash of a trip, most recently deleted first. Private costs are only
+// returned to their creator
+func (cr *CostRepository) GetDeletedCostsByTripID(ctx context.Context, tripId *uuid.UUID, userId *uuid.UUID) ([]*models.CostSchema, *models.ExpenseServiceError) {
+	query := "SELECT c.id, c.amount, c.description, c.created_at, c.deducted_at, c.end_date, c.is_private, c.paid_from_kitty, c.split_by_presence, c.id_cost_category, c.created_by, c.updated_by, c.status, c.deleted_at FROM cost c " +
+		"INNER JOIN cost_category cc ON c.id_cost_category = cc.id " +
+		"WHERE cc.id_trip = $1 AND c.deleted_at IS NOT NULL AND (c.is_private = false OR c.created_by = $2) ORDER BY c.deleted_at DESC"
+	rows, err := cr.DatabaseMgr.ExecuteQuery(ctx, query, tripId, userId)
+	if err != nil {
+		log.Printf("Error while querying database: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	defer rows.Close()
+
+	costs := make([]*models.CostSchema, 0)
+	for rows.Next() {
+		var cost models.CostSchema
+		if err := rows.Scan(&cost.CostID, &cost.Amount, &cost.Description, &cost.CreationDate, &cost.DeductionDate, &cost.EndDate, &cost.IsPrivate, &cost.PaidFromKitty, &cost.SplitByPresence, &cost.CostCategoryID, &cost.CreatedBy, &cost.UpdatedBy, &cost.Status, &cost.DeletedAt); err != nil {
+			log.Printf("Error while scanning row: %v", err)
+			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+		}
+		costs = append(costs, &cost)
+	}
+
+	return costs, nil
+}
+
+// RestoreTx moves a cost out of the trash
+func (*CostRepository) RestoreTx(ctx context.Context, tx pgx.Tx, costId *uuid.UUID) *models.ExpenseServiceError {
+	result, err := tx.Exec(ctx, "UPDATE cost SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL", costId)
+	if err != nil {
+		log.Printf("Error while restoring cost in database: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	if rowsAffected := result.RowsAffected(); rowsAffected == 0 {
+		return expense_errors.EXPENSE_NOT_FOUND
+	}
+
+	return nil
+}
+
+// PurgeDeletedCosts removes the costs that were deleted before the given date for good. Their debts were already taken
+// back when they were deleted
+func (cr *CostRepository) PurgeDeletedCosts(ctx context.Context, deletedBefore *time.Time) (int64, *models.ExpenseServiceError) {
+	result, err := cr.DatabaseMgr.ExecuteStatement(ctx, "DELETE FROM cost WHERE deleted_at <= $1", deletedBefore)
+	if err != nil {
+		log.Printf("Error while purging deleted costs: %v", err)
+		return 0, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return result.RowsAffected(), nil
+}
+
 //********************************************************************************************************************\\
 // Cost Contributor   																								  \\
 //********************************************************************************************************************\\
@@ -422,9 +690,9 @@ func (*CostRepository) DeleteCostContributionTx(ctx context.Context, tx pgx.Tx,
 // Calculation  																									  \\
 //********************************************************************************************************************\\
 
-// GetTotalCostByTripID returns the total cost of a trip
+// GetTotalCostByTripID returns the total shared cost of a trip. Private costs are excluded
 func (cr *CostRepository) GetTotalCostByTripID(ctx context.Context, tripId *uuid.UUID) (*decimal.Decimal, *models.ExpenseServiceError) {
-	row, err := cr.DatabaseMgr.ExecuteQuery(ctx, "SELECT COALESCE(SUM(c.amount),0) FROM cost c INNER JOIN cost_category cc ON c.id_cost_category = cc.id WHERE cc.id_trip = $1", tripId)
+	row, err := cr.DatabaseMgr.ExecuteQuery(ctx, "SELECT COALESCE(SUM(c.amount),0) FROM cost c INNER JOIN cost_category cc ON c.id_cost_category = cc.id WHERE cc.id_trip = $1 AND c.is_private = false AND c.deleted_at IS NULL", tripId)
 	if err != nil {
 		log.Printf("Error while querying database: %v", err)
 		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
@@ -445,10 +713,11 @@ func (cr *CostRepository) GetTotalCostByTripID(ctx context.Context, tripId *uuid
 	return &totalCost, nil
 }
 
-// GetTotalCostByCostCategoryID returns the total cost of a cost category
+// GetTotalCostByCostCategoryID returns the total of the public costs of a cost category, rolled up with the costs of its
+// subcategories. Cost categories are nested one level deep, so subcategories have no subcategories of their own
 func (cr *CostRepository) GetTotalCostByCostCategoryID(ctx context.Context, costCategoryId *uuid.UUID) (*decimal.Decimal, *models.ExpenseServiceError) {
 	var totalCost decimal.Decimal
-	row := cr.DatabaseMgr.ExecuteQueryRow(ctx, "SELECT COALESCE(SUM(amount),0) FROM cost WHERE id_cost_category = $1", costCategoryId)
+	row := cr.DatabaseMgr.ExecuteQueryRow(ctx, "SELECT COALESCE(SUM(amount),0) FROM cost WHERE id_cost_category IN (SELECT id FROM cost_category WHERE id = $1 OR id_parent = $1) AND is_private = false AND deleted_at IS NULL", costCategoryId)
 	err := row.Scan(&totalCost)
 	if err != nil {
 		log.Printf("Error while scanning row: %v", err)
@@ -466,7 +735,7 @@ func getCostsFromRows(rows pgx.Rows) ([]*models.CostSchema, *models.ExpenseServi
 	costs := make([]*models.CostSchema, 0) // Empty slice
 	for rows.Next() {
 		var cost models.CostSchema
-		if err := rows.Scan(&cost.CostID, &cost.Amount, &cost.Description, &cost.CreationDate, &cost.DeductionDate, &cost.EndDate, &cost.CostCategoryID); err != nil {
+		if err := rows.Scan(&cost.CostID, &cost.Amount, &cost.Description, &cost.CreationDate, &cost.DeductionDate, &cost.EndDate, &cost.IsPrivate, &cost.PaidFromKitty, &cost.SplitByPresence, &cost.CostCategoryID, &cost.CreatedBy, &cost.UpdatedBy, &cost.Status); err != nil {
 			log.Printf("Error while scanning row: %v", err)
 			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
 		}
@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"context"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/expense_errors"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/managers"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"log"
+)
+
+type CostSuggestionRepo interface {
+	GetCostSuggestionByID(ctx context.Context, costSuggestionId *uuid.UUID) (*models.CostSuggestionSchema, *models.ExpenseServiceError)
+	GetCostSuggestionsByCostID(ctx context.Context, costId *uuid.UUID) ([]*models.CostSuggestionSchema, *models.ExpenseServiceError)
+	AddCostSuggestion(ctx context.Context, suggestion *models.CostSuggestionSchema) *models.ExpenseServiceError
+	UpdateCostSuggestionStatusTx(ctx context.Context, tx pgx.Tx, suggestion *models.CostSuggestionSchema) *models.ExpenseServiceError
+}
+
+type CostSuggestionRepository struct {
+	DatabaseMgr managers.DatabaseMgr
+}
+
+func (csr *CostSuggestionRepository) GetCostSuggestionByID(ctx context.Context, costSuggestionId *uuid.UUID) (*models.CostSuggestionSchema, *models.ExpenseServiceError) {
+	query := "SELECT id, id_cost, id_user, changes, comment, status, created_at, decided_at, decided_by FROM cost_suggestion WHERE id = $1"
+	row := csr.DatabaseMgr.ExecuteQueryRow(ctx, query, costSuggestionId)
+
+	var suggestion models.CostSuggestionSchema
+	if err := row.Scan(&suggestion.CostSuggestionID, &suggestion.CostID, &suggestion.UserID, &suggestion.Changes, &suggestion.Comment, &suggestion.Status, &suggestion.CreationDate, &suggestion.DecisionDate, &suggestion.DecidedBy); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, expense_errors.EXPENSE_NOT_FOUND
+		}
+
+		log.Printf("Error while scanning cost suggestion: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return &suggestion, nil
+}
+
+// GetCostSuggestionsByCostID returns all suggestions for a cost, the newest first
+func (csr *CostSuggestionRepository) GetCostSuggestionsByCostID(ctx context.Context, costId *uuid.UUID) ([]*models.CostSuggestionSchema, *models.ExpenseServiceError) {
+	query := "SELECT id, id_cost, id_user, changes, comment, status, created_at, decided_at, decided_by FROM cost_suggestion WHERE id_cost = $1 ORDER BY created_at DESC"
+	rows, err := csr.DatabaseMgr.ExecuteQuery(ctx, query, costId)
+	if err != nil {
+		log.Printf("Error while querying cost suggestions: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	defer rows.Close()
+
+	suggestions := make([]*models.CostSuggestionSchema, 0)
+	for rows.Next() {
+		var suggestion models.CostSuggestionSchema
+		if err := rows.Scan(&suggestion.CostSuggestionID, &suggestion.CostID, &suggestion.UserID, &suggestion.Changes, &suggestion.Comment, &suggestion.Status, &suggestion.CreationDate, &suggestion.DecisionDate, &suggestion.DecidedBy); err != nil {
+			log.Printf("Error while scanning cost suggestion: %v", err)
+			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+		}
+		suggestions = append(suggestions, &suggestion)
+	}
+
+	return suggestions, nil
+}
+
+func (csr *CostSuggestionRepository) AddCostSuggestion(ctx context.Context, suggestion *models.CostSuggestionSchema) *models.ExpenseServiceError {
+	query := "INSERT INTO cost_suggestion (id, id_cost, id_user, changes, comment, status, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)"
+	if _, err := csr.DatabaseMgr.ExecuteStatement(ctx, query, suggestion.CostSuggestionID, suggestion.CostID, suggestion.UserID, suggestion.Changes, suggestion.Comment, suggestion.Status, suggestion.CreationDate); err != nil {
+		log.Printf("Error while inserting cost suggestion: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return nil
+}
+
+// UpdateCostSuggestionStatusTx stores the decision on a suggestion. Only pending suggestions can be decided on
+func (*CostSuggestionRepository) UpdateCostSuggestionStatusTx(ctx context.Context, tx pgx.Tx, suggestion *models.CostSuggestionSchema) *models.ExpenseServiceError {
+	query := "UPDATE cost_suggestion SET status = $1, decided_at = $2, decided_by = $3 WHERE id = $4 AND status = $5"
+	result, err := tx.Exec(ctx, query, suggestion.Status, suggestion.DecisionDate, suggestion.DecidedBy, suggestion.CostSuggestionID, models.CostSuggestionStatusPending)
+	if err != nil {
+		log.Printf("Error while updating cost suggestion: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	if rowsAffected := result.RowsAffected(); rowsAffected == 0 {
+		return expense_errors.EXPENSE_CONFLICT
+	}
+
+	return nil
+}
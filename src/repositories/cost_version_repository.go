@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"context"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/expense_errors"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/managers"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"log"
+)
+
+type CostVersionRepo interface {
+	GetCostVersionByID(ctx context.Context, costVersionId *uuid.UUID) (*models.CostVersionSchema, *models.ExpenseServiceError)
+	GetCostVersionsByCostID(ctx context.Context, costId *uuid.UUID) ([]*models.CostVersionSchema, *models.ExpenseServiceError)
+	CountCostVersionsTx(ctx context.Context, tx pgx.Tx, costId *uuid.UUID) (int, *models.ExpenseServiceError)
+	AddCostVersionTx(ctx context.Context, tx pgx.Tx, costVersion *models.CostVersionSchema) *models.ExpenseServiceError
+}
+
+type CostVersionRepository struct {
+	DatabaseMgr managers.DatabaseMgr
+}
+
+func (cvr *CostVersionRepository) GetCostVersionByID(ctx context.Context, costVersionId *uuid.UUID) (*models.CostVersionSchema, *models.ExpenseServiceError) {
+	query := "SELECT id, id_cost, version, snapshot, created_by, created_at FROM cost_version WHERE id = $1"
+	row := cvr.DatabaseMgr.ExecuteQueryRow(ctx, query, costVersionId)
+
+	var costVersion models.CostVersionSchema
+	if err := row.Scan(&costVersion.CostVersionID, &costVersion.CostID, &costVersion.Version, &costVersion.Snapshot, &costVersion.CreatedBy, &costVersion.CreationDate); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, expense_errors.EXPENSE_NOT_FOUND
+		}
+
+		log.Printf("Error while scanning cost version: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return &costVersion, nil
+}
+
+// GetCostVersionsByCostID returns all versions of a cost, oldest first
+func (cvr *CostVersionRepository) GetCostVersionsByCostID(ctx context.Context, costId *uuid.UUID) ([]*models.CostVersionSchema, *models.ExpenseServiceError) {
+	query := "SELECT id, id_cost, version, snapshot, created_by, created_at FROM cost_version WHERE id_cost = $1 ORDER BY version"
+	rows, err := cvr.DatabaseMgr.ExecuteQuery(ctx, query, costId)
+	if err != nil {
+		log.Printf("Error while querying cost versions: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	defer rows.Close()
+
+	costVersions := make([]*models.CostVersionSchema, 0)
+	for rows.Next() {
+		var costVersion models.CostVersionSchema
+		if err := rows.Scan(&costVersion.CostVersionID, &costVersion.CostID, &costVersion.Version, &costVersion.Snapshot, &costVersion.CreatedBy, &costVersion.CreationDate); err != nil {
+			log.Printf("Error while scanning cost version: %v", err)
+			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+		}
+		costVersions = append(costVersions, &costVersion)
+	}
+
+	return costVersions, nil
+}
+
+func (*CostVersionRepository) CountCostVersionsTx(ctx context.Context, tx pgx.Tx, costId *uuid.UUID) (int, *models.ExpenseServiceError) {
+	var count int
+	if err := tx.QueryRow(ctx, "SELECT COUNT(*) FROM cost_version WHERE id_cost = $1", costId).Scan(&count); err != nil {
+		log.Printf("Error while counting cost versions: %v", err)
+		return 0, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return count, nil
+}
+
+// AddCostVersionTx stores a new version of a cost. The version number follows the latest version of the cost
+func (*CostVersionRepository) AddCostVersionTx(ctx context.Context, tx pgx.Tx, costVersion *models.CostVersionSchema) *models.ExpenseServiceError {
+	query := "INSERT INTO cost_version (id, id_cost, version, snapshot, created_by, created_at) " +
+		"SELECT $1, $2, COALESCE(MAX(version), 0) + 1, $3, $4, $5 FROM cost_version WHERE id_cost = $2 RETURNING version"
+	if err := tx.QueryRow(ctx, query, costVersion.CostVersionID, costVersion.CostID, costVersion.Snapshot, costVersion.CreatedBy, costVersion.CreationDate).Scan(&costVersion.Version); err != nil {
+		log.Printf("Error while inserting cost version: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/expense_errors"
 	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/managers"
 	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/utils"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/shopspring/decimal"
@@ -14,15 +15,22 @@ import (
 
 type DebtRepo interface {
 	GetDebtById(ctx context.Context, debtId *uuid.UUID) (*models.DebtSchema, *models.ExpenseServiceError)
+	GetDebtByIdForUpdateTx(ctx context.Context, tx pgx.Tx, debtId *uuid.UUID) (*models.DebtSchema, *models.ExpenseServiceError)
 	AddTx(ctx context.Context, tx pgx.Tx, debt *models.DebtSchema) *models.ExpenseServiceError
+	AddMissingDebtsTx(ctx context.Context, tx pgx.Tx, tripId *uuid.UUID, userId *uuid.UUID, otherUserId *uuid.UUID) *models.ExpenseServiceError
 	UpdateTx(ctx context.Context, tx pgx.Tx, debt *models.DebtSchema) *models.ExpenseServiceError
 	DeleteTx(ctx context.Context, tx pgx.Tx, debtId *uuid.UUID) *models.ExpenseServiceError
 
 	GetDebtByCreditorId(ctx context.Context, creditorId *uuid.UUID) (*models.DebtSchema, *models.ExpenseServiceError)
 	GetDebtByCreditorIdAndDebtorIdAndTripIdTx(ctx context.Context, tx pgx.Tx, creditorId *uuid.UUID, debtorId *uuid.UUID, tripId *uuid.UUID) (*models.DebtSchema, *models.ExpenseServiceError)
 	GetDebtEntriesByTripId(ctx context.Context, tripId *uuid.UUID) ([]*models.DebtSchema, *models.ExpenseServiceError)
+	GetDebtEntriesByTripIdForUpdateTx(ctx context.Context, tx pgx.Tx, tripId *uuid.UUID) ([]*models.DebtSchema, *models.ExpenseServiceError)
 	GetCumulativeDebtByUserIDAndTripID(ctx context.Context, userId *uuid.UUID, tripId *uuid.UUID) (decimal.Decimal, *models.ExpenseServiceError)
 	GetCumulativeCreditByUserIDAndTripID(ctx context.Context, userId *uuid.UUID, tripId *uuid.UUID) (decimal.Decimal, *models.ExpenseServiceError)
+	GetOpenDebtsByUserId(ctx context.Context, userId *uuid.UUID) ([]*models.DebtSchema, *models.ExpenseServiceError)
+
+	CountOpenDebtsByUserIdAndTripIdTx(ctx context.Context, tx pgx.Tx, userId *uuid.UUID, tripId *uuid.UUID) (int, *models.ExpenseServiceError)
+	CountOpenDebtsByTripIdTx(ctx context.Context, tx pgx.Tx, tripId *uuid.UUID) (int, *models.ExpenseServiceError)
 
 	CalculateDebt(ctx context.Context, tx pgx.Tx, creditorId *uuid.UUID, debtorId *uuid.UUID, tripId *uuid.UUID, amountToAdd decimal.Decimal) *models.ExpenseServiceError
 	GetDebtEntries(ctx context.Context, id *uuid.UUID) ([]*models.DebtDTO, *models.ExpenseServiceError)
@@ -43,7 +51,7 @@ func (dr *DebtRepository) GetDebtEntries(ctx context.Context, userId *uuid.UUID)
 		"INNER JOIN \"user\" AS creditor ON debt.id_creditor = creditor.id " +
 		"INNER JOIN \"user\" AS debtor ON debt.id_debtor = debtor.id " +
 		"INNER JOIN trip ON debt.id_trip = trip.id " +
-		"WHERE debt.id_creditor = $1"
+		"WHERE debt.id_creditor = $1 AND trip.deleted_at IS NULL"
 
 	rows, err := dr.DatabaseMgr.ExecuteQuery(ctx, query, userId)
 	if err != nil {
@@ -72,6 +80,7 @@ func (dr *DebtRepository) GetDebtEntries(ctx context.Context, userId *uuid.UUID)
 
 		debt.CreationDate = creationDate.String()
 		debt.UpdateDate = updateDate.String()
+		debt.Reference = utils.GenerateSettlementReference(debt.DebtID)
 		debt.Trip.StartDate = startDate.String()
 		debt.Trip.EndDate = endDate.String()
 
@@ -98,6 +107,23 @@ func (dr *DebtRepository) GetDebtById(ctx context.Context, debtId *uuid.UUID) (*
 	return debt, nil
 }
 
+// GetDebtByIdForUpdateTx returns the debt and locks it until the end of the transaction, so no payment can change it in the meantime
+func (*DebtRepository) GetDebtByIdForUpdateTx(ctx context.Context, tx pgx.Tx, debtId *uuid.UUID) (*models.DebtSchema, *models.ExpenseServiceError) {
+	query := "SELECT id, id_creditor, id_debtor, id_trip, amount, currency_code, created_at, updated_at FROM debt WHERE id = $1 FOR UPDATE"
+	debt := &models.DebtSchema{}
+
+	if err := tx.QueryRow(ctx, query, debtId).Scan(&debt.DebtID, &debt.CreditorId, &debt.DebtorId, &debt.TripId, &debt.Amount, &debt.CurrencyCode, &debt.CreationDate, &debt.UpdateDate); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, expense_errors.EXPENSE_NOT_FOUND
+		}
+
+		log.Printf("Error while scanning debt: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return debt, nil
+}
+
 func (*DebtRepository) AddTx(ctx context.Context, tx pgx.Tx, debt *models.DebtSchema) *models.ExpenseServiceError {
 	query := "INSERT INTO debt (id, id_creditor, id_debtor, id_trip, amount, currency_code, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)"
 	_, err := tx.Exec(ctx, query, debt.DebtID, debt.CreditorId, debt.DebtorId, debt.TripId, debt.Amount, debt.CurrencyCode, debt.CreationDate, debt.UpdateDate)
@@ -108,6 +134,18 @@ func (*DebtRepository) AddTx(ctx context.Context, tx pgx.Tx, debt *models.DebtSc
 	return nil
 }
 
+// AddMissingDebtsTx adds the debts between two users of a trip in both directions, unless they already exist
+func (*DebtRepository) AddMissingDebtsTx(ctx context.Context, tx pgx.Tx, tripId *uuid.UUID, userId *uuid.UUID, otherUserId *uuid.UUID) *models.ExpenseServiceError {
+	query := "INSERT INTO debt (id, id_creditor, id_debtor, id_trip, amount, currency_code, created_at, updated_at) VALUES " +
+		"($1, $3, $4, $5, 0, 'EUR', $6, $6), ($2, $4, $3, $5, 0, 'EUR', $6, $6) ON CONFLICT (id_creditor, id_debtor, id_trip) DO NOTHING"
+	if _, err := tx.Exec(ctx, query, uuid.New(), uuid.New(), userId, otherUserId, tripId, time.Now()); err != nil {
+		log.Printf("Error while inserting debts: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return nil
+}
+
 func (*DebtRepository) UpdateTx(ctx context.Context, tx pgx.Tx, debt *models.DebtSchema) *models.ExpenseServiceError {
 	query := "UPDATE debt SET id_creditor = $1, id_debtor = $2, id_trip = $3, amount = $4, currency_code = $5, updated_at = $6 WHERE id = $7"
 	_, err := tx.Exec(ctx, query, debt.CreditorId, debt.DebtorId, debt.TripId, debt.Amount, debt.CurrencyCode, debt.UpdateDate, debt.DebtID)
@@ -178,6 +216,56 @@ func (dr *DebtRepository) GetDebtEntriesByTripId(ctx context.Context, tripId *uu
 	return debts, nil
 }
 
+// GetDebtEntriesByTripIdForUpdateTx returns all debts of a trip and locks them until the end of the transaction, so no
+// payment can change them in the meantime
+func (*DebtRepository) GetDebtEntriesByTripIdForUpdateTx(ctx context.Context, tx pgx.Tx, tripId *uuid.UUID) ([]*models.DebtSchema, *models.ExpenseServiceError) {
+	query := "SELECT id, id_creditor, id_debtor, id_trip, amount, currency_code, created_at, updated_at FROM debt WHERE id_trip = $1 FOR UPDATE"
+	rows, err := tx.Query(ctx, query, tripId)
+	if err != nil {
+		log.Printf("Error while getting debt entries by trip id: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	defer rows.Close()
+
+	var debts []*models.DebtSchema
+	for rows.Next() {
+		debt := &models.DebtSchema{}
+		if err := rows.Scan(&debt.DebtID, &debt.CreditorId, &debt.DebtorId, &debt.TripId, &debt.Amount, &debt.CurrencyCode, &debt.CreationDate, &debt.UpdateDate); err != nil {
+			log.Printf("Error while scanning debt entry: %v", err)
+			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+		}
+
+		debts = append(debts, debt)
+	}
+
+	return debts, nil
+}
+
+// GetOpenDebtsByUserId returns all debts with a positive amount in which the user is either creditor or debtor
+func (dr *DebtRepository) GetOpenDebtsByUserId(ctx context.Context, userId *uuid.UUID) ([]*models.DebtSchema, *models.ExpenseServiceError) {
+	query := "SELECT id, id_creditor, id_debtor, id_trip, amount, currency_code, created_at, updated_at FROM debt WHERE (id_creditor = $1 OR id_debtor = $1) AND amount > 0 AND id_trip IN (SELECT id FROM trip WHERE deleted_at IS NULL)"
+	rows, err := dr.DatabaseMgr.ExecuteQuery(ctx, query, userId)
+	if err != nil {
+		log.Printf("Error while getting open debts by user id: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	defer rows.Close()
+
+	debts := make([]*models.DebtSchema, 0)
+	for rows.Next() {
+		debt := &models.DebtSchema{}
+		err := rows.Scan(&debt.DebtID, &debt.CreditorId, &debt.DebtorId, &debt.TripId, &debt.Amount, &debt.CurrencyCode, &debt.CreationDate, &debt.UpdateDate)
+		if err != nil {
+			log.Printf("Error while scanning debt: %v", err)
+			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+		}
+
+		debts = append(debts, debt)
+	}
+
+	return debts, nil
+}
+
 func (dr *DebtRepository) GetCumulativeCreditByUserIDAndTripID(ctx context.Context, userId *uuid.UUID, tripId *uuid.UUID) (decimal.Decimal, *models.ExpenseServiceError) {
 	query := "SELECT COALESCE(SUM(amount),0) FROM debt WHERE id_creditor = $1 AND id_trip = $2 AND amount > 0"
 	row := dr.DatabaseMgr.ExecuteQueryRow(ctx, query, userId, tripId)
@@ -204,6 +292,30 @@ func (dr *DebtRepository) GetCumulativeDebtByUserIDAndTripID(ctx context.Context
 	return cumulativeCredit, nil
 }
 
+// CountOpenDebtsByUserIdAndTripIdTx counts the non-zero debts of a trip in which the user is creditor or debtor
+func (*DebtRepository) CountOpenDebtsByUserIdAndTripIdTx(ctx context.Context, tx pgx.Tx, userId *uuid.UUID, tripId *uuid.UUID) (int, *models.ExpenseServiceError) {
+	query := "SELECT COUNT(*) FROM debt WHERE id_trip = $1 AND (id_creditor = $2 OR id_debtor = $2) AND amount <> 0"
+
+	var count int
+	if err := tx.QueryRow(ctx, query, tripId, userId).Scan(&count); err != nil {
+		log.Printf("Error while counting open debts: %v", err)
+		return 0, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return count, nil
+}
+
+// CountOpenDebtsByTripIdTx counts the debts of a trip that are not settled yet
+func (*DebtRepository) CountOpenDebtsByTripIdTx(ctx context.Context, tx pgx.Tx, tripId *uuid.UUID) (int, *models.ExpenseServiceError) {
+	var count int
+	if err := tx.QueryRow(ctx, "SELECT COUNT(*) FROM debt WHERE id_trip = $1 AND amount <> 0", tripId).Scan(&count); err != nil {
+		log.Printf("Error while counting open debts: %v", err)
+		return 0, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return count, nil
+}
+
 func (dr *DebtRepository) CalculateDebt(ctx context.Context, tx pgx.Tx, creditorId *uuid.UUID, debtorId *uuid.UUID, tripId *uuid.UUID, amountToAdd decimal.Decimal) *models.ExpenseServiceError {
 	// Check if creditor and debtor are the same
 	if creditorId.String() == debtorId.String() {
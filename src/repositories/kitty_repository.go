@@ -0,0 +1,202 @@
+package repositories
+
+import (
+	"context"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/expense_errors"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/managers"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"log"
+	"time"
+)
+
+type KittyRepo interface {
+	GetKittyByTripID(ctx context.Context, tripId *uuid.UUID) (*models.KittySchema, *models.ExpenseServiceError)
+	GetKittyByTripIDForShareTx(ctx context.Context, tx pgx.Tx, tripId *uuid.UUID) (*models.KittySchema, *models.ExpenseServiceError)
+	GetKittyByTripIDForUpdateTx(ctx context.Context, tx pgx.Tx, tripId *uuid.UUID) (*models.KittySchema, *models.ExpenseServiceError)
+	AddKittyTx(ctx context.Context, tx pgx.Tx, kitty *models.KittySchema) *models.ExpenseServiceError
+	SettleKittyTx(ctx context.Context, tx pgx.Tx, kittyId *uuid.UUID, settledAt *time.Time) *models.ExpenseServiceError
+
+	GetKittyEntries(ctx context.Context, kittyId *uuid.UUID) ([]*models.KittyEntrySchema, *models.ExpenseServiceError)
+	AddKittyEntryTx(ctx context.Context, tx pgx.Tx, entry *models.KittyEntrySchema) *models.ExpenseServiceError
+
+	GetKittyBalances(ctx context.Context, kitty *models.KittySchema) ([]*models.KittyBalanceSchema, *models.ExpenseServiceError)
+	GetKittyBalancesTx(ctx context.Context, tx pgx.Tx, kitty *models.KittySchema) ([]*models.KittyBalanceSchema, *models.ExpenseServiceError)
+}
+
+type KittyRepository struct {
+	DatabaseMgr managers.DatabaseMgr
+}
+
+//********************************************************************************************************************\\
+// Kitty																											  \\
+//********************************************************************************************************************\\
+
+// GetKittyByTripID returns the kitty of a trip
+func (kr *KittyRepository) GetKittyByTripID(ctx context.Context, tripId *uuid.UUID) (*models.KittySchema, *models.ExpenseServiceError) {
+	kitty := &models.KittySchema{}
+
+	row := kr.DatabaseMgr.ExecuteQueryRow(ctx, "SELECT id, id_trip, id_user, created_at, settled_at FROM kitty WHERE id_trip = $1", tripId)
+	if err := row.Scan(&kitty.KittyID, &kitty.TripID, &kitty.UserID, &kitty.CreationDate, &kitty.SettledAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, expense_errors.EXPENSE_NOT_FOUND
+		}
+
+		log.Printf("Error while scanning row: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return kitty, nil
+}
+
+// GetKittyByTripIDForShareTx returns the kitty of a trip and share-locks it until the end of the transaction, so a
+// settlement waits for all bookings that are in progress and vice versa
+func (*KittyRepository) GetKittyByTripIDForShareTx(ctx context.Context, tx pgx.Tx, tripId *uuid.UUID) (*models.KittySchema, *models.ExpenseServiceError) {
+	kitty := &models.KittySchema{}
+
+	row := tx.QueryRow(ctx, "SELECT id, id_trip, id_user, created_at, settled_at FROM kitty WHERE id_trip = $1 FOR SHARE", tripId)
+	if err := row.Scan(&kitty.KittyID, &kitty.TripID, &kitty.UserID, &kitty.CreationDate, &kitty.SettledAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, expense_errors.EXPENSE_NOT_FOUND
+		}
+
+		log.Printf("Error while scanning row: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return kitty, nil
+}
+
+// GetKittyByTripIDForUpdateTx returns the kitty of a trip and locks it until the end of the transaction, so that no
+// deposit or cost paid from the kitty can be booked in the meantime
+func (*KittyRepository) GetKittyByTripIDForUpdateTx(ctx context.Context, tx pgx.Tx, tripId *uuid.UUID) (*models.KittySchema, *models.ExpenseServiceError) {
+	kitty := &models.KittySchema{}
+
+	row := tx.QueryRow(ctx, "SELECT id, id_trip, id_user, created_at, settled_at FROM kitty WHERE id_trip = $1 FOR UPDATE", tripId)
+	if err := row.Scan(&kitty.KittyID, &kitty.TripID, &kitty.UserID, &kitty.CreationDate, &kitty.SettledAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, expense_errors.EXPENSE_NOT_FOUND
+		}
+
+		log.Printf("Error while scanning row: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return kitty, nil
+}
+
+// AddKittyTx inserts the kitty of a trip. If the trip already has a kitty, EXPENSE_CONFLICT is returned
+func (*KittyRepository) AddKittyTx(ctx context.Context, tx pgx.Tx, kitty *models.KittySchema) *models.ExpenseServiceError {
+	query := "INSERT INTO kitty (id, id_trip, id_user, created_at) VALUES ($1, $2, $3, $4) ON CONFLICT (id_trip) DO NOTHING"
+	result, err := tx.Exec(ctx, query, kitty.KittyID, kitty.TripID, kitty.UserID, kitty.CreationDate)
+	if err != nil {
+		log.Printf("Error while inserting kitty into database: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	if rowsAffected := result.RowsAffected(); rowsAffected == 0 {
+		return expense_errors.EXPENSE_CONFLICT
+	}
+
+	return nil
+}
+
+func (*KittyRepository) SettleKittyTx(ctx context.Context, tx pgx.Tx, kittyId *uuid.UUID, settledAt *time.Time) *models.ExpenseServiceError {
+	result, err := tx.Exec(ctx, "UPDATE kitty SET settled_at = $1 WHERE id = $2 AND settled_at IS NULL", settledAt, kittyId)
+	if err != nil {
+		log.Printf("Error while updating kitty in database: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	if rowsAffected := result.RowsAffected(); rowsAffected == 0 {
+		return expense_errors.EXPENSE_KITTY_SETTLED
+	}
+
+	return nil
+}
+
+//********************************************************************************************************************\\
+// Kitty Entry																										  \\
+//********************************************************************************************************************\\
+
+// GetKittyEntries returns all deposits and payouts of a kitty
+func (kr *KittyRepository) GetKittyEntries(ctx context.Context, kittyId *uuid.UUID) ([]*models.KittyEntrySchema, *models.ExpenseServiceError) {
+	rows, err := kr.DatabaseMgr.ExecuteQuery(ctx, "SELECT id, id_kitty, id_user, amount, description, created_at FROM kitty_entry WHERE id_kitty = $1 ORDER BY created_at", kittyId)
+	if err != nil {
+		log.Printf("Error while querying database: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	defer rows.Close()
+
+	entries := make([]*models.KittyEntrySchema, 0)
+	for rows.Next() {
+		var entry models.KittyEntrySchema
+		if err := rows.Scan(&entry.KittyEntryID, &entry.KittyID, &entry.UserID, &entry.Amount, &entry.Description, &entry.CreationDate); err != nil {
+			log.Printf("Error while scanning row: %v", err)
+			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+		}
+		entries = append(entries, &entry)
+	}
+
+	return entries, nil
+}
+
+func (*KittyRepository) AddKittyEntryTx(ctx context.Context, tx pgx.Tx, entry *models.KittyEntrySchema) *models.ExpenseServiceError {
+	query := "INSERT INTO kitty_entry (id, id_kitty, id_user, amount, description, created_at) VALUES ($1, $2, $3, $4, $5, $6)"
+	if _, err := tx.Exec(ctx, query, entry.KittyEntryID, entry.KittyID, entry.UserID, entry.Amount, entry.Description, entry.CreationDate); err != nil {
+		log.Printf("Error while inserting kitty entry into database: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	return nil
+}
+
+//********************************************************************************************************************\\
+// Kitty Balance																									  \\
+//********************************************************************************************************************\\
+
+// kittyBalancesQuery sums up the entries of every user and their shares of the approved costs paid from the kitty
+const kittyBalancesQuery = "SELECT COALESCE(d.id_user, s.id_user), COALESCE(d.deposited, 0), COALESCE(s.spent, 0) " +
+	"FROM (SELECT id_user, SUM(amount) AS deposited FROM kitty_entry WHERE id_kitty = $1 GROUP BY id_user) d " +
+	"FULL OUTER JOIN (SELECT uca.id_user, SUM(uca.amount) AS spent FROM user_cost_association uca " +
+	"INNER JOIN cost c ON uca.id_cost = c.id INNER JOIN cost_category cc ON c.id_cost_category = cc.id " +
+	"WHERE cc.id_trip = $2 AND c.paid_from_kitty = true AND c.status = $3 AND c.deleted_at IS NULL GROUP BY uca.id_user) s " +
+	"ON d.id_user = s.id_user ORDER BY 1"
+
+// GetKittyBalances returns the deposited and spent amounts of all users of a kitty
+func (kr *KittyRepository) GetKittyBalances(ctx context.Context, kitty *models.KittySchema) ([]*models.KittyBalanceSchema, *models.ExpenseServiceError) {
+	rows, err := kr.DatabaseMgr.ExecuteQuery(ctx, kittyBalancesQuery, kitty.KittyID, kitty.TripID, models.CostStatusApproved)
+	if err != nil {
+		log.Printf("Error while querying database: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	defer rows.Close()
+
+	return getKittyBalancesFromRows(rows)
+}
+
+// GetKittyBalancesTx returns the deposited and spent amounts of all users of a kitty within the transaction
+func (*KittyRepository) GetKittyBalancesTx(ctx context.Context, tx pgx.Tx, kitty *models.KittySchema) ([]*models.KittyBalanceSchema, *models.ExpenseServiceError) {
+	rows, err := tx.Query(ctx, kittyBalancesQuery, kitty.KittyID, kitty.TripID, models.CostStatusApproved)
+	if err != nil {
+		log.Printf("Error while querying database: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	defer rows.Close()
+
+	return getKittyBalancesFromRows(rows)
+}
+
+func getKittyBalancesFromRows(rows pgx.Rows) ([]*models.KittyBalanceSchema, *models.ExpenseServiceError) {
+	balances := make([]*models.KittyBalanceSchema, 0)
+	for rows.Next() {
+		var balance models.KittyBalanceSchema
+		if err := rows.Scan(&balance.UserID, &balance.Deposited, &balance.Spent); err != nil {
+			log.Printf("Error while scanning row: %v", err)
+			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+		}
+		balances = append(balances, &balance)
+	}
+
+	return balances, nil
+}
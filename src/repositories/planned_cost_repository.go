@@ -0,0 +1,224 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/expense_errors"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/managers"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"log"
+)
+
+type PlannedCostRepo interface {
+	GetPlannedCostByID(ctx context.Context, plannedCostId *uuid.UUID) (*models.PlannedCostSchema, *models.ExpenseServiceError)
+	GetPlannedCostsByTripID(ctx context.Context, tripId *uuid.UUID) ([]*models.PlannedCostSchema, *models.ExpenseServiceError)
+	AddTx(ctx context.Context, tx pgx.Tx, plannedCost *models.PlannedCostSchema) *models.ExpenseServiceError
+	UpdateTx(ctx context.Context, tx pgx.Tx, plannedCost *models.PlannedCostSchema) *models.ExpenseServiceError
+	DeleteTx(ctx context.Context, tx pgx.Tx, plannedCostId *uuid.UUID) *models.ExpenseServiceError
+	MovePlannedCostsToCostCategoryTx(ctx context.Context, tx pgx.Tx, sourceCostCategoryId *uuid.UUID, targetCostCategoryId *uuid.UUID) *models.ExpenseServiceError
+
+	GetPlannedCostContributors(ctx context.Context, plannedCostId *uuid.UUID) ([]*models.PlannedCostContributionSchema, *models.ExpenseServiceError)
+	AddPlannedCostContributorTx(ctx context.Context, tx pgx.Tx, contributor *models.PlannedCostContributionSchema) *models.ExpenseServiceError
+	DeletePlannedCostContributionsTx(ctx context.Context, tx pgx.Tx, plannedCostId *uuid.UUID) *models.ExpenseServiceError
+
+	GetLinkedCostIDs(ctx context.Context, plannedCostId *uuid.UUID) ([]*uuid.UUID, *models.ExpenseServiceError)
+	AddPlannedCostLinkTx(ctx context.Context, tx pgx.Tx, plannedCostId *uuid.UUID, costId *uuid.UUID) *models.ExpenseServiceError
+	DeletePlannedCostLink(ctx context.Context, plannedCostId *uuid.UUID, costId *uuid.UUID) *models.ExpenseServiceError
+}
+
+type PlannedCostRepository struct {
+	DatabaseMgr managers.DatabaseMgr
+}
+
+//********************************************************************************************************************\\
+// Planned Cost																										  \\
+//********************************************************************************************************************\\
+
+// GetPlannedCostByID returns a planned cost by its id
+func (pcr *PlannedCostRepository) GetPlannedCostByID(ctx context.Context, plannedCostId *uuid.UUID) (*models.PlannedCostSchema, *models.ExpenseServiceError) {
+	plannedCost := &models.PlannedCostSchema{}
+
+	row := pcr.DatabaseMgr.ExecuteQueryRow(ctx, "SELECT id, amount, description, created_at, id_cost_category FROM planned_cost WHERE id = $1", plannedCostId)
+	if err := row.Scan(&plannedCost.PlannedCostID, &plannedCost.Amount, &plannedCost.Description, &plannedCost.CreationDate, &plannedCost.CostCategoryID); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, expense_errors.EXPENSE_NOT_FOUND
+		}
+
+		log.Printf("Error while scanning row: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return plannedCost, nil
+}
+
+// GetPlannedCostsByTripID returns all planned costs associated with a trip through the cost_category database table
+func (pcr *PlannedCostRepository) GetPlannedCostsByTripID(ctx context.Context, tripId *uuid.UUID) ([]*models.PlannedCostSchema, *models.ExpenseServiceError) {
+	rows, err := pcr.DatabaseMgr.ExecuteQuery(ctx, "SELECT pc.id, pc.amount, pc.description, pc.created_at, pc.id_cost_category FROM planned_cost pc INNER JOIN cost_category cc ON pc.id_cost_category = cc.id WHERE cc.id_trip = $1 AND cc.deleted_at IS NULL ORDER BY pc.created_at", tripId)
+	if err != nil {
+		log.Printf("Error while querying database: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	defer rows.Close()
+
+	plannedCosts := make([]*models.PlannedCostSchema, 0)
+	for rows.Next() {
+		var plannedCost models.PlannedCostSchema
+		if err := rows.Scan(&plannedCost.PlannedCostID, &plannedCost.Amount, &plannedCost.Description, &plannedCost.CreationDate, &plannedCost.CostCategoryID); err != nil {
+			log.Printf("Error while scanning row: %v", err)
+			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+		}
+		plannedCosts = append(plannedCosts, &plannedCost)
+	}
+
+	return plannedCosts, nil
+}
+
+func (*PlannedCostRepository) AddTx(ctx context.Context, tx pgx.Tx, plannedCost *models.PlannedCostSchema) *models.ExpenseServiceError {
+	query := "INSERT INTO planned_cost (id, amount, description, created_at, id_cost_category) VALUES ($1, $2, $3, $4, $5)"
+	_, err := tx.Exec(ctx, query, plannedCost.PlannedCostID, plannedCost.Amount, plannedCost.Description, plannedCost.CreationDate, plannedCost.CostCategoryID)
+	if err != nil {
+		log.Printf("Error while inserting planned cost into database: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	return nil
+}
+
+func (*PlannedCostRepository) UpdateTx(ctx context.Context, tx pgx.Tx, plannedCost *models.PlannedCostSchema) *models.ExpenseServiceError {
+	query := "UPDATE planned_cost SET amount = $1, description = $2, id_cost_category = $3 WHERE id = $4"
+	result, err := tx.Exec(ctx, query, plannedCost.Amount, plannedCost.Description, plannedCost.CostCategoryID, plannedCost.PlannedCostID)
+	if err != nil {
+		log.Printf("Error while updating planned cost in database: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	if rowsAffected := result.RowsAffected(); rowsAffected == 0 {
+		return expense_errors.EXPENSE_NOT_FOUND
+	}
+
+	return nil
+}
+
+func (*PlannedCostRepository) DeleteTx(ctx context.Context, tx pgx.Tx, plannedCostId *uuid.UUID) *models.ExpenseServiceError {
+	result, err := tx.Exec(ctx, "DELETE FROM planned_cost WHERE id = $1", plannedCostId)
+	if err != nil {
+		log.Printf("Error while deleting planned cost from database: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	if rowsAffected := result.RowsAffected(); rowsAffected == 0 {
+		return expense_errors.EXPENSE_NOT_FOUND
+	}
+
+	return nil
+}
+
+// MovePlannedCostsToCostCategoryTx reassigns all planned costs of the source cost category to the target cost category
+func (*PlannedCostRepository) MovePlannedCostsToCostCategoryTx(ctx context.Context, tx pgx.Tx, sourceCostCategoryId *uuid.UUID, targetCostCategoryId *uuid.UUID) *models.ExpenseServiceError {
+	if _, err := tx.Exec(ctx, "UPDATE planned_cost SET id_cost_category = $1 WHERE id_cost_category = $2", targetCostCategoryId, sourceCostCategoryId); err != nil {
+		log.Printf("Error while moving planned costs to cost category: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return nil
+}
+
+//********************************************************************************************************************\\
+// Planned Cost Contributor																							  \\
+//********************************************************************************************************************\\
+
+// GetPlannedCostContributors returns the planned shares of all users for a planned cost
+func (pcr *PlannedCostRepository) GetPlannedCostContributors(ctx context.Context, plannedCostId *uuid.UUID) ([]*models.PlannedCostContributionSchema, *models.ExpenseServiceError) {
+	rows, err := pcr.DatabaseMgr.ExecuteQuery(ctx, "SELECT id_user, id_planned_cost, amount FROM user_planned_cost_association WHERE id_planned_cost = $1", plannedCostId)
+	if err != nil {
+		log.Printf("Error while querying database: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	defer rows.Close()
+
+	contributors := make([]*models.PlannedCostContributionSchema, 0)
+	for rows.Next() {
+		var contributor models.PlannedCostContributionSchema
+		if err := rows.Scan(&contributor.UserID, &contributor.PlannedCostID, &contributor.Amount); err != nil {
+			log.Printf("Error while scanning row: %v", err)
+			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+		}
+		contributors = append(contributors, &contributor)
+	}
+
+	return contributors, nil
+}
+
+func (*PlannedCostRepository) AddPlannedCostContributorTx(ctx context.Context, tx pgx.Tx, contributor *models.PlannedCostContributionSchema) *models.ExpenseServiceError {
+	query := "INSERT INTO user_planned_cost_association (id_user, id_planned_cost, amount) VALUES ($1, $2, $3)"
+	if _, err := tx.Exec(ctx, query, contributor.UserID, contributor.PlannedCostID, contributor.Amount); err != nil {
+		log.Printf("Error while inserting planned cost contribution into database: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	return nil
+}
+
+func (*PlannedCostRepository) DeletePlannedCostContributionsTx(ctx context.Context, tx pgx.Tx, plannedCostId *uuid.UUID) *models.ExpenseServiceError {
+	if _, err := tx.Exec(ctx, "DELETE FROM user_planned_cost_association WHERE id_planned_cost = $1", plannedCostId); err != nil {
+		log.Printf("Error while deleting planned cost contributions: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	return nil
+}
+
+//********************************************************************************************************************\\
+// Planned Cost Link																								  \\
+//********************************************************************************************************************\\
+
+// GetLinkedCostIDs returns the ids of all actual costs linked to a planned cost, costs in the trash are left out
+func (pcr *PlannedCostRepository) GetLinkedCostIDs(ctx context.Context, plannedCostId *uuid.UUID) ([]*uuid.UUID, *models.ExpenseServiceError) {
+	rows, err := pcr.DatabaseMgr.ExecuteQuery(ctx, "SELECT pcl.id_cost FROM planned_cost_link pcl INNER JOIN cost c ON pcl.id_cost = c.id WHERE pcl.id_planned_cost = $1 AND c.deleted_at IS NULL", plannedCostId)
+	if err != nil {
+		log.Printf("Error while querying database: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	defer rows.Close()
+
+	costIds := make([]*uuid.UUID, 0)
+	for rows.Next() {
+		var costId uuid.UUID
+		if err := rows.Scan(&costId); err != nil {
+			log.Printf("Error while scanning row: %v", err)
+			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+		}
+		costIds = append(costIds, &costId)
+	}
+
+	return costIds, nil
+}
+
+// AddPlannedCostLinkTx links an actual cost to a planned cost. Linking the same cost twice has no effect, a cost that is
+// already linked to another planned cost results in a conflict, so that it is not counted twice
+func (*PlannedCostRepository) AddPlannedCostLinkTx(ctx context.Context, tx pgx.Tx, plannedCostId *uuid.UUID, costId *uuid.UUID) *models.ExpenseServiceError {
+	query := "INSERT INTO planned_cost_link (id_planned_cost, id_cost) VALUES ($1, $2) ON CONFLICT (id_planned_cost, id_cost) DO NOTHING"
+	if _, err := tx.Exec(ctx, query, plannedCostId, costId); err != nil {
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) && pgxErr.Code == "23505" {
+			return expense_errors.EXPENSE_CONFLICT
+		}
+		log.Printf("Error while inserting planned cost link into database: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	return nil
+}
+
+func (pcr *PlannedCostRepository) DeletePlannedCostLink(ctx context.Context, plannedCostId *uuid.UUID, costId *uuid.UUID) *models.ExpenseServiceError {
+	result, err := pcr.DatabaseMgr.ExecuteStatement(ctx, "DELETE FROM planned_cost_link WHERE id_planned_cost = $1 AND id_cost = $2", plannedCostId, costId)
+	if err != nil {
+		log.Printf("Error while deleting planned cost link: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	if rowsAffected := result.RowsAffected(); rowsAffected == 0 {
+		return expense_errors.EXPENSE_NOT_FOUND
+	}
+
+	return nil
+}
@@ -18,6 +18,7 @@ type TransactionRepo interface {
 	GetAllTransactions(ctx context.Context, userId *uuid.UUID) ([]*models.TransactionSchema, *models.ExpenseServiceError)
 	GetTransactionsByTripIdAndUserId(ctx context.Context, tripId *uuid.UUID, userId *uuid.UUID) ([]*models.TransactionSchema, *models.ExpenseServiceError)
 	GetTransactionById(ctx context.Context, id *uuid.UUID) (*models.TransactionSchema, *models.ExpenseServiceError)
+	GetPendingTransactionsByDebtorId(ctx context.Context, debtorId *uuid.UUID) ([]*models.TransactionSchema, *models.ExpenseServiceError)
 }
 
 type TransactionRepository struct {
@@ -25,7 +26,7 @@ type TransactionRepository struct {
 }
 
 func (tr *TransactionRepository) GetAllTransactions(ctx context.Context, userId *uuid.UUID) ([]*models.TransactionSchema, *models.ExpenseServiceError) {
-	query := "SELECT * FROM transaction WHERE id_creditor = $1 OR id_debtor = $1"
+	query := "SELECT id, id_creditor, id_debtor, id_trip, amount, created_at, currency_code, is_confirmed, type, note FROM transaction WHERE (id_creditor = $1 OR id_debtor = $1) AND id_trip IN (SELECT id FROM trip WHERE deleted_at IS NULL)"
 	rows, err := tr.DatabaseMgr.ExecuteQuery(ctx, query, userId)
 	if err != nil {
 		log.Printf("Error while executing query: %v", err)
@@ -36,7 +37,7 @@ func (tr *TransactionRepository) GetAllTransactions(ctx context.Context, userId
 	var transactions []*models.TransactionSchema
 	for rows.Next() {
 		var transaction models.TransactionSchema
-		err := rows.Scan(&transaction.TransactionId, &transaction.CreditorId, &transaction.DebtorId, &transaction.TripId, &transaction.Amount, &transaction.CreationDate, &transaction.CurrencyCode, &transaction.IsConfirmed)
+		err := rows.Scan(&transaction.TransactionId, &transaction.CreditorId, &transaction.DebtorId, &transaction.TripId, &transaction.Amount, &transaction.CreationDate, &transaction.CurrencyCode, &transaction.IsConfirmed, &transaction.Type, &transaction.Note)
 		if err != nil {
 			log.Printf("Error while scanning row: %v", err)
 			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
@@ -47,8 +48,8 @@ func (tr *TransactionRepository) GetAllTransactions(ctx context.Context, userId
 }
 
 func (*TransactionRepository) AddTx(ctx context.Context, tx pgx.Tx, transaction *models.TransactionSchema) *models.ExpenseServiceError {
-	query := "INSERT INTO transaction (id, id_creditor, id_debtor, id_trip, amount, created_at, currency_code, is_confirmed) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)"
-	_, err := tx.Exec(ctx, query, transaction.TransactionId, transaction.CreditorId, transaction.DebtorId, transaction.TripId, transaction.Amount, transaction.CreationDate, transaction.CurrencyCode, transaction.IsConfirmed)
+	query := "INSERT INTO transaction (id, id_creditor, id_debtor, id_trip, amount, created_at, currency_code, is_confirmed, type, note) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)"
+	_, err := tx.Exec(ctx, query, transaction.TransactionId, transaction.CreditorId, transaction.DebtorId, transaction.TripId, transaction.Amount, transaction.CreationDate, transaction.CurrencyCode, transaction.IsConfirmed, transaction.Type, transaction.Note)
 	if err != nil {
 		log.Printf("Error while executing query: %v", err)
 		return expense_errors.EXPENSE_INTERNAL_ERROR
@@ -77,11 +78,11 @@ func (*TransactionRepository) DeleteTx(ctx context.Context, tx pgx.Tx, transacti
 }
 
 func (tr *TransactionRepository) GetTransactionById(ctx context.Context, id *uuid.UUID) (*models.TransactionSchema, *models.ExpenseServiceError) {
-	query := "SELECT id, id_creditor, id_debtor, id_trip, amount, created_at, currency_code, is_confirmed FROM transaction WHERE id = $1"
+	query := "SELECT id, id_creditor, id_debtor, id_trip, amount, created_at, currency_code, is_confirmed, type, note FROM transaction WHERE id = $1"
 	row := tr.DatabaseMgr.ExecuteQueryRow(ctx, query, id)
 
 	var transaction models.TransactionSchema
-	err := row.Scan(&transaction.TransactionId, &transaction.CreditorId, &transaction.DebtorId, &transaction.TripId, &transaction.Amount, &transaction.CreationDate, &transaction.CurrencyCode, &transaction.IsConfirmed)
+	err := row.Scan(&transaction.TransactionId, &transaction.CreditorId, &transaction.DebtorId, &transaction.TripId, &transaction.Amount, &transaction.CreationDate, &transaction.CurrencyCode, &transaction.IsConfirmed, &transaction.Type, &transaction.Note)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, expense_errors.EXPENSE_NOT_FOUND
@@ -95,7 +96,7 @@ func (tr *TransactionRepository) GetTransactionById(ctx context.Context, id *uui
 }
 
 func (tr *TransactionRepository) GetTransactionsByTripIdAndUserId(ctx context.Context, tripId *uuid.UUID, userId *uuid.UUID) ([]*models.TransactionSchema, *models.ExpenseServiceError) {
-	query := "SELECT id, id_creditor, id_debtor, id_trip, amount, created_at, is_confirmed FROM transaction WHERE id_trip = $1 AND (id_creditor = $2 OR id_debtor = $2)"
+	query := "SELECT id, id_creditor, id_debtor, id_trip, amount, created_at, is_confirmed, type, note FROM transaction WHERE id_trip = $1 AND (id_creditor = $2 OR id_debtor = $2)"
 	rows, err := tr.DatabaseMgr.ExecuteQuery(ctx, query, tripId, userId)
 	if err != nil {
 
@@ -107,7 +108,31 @@ func (tr *TransactionRepository) GetTransactionsByTripIdAndUserId(ctx context.Co
 	transactions := make([]*models.TransactionSchema, 0)
 	for rows.Next() {
 		var transaction models.TransactionSchema
-		err = rows.Scan(&transaction.TransactionId, &transaction.CreditorId, &transaction.DebtorId, &transaction.TripId, &transaction.Amount, &transaction.CreationDate, &transaction.IsConfirmed)
+		err = rows.Scan(&transaction.TransactionId, &transaction.CreditorId, &transaction.DebtorId, &transaction.TripId, &transaction.Amount, &transaction.CreationDate, &transaction.IsConfirmed, &transaction.Type, &transaction.Note)
+		if err != nil {
+			log.Printf("Error while scanning transaction: %v", err)
+			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+		}
+		transactions = append(transactions, &transaction)
+	}
+
+	return transactions, nil
+}
+
+// GetPendingTransactionsByDebtorId returns all unconfirmed transactions that were sent to the given user
+func (tr *TransactionRepository) GetPendingTransactionsByDebtorId(ctx context.Context, debtorId *uuid.UUID) ([]*models.TransactionSchema, *models.ExpenseServiceError) {
+	query := "SELECT id, id_creditor, id_debtor, id_trip, amount, created_at, currency_code, is_confirmed, type, note FROM transaction WHERE id_debtor = $1 AND is_confirmed = false AND id_trip IN (SELECT id FROM trip WHERE deleted_at IS NULL)"
+	rows, err := tr.DatabaseMgr.ExecuteQuery(ctx, query, debtorId)
+	if err != nil {
+		log.Printf("Error while executing query: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	defer rows.Close()
+
+	transactions := make([]*models.TransactionSchema, 0)
+	for rows.Next() {
+		var transaction models.TransactionSchema
+		err = rows.Scan(&transaction.TransactionId, &transaction.CreditorId, &transaction.DebtorId, &transaction.TripId, &transaction.Amount, &transaction.CreationDate, &transaction.CurrencyCode, &transaction.IsConfirmed, &transaction.Type, &transaction.Note)
 		if err != nil {
 			log.Printf("Error while scanning transaction: %v", err)
 			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
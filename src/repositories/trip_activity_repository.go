@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"context"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/expense_errors"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/managers"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
+	"github.com/google/uuid"
+	"log"
+)
+
+type TripActivityRepo interface {
+	AddTripActivity(ctx context.Context, tripActivity *models.TripActivitySchema) *models.ExpenseServiceError
+	GetTripActivitiesByTripID(ctx context.Context, tripId *uuid.UUID, limit int, offset int) ([]*models.TripActivitySchema, *models.ExpenseServiceError)
+}
+
+type TripActivityRepository struct {
+	DatabaseMgr managers.DatabaseMgr
+}
+
+func (tar *TripActivityRepository) AddTripActivity(ctx context.Context, tripActivity *models.TripActivitySchema) *models.ExpenseServiceError {
+	query := "INSERT INTO trip_activity (id, id_trip, id_user, type, id_subject, details, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)"
+	if _, err := tar.DatabaseMgr.ExecuteStatement(ctx, query, tripActivity.TripActivityID, tripActivity.TripID, tripActivity.UserID, tripActivity.Type, tripActivity.SubjectID, tripActivity.Details, tripActivity.CreationDate); err != nil {
+		log.Printf("Error while inserting trip activity: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return nil
+}
+
+// GetTripActivitiesByTripID returns a page of the activities of a trip, newest first
+func (tar *TripActivityRepository) GetTripActivitiesByTripID(ctx context.Context, tripId *uuid.UUID, limit int, offset int) ([]*models.TripActivitySchema, *models.ExpenseServiceError) {
+	query := "SELECT id, id_trip, id_user, type, id_subject, details, created_at FROM trip_activity WHERE id_trip = $1 ORDER BY created_at DESC, id LIMIT $2 OFFSET $3"
+	rows, err := tar.DatabaseMgr.ExecuteQuery(ctx, query, tripId, limit, offset)
+	if err != nil {
+		log.Printf("Error while querying trip activities: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	defer rows.Close()
+
+	tripActivities := make([]*models.TripActivitySchema, 0)
+	for rows.Next() {
+		var tripActivity models.TripActivitySchema
+		if err := rows.Scan(&tripActivity.TripActivityID, &tripActivity.TripID, &tripActivity.UserID, &tripActivity.Type, &tripActivity.SubjectID, &tripActivity.Details, &tripActivity.CreationDate); err != nil {
+			log.Printf("Error while scanning trip activity: %v", err)
+			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+		}
+		tripActivities = append(tripActivities, &tripActivity)
+	}
+
+	return tripActivities, nil
+}
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"context"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/expense_errors"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/managers"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"log"
+	"time"
+)
+
+type TripInviteRepo interface {
+	GetTripInviteByID(ctx context.Context, tripInviteId *uuid.UUID) (*models.TripInviteSchema, *models.ExpenseServiceError)
+	GetTripInviteByToken(ctx context.Context, token string) (*models.TripInviteSchema, *models.ExpenseServiceError)
+	GetOutstandingTripInvites(ctx context.Context, tripId *uuid.UUID) ([]*models.TripInviteSchema, *models.ExpenseServiceError)
+	AddTripInvite(ctx context.Context, invite *models.TripInviteSchema) *models.ExpenseServiceError
+	AcceptTripInviteTx(ctx context.Context, tx pgx.Tx, tripInviteId *uuid.UUID, acceptDate *time.Time) *models.ExpenseServiceError
+	RevokeTripInvite(ctx context.Context, tripInviteId *uuid.UUID, revokeDate *time.Time) *models.ExpenseServiceError
+}
+
+type TripInviteRepository struct {
+	DatabaseMgr managers.DatabaseMgr
+}
+
+const tripInviteSelect = "SELECT id, id_trip, id_inviter, id_guest, email, token, role, created_at, expires_at, accepted_at, revoked_at FROM trip_invite"
+
+func (tir *TripInviteRepository) GetTripInviteByID(ctx context.Context, tripInviteId *uuid.UUID) (*models.TripInviteSchema, *models.ExpenseServiceError) {
+	row := tir.DatabaseMgr.ExecuteQueryRow(ctx, tripInviteSelect+" WHERE id = $1", tripInviteId)
+	return rowToTripInviteSchema(row)
+}
+
+func (tir *TripInviteRepository) GetTripInviteByToken(ctx context.Context, token string) (*models.TripInviteSchema, *models.ExpenseServiceError) {
+	row := tir.DatabaseMgr.ExecuteQueryRow(ctx, tripInviteSelect+" WHERE token = $1", token)
+	return rowToTripInviteSchema(row)
+}
+
+// GetOutstandingTripInvites returns all invites of a trip that were neither used, revoked nor have expired, the newest first
+func (tir *TripInviteRepository) GetOutstandingTripInvites(ctx context.Context, tripId *uuid.UUID) ([]*models.TripInviteSchema, *models.ExpenseServiceError) {
+	query := tripInviteSelect + " WHERE id_trip = $1 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > $2 ORDER BY created_at DESC"
+	rows, err := tir.DatabaseMgr.ExecuteQuery(ctx, query, tripId, time.Now())
+	if err != nil {
+		log.Printf("Error while querying trip invites: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	defer rows.Close()
+
+	invites := make([]*models.TripInviteSchema, 0)
+	for rows.Next() {
+		invite, repoErr := rowToTripInviteSchema(rows)
+		if repoErr != nil {
+			return nil, repoErr
+		}
+		invites = append(invites, invite)
+	}
+
+	return invites, nil
+}
+
+func (tir *TripInviteRepository) AddTripInvite(ctx context.Context, invite *models.TripInviteSchema) *models.ExpenseServiceError {
+	query := "INSERT INTO trip_invite (id, id_trip, id_inviter, id_guest, email, token, role, created_at, expires_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)"
+	if _, err := tir.DatabaseMgr.ExecuteStatement(ctx, query, invite.TripInviteID, invite.TripID, invite.InviterID, invite.GuestID, invite.Email, invite.Token, invite.Role, invite.CreationDate, invite.ExpiryDate); err != nil {
+		log.Printf("Error while inserting trip invite: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return nil
+}
+
+// AcceptTripInviteTx marks a single-use invite as used. Invites that were already used or revoked cannot be accepted
+func (*TripInviteRepository) AcceptTripInviteTx(ctx context.Context, tx pgx.Tx, tripInviteId *uuid.UUID, acceptDate *time.Time) *models.ExpenseServiceError {
+	query := "UPDATE trip_invite SET accepted_at = $1 WHERE id = $2 AND accepted_at IS NULL AND revoked_at IS NULL"
+	result, err := tx.Exec(ctx, query, acceptDate, tripInviteId)
+	if err != nil {
+		log.Printf("Error while accepting trip invite: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	if rowsAffected := result.RowsAffected(); rowsAffected == 0 {
+		return expense_errors.EXPENSE_CONFLICT
+	}
+
+	return nil
+}
+
+// RevokeTripInvite invalidates an outstanding invite. Invites that were already used or revoked cannot be revoked
+func (tir *TripInviteRepository) RevokeTripInvite(ctx context.Context, tripInviteId *uuid.UUID, revokeDate *time.Time) *models.ExpenseServiceError {
+	query := "UPDATE trip_invite SET revoked_at = $1 WHERE id = $2 AND accepted_at IS NULL AND revoked_at IS NULL"
+	result, err := tir.DatabaseMgr.ExecuteStatement(ctx, query, revokeDate, tripInviteId)
+	if err != nil {
+		log.Printf("Error while revoking trip invite: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	if rowsAffected := result.RowsAffected(); rowsAffected == 0 {
+		return expense_errors.EXPENSE_CONFLICT
+	}
+
+	return nil
+}
+
+// rowToTripInviteSchema converts a row to a TripInviteSchema
+func rowToTripInviteSchema(row pgx.Row) (*models.TripInviteSchema, *models.ExpenseServiceError) {
+	var invite models.TripInviteSchema
+	if err := row.Scan(&invite.TripInviteID, &invite.TripID, &invite.InviterID, &invite.GuestID, &invite.Email, &invite.Token, &invite.Role, &invite.CreationDate, &invite.ExpiryDate, &invite.AcceptDate, &invite.RevokeDate); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, expense_errors.EXPENSE_NOT_FOUND
+		}
+
+		log.Printf("Error while scanning trip invite: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return &invite, nil
+}
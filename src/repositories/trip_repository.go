@@ -2,24 +2,24 @@ package repositories
 
 import (
 	"context"
-	"errors"
 	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/expense_errors"
 	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/managers"
 	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgconn"
 	"log"
+	"time"
 )
 
 type TripRepo interface {
 	GetTripById(ctx context.Context, tripId *uuid.UUID) (*models.TripSchema, *models.ExpenseServiceError)
 	GetTripsByUserId(ctx context.Context, userId *uuid.UUID) ([]*models.TripSchema, *models.ExpenseServiceError)
-	CreateTrip(ctx context.Context, trip *models.TripSchema) *models.ExpenseServiceError
+	CreateTripTx(ctx context.Context, tx pgx.Tx, trip *models.TripSchema) *models.ExpenseServiceError
 	UpdateTrip(ctx context.Context, trip *models.TripSchema) *models.ExpenseServiceError
 	DeleteTrip(ctx context.Context, tripId *uuid.UUID) *models.ExpenseServiceError
+	UpdateTripStatusTx(ctx context.Context, tx pgx.Tx, tripId *uuid.UUID, status string, closedAt *time.Time) *models.ExpenseServiceError
 
-	AddUserToTrip(ctx context.Context, trip *models.TripSchema, invitedUserId *uuid.UUID, isCreator bool) *models.ExpenseServiceError
+	AddTripParticipantTx(ctx context.Context, tx pgx.Tx, userTrip *models.UserTripSchema) *models.ExpenseServiceError
 	AcceptTripInvite(ctx context.Context, tripId *uuid.UUID, userId *uuid.UUID) *models.ExpenseServiceError
 	DeclineTripInvite(ctx context.Context, tripId *uuid.UUID, userId *uuid.UUID) *models.ExpenseServiceError
 
@@ -29,8 +29,20 @@ type TripRepo interface {
 	GetTripParticipant(ctx context.Context, tripId *uuid.UUID, userId *uuid.UUID) (*models.UserTripSchema, *models.ExpenseServiceError)
 	GetTripParticipants(ctx context.Context, tripId *uuid.UUID) ([]*models.UserTripSchema, *models.ExpenseServiceError)
 	GetAcceptedTripParticipants(ctx context.Context, tripId *uuid.UUID) ([]*models.UserTripSchema, *models.ExpenseServiceError)
+	GetAcceptedTripParticipantsTx(ctx context.Context, tx pgx.Tx, tripId *uuid.UUID) ([]*models.UserTripSchema, *models.ExpenseServiceError)
 	UpdateTripParticipant(ctx context.Context, userTrip *models.UserTripSchema) *models.ExpenseServiceError
 	UpdateTripParticipantTx(ctx context.Context, tx pgx.Tx, userTrip *models.UserTripSchema) *models.ExpenseServiceError
+	UpdateTripParticipantRoleTx(ctx context.Context, tx pgx.Tx, tripId *uuid.UUID, userId *uuid.UUID, role string) *models.ExpenseServiceError
+	DeleteTripParticipantTx(ctx context.Context, tx pgx.Tx, tripId *uuid.UUID, userId *uuid.UUID) *models.ExpenseServiceError
+
+	GetPendingTripInvitesByUserId(ctx context.Context, userId *uuid.UUID) ([]*models.UserTripSchema, *models.ExpenseServiceError)
+	GetTripInvitesDueForReminder(ctx context.Context, expiresBefore *time.Time) ([]*models.UserTripSchema, *models.ExpenseServiceError)
+	UpdateTripInviteReminderDate(ctx context.Context, tripId *uuid.UUID, userId *uuid.UUID, reminderDate *time.Time) *models.ExpenseServiceError
+	DeleteExpiredTripInvites(ctx context.Context) (int64, *models.ExpenseServiceError)
+
+	GetDeletedTripsByUserId(ctx context.Context, userId *uuid.UUID) ([]*models.TripSchema, *models.ExpenseServiceError)
+	RestoreTrip(ctx context.Context, tripId *uuid.UUID) *models.ExpenseServiceError
+	PurgeDeletedTrips(ctx context.Context, deletedBefore *time.Time) (int64, *models.ExpenseServiceError)
 }
 
 type TripRepository struct {
@@ -38,12 +50,12 @@ type TripRepository struct {
 }
 
 func (tr *TripRepository) GetTripById(ctx context.Context, tripId *uuid.UUID) (*models.TripSchema, *models.ExpenseServiceError) {
-	row := tr.DatabaseMgr.ExecuteQueryRow(ctx, "SELECT id, name, description, location, start_date, end_date FROM trip WHERE id = $1", tripId)
+	row := tr.DatabaseMgr.ExecuteQueryRow(ctx, "SELECT id, name, description, location, start_date, end_date, budget, approval_threshold, status, closed_at FROM trip WHERE id = $1 AND deleted_at IS NULL", tripId)
 	return rowToTripSchema(row)
 }
 
 func (tr *TripRepository) GetTripsByUserId(ctx context.Context, userId *uuid.UUID) ([]*models.TripSchema, *models.ExpenseServiceError) {
-	rows, err := tr.DatabaseMgr.ExecuteQuery(ctx, "SELECT t.id, t.name, t.description, t.location, t.start_date, t.end_date FROM trip t JOIN user_trip_association uta on t.id = uta.id_trip WHERE uta.id_user = $1", userId)
+	rows, err := tr.DatabaseMgr.ExecuteQuery(ctx, "SELECT t.id, t.name, t.description, t.location, t.start_date, t.end_date, t.budget, t.approval_threshold, t.status, t.closed_at FROM trip t JOIN user_trip_association uta on t.id = uta.id_trip WHERE uta.id_user = $1 AND t.deleted_at IS NULL", userId)
 	if err != nil {
 		log.Printf("Error while querying trips: %v", err)
 		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
@@ -52,14 +64,9 @@ func (tr *TripRepository) GetTripsByUserId(ctx context.Context, userId *uuid.UUI
 	return rowsToTripSchema(rows)
 }
 
-func (tr *TripRepository) CreateTrip(ctx context.Context, trip *models.TripSchema) *models.ExpenseServiceError {
-	result, err := tr.DatabaseMgr.ExecuteStatement(ctx, "INSERT INTO trip (id, name, description, location, start_date, end_date) VALUES ($1, $2, $3, $4, $5, $6)", trip.TripID, trip.Name, trip.Description, trip.Location, trip.StartDate, trip.EndDate)
-	if err != nil {
-		log.Printf("Error while inserting trip: %v", err)
-		return expense_errors.EXPENSE_INTERNAL_ERROR
-	}
-
-	if rowsAffected := result.RowsAffected(); rowsAffected == 0 {
+func (*TripRepository) CreateTripTx(ctx context.Context, tx pgx.Tx, trip *models.TripSchema) *models.ExpenseServiceError {
+	query := "INSERT INTO trip (id, name, description, location, start_date, end_date, budget, approval_threshold, status) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)"
+	if _, err := tx.Exec(ctx, query, trip.TripID, trip.Name, trip.Description, trip.Location, trip.StartDate, trip.EndDate, trip.Budget, trip.ApprovalThreshold, trip.Status); err != nil {
 		log.Printf("Error while inserting trip: %v", err)
 		return expense_errors.EXPENSE_INTERNAL_ERROR
 	}
@@ -72,8 +79,8 @@ func (tr *TripRepository) UpdateTrip(ctx context.Context, trip *models.TripSchem
 	// https://stackoverflow.com/questions/17267417/how-to-upsert-merge-insert-on-duplicate-update-in-postgresql
 
 	// Update trip
-	updateString := "UPDATE trip SET name = $1, description = $2, location = $3, start_date = $4, end_date = $5 WHERE id = $6"
-	result, err := tr.DatabaseMgr.ExecuteStatement(ctx, updateString, trip.Name, trip.Description, trip.Location, trip.StartDate, trip.EndDate, trip.TripID)
+	updateString := "UPDATE trip SET name = $1, description = $2, location = $3, start_date = $4, end_date = $5, budget = $6, approval_threshold = $7 WHERE id = $8"
+	result, err := tr.DatabaseMgr.ExecuteStatement(ctx, updateString, trip.Name, trip.Description, trip.Location, trip.StartDate, trip.EndDate, trip.Budget, trip.ApprovalThreshold, trip.TripID)
 	if err != nil {
 		log.Printf("Error while updating trip: %v", err)
 		return expense_errors.EXPENSE_INTERNAL_ERROR
@@ -87,8 +94,24 @@ func (tr *TripRepository) UpdateTrip(ctx context.Context, trip *models.TripSchem
 	return nil
 }
 
+// UpdateTripStatusTx moves a trip to another lifecycle status
+func (*TripRepository) UpdateTripStatusTx(ctx context.Context, tx pgx.Tx, tripId *uuid.UUID, status string, closedAt *time.Time) *models.ExpenseServiceError {
+	result, err := tx.Exec(ctx, "UPDATE trip SET status = $1, closed_at = $2 WHERE id = $3", status, closedAt, tripId)
+	if err != nil {
+		log.Printf("Error while updating trip status: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	if result.RowsAffected() == 0 {
+		return expense_errors.EXPENSE_TRIP_NOT_FOUND
+	}
+
+	return nil
+}
+
+// DeleteTrip moves the trip to the trash, it is removed for good by PurgeDeletedTrips
 func (tr *TripRepository) DeleteTrip(ctx context.Context, tripId *uuid.UUID) *models.ExpenseServiceError {
-	result, err := tr.DatabaseMgr.ExecuteStatement(ctx, "DELETE FROM trip WHERE id = $1", tripId)
+	result, err := tr.DatabaseMgr.ExecuteStatement(ctx, "UPDATE trip SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL", time.Now(), tripId)
 	if err != nil {
 		log.Printf("Error while deleting trip: %v", err)
 		return expense_errors.EXPENSE_INTERNAL_ERROR
@@ -102,20 +125,21 @@ func (tr *TripRepository) DeleteTrip(ctx context.Context, tripId *uuid.UUID) *mo
 	return nil
 }
 
-func (tr *TripRepository) AddUserToTrip(ctx context.Context, trip *models.TripSchema, invitedUserId *uuid.UUID, isCreator bool) *models.ExpenseServiceError {
-	// Insert user into user_trip_association
-	_, err := tr.DatabaseMgr.ExecuteStatement(ctx, "INSERT INTO user_trip_association (id_user, id_trip, is_accepted, presence_start_date, presence_end_date) VALUES ($1, $2, $3, $4, $5)", invitedUserId, trip.TripID, isCreator, trip.StartDate, trip.EndDate)
+// AddTripParticipantTx inserts a participant with the given role and acceptance state. Pending participants are invitations
+// that expire at InvitationExpiryDate
+func (*TripRepository) AddTripParticipantTx(ctx context.Context, tx pgx.Tx, userTrip *models.UserTripSchema) *models.ExpenseServiceError {
+	query := "INSERT INTO user_trip_association (id_user, id_trip, is_accepted, role, presence_start_date, presence_end_date, invited_by, invited_at, invite_expires_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) ON CONFLICT DO NOTHING"
+	result, err := tx.Exec(ctx, query, userTrip.UserID, userTrip.TripID, userTrip.HasAccepted, userTrip.Role, userTrip.PresenceStartDate, userTrip.PresenceEndDate, userTrip.InvitedBy, userTrip.InvitationDate, userTrip.InvitationExpiryDate)
 	if err != nil {
-		// If user is already invited, return conflict
-		var pgxErr *pgconn.PgError
-		if ok := errors.As(err, &pgxErr); ok && pgxErr.Code == "unique_violation" {
-			return expense_errors.EXPENSE_CONFLICT
-		}
-
 		log.Printf("Error while inserting user_trip_association: %v", err)
 		return expense_errors.EXPENSE_INTERNAL_ERROR
 	}
 
+	// If no rows were affected, the user already is a participant of the trip
+	if rowsAffected := result.RowsAffected(); rowsAffected == 0 {
+		return expense_errors.EXPENSE_CONFLICT
+	}
+
 	return nil
 }
 
@@ -150,7 +174,7 @@ func (tr *TripRepository) DeclineTripInvite(ctx context.Context, tripId *uuid.UU
 }
 
 func (tr *TripRepository) ValidateIfTripExists(ctx context.Context, tripId *uuid.UUID) *models.ExpenseServiceError {
-	rows, err := tr.DatabaseMgr.ExecuteQuery(ctx, "SELECT id FROM trip WHERE id = $1", tripId)
+	rows, err := tr.DatabaseMgr.ExecuteQuery(ctx, "SELECT id FROM trip WHERE id = $1 AND deleted_at IS NULL", tripId)
 	if err != nil {
 		log.Printf("Error while querying trip: %v", err)
 		return expense_errors.EXPENSE_INTERNAL_ERROR
@@ -195,10 +219,14 @@ func (tr *TripRepository) CheckIfUserIsInvited(ctx context.Context, tripId *uuid
 }
 
 func (tr *TripRepository) GetTripParticipant(ctx context.Context, tripId *uuid.UUID, userId *uuid.UUID) (*models.UserTripSchema, *models.ExpenseServiceError) {
-	row := tr.DatabaseMgr.ExecuteQueryRow(ctx, "SELECT id_user, id_trip, is_accepted, presence_start_date, presence_end_date FROM user_trip_association WHERE id_user = $1 AND id_trip = $2", userId, tripId)
+	row := tr.DatabaseMgr.ExecuteQueryRow(ctx, "SELECT id_user, id_trip, is_accepted, role, presence_start_date, presence_end_date, invited_by, invited_at, invite_expires_at, invite_reminded_at FROM user_trip_association WHERE id_user = $1 AND id_trip = $2", userId, tripId)
 
 	var participant models.UserTripSchema
-	if err := row.Scan(&participant.UserID, &participant.TripID, &participant.HasAccepted, &participant.PresenceStartDate, &participant.PresenceEndDate); err != nil {
+	if err := row.Scan(&participant.UserID, &participant.TripID, &participant.HasAccepted, &participant.Role, &participant.PresenceStartDate, &participant.PresenceEndDate, &participant.InvitedBy, &participant.InvitationDate, &participant.InvitationExpiryDate, &participant.ReminderDate); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, expense_errors.EXPENSE_NOT_FOUND
+		}
+
 		log.Printf("Error while scanning user_trip_association: %v", err)
 		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
 	}
@@ -207,7 +235,7 @@ func (tr *TripRepository) GetTripParticipant(ctx context.Context, tripId *uuid.U
 }
 
 func (tr *TripRepository) GetTripParticipants(ctx context.Context, tripId *uuid.UUID) ([]*models.UserTripSchema, *models.ExpenseServiceError) {
-	rows, err := tr.DatabaseMgr.ExecuteQuery(ctx, "SELECT id_user, id_trip, is_accepted, presence_start_date, presence_end_date FROM user_trip_association WHERE id_trip = $1", tripId)
+	rows, err := tr.DatabaseMgr.ExecuteQuery(ctx, "SELECT id_user, id_trip, is_accepted, role, presence_start_date, presence_end_date, invited_by, invited_at, invite_expires_at, invite_reminded_at FROM user_trip_association WHERE id_trip = $1", tripId)
 	if err != nil {
 		log.Printf("Error while querying user_trip_association: %v", err)
 		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
@@ -217,7 +245,7 @@ func (tr *TripRepository) GetTripParticipants(ctx context.Context, tripId *uuid.
 	var participants []*models.UserTripSchema
 	for rows.Next() {
 		var participant models.UserTripSchema
-		err := rows.Scan(&participant.UserID, &participant.TripID, &participant.HasAccepted, &participant.PresenceStartDate, &participant.PresenceEndDate)
+		err := rows.Scan(&participant.UserID, &participant.TripID, &participant.HasAccepted, &participant.Role, &participant.PresenceStartDate, &participant.PresenceEndDate, &participant.InvitedBy, &participant.InvitationDate, &participant.InvitationExpiryDate, &participant.ReminderDate)
 		if err != nil {
 			log.Printf("Error while scanning user_trip_association: %v", err)
 			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
@@ -229,7 +257,7 @@ func (tr *TripRepository) GetTripParticipants(ctx context.Context, tripId *uuid.
 }
 
 func (tr *TripRepository) GetAcceptedTripParticipants(ctx context.Context, tripId *uuid.UUID) ([]*models.UserTripSchema, *models.ExpenseServiceError) {
-	query := "SELECT id_user, id_trip, is_accepted, presence_start_date, presence_end_date FROM user_trip_association WHERE id_trip = $1 AND is_accepted = $2"
+	query := "SELECT id_user, id_trip, is_accepted, role, presence_start_date, presence_end_date, invited_by, invited_at, invite_expires_at, invite_reminded_at FROM user_trip_association WHERE id_trip = $1 AND is_accepted = $2"
 	rows, err := tr.DatabaseMgr.ExecuteQuery(ctx, query, tripId, true)
 	if err != nil {
 		log.Printf("Error while querying user_trip_association: %v", err)
@@ -240,7 +268,7 @@ func (tr *TripRepository) GetAcceptedTripParticipants(ctx context.Context, tripI
 	participants := make([]*models.UserTripSchema, 0)
 	for rows.Next() {
 		var participant models.UserTripSchema
-		err := rows.Scan(&participant.UserID, &participant.TripID, &participant.HasAccepted, &participant.PresenceStartDate, &participant.PresenceEndDate)
+		err := rows.Scan(&participant.UserID, &participant.TripID, &participant.HasAccepted, &participant.Role, &participant.PresenceStartDate, &participant.PresenceEndDate, &participant.InvitedBy, &participant.InvitationDate, &participant.InvitationExpiryDate, &participant.ReminderDate)
 		if err != nil {
 			log.Printf("Error while scanning user_trip_association: %v", err)
 			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
@@ -251,6 +279,19 @@ func (tr *TripRepository) GetAcceptedTripParticipants(ctx context.Context, tripI
 	return participants, nil
 }
 
+// GetAcceptedTripParticipantsTx returns the accepted participants of a trip including uncommitted changes of the transaction
+func (*TripRepository) GetAcceptedTripParticipantsTx(ctx context.Context, tx pgx.Tx, tripId *uuid.UUID) ([]*models.UserTripSchema, *models.ExpenseServiceError) {
+	query := "SELECT id_user, id_trip, is_accepted, role, presence_start_date, presence_end_date, invited_by, invited_at, invite_expires_at, invite_reminded_at FROM user_trip_association WHERE id_trip = $1 AND is_accepted = $2"
+	rows, err := tx.Query(ctx, query, tripId, true)
+	if err != nil {
+		log.Printf("Error while querying user_trip_association: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	defer rows.Close()
+
+	return rowsToTripParticipantSchema(rows)
+}
+
 func (tr *TripRepository) UpdateTripParticipant(ctx context.Context, userTrip *models.UserTripSchema) *models.ExpenseServiceError {
 	// Update user_trip_association
 	result, err := tr.DatabaseMgr.ExecuteStatement(ctx, "UPDATE user_trip_association SET is_accepted = $1, presence_start_date = $2, presence_end_date = $3 WHERE id_user = $4 AND id_trip = $5", userTrip.HasAccepted, userTrip.PresenceStartDate, userTrip.PresenceEndDate, userTrip.UserID, userTrip.TripID)
@@ -278,6 +319,133 @@ func (*TripRepository) UpdateTripParticipantTx(ctx context.Context, tx pgx.Tx, u
 	return nil
 }
 
+// UpdateTripParticipantRoleTx changes the role of a trip participant
+func (*TripRepository) UpdateTripParticipantRoleTx(ctx context.Context, tx pgx.Tx, tripId *uuid.UUID, userId *uuid.UUID, role string) *models.ExpenseServiceError {
+	result, err := tx.Exec(ctx, "UPDATE user_trip_association SET role = $1 WHERE id_user = $2 AND id_trip = $3", role, userId, tripId)
+	if err != nil {
+		log.Printf("Error while updating user_trip_association: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	if rowsAffected := result.RowsAffected(); rowsAffected == 0 {
+		return expense_errors.EXPENSE_NOT_FOUND
+	}
+
+	return nil
+}
+
+func (*TripRepository) DeleteTripParticipantTx(ctx context.Context, tx pgx.Tx, tripId *uuid.UUID, userId *uuid.UUID) *models.ExpenseServiceError {
+	result, err := tx.Exec(ctx, "DELETE FROM user_trip_association WHERE id_user = $1 AND id_trip = $2", userId, tripId)
+	if err != nil {
+		log.Printf("Error while deleting user_trip_association: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	if rowsAffected := result.RowsAffected(); rowsAffected == 0 {
+		return expense_errors.EXPENSE_NOT_FOUND
+	}
+
+	return nil
+}
+
+// GetPendingTripInvitesByUserId returns the invitations of a user that were neither accepted nor have expired
+func (tr *TripRepository) GetPendingTripInvitesByUserId(ctx context.Context, userId *uuid.UUID) ([]*models.UserTripSchema, *models.ExpenseServiceError) {
+	query := "SELECT id_user, id_trip, is_accepted, role, presence_start_date, presence_end_date, invited_by, invited_at, invite_expires_at, invite_reminded_at FROM user_trip_association WHERE id_user = $1 AND is_accepted = false AND (invite_expires_at IS NULL OR invite_expires_at > $2) AND id_trip IN (SELECT id FROM trip WHERE deleted_at IS NULL) ORDER BY invited_at DESC"
+	rows, err := tr.DatabaseMgr.ExecuteQuery(ctx, query, userId, time.Now())
+	if err != nil {
+		log.Printf("Error while querying user_trip_association: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	defer rows.Close()
+
+	return rowsToTripParticipantSchema(rows)
+}
+
+// GetTripInvitesDueForReminder returns the pending invitations that expire before the given date and were not reminded yet
+func (tr *TripRepository) GetTripInvitesDueForReminder(ctx context.Context, expiresBefore *time.Time) ([]*models.UserTripSchema, *models.ExpenseServiceError) {
+	query := "SELECT id_user, id_trip, is_accepted, role, presence_start_date, presence_end_date, invited_by, invited_at, invite_expires_at, invite_reminded_at FROM user_trip_association WHERE is_accepted = false AND invite_reminded_at IS NULL AND invite_expires_at > $1 AND invite_expires_at <= $2 AND id_trip IN (SELECT id FROM trip WHERE deleted_at IS NULL)"
+	rows, err := tr.DatabaseMgr.ExecuteQuery(ctx, query, time.Now(), expiresBefore)
+	if err != nil {
+		log.Printf("Error while querying user_trip_association: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	defer rows.Close()
+
+	return rowsToTripParticipantSchema(rows)
+}
+
+func (tr *TripRepository) UpdateTripInviteReminderDate(ctx context.Context, tripId *uuid.UUID, userId *uuid.UUID, reminderDate *time.Time) *models.ExpenseServiceError {
+	_, err := tr.DatabaseMgr.ExecuteStatement(ctx, "UPDATE user_trip_association SET invite_reminded_at = $1 WHERE id_user = $2 AND id_trip = $3", reminderDate, userId, tripId)
+	if err != nil {
+		log.Printf("Error while updating user_trip_association: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return nil
+}
+
+// DeleteExpiredTripInvites removes all invitations that were not accepted before they expired
+func (tr *TripRepository) DeleteExpiredTripInvites(ctx context.Context) (int64, *models.ExpenseServiceError) {
+	result, err := tr.DatabaseMgr.ExecuteStatement(ctx, "DELETE FROM user_trip_association WHERE is_accepted = false AND invite_expires_at <= $1", time.Now())
+	if err != nil {
+		log.Printf("Error while deleting expired user_trip_association: %v", err)
+		return 0, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// GetDeletedTripsByUserId returns the trips in the trash that the user has accepted, most recently deleted first
+func (tr *TripRepository) GetDeletedTripsByUserId(ctx context.Context, userId *uuid.UUID) ([]*models.TripSchema, *models.ExpenseServiceError) {
+	query := "SELECT t.id, t.name, t.description, t.location, t.start_date, t.end_date, t.budget, t.approval_threshold, t.status, t.closed_at, t.deleted_at FROM trip t " +
+		"JOIN user_trip_association uta on t.id = uta.id_trip WHERE uta.id_user = $1 AND uta.is_accepted = true AND t.deleted_at IS NOT NULL ORDER BY t.deleted_at DESC"
+	rows, err := tr.DatabaseMgr.ExecuteQuery(ctx, query, userId)
+	if err != nil {
+		log.Printf("Error while querying deleted trips: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	defer rows.Close()
+
+	trips := make([]*models.TripSchema, 0)
+	for rows.Next() {
+		var trip models.TripSchema
+		if err := rows.Scan(&trip.TripID, &trip.Name, &trip.Description, &trip.Location, &trip.StartDate, &trip.EndDate, &trip.Budget, &trip.ApprovalThreshold, &trip.Status, &trip.ClosedAt, &trip.DeletedAt); err != nil {
+			log.Printf("Error while scanning deleted trip: %v", err)
+			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+		}
+		trips = append(trips, &trip)
+	}
+
+	return trips, nil
+}
+
+// RestoreTrip moves a trip out of the trash. Its costs and debts were kept while it was deleted
+func (tr *TripRepository) RestoreTrip(ctx context.Context, tripId *uuid.UUID) *models.ExpenseServiceError {
+	result, err := tr.DatabaseMgr.ExecuteStatement(ctx, "UPDATE trip SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL", tripId)
+	if err != nil {
+		log.Printf("Error while restoring trip: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	if rowsAffected := result.RowsAffected(); rowsAffected == 0 {
+		return expense_errors.EXPENSE_TRIP_NOT_FOUND
+	}
+
+	return nil
+}
+
+// PurgeDeletedTrips removes the trips that were deleted before the given date for good, together with everything that
+// belongs to them
+func (tr *TripRepository) PurgeDeletedTrips(ctx context.Context, deletedBefore *time.Time) (int64, *models.ExpenseServiceError) {
+	result, err := tr.DatabaseMgr.ExecuteStatement(ctx, "DELETE FROM trip WHERE deleted_at <= $1", deletedBefore)
+	if err != nil {
+		log.Printf("Error while purging deleted trips: %v", err)
+		return 0, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return result.RowsAffected(), nil
+}
+
 // ************************************************************
 // ********************* Helper Functions *********************
 // ************************************************************
@@ -285,7 +453,7 @@ func (*TripRepository) UpdateTripParticipantTx(ctx context.Context, tx pgx.Tx, u
 // rowToTripSchema converts a row to a TripSchema
 func rowToTripSchema(row pgx.Row) (*models.TripSchema, *models.ExpenseServiceError) {
 	trip := models.TripSchema{}
-	if err := row.Scan(&trip.TripID, &trip.Name, &trip.Description, &trip.Location, &trip.StartDate, &trip.EndDate); err != nil {
+	if err := row.Scan(&trip.TripID, &trip.Name, &trip.Description, &trip.Location, &trip.StartDate, &trip.EndDate, &trip.Budget, &trip.ApprovalThreshold, &trip.Status, &trip.ClosedAt); err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, expense_errors.EXPENSE_TRIP_NOT_FOUND
 		}
@@ -302,7 +470,7 @@ func rowsToTripSchema(rows pgx.Rows) ([]*models.TripSchema, *models.ExpenseServi
 	trips := make([]*models.TripSchema, 0) // It is important to initialize the slice with 0 length so that it is serialized to [] instead of null
 	for rows.Next() {
 		var trip models.TripSchema
-		err := rows.Scan(&trip.TripID, &trip.Name, &trip.Description, &trip.Location, &trip.StartDate, &trip.EndDate)
+		err := rows.Scan(&trip.TripID, &trip.Name, &trip.Description, &trip.Location, &trip.StartDate, &trip.EndDate, &trip.Budget, &trip.ApprovalThreshold, &trip.Status, &trip.ClosedAt)
 		if err != nil {
 			log.Printf("Error while scanning trip: %v", err)
 			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
@@ -312,3 +480,19 @@ func rowsToTripSchema(rows pgx.Rows) ([]*models.TripSchema, *models.ExpenseServi
 
 	return trips, nil
 }
+
+// rowsToTripParticipantSchema converts a set of rows to a slice of UserTripSchema
+func rowsToTripParticipantSchema(rows pgx.Rows) ([]*models.UserTripSchema, *models.ExpenseServiceError) {
+	participants := make([]*models.UserTripSchema, 0)
+	for rows.Next() {
+		var participant models.UserTripSchema
+		err := rows.Scan(&participant.UserID, &participant.TripID, &participant.HasAccepted, &participant.Role, &participant.PresenceStartDate, &participant.PresenceEndDate, &participant.InvitedBy, &participant.InvitationDate, &participant.InvitationExpiryDate, &participant.ReminderDate)
+		if err != nil {
+			log.Printf("Error while scanning user_trip_association: %v", err)
+			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+		}
+		participants = append(participants, &participant)
+	}
+
+	return participants, nil
+}
@@ -0,0 +1,158 @@
+package repositories
+
+import (
+	"context"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/expense_errors"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/managers"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"log"
+)
+
+type TripTemplateRepo interface {
+	GetTripTemplateByID(ctx context.Context, tripTemplateId *uuid.UUID) (*models.TripTemplateSchema, *models.ExpenseServiceError)
+	GetTripTemplatesByUserId(ctx context.Context, userId *uuid.UUID) ([]*models.TripTemplateSchema, *models.ExpenseServiceError)
+	GetTripTemplateCostCategories(ctx context.Context, tripTemplateId *uuid.UUID) ([]*models.TripTemplateCostCategorySchema, *models.ExpenseServiceError)
+	GetTripTemplateParticipants(ctx context.Context, tripTemplateId *uuid.UUID) ([]*models.TripTemplateParticipantSchema, *models.ExpenseServiceError)
+
+	AddTripTemplateTx(ctx context.Context, tx pgx.Tx, template *models.TripTemplateSchema) *models.ExpenseServiceError
+	AddTripTemplateCostCategoryTx(ctx context.Context, tx pgx.Tx, costCategory *models.TripTemplateCostCategorySchema) *models.ExpenseServiceError
+	AddTripTemplateParticipantTx(ctx context.Context, tx pgx.Tx, participant *models.TripTemplateParticipantSchema) *models.ExpenseServiceError
+	DeleteTripTemplate(ctx context.Context, tripTemplateId *uuid.UUID) *models.ExpenseServiceError
+}
+
+type TripTemplateRepository struct {
+	DatabaseMgr managers.DatabaseMgr
+}
+
+const tripTemplateSelect = "SELECT id, id_user, name, description, location, budget, approval_threshold, created_at FROM trip_template"
+
+func (ttr *TripTemplateRepository) GetTripTemplateByID(ctx context.Context, tripTemplateId *uuid.UUID) (*models.TripTemplateSchema, *models.ExpenseServiceError) {
+	row := ttr.DatabaseMgr.ExecuteQueryRow(ctx, tripTemplateSelect+" WHERE id = $1", tripTemplateId)
+	return rowToTripTemplateSchema(row)
+}
+
+// GetTripTemplatesByUserId returns the templates saved by the user, the newest first
+func (ttr *TripTemplateRepository) GetTripTemplatesByUserId(ctx context.Context, userId *uuid.UUID) ([]*models.TripTemplateSchema, *models.ExpenseServiceError) {
+	rows, err := ttr.DatabaseMgr.ExecuteQuery(ctx, tripTemplateSelect+" WHERE id_user = $1 ORDER BY created_at DESC", userId)
+	if err != nil {
+		log.Printf("Error while querying trip templates: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	defer rows.Close()
+
+	templates := make([]*models.TripTemplateSchema, 0)
+	for rows.Next() {
+		template, repoErr := rowToTripTemplateSchema(rows)
+		if repoErr != nil {
+			return nil, repoErr
+		}
+		templates = append(templates, template)
+	}
+
+	return templates, nil
+}
+
+func (ttr *TripTemplateRepository) GetTripTemplateCostCategories(ctx context.Context, tripTemplateId *uuid.UUID) ([]*models.TripTemplateCostCategorySchema, *models.ExpenseServiceError) {
+	query := "SELECT id, id_trip_template, name, description, icon, color, budget, alert_threshold_percent, parent_name FROM trip_template_cost_category WHERE id_trip_template = $1 ORDER BY name"
+	rows, err := ttr.DatabaseMgr.ExecuteQuery(ctx, query, tripTemplateId)
+	if err != nil {
+		log.Printf("Error while querying trip template cost categories: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	defer rows.Close()
+
+	costCategories := make([]*models.TripTemplateCostCategorySchema, 0)
+	for rows.Next() {
+		var costCategory models.TripTemplateCostCategorySchema
+		if err := rows.Scan(&costCategory.TripTemplateCostCategoryID, &costCategory.TripTemplateID, &costCategory.Name, &costCategory.Description, &costCategory.Icon, &costCategory.Color, &costCategory.Budget, &costCategory.AlertThresholdPercent, &costCategory.ParentName); err != nil {
+			log.Printf("Error while scanning trip template cost category: %v", err)
+			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+		}
+		costCategories = append(costCategories, &costCategory)
+	}
+
+	return costCategories, nil
+}
+
+func (ttr *TripTemplateRepository) GetTripTemplateParticipants(ctx context.Context, tripTemplateId *uuid.UUID) ([]*models.TripTemplateParticipantSchema, *models.ExpenseServiceError) {
+	rows, err := ttr.DatabaseMgr.ExecuteQuery(ctx, "SELECT id_trip_template, id_user, role FROM trip_template_participant WHERE id_trip_template = $1", tripTemplateId)
+	if err != nil {
+		log.Printf("Error while querying trip template participants: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+	defer rows.Close()
+
+	participants := make([]*models.TripTemplateParticipantSchema, 0)
+	for rows.Next() {
+		var participant models.TripTemplateParticipantSchema
+		if err := rows.Scan(&participant.TripTemplateID, &participant.UserID, &participant.Role); err != nil {
+			log.Printf("Error while scanning trip template participant: %v", err)
+			return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+		}
+		participants = append(participants, &participant)
+	}
+
+	return participants, nil
+}
+
+func (*TripTemplateRepository) AddTripTemplateTx(ctx context.Context, tx pgx.Tx, template *models.TripTemplateSchema) *models.ExpenseServiceError {
+	query := "INSERT INTO trip_template (id, id_user, name, description, location, budget, approval_threshold, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)"
+	if _, err := tx.Exec(ctx, query, template.TripTemplateID, template.UserID, template.Name, template.Description, template.Location, template.Budget, template.ApprovalThreshold, template.CreationDate); err != nil {
+		log.Printf("Error while inserting trip template: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return nil
+}
+
+func (*TripTemplateRepository) AddTripTemplateCostCategoryTx(ctx context.Context, tx pgx.Tx, costCategory *models.TripTemplateCostCategorySchema) *models.ExpenseServiceError {
+	query := "INSERT INTO trip_template_cost_category (id, id_trip_template, name, description, icon, color, budget, alert_threshold_percent, parent_name) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)"
+	if _, err := tx.Exec(ctx, query, costCategory.TripTemplateCostCategoryID, costCategory.TripTemplateID, costCategory.Name, costCategory.Description, costCategory.Icon, costCategory.Color, costCategory.Budget, costCategory.AlertThresholdPercent, costCategory.ParentName); err != nil {
+		log.Printf("Error while inserting trip template cost category: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return nil
+}
+
+func (*TripTemplateRepository) AddTripTemplateParticipantTx(ctx context.Context, tx pgx.Tx, participant *models.TripTemplateParticipantSchema) *models.ExpenseServiceError {
+	query := "INSERT INTO trip_template_participant (id_trip_template, id_user, role) VALUES ($1, $2, $3)"
+	if _, err := tx.Exec(ctx, query, participant.TripTemplateID, participant.UserID, participant.Role); err != nil {
+		log.Printf("Error while inserting trip template participant: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return nil
+}
+
+// DeleteTripTemplate deletes a template together with its cost categories and participants
+func (ttr *TripTemplateRepository) DeleteTripTemplate(ctx context.Context, tripTemplateId *uuid.UUID) *models.ExpenseServiceError {
+	result, err := ttr.DatabaseMgr.ExecuteStatement(ctx, "DELETE FROM trip_template WHERE id = $1", tripTemplateId)
+	if err != nil {
+		log.Printf("Error while deleting trip template: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	if result.RowsAffected() == 0 {
+		return expense_errors.EXPENSE_NOT_FOUND
+	}
+
+	return nil
+}
+
+// rowToTripTemplateSchema converts a row to a TripTemplateSchema
+func rowToTripTemplateSchema(row pgx.Row) (*models.TripTemplateSchema, *models.ExpenseServiceError) {
+	var template models.TripTemplateSchema
+	if err := row.Scan(&template.TripTemplateID, &template.UserID, &template.Name, &template.Description, &template.Location, &template.Budget, &template.ApprovalThreshold, &template.CreationDate); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, expense_errors.EXPENSE_NOT_FOUND
+		}
+
+		log.Printf("Error while scanning trip template: %v", err)
+		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	return &template, nil
+}
@@ -39,6 +39,9 @@ type UserRepo interface {
 	ConfirmTokenByType(ctx context.Context, userId *uuid.UUID, tokenType string) *models.ExpenseServiceError
 
 	FindUsersLikeUsername(ctx context.Context, username string) ([]*models.UserSchema, *models.ExpenseServiceError)
+
+	CreateGuestUserTx(ctx context.Context, tx pgx.Tx, guest *models.UserSchema) *models.ExpenseServiceError
+	TransferGuestUserTx(ctx context.Context, tx pgx.Tx, guestId *uuid.UUID, userId *uuid.UUID) *models.ExpenseServiceError
 }
 
 type UserRepository struct {
@@ -117,9 +120,9 @@ func (ur *UserRepository) DeleteUser(ctx context.Context, userId *uuid.UUID) *mo
 
 func (ur *UserRepository) GetUserById(ctx context.Context, userId *uuid.UUID) (*models.UserSchema, *models.ExpenseServiceError) {
 	user := &models.UserSchema{}
-	row := ur.DatabaseMgr.ExecuteQueryRow(ctx, "SELECT * FROM \"user\" WHERE id = $1", userId)
+	row := ur.DatabaseMgr.ExecuteQueryRow(ctx, "SELECT id, username, firstname, lastname, location, email, password, activated, profile_pic, birthday, created_at, is_guest FROM \"user\" WHERE id = $1", userId)
 	if err := row.Scan(&user.UserID, &user.Username, &user.FirstName, &user.LastName, &user.Location, &user.Email,
-		&user.Password, &user.Activated, &user.ProfilePicture, &user.Birthday, &user.CreatedAt); err != nil {
+		&user.Password, &user.Activated, &user.ProfilePicture, &user.Birthday, &user.CreatedAt, &user.IsGuest); err != nil {
 		// Check if no rows were returned, if so return error EXPENSE_USER_NOT_FOUND
 		if err == pgx.ErrNoRows {
 			return nil, expense_errors.EXPENSE_USER_NOT_FOUND
@@ -134,8 +137,8 @@ func (ur *UserRepository) GetUserById(ctx context.Context, userId *uuid.UUID) (*
 
 func (ur *UserRepository) GetUserBySchema(ctx context.Context, request *models.UserSchema) (*models.UserSchema, *models.ExpenseServiceError) {
 	user := &models.UserSchema{}
-	row := ur.DatabaseMgr.ExecuteQueryRow(ctx, "SELECT id, username, email, password, activated FROM \"user\" WHERE username = $1 OR email = $2", request.Username, request.Email)
-	if err := row.Scan(&user.UserID, &user.Username, &user.Email, &user.Password, &user.Activated); err != nil {
+	row := ur.DatabaseMgr.ExecuteQueryRow(ctx, "SELECT id, username, email, password, activated, is_guest FROM \"user\" WHERE username = $1 OR email = $2", request.Username, request.Email)
+	if err := row.Scan(&user.UserID, &user.Username, &user.Email, &user.Password, &user.Activated, &user.IsGuest); err != nil {
 		// Check if no rows were returned, if so return error EXPENSE_USER_NOT_FOUND
 		log.Printf("Error while getting user by schema: %v", err)
 		if err == pgx.ErrNoRows {
@@ -292,7 +295,7 @@ func (ur *UserRepository) ValidateIfUserIsActivated(ctx context.Context, userId
 }
 
 func (ur *UserRepository) FindUsersLikeUsername(ctx context.Context, username string) ([]*models.UserSchema, *models.ExpenseServiceError) {
-	rows, err := ur.DatabaseMgr.ExecuteQuery(ctx, "SELECT id, username, email, activated FROM \"user\" WHERE username LIKE $1", "%"+username+"%")
+	rows, err := ur.DatabaseMgr.ExecuteQuery(ctx, "SELECT id, username, email, activated FROM \"user\" WHERE username LIKE $1 AND is_guest = false", "%"+username+"%")
 	if err != nil {
 		log.Printf("Error while getting user by username: %v", err)
 		return nil, expense_errors.EXPENSE_INTERNAL_ERROR
@@ -357,3 +360,61 @@ func (ur *UserRepository) ValidateUsernameExistence(ctx context.Context, usernam
 
 	return nil
 }
+
+// CreateGuestUserTx inserts a placeholder user without credentials. Guests cannot log in and are only referenced by their trip
+func (*UserRepository) CreateGuestUserTx(ctx context.Context, tx pgx.Tx, guest *models.UserSchema) *models.ExpenseServiceError {
+	query := "INSERT INTO \"user\" (id, username, firstname, lastname, email, password, activated, profile_pic, created_at, is_guest) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) ON CONFLICT DO NOTHING"
+	result, err := tx.Exec(ctx, query, guest.UserID, guest.Username, guest.FirstName, guest.LastName, guest.Email, guest.Password, false, guest.ProfilePicture, guest.CreatedAt, true)
+	if err != nil {
+		log.Printf("Error while creating guest user: %v", err)
+		return expense_errors.EXPENSE_INTERNAL_ERROR
+	}
+
+	if rowsAffected := result.RowsAffected(); rowsAffected == 0 {
+		return expense_errors.EXPENSE_USERNAME_EXISTS
+	}
+
+	return nil
+}
+
+// TransferGuestUserTx moves everything a guest took part in over to a real account, including the versions of their costs,
+// and deletes the guest afterwards
+func (*UserRepository) TransferGuestUserTx(ctx context.Context, tx pgx.Tx, guestId *uuid.UUID, userId *uuid.UUID) *models.ExpenseServiceError {
+	statements := []string{
+		"UPDATE user_trip_association SET id_user = $2 WHERE id_user = $1",
+		"UPDATE user_cost_association SET id_user = $2 WHERE id_user = $1",
+		"UPDATE user_planned_cost_association SET id_user = $2 WHERE id_user = $1",
+		"UPDATE debt SET id_creditor = $2 WHERE id_creditor = $1",
+		"UPDATE debt SET id_debtor = $2 WHERE id_debtor = $1",
+		"UPDATE transaction SET id_creditor = $2 WHERE id_creditor = $1",
+		"UPDATE transaction SET id_debtor = $2 WHERE id_debtor = $1",
+		"UPDATE kitty_entry SET id_user = $2 WHERE id_user = $1",
+		"UPDATE cost SET created_by = $2 WHERE created_by = $1",
+		"UPDATE cost SET updated_by = $2 WHERE updated_by = $1",
+		"UPDATE cost_version SET created_by = $2 WHERE created_by = $1",
+		"UPDATE cost_comment SET id_user = $2 WHERE id_user = $1",
+		"UPDATE cost_suggestion SET id_user = $2 WHERE id_user = $1",
+		"UPDATE cost_suggestion SET decided_by = $2 WHERE decided_by = $1",
+		"UPDATE trip_activity SET id_user = $2 WHERE id_user = $1",
+		"UPDATE user_trip_association SET invited_by = $2 WHERE invited_by = $1",
+		// Rows the user already has are kept, the ones of the guest are removed together with the guest
+		"UPDATE cost_approval ca SET id_user = $2 WHERE ca.id_user = $1 AND NOT EXISTS (SELECT 1 FROM cost_approval o WHERE o.id_cost = ca.id_cost AND o.id_user = $2)",
+		"UPDATE cost_category_rule_debtor d SET id_user = $2 WHERE d.id_user = $1 AND NOT EXISTS (SELECT 1 FROM cost_category_rule_debtor o WHERE o.id_cost_category_rule = d.id_cost_category_rule AND o.id_user = $2)",
+		"UPDATE trip_template_participant p SET id_user = $2 WHERE p.id_user = $1 AND NOT EXISTS (SELECT 1 FROM trip_template_participant o WHERE o.id_trip_template = p.id_trip_template AND o.id_user = $2)",
+		// Versions of costs keep their contributions as JSON, so the guest is replaced inside the snapshots
+		"UPDATE cost_version SET snapshot = jsonb_set(snapshot, '{contributions}', (" +
+			"SELECT jsonb_agg(CASE WHEN contribution->>'userId' = $1::uuid::text THEN jsonb_set(contribution, '{userId}', to_jsonb($2::uuid::text)) ELSE contribution END ORDER BY index) " +
+			"FROM jsonb_array_elements(snapshot->'contributions') WITH ORDINALITY AS contributions(contribution, index))) " +
+			"WHERE snapshot->'contributions' @> jsonb_build_array(jsonb_build_object('userId', $1::uuid::text))",
+		"DELETE FROM \"user\" WHERE id = $1 AND is_guest = true",
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.Exec(ctx, statement, guestId, userId); err != nil {
+			log.Printf("Error while transferring guest user: %v", err)
+			return expense_errors.EXPENSE_INTERNAL_ERROR
+		}
+	}
+
+	return nil
+}
@@ -4,11 +4,13 @@ import (
 	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/repositories"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"net/http"
+	"time"
 
 	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/controllers"
 	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/handlers"
 	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/managers"
 	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/middlewares"
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
 	"github.com/gin-gonic/gin"
 )
 
@@ -21,6 +23,12 @@ type Controllers struct {
 	DebtController         controllers.DebtCtl
 	MailController         controllers.MailCtl
 	TransactionController  controllers.TransactionCtl
+	PlannedCostController  controllers.PlannedCostCtl
+	KittyController        controllers.KittyCtl
+	TripTemplateController controllers.TripTemplateCtl
+
+	CostCategoryRuleController controllers.CostCategoryRuleCtl
+	TripActivityController     controllers.TripActivityCtl
 }
 
 func createRouter(dbConnection *pgxpool.Pool) *gin.Engine {
@@ -49,6 +57,11 @@ func createRouter(dbConnection *pgxpool.Pool) *gin.Engine {
 	securedTripApiv1 := securedApiv1.Group("/trips/:tripId")
 	securedTripApiv1.Use(middlewares.TripValidationMiddleware(databaseMgr))
 
+	// Settling trips no longer accept cost changes, but pending costs and suggestions can still be decided on so the trip
+	// can be closed. The finances of closed trips are locked completely
+	costLock := middlewares.TripStatusLockMiddleware(databaseMgr, models.TripStatusSettling, models.TripStatusClosed, models.TripStatusArchived)
+	financeLock := middlewares.TripStatusLockMiddleware(databaseMgr, models.TripStatusClosed, models.TripStatusArchived)
+
 	// Initialize Mailgun client
 	mgInstance := managers.InitializeMailgunClient()
 	if mgInstance == nil {
@@ -87,34 +100,131 @@ func createRouter(dbConnection *pgxpool.Pool) *gin.Engine {
 		DatabaseMgr: databaseMgr,
 	}
 
+	plannedCostRepo := &repositories.PlannedCostRepository{
+		DatabaseMgr: databaseMgr,
+	}
+
+	kittyRepo := &repositories.KittyRepository{
+		DatabaseMgr: databaseMgr,
+	}
+
+	costSuggestionRepo := &repositories.CostSuggestionRepository{
+		DatabaseMgr: databaseMgr,
+	}
+
+	costApprovalRepo := &repositories.CostApprovalRepository{
+		DatabaseMgr: databaseMgr,
+	}
+
+	tripInviteRepo := &repositories.TripInviteRepository{
+		DatabaseMgr: databaseMgr,
+	}
+
+	tripTemplateRepo := &repositories.TripTemplateRepository{
+		DatabaseMgr: databaseMgr,
+	}
+
+	costCategoryRuleRepo := &repositories.CostCategoryRuleRepository{
+		DatabaseMgr: databaseMgr,
+	}
+
+	costCommentRepo := &repositories.CostCommentRepository{
+		DatabaseMgr: databaseMgr,
+	}
+
+	costVersionRepo := &repositories.CostVersionRepository{
+		DatabaseMgr: databaseMgr,
+	}
+
+	tripActivityRepo := &repositories.TripActivityRepository{
+		DatabaseMgr: databaseMgr,
+	}
+
+	jobMgr := &managers.JobManager{}
+
+	// Controllers publish trip events after their changes are committed, the activity feed records all of them
+	eventMgr := &managers.EventManager{}
+	tripActivityController := &controllers.TripActivityController{
+		TripActivityRepo: tripActivityRepo,
+		TripRepo:         tripRepo,
+		UserRepo:         userRepo,
+	}
+	eventMgr.Subscribe(tripActivityController.RecordTripEvent)
+
+	costController := &controllers.CostController{
+		MailMgr:            mailMgr,
+		DatabaseMgr:        databaseMgr,
+		EventMgr:           eventMgr,
+		JobMgr:             jobMgr,
+		CostRepo:           costRepo,
+		UserRepo:           userRepo,
+		TripRepo:           tripRepo,
+		CostCategoryRepo:   costCategoryRepo,
+		DebtRepo:           debtRepo,
+		KittyRepo:          kittyRepo,
+		CostSuggestionRepo: costSuggestionRepo,
+		CostApprovalRepo:   costApprovalRepo,
+
+		CostCategoryRuleRepo: costCategoryRuleRepo,
+		CostCommentRepo:      costCommentRepo,
+		CostVersionRepo:      costVersionRepo,
+	}
+
+	tripController := &controllers.TripController{
+		MailMgr:          mailMgr,
+		DatabaseMgr:      databaseMgr,
+		EventMgr:         eventMgr,
+		TripRepo:         tripRepo,
+		UserRepo:         userRepo,
+		CostRepo:         costRepo,
+		CostCategoryRepo: costCategoryRepo,
+		DebtRepo:         debtRepo,
+		TripInviteRepo:   tripInviteRepo,
+		TransactionRepo:  transactionRepo,
+		KittyRepo:        kittyRepo,
+		CostController:   costController,
+	}
+
 	controller := Controllers{
 		UserController: &controllers.UserController{
-			MailMgr:     mailMgr,
-			DatabaseMgr: databaseMgr,
-			ImageMgr:    imageMgr,
-			UserRepo:    userRepo,
+			MailMgr:        mailMgr,
+			DatabaseMgr:    databaseMgr,
+			EventMgr:       eventMgr,
+			ImageMgr:       imageMgr,
+			UserRepo:       userRepo,
+			TripRepo:       tripRepo,
+			DebtRepo:       debtRepo,
+			TripInviteRepo: tripInviteRepo,
 		},
-		TripController: &controllers.TripController{
+		TripController: tripController,
+		TripTemplateController: &controllers.TripTemplateController{
 			DatabaseMgr:      databaseMgr,
+			TripTemplateRepo: tripTemplateRepo,
 			TripRepo:         tripRepo,
 			UserRepo:         userRepo,
-			CostRepo:         costRepo,
 			CostCategoryRepo: costCategoryRepo,
-			DebtRepo:         debtRepo,
+			TripController:   tripController,
 		},
 		CostCategoryController: &controllers.CostCategoryController{
 			DatabaseMgr:      databaseMgr,
+			EventMgr:         eventMgr,
 			CostCategoryRepo: costCategoryRepo,
 			CostRepo:         costRepo,
-		},
-		CostController: &controllers.CostController{
-			DatabaseMgr:      databaseMgr,
-			CostRepo:         costRepo,
-			UserRepo:         userRepo,
+			PlannedCostRepo:  plannedCostRepo,
 			TripRepo:         tripRepo,
-			CostCategoryRepo: costCategoryRepo,
-			DebtRepo:         debtRepo,
+
+			CostCategoryRuleRepo: costCategoryRuleRepo,
 		},
+		CostCategoryRuleController: &controllers.CostCategoryRuleController{
+			DatabaseMgr:          databaseMgr,
+			CostCategoryRuleRepo: costCategoryRuleRepo,
+			CostCategoryRepo:     costCategoryRepo,
+			CostRepo:             costRepo,
+			UserRepo:             userRepo,
+			TripRepo:             tripRepo,
+		},
+		CostController: costController,
+
 		DebtController: &controllers.DebtController{
 			DatabaseMgr:     databaseMgr,
 			DebtRepo:        debtRepo,
@@ -124,16 +234,39 @@ func createRouter(dbConnection *pgxpool.Pool) *gin.Engine {
 		},
 		TransactionController: &controllers.TransactionController{
 			DatabaseMgr:     databaseMgr,
+			EventMgr:        eventMgr,
 			TransactionRepo: transactionRepo,
 			UserRepo:        userRepo,
 			TripRepo:        tripRepo,
 			DebtRepo:        debtRepo,
 		},
+		PlannedCostController: &controllers.PlannedCostController{
+			DatabaseMgr:      databaseMgr,
+			PlannedCostRepo:  plannedCostRepo,
+			CostRepo:         costRepo,
+			CostCategoryRepo: costCategoryRepo,
+			UserRepo:         userRepo,
+			TripRepo:         tripRepo,
+			CostController:   costController,
+		},
+		KittyController: &controllers.KittyController{
+			DatabaseMgr: databaseMgr,
+			KittyRepo:   kittyRepo,
+			UserRepo:    userRepo,
+			TripRepo:    tripRepo,
+			DebtRepo:    debtRepo,
+		},
 		MailController: &controllers.MailController{
 			MailMgr: mailMgr,
 		},
+		TripActivityController: tripActivityController,
 	}
 
+	// Background Jobs
+	jobMgr.ScheduleJob("trip-invite-reminders", time.Hour, controller.TripController.RemindPendingTripInvites)
+	jobMgr.ScheduleJob("expired-trip-invites", time.Hour, controller.TripController.DeleteExpiredTripInvites)
+	jobMgr.ScheduleJob("trash-purge", time.Hour, controller.TripController.PurgeTrash)
+
 	router.Handle(http.MethodGet, "/lifecheck", handlers.LifeCheckHandler())
 	apiv1.Handle(http.MethodPost, "/send-email", handlers.SendContactMailHandler(controller.MailController))
 
@@ -150,46 +283,110 @@ func createRouter(dbConnection *pgxpool.Pool) *gin.Engine {
 	apiv1.Handle(http.MethodPost, "/users/reset-password", handlers.ResetPasswordHandler(controller.UserController))
 	securedApiv1.Handle(http.MethodGet, "/users/suggest", handlers.SuggestUsersHandler(controller.UserController))
 	securedApiv1.Handle(http.MethodGet, "/users", handlers.GetUserDetailsHandler(controller.UserController))
+	securedApiv1.Handle(http.MethodGet, "/users/invites", handlers.GetPendingTripInvitesHandler(controller.UserController))
 	securedApiv1.Handle(http.MethodPatch, "/users", handlers.UpdateUserHandler(controller.UserController))
 	securedApiv1.Handle(http.MethodDelete, "/users", handlers.DeleteUserHandler(controller.UserController))
 
 	// Trip Routes
 	securedApiv1.Handle(http.MethodPost, "/trips", handlers.CreateTripEntryHandler(controller.TripController))
 	securedApiv1.Handle(http.MethodGet, "/trips", handlers.GetTripEntriesHandler(controller.TripController))
+	securedApiv1.Handle(http.MethodGet, "/trips/trash", handlers.GetDeletedTripsHandler(controller.TripController))
+	securedApiv1.Handle(http.MethodPost, "/trips/:tripId/restore", handlers.RestoreTripHandler(controller.TripController))
 	securedTripApiv1.Handle(http.MethodGet, "", handlers.GetTripDetailsHandler(controller.TripController))
 	securedTripApiv1.Handle(http.MethodPatch, "", handlers.UpdateTripEntryHandler(controller.TripController))
 	securedTripApiv1.Handle(http.MethodDelete, "", handlers.DeleteTripEntryHandler(controller.TripController))
 	securedTripApiv1.Handle(http.MethodPost, "/invite", handlers.InviteUserToTripHandler(controller.TripController))
 	securedTripApiv1.Handle(http.MethodPost, "/accept", handlers.AcceptTripInviteHandler(controller.TripController))
 	securedTripApiv1.Handle(http.MethodPost, "/decline", handlers.DeclineTripInviteHandler(controller.TripController))
+	securedTripApiv1.Handle(http.MethodPost, "/status", handlers.UpdateTripStatusHandler(controller.TripController))
+	securedTripApiv1.Handle(http.MethodPost, "/leave", handlers.LeaveTripHandler(controller.TripController))
+	securedTripApiv1.Handle(http.MethodGet, "/forecast", handlers.GetTripForecastHandler(controller.TripController))
+	securedTripApiv1.Handle(http.MethodGet, "/trash", handlers.GetTripTrashHandler(controller.TripController))
+	securedTripApiv1.Handle(http.MethodGet, "/activity", handlers.GetTripActivityHandler(controller.TripActivityController))
+	securedTripApiv1.Handle(http.MethodPatch, "/participants/me", costLock, handlers.UpdateOwnPresenceHandler(controller.TripController))
+	securedTripApiv1.Handle(http.MethodDelete, "/participants/:userId", handlers.RemoveTripParticipantHandler(controller.TripController))
+	securedTripApiv1.Handle(http.MethodPatch, "/participants/:userId/role", handlers.UpdateParticipantRoleHandler(controller.TripController))
+	securedTripApiv1.Handle(http.MethodPost, "/transfer-ownership", handlers.TransferTripOwnershipHandler(controller.TripController))
+	securedTripApiv1.Handle(http.MethodPost, "/guests", handlers.AddGuestToTripHandler(controller.TripController))
+	securedTripApiv1.Handle(http.MethodPost, "/invites", handlers.CreateTripInviteHandler(controller.TripController))
+	securedTripApiv1.Handle(http.MethodGet, "/invites", handlers.GetTripInvitesHandler(controller.TripController))
+	securedTripApiv1.Handle(http.MethodDelete, "/invites/:tripInviteId", handlers.RevokeTripInviteHandler(controller.TripController))
+	securedApiv1.Handle(http.MethodPost, "/invites/accept", handlers.JoinTripWithInviteHandler(controller.TripController))
+
+	// Trip Template Routes
+	securedTripApiv1.Handle(http.MethodPost, "/clone", handlers.CloneTripHandler(controller.TripTemplateController))
+	securedTripApiv1.Handle(http.MethodPost, "/templates", handlers.CreateTripTemplateHandler(controller.TripTemplateController))
+	securedApiv1.Handle(http.MethodGet, "/trip-templates", handlers.GetTripTemplatesHandler(controller.TripTemplateController))
+	securedApiv1.Handle(http.MethodDelete, "/trip-templates/:tripTemplateId", handlers.DeleteTripTemplateHandler(controller.TripTemplateController))
+	securedApiv1.Handle(http.MethodPost, "/trip-templates/:tripTemplateId/trips", handlers.CreateTripFromTemplateHandler(controller.TripTemplateController))
 
 	// Cost Category Routes
-	securedTripApiv1.Handle(http.MethodPost, "/cost-categories", handlers.CreateCostCategoryEntryHandler(controller.CostCategoryController))
+	securedApiv1.Handle(http.MethodGet, "/cost-category-presets", handlers.GetCostCategoryPresetsHandler(controller.CostCategoryController))
+	securedTripApiv1.Handle(http.MethodPost, "/cost-categories", costLock, handlers.CreateCostCategoryEntryHandler(controller.CostCategoryController))
 	securedTripApiv1.Handle(http.MethodGet, "/cost-categories", handlers.GetCostCategoryEntriesHandler(controller.CostCategoryController))
 	securedTripApiv1.Handle(http.MethodGet, "/cost-categories/:costCategoryId", handlers.GetCostCategoryDetailsHandler(controller.CostCategoryController))
-	securedTripApiv1.Handle(http.MethodPatch, "/cost-categories/:costCategoryId", handlers.UpdateCostCategoryEntryHandler(controller.CostCategoryController))
-	securedTripApiv1.Handle(http.MethodDelete, "/cost-categories/:costCategoryId", handlers.DeleteCostCategoryEntryHandler(controller.CostCategoryController))
+	securedTripApiv1.Handle(http.MethodPatch, "/cost-categories/:costCategoryId", costLock, handlers.UpdateCostCategoryEntryHandler(controller.CostCategoryController))
+	securedTripApiv1.Handle(http.MethodDelete, "/cost-categories/:costCategoryId", costLock, handlers.DeleteCostCategoryEntryHandler(controller.CostCategoryController))
+	securedTripApiv1.Handle(http.MethodPost, "/cost-categories/:costCategoryId/merge", costLock, handlers.MergeCostCategoryHandler(controller.CostCategoryController))
+	securedTripApiv1.Handle(http.MethodPost, "/cost-categories/:costCategoryId/restore", costLock, handlers.RestoreCostCategoryHandler(controller.CostCategoryController))
+
+	// Cost Category Rule Routes
+	securedTripApiv1.Handle(http.MethodPost, "/cost-category-rules", handlers.CreateCostCategoryRuleHandler(controller.CostCategoryRuleController))
+	securedTripApiv1.Handle(http.MethodGet, "/cost-category-rules", handlers.GetCostCategoryRulesHandler(controller.CostCategoryRuleController))
+	securedTripApiv1.Handle(http.MethodGet, "/cost-category-rules/test", handlers.TestCostCategoryRulesHandler(controller.CostCategoryRuleController))
+	securedTripApiv1.Handle(http.MethodPatch, "/cost-category-rules/:costCategoryRuleId", handlers.UpdateCostCategoryRuleHandler(controller.CostCategoryRuleController))
+	securedTripApiv1.Handle(http.MethodDelete, "/cost-category-rules/:costCategoryRuleId", handlers.DeleteCostCategoryRuleHandler(controller.CostCategoryRuleController))
 
 	// Cost Routes
 	securedApiv1.Handle(http.MethodGet, "/costs/overview", handlers.GetCostOverviewHandler(controller.CostController))
-	securedTripApiv1.Handle(http.MethodPost, "/costs", handlers.CreateCostEntryHandler(controller.CostController))
+	securedTripApiv1.Handle(http.MethodPost, "/costs", costLock, handlers.CreateCostEntryHandler(controller.CostController))
 	securedTripApiv1.Handle(http.MethodGet, "/costs", handlers.GetCostEntriesHandler(controller.CostController))
+	securedTripApiv1.Handle(http.MethodGet, "/costs/pending-approvals", handlers.GetCostsAwaitingApprovalHandler(controller.CostController))
 	securedTripApiv1.Handle(http.MethodGet, "/costs/:costId", handlers.GetCostDetailsHandler(controller.CostController))
-	securedTripApiv1.Handle(http.MethodPatch, "/costs/:costId", handlers.UpdateCostEntryHandler(controller.CostController))
-	securedTripApiv1.Handle(http.MethodDelete, "/costs/:costId", handlers.DeleteCostEntryHandler(controller.CostController))
+	securedTripApiv1.Handle(http.MethodPatch, "/costs/:costId", costLock, handlers.UpdateCostEntryHandler(controller.CostController))
+	securedTripApiv1.Handle(http.MethodDelete, "/costs/:costId", costLock, handlers.DeleteCostEntryHandler(controller.CostController))
+	securedTripApiv1.Handle(http.MethodPost, "/costs/:costId/restore", costLock, handlers.RestoreCostHandler(controller.CostController))
+	securedTripApiv1.Handle(http.MethodPost, "/costs/:costId/approve", financeLock, handlers.ApproveCostHandler(controller.CostController))
+	securedTripApiv1.Handle(http.MethodPost, "/costs/:costId/reject", financeLock, handlers.RejectCostHandler(controller.CostController))
+	securedTripApiv1.Handle(http.MethodPost, "/costs/:costId/suggestions", costLock, handlers.CreateCostSuggestionHandler(controller.CostController))
+	securedTripApiv1.Handle(http.MethodGet, "/costs/:costId/suggestions", handlers.GetCostSuggestionsHandler(controller.CostController))
+	securedTripApiv1.Handle(http.MethodPost, "/costs/:costId/suggestions/:costSuggestionId/approve", financeLock, handlers.ApproveCostSuggestionHandler(controller.CostController))
+	securedTripApiv1.Handle(http.MethodPost, "/costs/:costId/suggestions/:costSuggestionId/reject", financeLock, handlers.RejectCostSuggestionHandler(controller.CostController))
+	securedTripApiv1.Handle(http.MethodPost, "/costs/:costId/comments", handlers.CreateCostCommentHandler(controller.CostController))
+	securedTripApiv1.Handle(http.MethodGet, "/costs/:costId/comments", handlers.GetCostCommentsHandler(controller.CostController))
+	securedTripApiv1.Handle(http.MethodDelete, "/costs/:costId/comments/:costCommentId", handlers.DeleteCostCommentHandler(controller.CostController))
+	securedTripApiv1.Handle(http.MethodGet, "/costs/:costId/versions", handlers.GetCostVersionsHandler(controller.CostController))
+	securedTripApiv1.Handle(http.MethodPost, "/costs/:costId/versions/:costVersionId/revert", costLock, handlers.RevertCostVersionHandler(controller.CostController))
+
+	// Planned Cost Routes
+	securedTripApiv1.Handle(http.MethodPost, "/planned-costs", handlers.CreatePlannedCostHandler(controller.PlannedCostController))
+	securedTripApiv1.Handle(http.MethodGet, "/planned-costs", handlers.GetPlannedCostEntriesHandler(controller.PlannedCostController))
+	securedTripApiv1.Handle(http.MethodGet, "/planned-costs/comparison", handlers.GetCostComparisonHandler(controller.PlannedCostController))
+	securedTripApiv1.Handle(http.MethodGet, "/planned-costs/:plannedCostId", handlers.GetPlannedCostDetailsHandler(controller.PlannedCostController))
+	securedTripApiv1.Handle(http.MethodPatch, "/planned-costs/:plannedCostId", handlers.UpdatePlannedCostHandler(controller.PlannedCostController))
+	securedTripApiv1.Handle(http.MethodDelete, "/planned-costs/:plannedCostId", handlers.DeletePlannedCostHandler(controller.PlannedCostController))
+	securedTripApiv1.Handle(http.MethodPost, "/planned-costs/:plannedCostId/links", handlers.LinkPlannedCostHandler(controller.PlannedCostController))
+	securedTripApiv1.Handle(http.MethodDelete, "/planned-costs/:plannedCostId/links/:costId", handlers.UnlinkPlannedCostHandler(controller.PlannedCostController))
+
+	// Kitty Routes
+	securedTripApiv1.Handle(http.MethodGet, "/kitty", handlers.GetKittyHandler(controller.KittyController))
+	securedTripApiv1.Handle(http.MethodPost, "/kitty/deposits", financeLock, handlers.CreateKittyDepositHandler(controller.KittyController))
+	securedTripApiv1.Handle(http.MethodPost, "/kitty/settle", financeLock, handlers.SettleKittyHandler(controller.KittyController))
 
 	// Debts Routes
 	securedApiv1.Handle(http.MethodGet, "/debts/overview", handlers.GetDebtsOverviewHandler(controller.DebtController))
 	securedTripApiv1.Handle(http.MethodGet, "/debts", handlers.GetDebtsHandler(controller.DebtController))
 	securedTripApiv1.Handle(http.MethodGet, "/debts/:debtId", handlers.GetDebtDetailsHandler(controller.DebtController))
+	securedTripApiv1.Handle(http.MethodPost, "/debts/:debtId/forgive", financeLock, handlers.ForgiveDebtHandler(controller.DebtController))
 
 	// Transaction Routes
-	securedTripApiv1.Handle(http.MethodPost, "/transactions", handlers.CreateTransactionHandler(controller.TransactionController))
+	securedTripApiv1.Handle(http.MethodPost, "/transactions", financeLock, handlers.CreateTransactionHandler(controller.TransactionController))
 	securedTripApiv1.Handle(http.MethodGet, "/transactions", handlers.GetTransactionsHandler(controller.TransactionController))
 	securedApiv1.Handle(http.MethodGet, "/transactions", handlers.GetUserTransactionsHandler(controller.TransactionController))
+	securedApiv1.Handle(http.MethodPost, "/transactions/import", handlers.ImportBankStatementHandler(controller.TransactionController))
 	securedTripApiv1.Handle(http.MethodGet, "/transactions/:transactionId", handlers.GetTransactionDetailsHandler(controller.TransactionController))
-	securedTripApiv1.Handle(http.MethodDelete, "/transactions/:transactionId", handlers.DeleteTransactionHandler(controller.TransactionController))
-	securedTripApiv1.Handle(http.MethodPost, "/transactions/:transactionId/accept", handlers.AcceptTransaction(controller.TransactionController))
-	securedTripApiv1.Handle(http.MethodPost, "/transactions/:transactionId/decline", handlers.DeclineTransaction(controller.TransactionController))
+	securedTripApiv1.Handle(http.MethodDelete, "/transactions/:transactionId", financeLock, handlers.DeleteTransactionHandler(controller.TransactionController))
+	securedTripApiv1.Handle(http.MethodPost, "/transactions/:transactionId/accept", financeLock, handlers.AcceptTransaction(controller.TransactionController))
+	securedTripApiv1.Handle(http.MethodPost, "/transactions/:transactionId/decline", financeLock, handlers.DeclineTransaction(controller.TransactionController))
 	return router
 }
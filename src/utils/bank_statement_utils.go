@@ -0,0 +1,269 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const settlementReferencePrefix = "CV-"
+
+// GenerateSettlementReference returns the reference text users should put into the remittance information of a transfer
+// that settles the debt or transaction with the given id
+func GenerateSettlementReference(id *uuid.UUID) string {
+	return settlementReferencePrefix + strings.ToUpper(strings.ReplaceAll(id.String(), "-", "")[:8])
+}
+
+// ParseBankStatement detects the format of the statement and returns its booked entries
+func ParseBankStatement(data []byte) (string, []*models.BankStatementEntry, error) {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("<")) {
+		entries, err := ParseCamt053(trimmed)
+		return models.BankStatementFormatCamt053, entries, err
+	}
+
+	entries, err := ParseMT940(trimmed)
+	return models.BankStatementFormatMT940, entries, err
+}
+
+// camt053Document only maps the parts of the CAMT.053 schema that are needed for matching.
+// The namespace is omitted on purpose, so that all versions of the schema (camt.053.001.02 - .08) are accepted
+type camt053Document struct {
+	Statements []struct {
+		Entries []struct {
+			Amount struct {
+				Value    string `xml:",chardata"`
+				Currency string `xml:"Ccy,attr"`
+			} `xml:"Amt"`
+			CreditDebitIndicator string `xml:"CdtDbtInd"`
+			BookingDate          struct {
+				Date     string `xml:"Dt"`
+				DateTime string `xml:"DtTm"`
+			} `xml:"BookgDt"`
+			Details []struct {
+				Debtor       string   `xml:"RltdPties>Dbtr>Nm"`
+				Creditor     string   `xml:"RltdPties>Cdtr>Nm"`
+				Unstructured []string `xml:"RmtInf>Ustrd"`
+				Reference    string   `xml:"RmtInf>Strd>CdtrRefInf>Ref"`
+			} `xml:"NtryDtls>TxDtls"`
+			AdditionalInfo string `xml:"AddtlNtryInf"`
+		} `xml:"Ntry"`
+	} `xml:"BkToCstmrStmt>Stmt"`
+}
+
+// ParseCamt053 parses an ISO 20022 CAMT.053 bank to customer statement
+func ParseCamt053(data []byte) ([]*models.BankStatementEntry, error) {
+	var document camt053Document
+	if err := xml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("error parsing CAMT.053 document: %v", err)
+	}
+
+	entries := make([]*models.BankStatementEntry, 0)
+	for _, statement := range document.Statements {
+		for _, ntry := range statement.Entries {
+			amount, err := decimal.NewFromString(strings.TrimSpace(ntry.Amount.Value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid amount %q in CAMT.053 entry", ntry.Amount.Value)
+			}
+
+			entry := &models.BankStatementEntry{
+				Amount:         amount.Abs(),
+				CurrencyCode:   ntry.Amount.Currency,
+				Direction:      models.BankStatementDirectionIncoming,
+				RemittanceInfo: ntry.AdditionalInfo,
+			}
+
+			if ntry.CreditDebitIndicator == "DBIT" {
+				entry.Direction = models.BankStatementDirectionOutgoing
+			}
+
+			if bookingDate, err := time.Parse(time.DateOnly, ntry.BookingDate.Date); err == nil {
+				entry.BookingDate = &bookingDate
+			} else if bookingDate, err := time.Parse(time.RFC3339, ntry.BookingDate.DateTime); err == nil {
+				entry.BookingDate = &bookingDate
+			}
+
+			var remittance []string
+			for _, details := range ntry.Details {
+				// The counterparty is the debtor for incoming and the creditor for outgoing transfers
+				if entry.Direction == models.BankStatementDirectionIncoming && details.Debtor != "" {
+					entry.CounterpartyName = details.Debtor
+				} else if entry.Direction == models.BankStatementDirectionOutgoing && details.Creditor != "" {
+					entry.CounterpartyName = details.Creditor
+				}
+
+				remittance = append(remittance, details.Unstructured...)
+				if details.Reference != "" {
+					remittance = append(remittance, details.Reference)
+				}
+			}
+
+			if len(remittance) > 0 {
+				entry.RemittanceInfo = strings.Join(remittance, " ")
+			}
+
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// ParseMT940 parses a SWIFT MT940 customer statement message. Only the tags :60F:, :61: and :86: are evaluated.
+// Statements without any :61: statement line are rejected
+func ParseMT940(data []byte) ([]*models.BankStatementEntry, error) {
+	entries := make([]*models.BankStatementEntry, 0)
+	var currencyCode string
+	var current *models.BankStatementEntry
+	var currentTag string
+
+	// Fields can span multiple lines, so every line without a tag belongs to the previous tag
+	fields := make([][2]string, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.HasPrefix(line, ":") {
+			if end := strings.Index(line[1:], ":"); end > 0 {
+				currentTag = line[1 : end+1]
+				fields = append(fields, [2]string{currentTag, line[end+2:]})
+				continue
+			}
+		}
+
+		if len(fields) > 0 && line != "-" {
+			fields[len(fields)-1][1] += line
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading MT940 statement: %v", err)
+	}
+
+	// Anything that is neither CAMT.053 nor MT940 ends up here, so a statement without tags is rejected
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no MT940 tags found in statement")
+	}
+
+	for _, field := range fields {
+		tag, value := field[0], field[1]
+		switch tag {
+		case "60F", "60M":
+			// Opening balance, e.g. C230101EUR1234,56
+			if len(value) >= 10 {
+				currencyCode = value[7:10]
+			}
+		case "61":
+			entry, err := parseMT940StatementLine(value)
+			if err != nil {
+				return nil, err
+			}
+			entry.CurrencyCode = currencyCode
+			entries = append(entries, entry)
+			current = entry
+		case "86":
+			if current != nil {
+				current.CounterpartyName, current.RemittanceInfo = parseMT940Information(value)
+			}
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no :61: statement lines found in MT940 statement")
+	}
+
+	return entries, nil
+}
+
+// parseMT940StatementLine parses the value of a :61: tag, e.g. 2301020102D12,50NTRFNONREF//123
+func parseMT940StatementLine(value string) (*models.BankStatementEntry, error) {
+	if len(value) < 7 {
+		return nil, fmt.Errorf("invalid MT940 statement line %q", value)
+	}
+
+	bookingDate, err := time.Parse("060102", value[:6])
+	if err != nil {
+		return nil, fmt.Errorf("invalid MT940 value date %q", value[:6])
+	}
+	rest := value[6:]
+
+	// Optional entry date (MMDD)
+	if len(rest) >= 4 && isDigits(rest[:4]) {
+		rest = rest[4:]
+	}
+
+	entry := &models.BankStatementEntry{BookingDate: &bookingDate}
+	switch {
+	case strings.HasPrefix(rest, "RC"):
+		entry.Direction = models.BankStatementDirectionOutgoing // Reversal of credit
+		rest = rest[2:]
+	case strings.HasPrefix(rest, "RD"):
+		entry.Direction = models.BankStatementDirectionIncoming // Reversal of debit
+		rest = rest[2:]
+	case strings.HasPrefix(rest, "C"):
+		entry.Direction = models.BankStatementDirectionIncoming
+		rest = rest[1:]
+	case strings.HasPrefix(rest, "D"):
+		entry.Direction = models.BankStatementDirectionOutgoing
+		rest = rest[1:]
+	default:
+		return nil, fmt.Errorf("invalid MT940 debit/credit mark in %q", value)
+	}
+
+	// Optional funds code (third character of the currency code)
+	if len(rest) > 0 && rest[0] >= 'A' && rest[0] <= 'Z' {
+		rest = rest[1:]
+	}
+
+	end := strings.IndexFunc(rest, func(r rune) bool { return !(r >= '0' && r <= '9') && r != ',' })
+	if end <= 0 {
+		return nil, fmt.Errorf("invalid MT940 amount in %q", value)
+	}
+
+	amount, err := decimal.NewFromString(strings.Replace(rest[:end], ",", ".", 1))
+	if err != nil {
+		return nil, fmt.Errorf("invalid MT940 amount %q", rest[:end])
+	}
+	entry.Amount = amount
+
+	return entry, nil
+}
+
+// parseMT940Information extracts the counterparty name and the remittance information of a :86: tag.
+// Structured (German ZKA) information uses ?20 - ?29 for the remittance and ?32 - ?33 for the name of the counterparty
+func parseMT940Information(value string) (string, string) {
+	if !strings.Contains(value, "?") {
+		return "", strings.TrimSpace(value)
+	}
+
+	var name, remittance strings.Builder
+	for _, subfield := range strings.Split(value, "?")[1:] {
+		if len(subfield) < 2 {
+			continue
+		}
+
+		code, content := subfield[:2], subfield[2:]
+		switch {
+		case code >= "20" && code <= "29", code >= "60" && code <= "63":
+			remittance.WriteString(content)
+		case code == "32" || code == "33":
+			name.WriteString(content)
+		}
+	}
+
+	return strings.TrimSpace(name.String()), strings.TrimSpace(remittance.String())
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
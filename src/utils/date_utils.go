@@ -1,6 +1,10 @@
 package utils
 
-import "time"
+import (
+	"log"
+	"os"
+	"time"
+)
 
 func IsValidDate(layout string, date ...string) bool {
 	for _, d := range date {
@@ -13,3 +17,19 @@ func IsValidDate(layout string, date ...string) bool {
 	}
 	return true
 }
+
+// GetDurationFromEnv reads a duration like "72h" from the environment and falls back to the default if it is unset or invalid
+func GetDurationFromEnv(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		log.Printf("Invalid duration %q for %s, using default of %v", value, key, fallback)
+		return fallback
+	}
+
+	return duration
+}
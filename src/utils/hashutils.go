@@ -1,6 +1,13 @@
 package utils
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
 
 func HashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
@@ -11,3 +18,25 @@ func CheckPasswordHash(password, hash string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
 }
+
+// GenerateSignedToken creates a random token of the given length and appends its HMAC signature
+func GenerateSignedToken(length int) string {
+	value := GenerateRandomString(length)
+	return value + "." + signValue(value)
+}
+
+// VerifySignedToken checks that the token was created by GenerateSignedToken and was not tampered with
+func VerifySignedToken(token string) bool {
+	value, signature, found := strings.Cut(token, ".")
+	if !found || value == "" {
+		return false
+	}
+
+	return hmac.Equal([]byte(signature), []byte(signValue(value)))
+}
+
+func signValue(value string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
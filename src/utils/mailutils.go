@@ -139,3 +139,154 @@ func PreparePasswordResetConfirmationMailBody(email string) string {
 
 	return emailBody
 }
+
+func PrepareBudgetAlertMailBody(username, tripName, costCategoryName, budget, spent, percentUsed string) string {
+	hermesMail := hermes.Email{
+		Body: hermes.Body{
+			Name: username,
+			Intros: []string{
+				fmt.Sprintf("The cost category \"%v\" of your trip \"%v\" has reached %v%% of its budget.", costCategoryName, tripName, percentUsed),
+			},
+			Dictionary: []hermes.Entry{
+				{Key: "Budget", Value: budget},
+				{Key: "Spent", Value: spent},
+			},
+			Actions: []hermes.Action{
+				{
+					Instructions: "To check the costs of your trip please click here:",
+					Button: hermes.Button{
+						Color: "#22BC66",
+						Text:  "Go to Costventures",
+						Link:  "https://costventures.works",
+					},
+				},
+			},
+			Outros: []string{
+				"You receive this email because you are a participant of this trip.",
+			},
+		},
+	}
+
+	emailBody, err := h.GenerateHTML(hermesMail)
+	if err != nil {
+		log.Printf("Error in utils.prepareBudgetAlertMailBody().GenerateHTML(): %v", err.Error())
+		return ""
+	}
+
+	return emailBody
+}
+
+func PrepareTripInviteMailBody(inviterName, tripName, inviteLink string) string {
+	hermesMail := hermes.Email{
+		Body: hermes.Body{
+			Intros: []string{
+				fmt.Sprintf("%v invited you to join the trip \"%v\" on Costventures.", inviterName, tripName),
+			},
+			Actions: []hermes.Action{
+				{
+					Instructions: "Create your account through this link to join the trip right away:",
+					Button: hermes.Button{
+						Color: "#22BC66",
+						Text:  "Join the trip",
+						Link:  inviteLink,
+					},
+				},
+			},
+			Outros: []string{
+				"If you did not expect this invitation, you can simply ignore this email.",
+			},
+		},
+	}
+
+	emailBody, err := h.GenerateHTML(hermesMail)
+	if err != nil {
+		log.Printf("Error in utils.prepareTripInviteMailBody().GenerateHTML(): %v", err.Error())
+		return ""
+	}
+
+	return emailBody
+}
+
+func PrepareTripInvitationMailBody(username, inviterName, tripName, startDate, endDate, expiresAt string) string {
+	return prepareTripInvitationMailBody(username, tripName, startDate, endDate, expiresAt,
+		fmt.Sprintf("%v invited you to join the trip \"%v\" on Costventures.", inviterName, tripName))
+}
+
+func PrepareTripInvitationReminderMailBody(username, inviterName, tripName, startDate, endDate, expiresAt string) string {
+	return prepareTripInvitationMailBody(username, tripName, startDate, endDate, expiresAt,
+		fmt.Sprintf("Just a reminder: %v is still waiting for you to join the trip \"%v\" on Costventures.", inviterName, tripName))
+}
+
+func prepareTripInvitationMailBody(username, tripName, startDate, endDate, expiresAt, intro string) string {
+	hermesMail := hermes.Email{
+		Body: hermes.Body{
+			Name: username,
+			Intros: []string{
+				intro,
+			},
+			Dictionary: []hermes.Entry{
+				{Key: "Trip", Value: tripName},
+				{Key: "Start", Value: startDate},
+				{Key: "End", Value: endDate},
+				{Key: "Invitation expires", Value: expiresAt},
+			},
+			Actions: []hermes.Action{
+				{
+					Instructions: "To accept or decline the invitation please click here:",
+					Button: hermes.Button{
+						Color: "#22BC66",
+						Text:  "Go to Costventures",
+						Link:  "https://costventures.works",
+					},
+				},
+			},
+			Outros: []string{
+				"If you do not respond, the invitation expires automatically.",
+			},
+		},
+	}
+
+	emailBody, err := h.GenerateHTML(hermesMail)
+	if err != nil {
+		log.Printf("Error in utils.prepareTripInvitationMailBody().GenerateHTML(): %v", err.Error())
+		return ""
+	}
+
+	return emailBody
+}
+
+func PrepareCostCommentMentionMailBody(username, authorName, tripName, costDescription, comment string) string {
+	hermesMail := hermes.Email{
+		Body: hermes.Body{
+			Name: username,
+			Intros: []string{
+				fmt.Sprintf("%v mentioned you in a comment on a cost of your trip \"%v\".", authorName, tripName),
+			},
+			Dictionary: []hermes.Entry{
+				{Key: "Cost", Value: costDescription},
+				{Key: "Comment", Value: comment},
+			},
+			Actions: []hermes.Action{
+				{
+					Instructions: "To reply to the comment please click here:",
+					Button: hermes.Button{
+						Color: "#22BC66",
+						Text:  "Go to Costventures",
+						Link:  "https://costventures.works",
+					},
+				},
+			},
+			Outros: []string{
+				"You receive this email because you are a participant of this trip.",
+			},
+		},
+	}
+
+	emailBody, err := h.GenerateHTML(hermesMail)
+	if err != nil {
+		log.Printf("Error in utils.prepareCostCommentMentionMailBody().GenerateHTML(): %v", err.Error())
+		return ""
+	}
+
+	return emailBody
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"github.com/Travel-Utilities-WWI21SEB/expense-management-service/src/models"
+)
+
+// tripRolePermissions maps each trip role to the permissions it grants
+var tripRolePermissions = map[string][]string{
+	models.TripRoleOwner: {
+		models.TripPermissionEditTrip,
+		models.TripPermissionDeleteTrip,
+		models.TripPermissionManageParticipants,
+		models.TripPermissionManageCostCategories,
+		models.TripPermissionWriteCosts,
+		models.TripPermissionManageCosts,
+	},
+	models.TripRoleAdmin: {
+		models.TripPermissionEditTrip,
+		models.TripPermissionManageParticipants,
+		models.TripPermissionManageCostCategories,
+		models.TripPermissionWriteCosts,
+		models.TripPermissionManageCosts,
+	},
+	models.TripRoleMember: {
+		models.TripPermissionWriteCosts,
+	},
+	models.TripRoleViewer: {},
+}
+
+// IsValidTripRole checks if the given role is one of the known trip roles
+func IsValidTripRole(role string) bool {
+	_, ok := tripRolePermissions[role]
+	return ok
+}
+
+// TripRoleHasPermission checks if the given trip role grants the permission
+func TripRoleHasPermission(role string, permission string) bool {
+	for _, p := range tripRolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+
+	return false
+}